@@ -36,6 +36,8 @@ func Command() *cobra.Command {
 		createCommand(),
 		lsCommand(),
 		rmCommand(),
+		exportCommand(),
+		importCommand(),
 	)
 
 	return cmd
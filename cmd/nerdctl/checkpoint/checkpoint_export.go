@@ -0,0 +1,116 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package checkpoint
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/mattn/go-isatty"
+	"github.com/spf13/cobra"
+
+	"github.com/containerd/log"
+
+	"github.com/containerd/nerdctl/v2/cmd/nerdctl/completion"
+	"github.com/containerd/nerdctl/v2/cmd/nerdctl/helpers"
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/clientutil"
+	"github.com/containerd/nerdctl/v2/pkg/cmd/checkpoint"
+)
+
+func exportCommand() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:               "export [OPTIONS] CONTAINER CHECKPOINT",
+		Short:             "Export a checkpoint to a tar archive (streamed to STDOUT by default)",
+		Long:              "The archive contains the checkpoint, the configuration and the rootfs diff of the container, so that the container can be restored on another host with `nerdctl container create --from-checkpoint`.",
+		Args:              cobra.ExactArgs(2),
+		RunE:              exportAction,
+		ValidArgsFunction: exportShellComplete,
+		SilenceUsage:      true,
+		SilenceErrors:     true,
+	}
+	cmd.Flags().StringP("output", "o", "", "Write to a file, instead of STDOUT")
+	cmd.Flags().String("checkpoint-dir", "", "Checkpoint directory")
+	return cmd
+}
+
+func processExportFlags(cmd *cobra.Command) (types.CheckpointExportOptions, error) {
+	globalOptions, err := helpers.ProcessRootCmdFlags(cmd)
+	if err != nil {
+		return types.CheckpointExportOptions{}, err
+	}
+
+	checkpointDir, err := cmd.Flags().GetString("checkpoint-dir")
+	if err != nil {
+		return types.CheckpointExportOptions{}, err
+	}
+	if checkpointDir == "" {
+		checkpointDir = filepath.Join(globalOptions.DataRoot, "checkpoints")
+	}
+
+	return types.CheckpointExportOptions{
+		GOptions:      globalOptions,
+		CheckpointDir: checkpointDir,
+	}, nil
+}
+
+func exportAction(cmd *cobra.Command, args []string) error {
+	options, err := processExportFlags(cmd)
+	if err != nil {
+		return err
+	}
+
+	output := cmd.OutOrStdout()
+	outputPath, err := cmd.Flags().GetString("output")
+	if err != nil {
+		return err
+	} else if outputPath != "" {
+		f, err := os.OpenFile(outputPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+		if err != nil {
+			return err
+		}
+		output = f
+		defer func() {
+			if err := f.Sync(); err != nil {
+				f.Close()
+				log.G(context.Background()).Error(err)
+				return
+			}
+			f.Close()
+		}()
+	} else if out, ok := output.(*os.File); ok && isatty.IsTerminal(out.Fd()) {
+		return fmt.Errorf("cowardly refusing to export to a terminal. Use the -o flag or redirect")
+	}
+	options.Stdout = output
+
+	client, ctx, cancel, err := clientutil.NewClient(cmd.Context(), options.GOptions.Namespace, options.GOptions.Address)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	if err = checkpoint.Export(ctx, client, args[0], args[1], options); err != nil && outputPath != "" {
+		os.Remove(outputPath)
+	}
+	return err
+}
+
+func exportShellComplete(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return completion.ContainerNames(cmd, nil)
+}
@@ -0,0 +1,104 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package checkpoint
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/containerd/nerdctl/mod/tigron/expect"
+	"github.com/containerd/nerdctl/mod/tigron/require"
+	"github.com/containerd/nerdctl/mod/tigron/test"
+
+	"github.com/containerd/nerdctl/v2/pkg/testutil"
+	"github.com/containerd/nerdctl/v2/pkg/testutil/nerdtest"
+)
+
+func TestCheckpointExportImport(t *testing.T) {
+	const checkpointName = "checkpoint-export"
+	testCase := nerdtest.Setup()
+	testCase.Require = require.All(
+		require.Not(nerdtest.Rootless),
+		// `nerdctl checkpoint export` and `nerdctl checkpoint import` are not implemented in Docker.
+		require.Not(nerdtest.Docker),
+	)
+	testCase.NoParallel = true
+
+	testCase.Setup = func(data test.Data, helpers test.Helpers) {
+		data.Temp().Save("bind-mount", "bind", "file")
+		helpers.Ensure("run", "-d", "--name", data.Identifier(),
+			"-v", data.Temp().Path("bind")+":/mnt/bind:ro", "--tmpfs", "/mnt/tmpfs",
+			testutil.CommonImage, "sh", "-c", "echo rootfs-diff > /state; sleep infinity")
+		nerdtest.EnsureContainerStarted(helpers, data.Identifier())
+		helpers.Ensure("checkpoint", "create", "--checkpoint-dir", data.Temp().Path("src"), data.Identifier(), checkpointName)
+		helpers.Ensure("checkpoint", "export", "--checkpoint-dir", data.Temp().Path("src"), "-o", data.Temp().Path("checkpoint.tar"), data.Identifier(), checkpointName)
+		helpers.Ensure("rm", "-f", data.Identifier())
+	}
+
+	testCase.Cleanup = func(data test.Data, helpers test.Helpers) {
+		helpers.Anyhow("rm", "-f", data.Identifier())
+	}
+
+	testCase.SubTests = []*test.Case{
+		{
+			Description: "import",
+			NoParallel:  true,
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("checkpoint", "import", "--checkpoint-dir", data.Temp().Path("imported"), "-i", data.Temp().Path("checkpoint.tar"))
+			},
+			Expected: test.Expects(expect.ExitCodeSuccess, nil, expect.Equals(checkpointName+"\n")),
+		},
+		{
+			Description: "create from checkpoint with a different mount",
+			NoParallel:  true,
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("create", "--from-checkpoint", data.Temp().Path("checkpoint.tar"), "--checkpoint-dir", data.Temp().Path("dst"),
+					"-v", data.Temp().Path("bind")+":/mnt/other")
+			},
+			Expected: test.Expects(expect.ExitCodeGenericFail, []error{errors.New("cannot be specified with --from-checkpoint")}, nil),
+		},
+		{
+			Description: "create from checkpoint",
+			NoParallel:  true,
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("create", "--from-checkpoint", data.Temp().Path("checkpoint.tar"), "--checkpoint-dir", data.Temp().Path("dst"))
+			},
+			Expected: test.Expects(expect.ExitCodeSuccess, nil, nil),
+		},
+		{
+			Description: "restore the created container",
+			NoParallel:  true,
+			Setup: func(data test.Data, helpers test.Helpers) {
+				helpers.Ensure("start", "--checkpoint", checkpointName, "--checkpoint-dir", data.Temp().Path("dst"), data.Identifier())
+			},
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("exec", data.Identifier(), "cat", "/state")
+			},
+			Expected: test.Expects(expect.ExitCodeSuccess, nil, expect.Equals("rootfs-diff\n")),
+		},
+		{
+			Description: "the mounts are restored",
+			NoParallel:  true,
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("exec", data.Identifier(), "sh", "-c", "cat /mnt/bind/file && grep -c ' /mnt/tmpfs tmpfs ' /proc/mounts")
+			},
+			Expected: test.Expects(expect.ExitCodeSuccess, nil, expect.Equals("bind-mount\n1\n")),
+		},
+	}
+
+	testCase.Run(t)
+}
@@ -0,0 +1,77 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package checkpoint
+
+import (
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/containerd/nerdctl/v2/cmd/nerdctl/helpers"
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/cmd/checkpoint"
+)
+
+func importCommand() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:           "import [OPTIONS]",
+		Short:         "Import a checkpoint from a tar archive created by `nerdctl checkpoint export` (read from STDIN by default)",
+		Long:          "The imported checkpoint can be restored with `nerdctl start --checkpoint` on a container created with the same configuration.\nTo recreate the container too, use `nerdctl container create --from-checkpoint` instead.",
+		Args:          cobra.NoArgs,
+		RunE:          importAction,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+	cmd.Flags().StringP("input", "i", "", "Read from tar archive file, instead of STDIN")
+	cmd.Flags().String("checkpoint-dir", "", "Checkpoint directory")
+	return cmd
+}
+
+func processImportFlags(cmd *cobra.Command) (types.CheckpointImportOptions, error) {
+	globalOptions, err := helpers.ProcessRootCmdFlags(cmd)
+	if err != nil {
+		return types.CheckpointImportOptions{}, err
+	}
+
+	input, err := cmd.Flags().GetString("input")
+	if err != nil {
+		return types.CheckpointImportOptions{}, err
+	}
+	checkpointDir, err := cmd.Flags().GetString("checkpoint-dir")
+	if err != nil {
+		return types.CheckpointImportOptions{}, err
+	}
+	if checkpointDir == "" {
+		checkpointDir = filepath.Join(globalOptions.DataRoot, "checkpoints")
+	}
+
+	return types.CheckpointImportOptions{
+		Stdout:        cmd.OutOrStdout(),
+		Stdin:         cmd.InOrStdin(),
+		GOptions:      globalOptions,
+		CheckpointDir: checkpointDir,
+		Input:         input,
+	}, nil
+}
+
+func importAction(cmd *cobra.Command, args []string) error {
+	options, err := processImportFlags(cmd)
+	if err != nil {
+		return err
+	}
+	return checkpoint.Import(cmd.Context(), options)
+}
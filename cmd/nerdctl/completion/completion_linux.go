@@ -46,3 +46,7 @@ func CgroupManagerNames(cmd *cobra.Command, args []string, toComplete string) ([
 	}
 	return candidates, cobra.ShellCompDirectiveNoFileComp
 }
+
+func FirewallBackendNames(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return []string{"iptables", "nftables"}, cobra.ShellCompDirectiveNoFileComp
+}
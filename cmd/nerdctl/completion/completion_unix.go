@@ -52,6 +52,10 @@ func NetworkOptions(cmd *cobra.Command, args []string, toComplete string) ([]str
 			"com.docker.network.driver.mtu=",
 			"ip-masq=",
 			"com.docker.network.bridge.enable_ip_masquerade=",
+			"ingress-rate=",
+			"ingress-burst=",
+			"egress-rate=",
+			"egress-burst=",
 		}
 	case "macvlan":
 		candidates = []string{
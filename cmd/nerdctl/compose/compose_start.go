@@ -90,6 +90,11 @@ func startAction(cmd *cobra.Command, args []string) error {
 			return fmt.Errorf("service %q has no container to start", svcName)
 		}
 
+		// The secrets and the configs on tmpfs are lost when the host reboots
+		if err := c.RestoreContainerFiles(ctx, svc.Containers); err != nil {
+			return err
+		}
+
 		if err := startContainers(ctx, client, containers, &globalOptions, nerdctlCmd, nerdctlArgs); err != nil {
 			return err
 		}
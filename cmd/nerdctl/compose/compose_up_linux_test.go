@@ -17,6 +17,7 @@
 package compose
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"path/filepath"
@@ -1315,6 +1316,207 @@ services:
 	testCase.Run(t)
 }
 
+func TestComposeUpDependsOnConditions(t *testing.T) {
+	testCase := nerdtest.Setup()
+
+	// healthchecks are run by systemd timers
+	testCase.Require = require.Not(nerdtest.Rootless)
+
+	testCase.SubTests = []*test.Case{
+		{
+			Description: "dependent service is started after the dependencies are healthy and completed",
+			Setup: func(data test.Data, helpers test.Helpers) {
+				composeYAML := fmt.Sprintf(`
+services:
+  init:
+    image: %[1]s
+    command: "true"
+  db:
+    image: %[1]s
+    command: sleep infinity
+    healthcheck:
+      test: ["CMD", "true"]
+      interval: 1s
+  app:
+    image: %[1]s
+    command: sleep infinity
+    depends_on:
+      init:
+        condition: service_completed_successfully
+      db:
+        condition: service_healthy
+`, testutil.CommonImage)
+				composePath := data.Temp().Save(composeYAML, "compose.yaml")
+				projectName := filepath.Base(filepath.Dir(composePath))
+				data.Labels().Set("composePath", composePath)
+				data.Labels().Set("dbContainer", serviceparser.DefaultContainerName(projectName, "db", "1"))
+				data.Labels().Set("appContainer", serviceparser.DefaultContainerName(projectName, "app", "1"))
+			},
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("compose", "-f", data.Labels().Get("composePath"), "up", "-d")
+			},
+			Expected: func(data test.Data, helpers test.Helpers) *test.Expected {
+				return &test.Expected{
+					ExitCode: expect.ExitCodeSuccess,
+					Output: func(stdout string, t tig.T) {
+						db := nerdtest.InspectContainer(helpers, data.Labels().Get("dbContainer"))
+						assert.Assert(t, db.State.Health != nil)
+						assert.Equal(t, db.State.Health.Status, "healthy")
+						app := nerdtest.InspectContainer(helpers, data.Labels().Get("appContainer"))
+						assert.Equal(t, app.State.Status, "running")
+					},
+				}
+			},
+			Cleanup: func(data test.Data, helpers test.Helpers) {
+				helpers.Anyhow("compose", "-f", data.Temp().Path("compose.yaml"), "down")
+			},
+		},
+		{
+			Description: "dependent service is not started when a dependency fails",
+			Setup: func(data test.Data, helpers test.Helpers) {
+				composeYAML := fmt.Sprintf(`
+services:
+  init:
+    image: %[1]s
+    command: "false"
+  app:
+    image: %[1]s
+    command: sleep infinity
+    depends_on:
+      init:
+        condition: service_completed_successfully
+`, testutil.CommonImage)
+				data.Temp().Save(composeYAML, "compose.yaml")
+			},
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("compose", "-f", data.Temp().Path("compose.yaml"), "up", "-d")
+			},
+			Expected: test.Expects(expect.ExitCodeGenericFail, []error{errors.New("exited with status 1")}, nil),
+			Cleanup: func(data test.Data, helpers test.Helpers) {
+				helpers.Anyhow("compose", "-f", data.Temp().Path("compose.yaml"), "down")
+			},
+		},
+		{
+			Description: "optional dependency does not block the dependent service",
+			Setup: func(data test.Data, helpers test.Helpers) {
+				composeYAML := fmt.Sprintf(`
+services:
+  init:
+    image: %[1]s
+    command: "false"
+  app:
+    image: %[1]s
+    command: sleep infinity
+    depends_on:
+      init:
+        condition: service_completed_successfully
+        required: false
+`, testutil.CommonImage)
+				data.Temp().Save(composeYAML, "compose.yaml")
+			},
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("compose", "-f", data.Temp().Path("compose.yaml"), "up", "-d")
+			},
+			Expected: test.Expects(expect.ExitCodeSuccess, nil, nil),
+			Cleanup: func(data test.Data, helpers test.Helpers) {
+				helpers.Anyhow("compose", "-f", data.Temp().Path("compose.yaml"), "down")
+			},
+		},
+	}
+
+	testCase.Run(t)
+}
+
+func TestComposeUpSecretsAndConfigs(t *testing.T) {
+	testCase := nerdtest.Setup()
+
+	testCase.Env = map[string]string{
+		"COMPOSE_TEST_SECRET": "content-secret2",
+	}
+
+	testCase.Setup = func(data test.Data, helpers test.Helpers) {
+		containerName := data.Identifier("secrets")
+		composeYAML := fmt.Sprintf(`
+services:
+  svc0:
+    container_name: %s
+    image: %s
+    command: sleep infinity
+    secrets:
+      - secret1
+      - source: secret1
+        target: /etc/secret1
+        mode: 0400
+      - source: secret2
+        uid: "1000"
+        gid: "1001"
+        mode: 0400
+    configs:
+      - source: config1
+        target: /etc/config1
+        mode: 0440
+secrets:
+  secret1:
+    file: ./secret1
+  secret2:
+    environment: COMPOSE_TEST_SECRET
+configs:
+  config1:
+    content: content-config1
+`, containerName, testutil.CommonImage)
+
+		data.Temp().Save("content-secret1", "secret1")
+		composeYAMLPath := data.Temp().Save(composeYAML, "compose.yaml")
+
+		helpers.Ensure("compose", "-f", composeYAMLPath, "up", "-d")
+		nerdtest.EnsureContainerStarted(helpers, containerName)
+
+		data.Labels().Set("composeYAML", composeYAMLPath)
+		data.Labels().Set("containerName", containerName)
+	}
+
+	testCase.SubTests = []*test.Case{
+		{
+			Description: "secret from file",
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("exec", data.Labels().Get("containerName"),
+					"sh", "-c", "cat /run/secrets/secret1; stat -c ' %u:%g %a' /run/secrets/secret1")
+			},
+			Expected: test.Expects(0, nil, expect.Equals("content-secret1 0:0 444\n")),
+		},
+		{
+			Description: "secret mounted to another target with another mode",
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("exec", data.Labels().Get("containerName"),
+					"sh", "-c", "cat /etc/secret1; stat -c ' %u:%g %a' /etc/secret1")
+			},
+			Expected: test.Expects(0, nil, expect.Equals("content-secret1 0:0 400\n")),
+		},
+		{
+			Description: "secret from environment with uid, gid and mode",
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("exec", data.Labels().Get("containerName"),
+					"sh", "-c", "cat /run/secrets/secret2; stat -c ' %u:%g %a' /run/secrets/secret2")
+			},
+			Expected: test.Expects(0, nil, expect.Equals("content-secret2 1000:1001 400\n")),
+		},
+		{
+			Description: "config from content with mode",
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("exec", data.Labels().Get("containerName"),
+					"sh", "-c", "cat /etc/config1; stat -c ' %u:%g %a' /etc/config1")
+			},
+			Expected: test.Expects(0, nil, expect.Equals("content-config1 0:0 440\n")),
+		},
+	}
+
+	testCase.Cleanup = func(data test.Data, helpers test.Helpers) {
+		helpers.Anyhow("compose", "-f", data.Labels().Get("composeYAML"), "down")
+	}
+
+	testCase.Run(t)
+}
+
 func TestComposeUpHealthcheck(t *testing.T) {
 	testCase := nerdtest.Setup()
 
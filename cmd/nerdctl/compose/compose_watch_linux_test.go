@@ -0,0 +1,97 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/containerd/nerdctl/mod/tigron/expect"
+	"github.com/containerd/nerdctl/mod/tigron/test"
+
+	"github.com/containerd/nerdctl/v2/pkg/testutil"
+	"github.com/containerd/nerdctl/v2/pkg/testutil/nerdtest"
+)
+
+func TestComposeWatchSync(t *testing.T) {
+	var dockerComposeYAML = fmt.Sprintf(`
+services:
+  svc0:
+    image: %s
+    command: "sleep infinity"
+    develop:
+      watch:
+        - path: ./src
+          action: sync
+          target: /app
+          ignore:
+            - "*.tmp"
+`, testutil.CommonImage)
+
+	testCase := nerdtest.Setup()
+
+	var watch test.TestableCommand
+
+	testCase.Setup = func(data test.Data, helpers test.Helpers) {
+		data.Temp().Save("initial", "src", "foo.txt")
+		compYamlPath := data.Temp().Save(dockerComposeYAML, "compose.yaml")
+		data.Labels().Set("composeYaml", compYamlPath)
+		helpers.Ensure("compose", "-f", compYamlPath, "up", "-d")
+
+		watch = helpers.Command("compose", "-f", compYamlPath, "watch", "--no-up")
+		watch.WithTimeout(60 * time.Second)
+		watch.Background()
+		// let the watcher start
+		time.Sleep(3 * time.Second)
+
+		data.Temp().Save("updated", "src", "foo.txt")
+		data.Temp().Save("ignored", "src", "bar.tmp")
+		data.Temp().Save("new", "src", "sub", "baz.txt")
+		// let the watcher debounce and sync the changes
+		time.Sleep(5 * time.Second)
+	}
+
+	testCase.Cleanup = func(data test.Data, helpers test.Helpers) {
+		if watch != nil {
+			watch.Signal(os.Interrupt)
+		}
+		helpers.Anyhow("compose", "-f", data.Temp().Path("compose.yaml"), "down", "-v")
+	}
+
+	testCase.SubTests = []*test.Case{
+		{
+			Description: "updated file is synced",
+			NoParallel:  true,
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("compose", "-f", data.Labels().Get("composeYaml"), "exec", "-T", "svc0", "cat", "/app/foo.txt", "/app/sub/baz.txt")
+			},
+			Expected: test.Expects(expect.ExitCodeSuccess, nil, expect.Equals("updatednew")),
+		},
+		{
+			Description: "ignored file is not synced",
+			NoParallel:  true,
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("compose", "-f", data.Labels().Get("composeYaml"), "exec", "-T", "svc0", "ls", "/app")
+			},
+			Expected: test.Expects(expect.ExitCodeSuccess, nil, expect.DoesNotContain("bar.tmp")),
+		},
+	}
+
+	testCase.Run(t)
+}
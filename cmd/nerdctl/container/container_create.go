@@ -17,12 +17,17 @@
 package container
 
 import (
+	"context"
 	"fmt"
+	"path/filepath"
 	"runtime"
 
 	"github.com/spf13/cobra"
 	cdiparser "tags.cncf.io/container-device-interface/pkg/parser"
 
+	containerd "github.com/containerd/containerd/v2/client"
+	"github.com/containerd/errdefs"
+
 	"github.com/containerd/nerdctl/v2/cmd/nerdctl/helpers"
 	"github.com/containerd/nerdctl/v2/pkg/api/types"
 	"github.com/containerd/nerdctl/v2/pkg/clientutil"
@@ -43,7 +48,7 @@ func CreateCommand() *cobra.Command {
 	}
 	var cmd = &cobra.Command{
 		Use:               "create [flags] IMAGE [COMMAND] [ARG...]",
-		Args:              cobra.MinimumNArgs(1),
+		Args:              createArgs,
 		Short:             shortHelp,
 		Long:              longHelp,
 		RunE:              createAction,
@@ -53,9 +58,48 @@ func CreateCommand() *cobra.Command {
 	}
 	cmd.Flags().SetInterspersed(false)
 	setCreateFlags(cmd)
+	cmd.Flags().String("from-checkpoint", "", "Create the container from a checkpoint archive created by `nerdctl checkpoint export`, to be restored with `nerdctl start --checkpoint`")
+	cmd.Flags().String("checkpoint-dir", "", "Checkpoint directory to import the checkpoint of --from-checkpoint into")
 	return cmd
 }
 
+func createArgs(cmd *cobra.Command, args []string) error {
+	if cmd.Flags().Changed("from-checkpoint") {
+		// IMAGE and COMMAND are taken from the checkpoint archive
+		return cobra.NoArgs(cmd, args)
+	}
+	return cobra.MinimumNArgs(1)(cmd, args)
+}
+
+// ParseCreateArgs parses the flags and the arguments of `nerdctl create` in-process, with the global flags of root.
+// It returns the options of container.Create and the arguments for the image and the command.
+// It is used by `nerdctl system serve`, so that the defaults of the options are resolved by the flags as in the CLI.
+func ParseCreateArgs(root *cobra.Command, args []string) (types.ContainerCreateOptions, types.NetworkOptions, []string, error) {
+	parent := &cobra.Command{Use: root.Name()}
+	parent.PersistentFlags().AddFlagSet(root.PersistentFlags())
+	cmd := CreateCommand()
+	parent.AddCommand(cmd)
+	if err := cmd.ParseFlags(args); err != nil {
+		return types.ContainerCreateOptions{}, types.NetworkOptions{}, nil, fmt.Errorf("%w: %w", err, errdefs.ErrInvalidArgument)
+	}
+	if cmd.Flags().Changed("from-checkpoint") {
+		return types.ContainerCreateOptions{}, types.NetworkOptions{}, nil, fmt.Errorf("--from-checkpoint is not supported: %w", errdefs.ErrInvalidArgument)
+	}
+	args = cmd.Flags().Args()
+	if err := cmd.ValidateArgs(args); err != nil {
+		return types.ContainerCreateOptions{}, types.NetworkOptions{}, nil, fmt.Errorf("%w: %w", err, errdefs.ErrInvalidArgument)
+	}
+	createOpt, err := createOptions(cmd)
+	if err != nil {
+		return types.ContainerCreateOptions{}, types.NetworkOptions{}, nil, fmt.Errorf("%w: %w", err, errdefs.ErrInvalidArgument)
+	}
+	netFlags, err := loadNetworkFlags(cmd, createOpt.GOptions)
+	if err != nil {
+		return types.ContainerCreateOptions{}, types.NetworkOptions{}, nil, fmt.Errorf("failed to load networking flags: %w: %w", err, errdefs.ErrInvalidArgument)
+	}
+	return createOpt, netFlags, args, nil
+}
+
 //revive:disable:function-length
 func createOptions(cmd *cobra.Command) (types.ContainerCreateOptions, error) {
 	var err error
@@ -279,6 +323,14 @@ func createOptions(cmd *cobra.Command) (types.ContainerCreateOptions, error) {
 	if err != nil {
 		return opt, err
 	}
+	opt.HealthStartInterval, err = cmd.Flags().GetDuration("health-start-interval")
+	if err != nil {
+		return opt, err
+	}
+	opt.HealthOnFailure, err = cmd.Flags().GetString("health-on-failure")
+	if err != nil {
+		return opt, err
+	}
 	opt.NoHealthcheck, err = cmd.Flags().GetBool("no-healthcheck")
 	if err != nil {
 		return opt, err
@@ -540,6 +592,10 @@ func createAction(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to load networking flags: %w", err)
 	}
 
+	if cmd.Flags().Changed("from-checkpoint") {
+		return createFromCheckpoint(ctx, cmd, client, createOpt, netFlags)
+	}
+
 	netManager, err := containerutil.NewNetworkingOptionsManager(createOpt.GOptions, netFlags, client)
 	if err != nil {
 		return err
@@ -562,3 +618,36 @@ func createAction(cmd *cobra.Command, args []string) error {
 	fmt.Fprintln(createOpt.Stdout, c.ID())
 	return nil
 }
+
+// createFromCheckpoint creates the container from the checkpoint archive of --from-checkpoint.
+// The options that are not set by the flags are taken from the checkpointed container.
+func createFromCheckpoint(ctx context.Context, cmd *cobra.Command, client *containerd.Client, createOpt types.ContainerCreateOptions, netFlags types.NetworkOptions) error {
+	flags := cmd.Flags()
+	input, err := flags.GetString("from-checkpoint")
+	if err != nil {
+		return err
+	}
+	checkpointDir, err := flags.GetString("checkpoint-dir")
+	if err != nil {
+		return err
+	}
+	if checkpointDir == "" {
+		checkpointDir = filepath.Join(createOpt.GOptions.DataRoot, "checkpoints")
+	}
+	// the networks default to the ones of the checkpointed container, instead of the default network
+	if !flags.Changed("network") && !flags.Changed("net") {
+		netFlags.NetworkSlice = nil
+	}
+
+	c, err := container.CreateFromCheckpoint(ctx, client, types.ContainerCreateFromCheckpointOptions{
+		Input:          input,
+		CheckpointDir:  checkpointDir,
+		CreateOptions:  createOpt,
+		NetworkOptions: netFlags,
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(createOpt.Stdout, c.ID())
+	return nil
+}
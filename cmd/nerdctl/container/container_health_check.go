@@ -26,6 +26,7 @@ import (
 
 	"github.com/containerd/nerdctl/v2/cmd/nerdctl/completion"
 	"github.com/containerd/nerdctl/v2/cmd/nerdctl/helpers"
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
 	"github.com/containerd/nerdctl/v2/pkg/clientutil"
 	"github.com/containerd/nerdctl/v2/pkg/cmd/container"
 	"github.com/containerd/nerdctl/v2/pkg/idutil/containerwalker"
@@ -58,6 +59,11 @@ func healthCheckAction(cmd *cobra.Command, args []string) error {
 	}
 	defer cancel()
 
+	options := types.ContainerHealthCheckOptions{
+		GOptions: globalOptions,
+	}
+	options.NerdctlCmd, options.NerdctlArgs = helpers.GlobalFlags(cmd)
+
 	containerID := args[0]
 	walker := &containerwalker.ContainerWalker{
 		Client: client,
@@ -65,7 +71,7 @@ func healthCheckAction(cmd *cobra.Command, args []string) error {
 			if found.MatchCount > 1 {
 				return fmt.Errorf("multiple IDs found with provided prefix: %s", found.Req)
 			}
-			return container.HealthCheck(ctx, client, found.Container)
+			return container.HealthCheck(ctx, client, found.Container, options)
 		},
 	}
 
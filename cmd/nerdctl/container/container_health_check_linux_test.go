@@ -189,6 +189,7 @@ func TestContainerHealthCheckDefaults(t *testing.T) {
 						assert.Equal(t, hc.Timeout, 30*time.Second, "expected default timeout of 30s")
 						assert.Equal(t, hc.Retries, 3, "expected default retries of 3")
 						assert.Equal(t, hc.StartPeriod, 0*time.Second, "expected default start period of 0s")
+						assert.Equal(t, hc.StartInterval, 0*time.Second, "expected no start interval without start period")
 
 						// Verify the command was set correctly
 						assert.DeepEqual(t, hc.Test, []string{"CMD-SHELL", "echo healthy"})
@@ -234,6 +235,7 @@ func TestContainerHealthCheckDefaults(t *testing.T) {
 						assert.Equal(t, hc.Timeout, 15*time.Second, "expected custom timeout of 15s")
 						assert.Equal(t, hc.Retries, 5, "expected custom retries of 5")
 						assert.Equal(t, hc.StartPeriod, 10*time.Second, "expected custom start period of 10s")
+						assert.Equal(t, hc.StartInterval, healthcheck.DefaultStartInterval, "expected default start interval of 5s")
 
 						// Verify the command was set correctly
 						assert.DeepEqual(t, hc.Test, []string{"CMD-SHELL", "echo custom"})
@@ -1159,6 +1161,298 @@ func TestHealthCheck_SystemdIntegration_Advanced(t *testing.T) {
 	testCase.Run(t)
 }
 
+func TestHealthCheck_SupervisorIntegration(t *testing.T) {
+	testCase := nerdtest.Setup()
+	// Docker CLI does not provide a standalone healthcheck command.
+	testCase.Require = require.Not(nerdtest.Docker)
+	// Force the supervisor process instead of systemd timers, so that the test behaves
+	// the same in rootful and rootless environments.
+	testCase.Config = test.WithConfig(nerdtest.NerdctlToml, `disable_hc_systemd = true`)
+
+	waitForHealth := func(t tig.T, helpers test.Helpers, name, status string) *healthcheck.Health {
+		var h *healthcheck.Health
+		for i := 0; i < 10; i++ {
+			h = nerdtest.InspectContainer(helpers, name).State.Health
+			if h != nil && h.Status == status {
+				return h
+			}
+			time.Sleep(1 * time.Second)
+		}
+		assert.Assert(t, false, fmt.Sprintf("container did not become %s, last health state: %+v", status, h))
+		return h
+	}
+
+	testCase.SubTests = []*test.Case{
+		{
+			Description: "Healthy container is probed without systemd",
+			Setup: func(data test.Data, helpers test.Helpers) {
+				helpers.Ensure("run", "-d", "--name", data.Identifier(),
+					"--health-cmd", "echo healthy",
+					"--health-interval", "1s",
+					testutil.CommonImage, "sleep", nerdtest.Infinity)
+				nerdtest.EnsureContainerStarted(helpers, data.Identifier())
+			},
+			Cleanup: func(data test.Data, helpers test.Helpers) {
+				helpers.Anyhow("rm", "-f", data.Identifier())
+			},
+			Expected: func(data test.Data, helpers test.Helpers) *test.Expected {
+				return &test.Expected{
+					ExitCode: expect.ExitCodeNoCheck,
+					Output: func(stdout string, t tig.T) {
+						h := waitForHealth(t, helpers, data.Identifier(), healthcheck.Healthy)
+						assert.Equal(t, h.FailingStreak, 0)
+						assert.Assert(t, len(h.Log) > 0, "expected at least one health check log entry")
+					},
+				}
+			},
+		},
+		{
+			Description: "Failing container becomes unhealthy without systemd",
+			Setup: func(data test.Data, helpers test.Helpers) {
+				helpers.Ensure("run", "-d", "--name", data.Identifier(),
+					"--health-cmd", "exit 1",
+					"--health-interval", "1s",
+					"--health-retries", "2",
+					testutil.CommonImage, "sleep", nerdtest.Infinity)
+				nerdtest.EnsureContainerStarted(helpers, data.Identifier())
+			},
+			Cleanup: func(data test.Data, helpers test.Helpers) {
+				helpers.Anyhow("rm", "-f", data.Identifier())
+			},
+			Expected: func(data test.Data, helpers test.Helpers) *test.Expected {
+				return &test.Expected{
+					ExitCode: expect.ExitCodeNoCheck,
+					Output: func(stdout string, t tig.T) {
+						h := waitForHealth(t, helpers, data.Identifier(), healthcheck.Unhealthy)
+						assert.Assert(t, h.FailingStreak >= 2, "expected failing streak of at least 2, got %d", h.FailingStreak)
+						assert.Assert(t, len(h.Log) > 0, "expected at least one health check log entry")
+					},
+				}
+			},
+		},
+		{
+			Description: "Container is probed at the start interval during the start period",
+			Setup: func(data test.Data, helpers test.Helpers) {
+				// The container only becomes ready after a few seconds, so that it has to be
+				// probed again within the start period to be reported healthy.
+				helpers.Ensure("run", "-d", "--name", data.Identifier(),
+					"--health-cmd", "test -f /tmp/ready",
+					"--health-interval", "1h",
+					"--health-start-period", "1h",
+					"--health-start-interval", "1s",
+					testutil.CommonImage, "sh", "-c", "sleep 3 && touch /tmp/ready && sleep "+nerdtest.Infinity)
+				nerdtest.EnsureContainerStarted(helpers, data.Identifier())
+			},
+			Cleanup: func(data test.Data, helpers test.Helpers) {
+				helpers.Anyhow("rm", "-f", data.Identifier())
+			},
+			Expected: func(data test.Data, helpers test.Helpers) *test.Expected {
+				return &test.Expected{
+					ExitCode: expect.ExitCodeNoCheck,
+					Output: func(stdout string, t tig.T) {
+						h := waitForHealth(t, helpers, data.Identifier(), healthcheck.Healthy)
+						// Once healthy, the container is probed at the regular interval again.
+						n := len(h.Log)
+						time.Sleep(3 * time.Second)
+						h = nerdtest.InspectContainer(helpers, data.Identifier()).State.Health
+						assert.Equal(t, len(h.Log), n, "expected no probe at the start interval once healthy")
+					},
+				}
+			},
+		},
+		{
+			Description: "Stop terminates the healthcheck supervisor",
+			Setup: func(data test.Data, helpers test.Helpers) {
+				helpers.Ensure("run", "-d", "--name", data.Identifier(),
+					"--health-cmd", "echo healthy",
+					"--health-interval", "1s",
+					testutil.CommonImage, "sleep", nerdtest.Infinity)
+				nerdtest.EnsureContainerStarted(helpers, data.Identifier())
+				helpers.Ensure("stop", data.Identifier())
+				// Give the supervisor a moment to handle SIGTERM and exit.
+				time.Sleep(1 * time.Second)
+			},
+			Cleanup: func(data test.Data, helpers test.Helpers) {
+				helpers.Anyhow("rm", "-f", data.Identifier())
+			},
+			Expected: func(data test.Data, helpers test.Helpers) *test.Expected {
+				return &test.Expected{
+					ExitCode: expect.ExitCodeNoCheck,
+					Output: func(stdout string, t tig.T) {
+						containerID := nerdtest.InspectContainer(helpers, data.Identifier()).ID
+						helpers.Custom("pgrep", "-f", healthcheck.SupervisorCommand+" "+containerID).Run(&test.Expected{
+							ExitCode: 1,
+						})
+					},
+				}
+			},
+		},
+	}
+	testCase.Run(t)
+}
+
+func TestHealthCheck_OnFailure(t *testing.T) {
+	testCase := nerdtest.Setup()
+	// Docker CLI does not provide --health-on-failure.
+	testCase.Require = require.Not(nerdtest.Docker)
+	// Use the supervisor process, so that the test behaves the same in rootful and rootless environments.
+	testCase.Config = test.WithConfig(nerdtest.NerdctlToml, `disable_hc_systemd = true`)
+
+	// waitForOnFailure waits until the health log records the health-on-failure action.
+	waitForOnFailure := func(t tig.T, helpers test.Helpers, name, action string) {
+		note := "health-on-failure action: " + action
+		for i := 0; i < 10; i++ {
+			if h := nerdtest.InspectContainer(helpers, name).State.Health; h != nil {
+				for _, l := range h.Log {
+					if strings.Contains(l.Output, note) {
+						return
+					}
+				}
+			}
+			time.Sleep(1 * time.Second)
+		}
+		assert.Assert(t, false, fmt.Sprintf("health log of %s does not record %q", name, note))
+	}
+
+	// countOnFailure returns the number of times the health log records the health-on-failure action.
+	countOnFailure := func(helpers test.Helpers, name, action string) int {
+		n := 0
+		if h := nerdtest.InspectContainer(helpers, name).State.Health; h != nil {
+			for _, l := range h.Log {
+				if strings.Contains(l.Output, "health-on-failure action: "+action) {
+					n++
+				}
+			}
+		}
+		return n
+	}
+
+	testCase.SubTests = []*test.Case{
+		{
+			Description: "Invalid action is rejected",
+			Command: test.Command("run", "--rm", "--health-cmd", "true", "--health-on-failure", "reboot",
+				testutil.CommonImage, "true"),
+			Expected: test.Expects(expect.ExitCodeGenericFail, []error{errors.New("invalid health-on-failure action")}, nil),
+		},
+		{
+			Description: "Action conflicts with --no-healthcheck",
+			Command: test.Command("run", "--rm", "--no-healthcheck", "--health-on-failure", "kill",
+				testutil.CommonImage, "true"),
+			Expected: test.Expects(expect.ExitCodeGenericFail, []error{errors.New("--no-healthcheck conflicts with --health-* options")}, nil),
+		},
+		{
+			Description: "Kill stops the unhealthy container",
+			Setup: func(data test.Data, helpers test.Helpers) {
+				helpers.Ensure("run", "-d", "--name", data.Identifier(),
+					"--health-cmd", "exit 1",
+					"--health-interval", "1s",
+					"--health-retries", "1",
+					"--health-on-failure", "kill",
+					testutil.CommonImage, "sleep", nerdtest.Infinity)
+			},
+			Cleanup: func(data test.Data, helpers test.Helpers) {
+				helpers.Anyhow("rm", "-f", data.Identifier())
+			},
+			Expected: func(data test.Data, helpers test.Helpers) *test.Expected {
+				return &test.Expected{
+					ExitCode: expect.ExitCodeNoCheck,
+					Output: func(stdout string, t tig.T) {
+						waitForOnFailure(t, helpers, data.Identifier(), "kill")
+						nerdtest.EnsureContainerExited(helpers, data.Identifier(), 137)
+					},
+				}
+			},
+		},
+		{
+			Description: "Kill is carried out again after the restart policy restarts the container",
+			// The containerd restart monitor only checks the containers every few seconds.
+			NoParallel: true,
+			Setup: func(data test.Data, helpers test.Helpers) {
+				helpers.Ensure("run", "-d", "--name", data.Identifier(),
+					"--restart", "always",
+					"--health-cmd", "exit 1",
+					"--health-interval", "1s",
+					"--health-retries", "1",
+					"--health-on-failure", "kill",
+					testutil.CommonImage, "sleep", nerdtest.Infinity)
+				data.Labels().Set("startedAt", nerdtest.InspectContainer(helpers, data.Identifier()).State.StartedAt)
+			},
+			Cleanup: func(data test.Data, helpers test.Helpers) {
+				helpers.Anyhow("rm", "-f", data.Identifier())
+			},
+			Expected: func(data test.Data, helpers test.Helpers) *test.Expected {
+				return &test.Expected{
+					ExitCode: expect.ExitCodeNoCheck,
+					Output: func(stdout string, t tig.T) {
+						waitForOnFailure(t, helpers, data.Identifier(), "kill")
+						// The supervisor keeps probing the container once containerd has restarted its task,
+						// so that the container is killed again.
+						for i := 0; i < 60 && countOnFailure(helpers, data.Identifier(), "kill") < 2; i++ {
+							time.Sleep(1 * time.Second)
+						}
+						assert.Assert(t, countOnFailure(helpers, data.Identifier(), "kill") >= 2,
+							"expected the container to be killed again after being restarted")
+						inspect := nerdtest.InspectContainer(helpers, data.Identifier())
+						assert.Assert(t, inspect.State.StartedAt != data.Labels().Get("startedAt"),
+							"expected container to be restarted")
+					},
+				}
+			},
+		},
+		{
+			Description: "Stop stops the unhealthy container",
+			Setup: func(data test.Data, helpers test.Helpers) {
+				helpers.Ensure("run", "-d", "--name", data.Identifier(),
+					"--health-cmd", "exit 1",
+					"--health-interval", "1s",
+					"--health-retries", "1",
+					"--health-on-failure", "stop",
+					testutil.CommonImage, "sleep", nerdtest.Infinity)
+			},
+			Cleanup: func(data test.Data, helpers test.Helpers) {
+				helpers.Anyhow("rm", "-f", data.Identifier())
+			},
+			Expected: func(data test.Data, helpers test.Helpers) *test.Expected {
+				return &test.Expected{
+					ExitCode: expect.ExitCodeNoCheck,
+					Output: func(stdout string, t tig.T) {
+						waitForOnFailure(t, helpers, data.Identifier(), "stop")
+						nerdtest.EnsureContainerExited(helpers, data.Identifier(), expect.ExitCodeNoCheck)
+					},
+				}
+			},
+		},
+		{
+			Description: "Restart restarts the unhealthy container",
+			Setup: func(data test.Data, helpers test.Helpers) {
+				helpers.Ensure("run", "-d", "--name", data.Identifier(),
+					"--health-cmd", "exit 1",
+					"--health-interval", "1s",
+					"--health-retries", "1",
+					"--health-on-failure", "restart",
+					testutil.CommonImage, "sleep", nerdtest.Infinity)
+				data.Labels().Set("startedAt", nerdtest.InspectContainer(helpers, data.Identifier()).State.StartedAt)
+			},
+			Cleanup: func(data test.Data, helpers test.Helpers) {
+				helpers.Anyhow("rm", "-f", data.Identifier())
+			},
+			Expected: func(data test.Data, helpers test.Helpers) *test.Expected {
+				return &test.Expected{
+					ExitCode: expect.ExitCodeNoCheck,
+					Output: func(stdout string, t tig.T) {
+						waitForOnFailure(t, helpers, data.Identifier(), "restart")
+						nerdtest.EnsureContainerStarted(helpers, data.Identifier())
+						inspect := nerdtest.InspectContainer(helpers, data.Identifier())
+						assert.Assert(t, inspect.State.StartedAt != data.Labels().Get("startedAt"),
+							"expected container to be restarted")
+					},
+				}
+			},
+		},
+	}
+	testCase.Run(t)
+}
+
 func TestStartHealthcheckedContainerAfterExited(t *testing.T) {
 	testCase := nerdtest.Setup()
 	testCase.Require = require.All(
@@ -19,6 +19,7 @@ package container
 import (
 	"fmt"
 	"slices"
+	"strconv"
 	"strings"
 	"testing"
 
@@ -35,6 +36,7 @@ import (
 	"github.com/containerd/nerdctl/v2/pkg/tabutil"
 	"github.com/containerd/nerdctl/v2/pkg/testutil"
 	"github.com/containerd/nerdctl/v2/pkg/testutil/nerdtest"
+	"github.com/containerd/nerdctl/v2/pkg/testutil/portlock"
 )
 
 // setupPsTestContainer creates a test container with labels, volumes, and network.
@@ -976,3 +978,77 @@ func TestContainerListWithHealthStatus(t *testing.T) {
 
 	testCase.Run(t)
 }
+
+func TestContainerListWithDockerFilters(t *testing.T) {
+	testCase := nerdtest.Setup()
+
+	testCase.Setup = func(data test.Data, helpers test.Helpers) {
+		port, err := portlock.Acquire(0)
+		if err != nil {
+			helpers.T().Log(fmt.Sprintf("Failed to acquire port: %v", err))
+			helpers.T().FailNow()
+		}
+		data.Labels().Set("hostPort", strconv.Itoa(port))
+		helpers.Ensure("run", "-d", "--name", data.Identifier("a"),
+			"--label", "foo=bar", "-p", strconv.Itoa(port)+":80", "--expose", "9000-9001",
+			"--health-cmd", "false", "--health-start-period", "1h",
+			testutil.CommonImage, "sleep", "infinity")
+		helpers.Ensure("run", "-d", "--name", data.Identifier("b"), testutil.CommonImage, "sleep", "infinity")
+	}
+
+	testCase.Cleanup = func(data test.Data, helpers test.Helpers) {
+		helpers.Anyhow("rm", "-f", data.Identifier("a"), data.Identifier("b"))
+		if port, err := strconv.Atoi(data.Labels().Get("hostPort")); err == nil {
+			_ = portlock.Release(port)
+		}
+	}
+
+	// newCase returns a case listing the containers matching a filter, and checking that only the
+	// containers with the wanted identifiers are listed among the test ones.
+	newCase := func(description string, filter func(data test.Data) string, want ...string) *test.Case {
+		return &test.Case{
+			Description: description,
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("ps", "-a", "--format", "{{.Names}}", "--filter", filter(data))
+			},
+			Expected: func(data test.Data, helpers test.Helpers) *test.Expected {
+				return &test.Expected{
+					Output: func(stdout string, t tig.T) {
+						lines := strings.Split(strings.TrimSpace(stdout), "\n")
+						for _, id := range []string{"a", "b"} {
+							assert.Equal(t, slices.Contains(lines, data.Identifier(id)), slices.Contains(want, id),
+								"unexpected presence of container %q for filter %q: %q", id, filter(data), stdout)
+						}
+					},
+				}
+			},
+		}
+	}
+
+	static := func(filter string) func(test.Data) string {
+		return func(test.Data) string { return filter }
+	}
+
+	testCase.SubTests = []*test.Case{
+		newCase("ancestor", static("ancestor="+testutil.CommonImage), "a", "b"),
+		newCase("negated label", static("label!=foo"), "b"),
+		newCase("negated label with value", static("label!=foo=baz"), "a", "b"),
+		newCase("publish", static("publish=80/tcp"), "a"),
+		newCase("publish with the host port", func(data test.Data) string { return "publish=" + data.Labels().Get("hostPort") }),
+		newCase("publish exposed port", static("publish=9001")),
+		newCase("expose", static("expose=9001/tcp"), "a"),
+		newCase("expose published port", static("expose=80"), "a"),
+		newCase("expose with another protocol", static("expose=9000/udp")),
+		newCase("health none", static("health=none"), "b"),
+		newCase("health starting", static("health=starting"), "a"),
+		newCase("is-task", static("is-task=true")),
+		newCase("not is-task", static("is-task=false"), "a", "b"),
+		{
+			Description: "invalid health",
+			Command:     test.Command("ps", "--filter", "health=foo"),
+			Expected:    test.Expects(expect.ExitCodeGenericFail, nil, nil),
+		},
+	}
+
+	testCase.Run(t)
+}
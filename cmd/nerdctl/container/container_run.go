@@ -43,6 +43,7 @@ import (
 	"github.com/containerd/nerdctl/v2/pkg/healthcheck"
 	"github.com/containerd/nerdctl/v2/pkg/labels"
 	"github.com/containerd/nerdctl/v2/pkg/logging"
+	"github.com/containerd/nerdctl/v2/pkg/mountutil/volumestore"
 	"github.com/containerd/nerdctl/v2/pkg/netutil"
 	"github.com/containerd/nerdctl/v2/pkg/signalutil"
 	"github.com/containerd/nerdctl/v2/pkg/taskutil"
@@ -140,6 +141,9 @@ func setCreateFlags(cmd *cobra.Command) {
 	cmd.Flags().StringP("hostname", "h", "", "Container host name")
 	cmd.Flags().String("domainname", "", "Container domain name")
 	cmd.Flags().String("mac-address", "", "MAC address to assign to the container")
+	// network-alias is defined as StringSlice, not StringArray, to allow specifying "--network-alias=foo,bar"
+	cmd.Flags().StringSlice("network-alias", nil, "Add network-scoped aliases for the container ([NETWORK:]ALIAS)")
+	cmd.Flags().StringSlice("network-opt", nil, "Set network options of the container (ingress-rate, ingress-burst, egress-rate, egress-burst)")
 	// #endregion
 
 	cmd.Flags().String("ipc", "", `IPC namespace to use ("host"|"private"|"shareable"|"container:<container>")`)
@@ -256,6 +260,11 @@ func setCreateFlags(cmd *cobra.Command) {
 	cmd.Flags().Duration("health-timeout", 0, "Maximum time to allow one check to run; 0 uses the image value or 30s when unset there too")
 	cmd.Flags().Int("health-retries", 0, "Consecutive failures needed to report unhealthy; 0 uses the image value or 3 when unset there too")
 	cmd.Flags().Duration("health-start-period", 0, "Start period for the container to initialize before starting health-retries countdown")
+	cmd.Flags().Duration("health-start-interval", 0, "Time between running the checks during the start period; 0 uses the image value or 5s when unset there too")
+	cmd.Flags().String("health-on-failure", healthcheck.OnFailureNone, `Action to take once the container turns unhealthy ("none"|"kill"|"restart"|"stop")`)
+	cmd.RegisterFlagCompletionFunc("health-on-failure", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{healthcheck.OnFailureNone, healthcheck.OnFailureKill, healthcheck.OnFailureRestart, healthcheck.OnFailureStop}, cobra.ShellCompDirectiveNoFileComp
+	})
 	cmd.Flags().Bool("no-healthcheck", false, "Disable any container-specified HEALTHCHECK")
 
 	// #region env flags
@@ -444,6 +453,13 @@ func runAction(cmd *cobra.Command, args []string) error {
 		return err
 	}
 	logURI := lab[labels.LogURI]
+	dataStore, err := clientutil.DataStore(createOpt.GOptions.DataRoot, createOpt.GOptions.Address)
+	if err != nil {
+		return err
+	}
+	if err := volumestore.MountVolumes(dataStore, createOpt.GOptions.Namespace, id, labels.GetVolumeNames(lab)); err != nil {
+		return err
+	}
 	detachC := make(chan struct{})
 	task, err := taskutil.NewTask(ctx, client, c, taskutil.TaskOptions{
 		AttachStreamOpt: createOpt.Attach,
@@ -458,6 +474,9 @@ func runAction(cmd *cobra.Command, args []string) error {
 		CheckpointDir:   "",
 	})
 	if err != nil {
+		if uerr := volumestore.UnmountVolumes(dataStore, createOpt.GOptions.Namespace, id, labels.GetVolumeNames(lab)); uerr != nil {
+			log.G(ctx).WithError(uerr).Warn("failed to unmount volumes")
+		}
 		return err
 	}
 
@@ -467,6 +486,9 @@ func runAction(cmd *cobra.Command, args []string) error {
 	}
 
 	if err := task.Start(ctx); err != nil {
+		if uerr := volumestore.UnmountVolumes(dataStore, createOpt.GOptions.Namespace, id, labels.GetVolumeNames(lab)); uerr != nil {
+			log.G(ctx).WithError(uerr).Warn("failed to unmount volumes")
+		}
 		return err
 	}
 
@@ -20,6 +20,8 @@ import (
 	"errors"
 	"fmt"
 	"net"
+	"slices"
+	"strings"
 
 	"github.com/spf13/cobra"
 
@@ -27,6 +29,8 @@ import (
 
 	"github.com/containerd/nerdctl/v2/pkg/api/types"
 	"github.com/containerd/nerdctl/v2/pkg/dnsutil"
+	"github.com/containerd/nerdctl/v2/pkg/netutil"
+	"github.com/containerd/nerdctl/v2/pkg/netutil/nettype"
 	"github.com/containerd/nerdctl/v2/pkg/portutil"
 	"github.com/containerd/nerdctl/v2/pkg/strutil"
 )
@@ -103,6 +107,35 @@ func loadNetworkFlags(cmd *cobra.Command, globalOpts types.GlobalCommandOptions)
 	}
 	netOpts.Domainname = domainname
 
+	// --network-alias=[<network>:]<alias> ...
+	networkAliases, err := cmd.Flags().GetStringSlice("network-alias")
+	if err != nil {
+		return netOpts, err
+	}
+	netOpts.NetworkAliases, err = parseNetworkAliases(networkAliases, netOpts.NetworkSlice)
+	if err != nil {
+		return netOpts, err
+	}
+
+	// --network-opt=<key>=<value> ...
+	networkOpts, err := cmd.Flags().GetStringSlice("network-opt")
+	if err != nil {
+		return netOpts, err
+	}
+	if len(networkOpts) > 0 {
+		netType, err := nettype.Detect(netOpts.NetworkSlice)
+		if err != nil {
+			return netOpts, err
+		}
+		if netType != nettype.CNI {
+			return netOpts, errors.New("network options are only supported for containers in CNI networks")
+		}
+		netOpts.Bandwidth, err = netutil.ParseBandwidth(strutil.ConvertKVStringsToMap(networkOpts))
+		if err != nil {
+			return netOpts, err
+		}
+	}
+
 	// --dns=<DNS host> ...
 	// Use command flags if set, otherwise use global config is set
 	var dnsSlice []string
@@ -202,7 +235,8 @@ func loadNetworkFlags(cmd *cobra.Command, globalOpts types.GlobalCommandOptions)
 
 	portMappings := []cni.PortMapping{}
 	for _, p := range portSlice {
-		pm, err := portutil.ParseFlagP(p)
+		// The host ports are allocated and reserved when the container is created
+		pm, err := portutil.ParseFlagPWithoutAllocation(p)
 		if err != nil {
 			return netOpts, err
 		}
@@ -212,3 +246,42 @@ func loadNetworkFlags(cmd *cobra.Command, globalOpts types.GlobalCommandOptions)
 
 	return netOpts, nil
 }
+
+// parseNetworkAliases returns the aliases of the container keyed by network.
+// An alias prefixed with "<network>:" is only set on that network, the others are set on all the networks.
+func parseNetworkAliases(aliases []string, networks []string) (map[string][]string, error) {
+	if len(aliases) == 0 {
+		return nil, nil
+	}
+	netType, err := nettype.Detect(networks)
+	if err != nil {
+		return nil, err
+	}
+	if netType != nettype.CNI {
+		return nil, errors.New("network-scoped aliases are only supported for containers in CNI networks")
+	}
+	res := make(map[string][]string)
+	for _, a := range aliases {
+		network, alias, scoped := strings.Cut(a, ":")
+		if !scoped {
+			alias = a
+		}
+		if alias == "" {
+			return nil, fmt.Errorf("invalid network alias %q", a)
+		}
+		if !scoped {
+			for _, n := range networks {
+				res[n] = append(res[n], alias)
+			}
+			continue
+		}
+		if !slices.Contains(networks, network) {
+			return nil, fmt.Errorf("invalid network alias %q: the container is not connected to network %q", a, network)
+		}
+		res[network] = append(res[network], alias)
+	}
+	for n, a := range res {
+		res[n] = strutil.DedupeStrSlice(a)
+	}
+	return res, nil
+}
@@ -17,6 +17,8 @@
 package container
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net"
@@ -40,6 +42,8 @@ import (
 	"github.com/containerd/nerdctl/mod/tigron/test"
 	"github.com/containerd/nerdctl/mod/tigron/tig"
 
+	"github.com/containerd/nerdctl/v2/pkg/inspecttypes/dockercompat"
+	"github.com/containerd/nerdctl/v2/pkg/labels"
 	"github.com/containerd/nerdctl/v2/pkg/rootlessutil"
 	"github.com/containerd/nerdctl/v2/pkg/testutil"
 	"github.com/containerd/nerdctl/v2/pkg/testutil/nerdtest"
@@ -379,6 +383,80 @@ func TestHostPortAlreadyInUse(t *testing.T) {
 	testCase.Run(t)
 }
 
+// TestHostPortReservation tests that the host ports of a container stay reserved while it is stopped,
+// and are kept when it is started again.
+func TestHostPortReservation(t *testing.T) {
+	testCase := nerdtest.Setup()
+
+	// Auto port assign is not supported rootless mode yet, and the reservation is nerdctl-only
+	testCase.Require = require.All(
+		require.Not(nerdtest.Docker),
+		nerdtest.Rootful,
+	)
+
+	testCase.Setup = func(data test.Data, helpers test.Helpers) {
+		helpers.Ensure("run", "-d", "--name", data.Identifier(), "-p", "80", testutil.NginxAlpineImage)
+		hostPort, err := extractHostPort(helpers.Capture("port", data.Identifier()), "80")
+		assert.NilError(helpers.T(), err)
+		data.Labels().Set("hostPort", hostPort)
+		helpers.Ensure("stop", data.Identifier())
+	}
+
+	testCase.Cleanup = func(data test.Data, helpers test.Helpers) {
+		helpers.Anyhow("rm", "-f", data.Identifier(), data.Identifier("other"), data.Identifier("auto"))
+	}
+
+	testCase.SubTests = []*test.Case{
+		{
+			Description: "the host port of a stopped container cannot be published by another container",
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("create", "--name", data.Identifier("other"), "-p", data.Labels().Get("hostPort")+":80", testutil.NginxAlpineImage)
+			},
+			Expected: test.Expects(expect.ExitCodeGenericFail, []error{errors.New("port is already reserved by container")}, nil),
+		},
+		{
+			Description: "the host port of a stopped container is not allocated to another container",
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				helpers.Ensure("create", "--name", data.Identifier("auto"), "-p", "80", testutil.NginxAlpineImage)
+				return helpers.Command("port", data.Identifier("auto"))
+			},
+			Expected: func(data test.Data, helpers test.Helpers) *test.Expected {
+				return &test.Expected{
+					Output: func(stdout string, t tig.T) {
+						hostPort, err := extractHostPort(stdout, "80")
+						assert.NilError(t, err)
+						assert.Assert(t, hostPort != data.Labels().Get("hostPort"), "the reserved host port was allocated again")
+					},
+				}
+			},
+		},
+		{
+			Description: "the container keeps its host port when it is started again",
+			NoParallel:  true,
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				helpers.Ensure("start", data.Identifier())
+				return helpers.Command("port", data.Identifier())
+			},
+			Expected: func(data test.Data, helpers test.Helpers) *test.Expected {
+				return &test.Expected{
+					Output: func(stdout string, t tig.T) {
+						hostPort, err := extractHostPort(stdout, "80")
+						assert.NilError(t, err)
+						assert.Equal(t, hostPort, data.Labels().Get("hostPort"))
+						resp, err := nettestutil.HTTPGet(fmt.Sprintf("http://127.0.0.1:%s", hostPort), 5, false)
+						assert.NilError(t, err)
+						respBody, err := io.ReadAll(resp.Body)
+						assert.NilError(t, err)
+						assert.Assert(t, strings.Contains(string(respBody), testutil.NginxAlpineIndexHTMLSnippet))
+					},
+				}
+			},
+		},
+	}
+
+	testCase.Run(t)
+}
+
 func TestRunPort(t *testing.T) {
 	baseTestRunPort(t, testutil.NginxAlpineImage, testutil.NginxAlpineIndexHTMLSnippet, true)
 }
@@ -1279,6 +1357,320 @@ dns_search = ["example.com", "test.local"]`
 	testCase.Run(t)
 }
 
+// TestRunNetworkAlias tests that the network-scoped aliases of a container resolve on their network,
+// and are reported by inspect.
+func TestRunNetworkAlias(t *testing.T) {
+	testCase := nerdtest.Setup()
+
+	testCase.Setup = func(data test.Data, helpers test.Helpers) {
+		helpers.Ensure("network", "create", data.Identifier("n1"))
+		helpers.Ensure("network", "create", data.Identifier("n2"))
+		helpers.Ensure("run", "-d", "--name", data.Identifier(), "--network", data.Identifier("n1"), "--network", data.Identifier("n2"),
+			"--network-alias", "db", "--network-alias", data.Identifier("n2")+":primary",
+			testutil.CommonImage, "sleep", nerdtest.Infinity)
+		nerdtest.EnsureContainerStarted(helpers, data.Identifier())
+	}
+
+	testCase.Cleanup = func(data test.Data, helpers test.Helpers) {
+		helpers.Anyhow("rm", "-f", data.Identifier())
+		helpers.Anyhow("network", "rm", data.Identifier("n1"), data.Identifier("n2"))
+	}
+
+	testCase.SubTests = []*test.Case{
+		{
+			Description: "inspect reports the aliases of each network",
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("container", "inspect", data.Identifier())
+			},
+			Expected: func(data test.Data, helpers test.Helpers) *test.Expected {
+				return &test.Expected{
+					Output: expect.JSON([]dockercompat.Container{}, func(dc []dockercompat.Container, t tig.T) {
+						assert.Equal(t, 1, len(dc))
+						networks := dc[0].NetworkSettings.Networks
+						assert.DeepEqual(t, []string{"db"}, networks[data.Identifier("n1")].Aliases)
+						assert.DeepEqual(t, []string{"db", "primary"}, networks[data.Identifier("n2")].Aliases)
+					}),
+				}
+			},
+		},
+		{
+			Description: "an alias resolves on its network",
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("run", "--rm", "--network", data.Identifier("n2"), testutil.CommonImage, "getent", "hosts", "primary")
+			},
+			Expected: test.Expects(expect.ExitCodeSuccess, nil, expect.Contains("primary")),
+		},
+		{
+			Description: "an alias does not resolve on the other networks",
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("run", "--rm", "--network", data.Identifier("n1"), testutil.CommonImage, "getent", "hosts", "primary")
+			},
+			Expected: test.Expects(expect.ExitCodeGenericFail, nil, nil),
+		},
+		{
+			Description: "aliases require a CNI network",
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("run", "--rm", "--network", "host", "--network-alias", "db", testutil.CommonImage, "true")
+			},
+			Expected: test.Expects(expect.ExitCodeGenericFail, []error{errors.New("only supported for containers in CNI networks")}, nil),
+		},
+		{
+			Description: "a scoped alias requires the container to be on the network",
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("run", "--rm", "--network", data.Identifier("n1"), "--network-alias", data.Identifier("n2")+":db", testutil.CommonImage, "true")
+			},
+			Expected: test.Expects(expect.ExitCodeGenericFail, []error{errors.New("is not connected to network")}, nil),
+		},
+	}
+
+	testCase.Run(t)
+}
+
+// TestRunNetworkOptBandwidth tests the bandwidth limits of the networks and of --network-opt.
+func TestRunNetworkOptBandwidth(t *testing.T) {
+	testCase := nerdtest.Setup()
+	// --network-opt is nerdctl-only
+	testCase.Require = require.Not(nerdtest.Docker)
+
+	testCase.Setup = func(data test.Data, helpers test.Helpers) {
+		helpers.Ensure("network", "create", "-o", "egress-rate=10mbit", data.Identifier("limited"))
+		helpers.Ensure("network", "create", data.Identifier("unlimited"))
+		data.Labels().Set("limitedNet", data.Identifier("limited"))
+		data.Labels().Set("unlimitedNet", data.Identifier("unlimited"))
+	}
+
+	testCase.Cleanup = func(data test.Data, helpers test.Helpers) {
+		helpers.Anyhow("network", "rm", data.Identifier("limited"))
+		helpers.Anyhow("network", "rm", data.Identifier("unlimited"))
+	}
+
+	testCase.SubTests = []*test.Case{
+		{
+			Description: "the network limits are set on the bandwidth plugin",
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("network", "inspect", data.Labels().Get("limitedNet"))
+			},
+			Expected: func(data test.Data, helpers test.Helpers) *test.Expected {
+				return &test.Expected{
+					Output: func(stdout string, t tig.T) {
+						nativeNet := nerdtest.InspectNetworkNative(helpers, data.Labels().Get("limitedNet"))
+						var cni struct {
+							Plugins []struct {
+								Type         string          `json:"type"`
+								Capabilities map[string]bool `json:"capabilities"`
+								EgressRate   uint64          `json:"egressRate"`
+								EgressBurst  uint64          `json:"egressBurst"`
+							} `json:"plugins"`
+						}
+						assert.NilError(t, json.Unmarshal(nativeNet.CNI, &cni))
+						p := cni.Plugins[len(cni.Plugins)-1]
+						assert.Equal(t, p.Type, "bandwidth")
+						assert.Assert(t, p.Capabilities["bandwidth"])
+						assert.Equal(t, p.EgressRate, uint64(10_000_000))
+						assert.Equal(t, p.EgressBurst, uint64(1_000_000))
+					},
+				}
+			},
+		},
+		{
+			Description: "a container with its own limits starts",
+			Cleanup: func(data test.Data, helpers test.Helpers) {
+				helpers.Anyhow("rm", "-f", data.Identifier())
+			},
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				helpers.Ensure("run", "-d", "--name", data.Identifier(), "--network", data.Labels().Get("unlimitedNet"),
+					"--network-opt", "ingress-rate=1mbit,ingress-burst=128k", testutil.CommonImage, "sleep", nerdtest.Infinity)
+				nerdtest.EnsureContainerStarted(helpers, data.Identifier())
+				return helpers.Command("container", "inspect", data.Identifier())
+			},
+			Expected: func(data test.Data, helpers test.Helpers) *test.Expected {
+				return &test.Expected{
+					Output: expect.JSON([]dockercompat.Container{}, func(dc []dockercompat.Container, t tig.T) {
+						assert.Equal(t, 1, len(dc))
+						assert.Equal(t, dc[0].Config.Labels[labels.Bandwidth], `{"IngressRate":1000000,"IngressBurst":1048576,"EgressRate":0,"EgressBurst":0}`)
+					}),
+				}
+			},
+		},
+		{
+			Description: "the network limits take precedence over the ones of the container",
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("run", "--rm", "--network", data.Labels().Get("limitedNet"), "--network-opt", "egress-rate=1mbit", testutil.CommonImage, "true")
+			},
+			Expected: test.Expects(expect.ExitCodeSuccess, []error{errors.New("take precedence")}, nil),
+		},
+		{
+			Description: "network options require a CNI network",
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("run", "--rm", "--network", "host", "--network-opt", "ingress-rate=1mbit", testutil.CommonImage, "true")
+			},
+			Expected: test.Expects(expect.ExitCodeGenericFail, []error{errors.New("only supported for containers in CNI networks")}, nil),
+		},
+		{
+			Description: "unknown network options are rejected",
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("run", "--rm", "--network-opt", "foo=bar", testutil.CommonImage, "true")
+			},
+			Expected: test.Expects(expect.ExitCodeGenericFail, []error{errors.New(`unsupported network option "foo"`)}, nil),
+		},
+	}
+
+	testCase.Run(t)
+}
+
+// TestRunEmbeddedDNS tests that containers resolve each other through the embedded DNS server
+// of their network, when it is enabled.
+func TestRunEmbeddedDNS(t *testing.T) {
+	var configContent test.ConfigValue = `embedded_dns = true`
+
+	testCase := nerdtest.Setup()
+	testCase.Config = test.WithConfig(nerdtest.NerdctlToml, configContent)
+	// NERDCTL_TOML not supported in Docker
+	testCase.Require = require.Not(nerdtest.Docker)
+
+	testCase.Setup = func(data test.Data, helpers test.Helpers) {
+		helpers.Ensure("network", "create", data.Identifier())
+		netw := nerdtest.InspectNetwork(helpers, data.Identifier())
+		assert.Assert(helpers.T(), len(netw.IPAM.Config) > 0)
+		data.Labels().Set("gateway", netw.IPAM.Config[0].Gateway)
+		// two replicas sharing a hostname
+		helpers.Ensure("run", "-d", "--name", data.Identifier("web-1"), "--hostname", "web", "--network", data.Identifier(),
+			testutil.CommonImage, "sleep", nerdtest.Infinity)
+		helpers.Ensure("run", "-d", "--name", data.Identifier("web-2"), "--hostname", "web", "--network", data.Identifier(),
+			testutil.CommonImage, "sleep", nerdtest.Infinity)
+	}
+
+	testCase.Cleanup = func(data test.Data, helpers test.Helpers) {
+		helpers.Anyhow("rm", "-f", data.Identifier("web-1"), data.Identifier("web-2"))
+		helpers.Anyhow("network", "rm", data.Identifier())
+	}
+
+	testCase.SubTests = []*test.Case{
+		{
+			Description: "the gateway is the nameserver",
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("run", "--rm", "--network", data.Identifier(), testutil.CommonImage, "cat", "/etc/resolv.conf")
+			},
+			Expected: func(data test.Data, helpers test.Helpers) *test.Expected {
+				return &test.Expected{
+					Output: expect.Contains("nameserver " + data.Labels().Get("gateway") + "\n"),
+				}
+			},
+		},
+		{
+			Description: "the other containers are not in /etc/hosts",
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("run", "--rm", "--network", data.Identifier(), testutil.CommonImage, "cat", "/etc/hosts")
+			},
+			Expected: func(data test.Data, helpers test.Helpers) *test.Expected {
+				return &test.Expected{
+					Output: expect.DoesNotContain(data.Identifier("web-1"), data.Identifier("web-2")),
+				}
+			},
+		},
+		{
+			Description: "the replicas and the container names resolve",
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("run", "--rm", "--network", data.Identifier(), testutil.CommonImage,
+					"sh", "-euxc", "nslookup web && nslookup "+data.Identifier("web-1")+" && nslookup "+data.Identifier("web-2")+"."+data.Identifier())
+			},
+			Expected: func(data test.Data, helpers test.Helpers) *test.Expected {
+				return &test.Expected{
+					Output: func(stdout string, t tig.T) {
+						// both replicas are returned for the hostname
+						assert.Assert(t, strings.Count(stdout, "Address: ") >= 4, stdout)
+					},
+				}
+			},
+		},
+		{
+			Description: "an explicit --dns disables the embedded DNS",
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("run", "--rm", "--network", data.Identifier(), "--dns", "10.10.10.10", testutil.CommonImage, "cat", "/etc/resolv.conf")
+			},
+			Expected: test.Expects(expect.ExitCodeSuccess, nil, expect.Contains("nameserver 10.10.10.10")),
+		},
+	}
+
+	testCase.Run(t)
+}
+
+// TestRunNFTablesFirewallBackend tests that the nftables firewall backend publishes ports
+// and isolates bridge networks from each other.
+func TestRunNFTablesFirewallBackend(t *testing.T) {
+	var configContent test.ConfigValue = `firewall_backend = "nftables"`
+
+	testCase := nerdtest.Setup()
+	testCase.Config = test.WithConfig(nerdtest.NerdctlToml, configContent)
+	// NERDCTL_TOML not supported in Docker
+	testCase.Require = require.All(
+		require.Not(nerdtest.Docker),
+		nerdtest.Rootful,
+		require.Binary("nft"),
+	)
+
+	testCase.Setup = func(data test.Data, helpers test.Helpers) {
+		helpers.Ensure("network", "create", data.Identifier("net-1"))
+		helpers.Ensure("network", "create", data.Identifier("net-2"))
+		helpers.Ensure("run", "-d", "--name", data.Identifier(), "--network", data.Identifier("net-1"),
+			"-p", "127.0.0.1::80", testutil.NginxAlpineImage)
+		nerdtest.EnsureContainerStarted(helpers, data.Identifier())
+		container := nerdtest.InspectContainer(helpers, data.Identifier())
+		data.Labels().Set("ip", container.NetworkSettings.Networks[data.Identifier("net-1")].IPAddress)
+		data.Labels().Set("bridge", "br-"+nerdtest.InspectNetwork(helpers, data.Identifier("net-1")).ID[:12])
+	}
+
+	testCase.Cleanup = func(data test.Data, helpers test.Helpers) {
+		helpers.Anyhow("rm", "-f", data.Identifier())
+		helpers.Anyhow("network", "rm", data.Identifier("net-1"))
+		helpers.Anyhow("network", "rm", data.Identifier("net-2"))
+	}
+
+	testCase.SubTests = []*test.Case{
+		{
+			Description: "the published port is a portmap nftables rule",
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Custom("nft", "list", "chain", "ip", "cni_hostport", "hostports")
+			},
+			Expected: func(data test.Data, helpers test.Helpers) *test.Expected {
+				return &test.Expected{
+					Output: expect.Contains("dnat ip to " + data.Labels().Get("ip") + ":80"),
+				}
+			},
+		},
+		{
+			Description: "the bridge is isolated in the nerdctl table",
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Custom("nft", "list", "table", "inet", "nerdctl")
+			},
+			Expected: func(data test.Data, helpers test.Helpers) *test.Expected {
+				bridge := data.Labels().Get("bridge")
+				return &test.Expected{
+					Output: expect.Contains(`"`+bridge+`" : jump `+bridge, "chain "+bridge+" {"),
+				}
+			},
+		},
+		{
+			Description: "containers on the same network reach the container",
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("run", "--rm", "--network", data.Identifier("net-1"), testutil.CommonImage,
+					"wget", "-T", "5", "-qO-", "http://"+data.Labels().Get("ip"))
+			},
+			Expected: test.Expects(expect.ExitCodeSuccess, nil, expect.Contains(testutil.NginxAlpineIndexHTMLSnippet)),
+		},
+		{
+			Description: "containers on another network do not reach the container",
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("run", "--rm", "--network", data.Identifier("net-2"), testutil.CommonImage,
+					"wget", "-T", "5", "-qO-", "http://"+data.Labels().Get("ip"))
+			},
+			Expected: test.Expects(expect.ExitCodeGenericFail, nil, nil),
+		},
+	}
+
+	testCase.Run(t)
+}
+
 // TestReservePorts tests that a published port appears
 // as a listening port on the host.
 // See https://github.com/containerd/nerdctl/pull/4526
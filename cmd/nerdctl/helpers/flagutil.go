@@ -23,6 +23,7 @@ import (
 
 	"github.com/containerd/nerdctl/v2/pkg/api/types"
 	"github.com/containerd/nerdctl/v2/pkg/fs"
+	"github.com/containerd/nerdctl/v2/pkg/healthcheck"
 )
 
 func VerifyOptions(cmd *cobra.Command) (opt types.ImageVerifyOptions, err error) {
@@ -52,7 +53,9 @@ func ValidateHealthcheckFlags(options types.ContainerCreateOptions) error {
 		options.HealthInterval != 0 ||
 			options.HealthTimeout != 0 ||
 			options.HealthRetries != 0 ||
-			options.HealthStartPeriod != 0
+			options.HealthStartPeriod != 0 ||
+			options.HealthStartInterval != 0 ||
+			(options.HealthOnFailure != "" && options.HealthOnFailure != healthcheck.OnFailureNone)
 
 	if options.NoHealthcheck {
 		if options.HealthCmd != "" || healthFlagsSet {
@@ -73,7 +76,10 @@ func ValidateHealthcheckFlags(options types.ContainerCreateOptions) error {
 	if options.HealthStartPeriod < 0 {
 		return fmt.Errorf("--health-start-period cannot be negative")
 	}
-	return nil
+	if options.HealthStartInterval < 0 {
+		return fmt.Errorf("--health-start-interval cannot be negative")
+	}
+	return healthcheck.ValidateOnFailureAction(options.HealthOnFailure)
 }
 
 func ProcessRootCmdFlags(cmd *cobra.Command) (types.GlobalCommandOptions, error) {
@@ -158,6 +164,14 @@ func ProcessRootCmdFlags(cmd *cobra.Command) (types.GlobalCommandOptions, error)
 	if err != nil {
 		return types.GlobalCommandOptions{}, err
 	}
+	embeddedDNS, err := cmd.Flags().GetBool("embedded-dns")
+	if err != nil {
+		return types.GlobalCommandOptions{}, err
+	}
+	firewallBackend, err := cmd.Flags().GetString("firewall-backend")
+	if err != nil {
+		return types.GlobalCommandOptions{}, err
+	}
 	// Point to dataRoot for filesystem-helpers implementing rollback / backups.
 	err = fs.InitFS(dataRoot)
 	if err != nil {
@@ -185,6 +199,8 @@ func ProcessRootCmdFlags(cmd *cobra.Command) (types.GlobalCommandOptions, error)
 		DNSOpts:          dnsOpts,
 		DNSSearch:        dnsSearch,
 		SelinuxEnabled:   selinuxEnabled,
+		EmbeddedDNS:      embeddedDNS,
+		FirewallBackend:  firewallBackend,
 	}, nil
 }
 
@@ -49,6 +49,9 @@ func Command() *cobra.Command {
 		encryptCommand(),
 		decryptCommand(),
 		pruneCommand(),
+		mountCommand(),
+		unmountCommand(),
+		diffCommand(),
 	)
 	return cmd
 }
@@ -0,0 +1,96 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package image
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/containerd/nerdctl/v2/cmd/nerdctl/completion"
+	"github.com/containerd/nerdctl/v2/cmd/nerdctl/helpers"
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/clientutil"
+	"github.com/containerd/nerdctl/v2/pkg/cmd/image"
+)
+
+func diffCommand() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:               "diff [flags] IMAGE1 IMAGE2",
+		Short:             "Show the differences of IMAGE2 from IMAGE1 in layers, configuration and filesystem contents",
+		Args:              helpers.IsExactArgs(2),
+		RunE:              diffAction,
+		ValidArgsFunction: diffShellComplete,
+		SilenceUsage:      true,
+		SilenceErrors:     true,
+	}
+	cmd.Flags().String("format", "", "Format the output using the given format (json)")
+	cmd.RegisterFlagCompletionFunc("format", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{"json"}, cobra.ShellCompDirectiveNoFileComp
+	})
+	cmd.Flags().Bool("files", false, "Also compare the filesystem contents of the images")
+	cmd.Flags().String("platform", "", "Compare the images for a specific platform")
+	cmd.RegisterFlagCompletionFunc("platform", completion.Platforms)
+	return cmd
+}
+
+func diffOptions(cmd *cobra.Command) (types.ImageDiffOptions, error) {
+	globalOptions, err := helpers.ProcessRootCmdFlags(cmd)
+	if err != nil {
+		return types.ImageDiffOptions{}, err
+	}
+	format, err := cmd.Flags().GetString("format")
+	if err != nil {
+		return types.ImageDiffOptions{}, err
+	}
+	files, err := cmd.Flags().GetBool("files")
+	if err != nil {
+		return types.ImageDiffOptions{}, err
+	}
+	platform, err := cmd.Flags().GetString("platform")
+	if err != nil {
+		return types.ImageDiffOptions{}, err
+	}
+	return types.ImageDiffOptions{
+		Stdout:   cmd.OutOrStdout(),
+		GOptions: globalOptions,
+		Format:   format,
+		Files:    files,
+		Platform: platform,
+	}, nil
+}
+
+func diffAction(cmd *cobra.Command, args []string) error {
+	options, err := diffOptions(cmd)
+	if err != nil {
+		return err
+	}
+
+	client, ctx, cancel, err := clientutil.NewClient(cmd.Context(), options.GOptions.Namespace, options.GOptions.Address)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	return image.Diff(ctx, client, args[0], args[1], options)
+}
+
+func diffShellComplete(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) < 2 {
+		// show image names
+		return completion.ImageNames(cmd)
+	}
+	return nil, cobra.ShellCompDirectiveNoFileComp
+}
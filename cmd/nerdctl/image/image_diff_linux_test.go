@@ -0,0 +1,88 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package image
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/containerd/nerdctl/mod/tigron/expect"
+	"github.com/containerd/nerdctl/mod/tigron/require"
+	"github.com/containerd/nerdctl/mod/tigron/test"
+	"github.com/containerd/nerdctl/mod/tigron/tig"
+
+	"github.com/containerd/nerdctl/v2/pkg/cmd/image"
+	"github.com/containerd/nerdctl/v2/pkg/testutil"
+	"github.com/containerd/nerdctl/v2/pkg/testutil/nerdtest"
+)
+
+func TestImageDiff(t *testing.T) {
+	testCase := nerdtest.Setup()
+
+	testCase.Require = require.Not(nerdtest.Docker)
+
+	testCase.Setup = func(data test.Data, helpers test.Helpers) {
+		helpers.Ensure("pull", "--quiet", testutil.CommonImage)
+		helpers.Ensure("run", "--name", data.Identifier(), testutil.CommonImage, "sh", "-c", "echo foo > /foo")
+		helpers.Ensure("commit", "--change", `CMD ["cat", "/foo"]`, data.Identifier(), data.Identifier())
+	}
+
+	testCase.Cleanup = func(data test.Data, helpers test.Helpers) {
+		helpers.Anyhow("rm", "-f", data.Identifier())
+		helpers.Anyhow("rmi", "-f", data.Identifier())
+	}
+
+	testCase.SubTests = []*test.Case{
+		{
+			Description: "same image",
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("image", "diff", testutil.CommonImage, testutil.CommonImage)
+			},
+			Expected: test.Expects(expect.ExitCodeSuccess, nil, expect.Equals("")),
+		},
+		{
+			Description: "committed image",
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("image", "diff", "--format=json", testutil.CommonImage, data.Identifier())
+			},
+			Expected: test.Expects(expect.ExitCodeSuccess, nil, expect.JSON(image.ImageDiff{}, func(diff image.ImageDiff, t tig.T) {
+				assert.Equal(t, len(diff.Layers), 1)
+				assert.Equal(t, diff.Layers[0].Kind, image.DiffAdded)
+				assert.Assert(t, slices.Contains(diff.Config,
+					image.ConfigDiff{Kind: image.DiffChanged, Field: "Cmd", Before: `["/bin/sh"]`, After: `["cat","/foo"]`}))
+				assert.Assert(t, len(diff.Files) == 0)
+			})),
+		},
+		{
+			Description: "committed image with files",
+			Require:     nerdtest.Rootful,
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("image", "diff", "--format=json", "--files", testutil.CommonImage, data.Identifier())
+			},
+			Expected: test.Expects(expect.ExitCodeSuccess, nil, func(stdout string, t tig.T) {
+				var diff image.ImageDiff
+				assert.NilError(t, json.Unmarshal([]byte(stdout), &diff))
+				assert.Assert(t, slices.Contains(diff.Files, image.FileDiff{Kind: image.DiffAdded, Path: "/foo"}))
+			}),
+		},
+	}
+
+	testCase.Run(t)
+}
@@ -37,7 +37,7 @@ func LoadCommand() *cobra.Command {
 		SilenceErrors: true,
 	}
 
-	cmd.Flags().StringP("input", "i", "", "Read from tar archive file, instead of STDIN")
+	cmd.Flags().StringP("input", "i", "", "Read from tar archive file (optionally compressed with gzip or zstd) or OCI layout directory, instead of STDIN")
 	cmd.Flags().BoolP("quiet", "q", false, "Suppress the load output")
 
 	// #region platform flags
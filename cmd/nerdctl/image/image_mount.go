@@ -0,0 +1,118 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package image
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/containerd/nerdctl/v2/cmd/nerdctl/completion"
+	"github.com/containerd/nerdctl/v2/cmd/nerdctl/helpers"
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/clientutil"
+	"github.com/containerd/nerdctl/v2/pkg/cmd/image"
+)
+
+func mountCommand() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:               "mount [flags] IMAGE [MOUNTPOINT]",
+		Short:             "Mount the root filesystem of an image read-only, and print the mount point",
+		Long:              "Mount the root filesystem of an image read-only, and print the mount point.\nThe mount point defaults to a directory under the data root.",
+		Args:              cobra.RangeArgs(1, 2),
+		RunE:              mountAction,
+		ValidArgsFunction: mountShellComplete,
+		SilenceUsage:      true,
+		SilenceErrors:     true,
+	}
+	cmd.Flags().String("platform", "", "Mount the image for a specific platform")
+	cmd.RegisterFlagCompletionFunc("platform", completion.Platforms)
+	return cmd
+}
+
+func mountAction(cmd *cobra.Command, args []string) error {
+	globalOptions, err := helpers.ProcessRootCmdFlags(cmd)
+	if err != nil {
+		return err
+	}
+	platform, err := cmd.Flags().GetString("platform")
+	if err != nil {
+		return err
+	}
+
+	options := types.ImageMountOptions{
+		Stdout:   cmd.OutOrStdout(),
+		GOptions: globalOptions,
+		Platform: platform,
+	}
+	if len(args) > 1 {
+		options.Target = args[1]
+	}
+
+	client, ctx, cancel, err := clientutil.NewClient(cmd.Context(), options.GOptions.Namespace, options.GOptions.Address)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	return image.Mount(ctx, client, args[0], options)
+}
+
+func mountShellComplete(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) == 0 {
+		// show image names
+		return completion.ImageNames(cmd)
+	}
+	return nil, cobra.ShellCompDirectiveFilterDirs
+}
+
+func unmountCommand() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:               "unmount [flags] IMAGE|MOUNTPOINT [IMAGE|MOUNTPOINT...]",
+		Aliases:           []string{"umount"},
+		Short:             "Unmount images mounted with `nerdctl image mount`",
+		Args:              cobra.MinimumNArgs(1),
+		RunE:              unmountAction,
+		ValidArgsFunction: unmountShellComplete,
+		SilenceUsage:      true,
+		SilenceErrors:     true,
+	}
+	return cmd
+}
+
+func unmountAction(cmd *cobra.Command, args []string) error {
+	globalOptions, err := helpers.ProcessRootCmdFlags(cmd)
+	if err != nil {
+		return err
+	}
+
+	options := types.ImageUnmountOptions{
+		Stdout:   cmd.OutOrStdout(),
+		GOptions: globalOptions,
+	}
+
+	client, ctx, cancel, err := clientutil.NewClient(cmd.Context(), options.GOptions.Namespace, options.GOptions.Address)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	return image.Unmount(ctx, client, args, options)
+}
+
+func unmountShellComplete(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	// show image names
+	return completion.ImageNames(cmd)
+}
@@ -0,0 +1,115 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package image
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/containerd/nerdctl/mod/tigron/expect"
+	"github.com/containerd/nerdctl/mod/tigron/require"
+	"github.com/containerd/nerdctl/mod/tigron/test"
+	"github.com/containerd/nerdctl/mod/tigron/tig"
+
+	"github.com/containerd/nerdctl/v2/pkg/testutil"
+	"github.com/containerd/nerdctl/v2/pkg/testutil/nerdtest"
+)
+
+func TestImageMount(t *testing.T) {
+	testCase := nerdtest.Setup()
+
+	testCase.Require = require.All(require.Not(nerdtest.Docker), nerdtest.Rootful)
+
+	testCase.Setup = func(data test.Data, helpers test.Helpers) {
+		helpers.Ensure("pull", "--quiet", testutil.CommonImage)
+	}
+
+	// Mounts are shared between the tags of the same image, so the subtests must not run in parallel.
+	testCase.SubTests = []*test.Case{
+		{
+			Description: "mount on the default mount point, then unmount by image",
+			NoParallel:  true,
+			Setup: func(data test.Data, helpers test.Helpers) {
+				helpers.Ensure("tag", testutil.CommonImage, data.Identifier())
+			},
+			Cleanup: func(data test.Data, helpers test.Helpers) {
+				helpers.Anyhow("image", "unmount", data.Identifier())
+				helpers.Anyhow("rmi", "-f", data.Identifier())
+			},
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("image", "mount", data.Identifier())
+			},
+			Expected: func(data test.Data, helpers test.Helpers) *test.Expected {
+				return &test.Expected{
+					Output: func(stdout string, t tig.T) {
+						mountPoint := strings.TrimSpace(stdout)
+						b, err := os.ReadFile(filepath.Join(mountPoint, "etc", "os-release"))
+						assert.NilError(t, err)
+						assert.Assert(t, strings.Contains(string(b), "Alpine"))
+						// The mount is read-only
+						assert.Assert(t, os.WriteFile(filepath.Join(mountPoint, "foo"), []byte("foo"), 0o644) != nil)
+						// Mounting again returns the same mount point
+						assert.Equal(t, strings.TrimSpace(helpers.Capture("image", "mount", data.Identifier())), mountPoint)
+
+						helpers.Ensure("image", "unmount", data.Identifier())
+						_, err = os.Stat(mountPoint)
+						assert.Assert(t, os.IsNotExist(err))
+					},
+				}
+			},
+		},
+		{
+			Description: "mount on a given mount point, then unmount by mount point",
+			NoParallel:  true,
+			Setup: func(data test.Data, helpers test.Helpers) {
+				helpers.Ensure("tag", testutil.CommonImage, data.Identifier())
+			},
+			Cleanup: func(data test.Data, helpers test.Helpers) {
+				helpers.Anyhow("image", "unmount", data.Identifier())
+				helpers.Anyhow("rmi", "-f", data.Identifier())
+			},
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("image", "mount", data.Identifier(), data.Temp().Path("rootfs"))
+			},
+			Expected: func(data test.Data, helpers test.Helpers) *test.Expected {
+				return &test.Expected{
+					Output: func(stdout string, t tig.T) {
+						assert.Equal(t, strings.TrimSpace(stdout), data.Temp().Path("rootfs"))
+						_, err := os.Stat(data.Temp().Path("rootfs", "etc", "os-release"))
+						assert.NilError(t, err)
+
+						helpers.Ensure("image", "unmount", data.Temp().Path("rootfs"))
+						_, err = os.Stat(data.Temp().Path("rootfs", "etc", "os-release"))
+						assert.Assert(t, os.IsNotExist(err))
+					},
+				}
+			},
+		},
+		{
+			Description: "unmount an image that is not mounted",
+			NoParallel:  true,
+			Command:     test.Command("image", "unmount", testutil.CommonImage),
+			Expected:    test.Expects(expect.ExitCodeGenericFail, nil, nil),
+		},
+	}
+
+	testCase.Run(t)
+}
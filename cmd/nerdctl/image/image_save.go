@@ -44,8 +44,16 @@ func SaveCommand() *cobra.Command {
 		SilenceUsage:      true,
 		SilenceErrors:     true,
 	}
-	cmd.Flags().StringP("output", "o", "", "Write to a file, instead of STDOUT")
+	cmd.Flags().StringP("output", "o", "", "Write to a file (or a directory for --format=oci-dir), instead of STDOUT")
 	cmd.Flags().BoolP("quiet", "q", false, "Suppress the progress output")
+	cmd.Flags().String("format", image.SaveFormatDockerArchive, "Format of the saved images (docker-archive|oci-archive|oci-dir)")
+	cmd.RegisterFlagCompletionFunc("format", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{image.SaveFormatDockerArchive, image.SaveFormatOCIArchive, image.SaveFormatOCIDir}, cobra.ShellCompDirectiveNoFileComp
+	})
+	cmd.Flags().String("compress", "none", "Compress the archive (none|gzip|zstd)")
+	cmd.RegisterFlagCompletionFunc("compress", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{"none", "gzip", "zstd"}, cobra.ShellCompDirectiveNoFileComp
+	})
 
 	// #region platform flags
 	// platform is defined as StringSlice, not StringArray, to allow specifying "--platform=amd64,arm64"
@@ -75,12 +83,22 @@ func saveOptions(cmd *cobra.Command) (types.ImageSaveOptions, error) {
 	if err != nil {
 		return types.ImageSaveOptions{}, err
 	}
+	format, err := cmd.Flags().GetString("format")
+	if err != nil {
+		return types.ImageSaveOptions{}, err
+	}
+	compress, err := cmd.Flags().GetString("compress")
+	if err != nil {
+		return types.ImageSaveOptions{}, err
+	}
 
 	return types.ImageSaveOptions{
 		GOptions:     globalOptions,
 		AllPlatforms: allPlatforms,
 		Platform:     platform,
 		Quiet:        quiet,
+		Format:       format,
+		Compression:  compress,
 	}, err
 }
 
@@ -94,6 +112,11 @@ func saveAction(cmd *cobra.Command, args []string) error {
 	outputPath, err := cmd.Flags().GetString("output")
 	if err != nil {
 		return err
+	} else if options.Format == image.SaveFormatOCIDir {
+		if outputPath == "" {
+			return fmt.Errorf("--format=%s requires -o, --output to be set to a directory", image.SaveFormatOCIDir)
+		}
+		options.Output = outputPath
 	} else if outputPath != "" {
 		f, err := os.OpenFile(outputPath, os.O_CREATE|os.O_WRONLY, 0644)
 		if err != nil {
@@ -119,7 +142,7 @@ func saveAction(cmd *cobra.Command, args []string) error {
 	}
 	defer cancel()
 
-	if err = image.Save(ctx, client, args, options); err != nil && outputPath != "" {
+	if err = image.Save(ctx, client, args, options); err != nil && outputPath != "" && options.Format != image.SaveFormatOCIDir {
 		os.Remove(outputPath)
 	}
 	return err
@@ -18,6 +18,7 @@ package image
 
 import (
 	"os"
+	"os/exec"
 	"path/filepath"
 	"runtime"
 	"strings"
@@ -230,3 +231,94 @@ func TestSaveMultipleImagesWithSameIDAndLoad(t *testing.T) {
 
 	testCase.Run(t)
 }
+
+func TestSaveFormatsAndCompression(t *testing.T) {
+	testCase := nerdtest.Setup()
+
+	// --format and --compress are nerdctl-specific flags
+	testCase.Require = require.All(require.Not(require.Windows), require.Not(nerdtest.Docker))
+
+	saveAndLoad := func(saveArgs func(data test.Data) []string, input func(data test.Data) string) *test.Case {
+		return &test.Case{
+			Setup: func(data test.Data, helpers test.Helpers) {
+				helpers.Ensure("pull", "--quiet", testutil.CommonImage)
+				helpers.Ensure("tag", testutil.CommonImage, data.Identifier())
+				helpers.Ensure(append(append([]string{"save", "--quiet"}, saveArgs(data)...), data.Identifier())...)
+				helpers.Ensure("rmi", data.Identifier())
+			},
+			Cleanup: func(data test.Data, helpers test.Helpers) {
+				helpers.Anyhow("rmi", "-f", data.Identifier())
+			},
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("load", "-i", input(data))
+			},
+			Expected: func(data test.Data, helpers test.Helpers) *test.Expected {
+				return &test.Expected{
+					Output: func(stdout string, t tig.T) {
+						helpers.Ensure("image", "inspect", data.Identifier())
+					},
+				}
+			},
+		}
+	}
+	archive := func(name string) func(data test.Data) string {
+		return func(data test.Data) string {
+			return filepath.Join(data.Temp().Path(), name)
+		}
+	}
+
+	ociArchive := saveAndLoad(func(data test.Data) []string {
+		return []string{"--format=oci-archive", "-o", archive("out.tar")(data)}
+	}, archive("out.tar"))
+	ociArchive.Description = "oci-archive"
+	ociArchive.Expected = func(data test.Data, helpers test.Helpers) *test.Expected {
+		return &test.Expected{
+			Output: func(stdout string, t tig.T) {
+				helpers.Ensure("image", "inspect", data.Identifier())
+				// The docker compatibility manifest is omitted
+				out, err := exec.Command("tar", "tf", archive("out.tar")(data)).Output()
+				assert.NilError(t, err)
+				assert.Assert(t, strings.Contains(string(out), "index.json"))
+				assert.Assert(t, !strings.Contains(string(out), "manifest.json"))
+			},
+		}
+	}
+
+	gzipArchive := saveAndLoad(func(data test.Data) []string {
+		return []string{"--compress=gzip", "-o", archive("out.tar.gz")(data)}
+	}, archive("out.tar.gz"))
+	gzipArchive.Description = "docker-archive compressed with gzip"
+
+	zstdArchive := saveAndLoad(func(data test.Data) []string {
+		return []string{"--format=oci-archive", "--compress=zstd", "-o", archive("out.tar.zst")(data)}
+	}, archive("out.tar.zst"))
+	zstdArchive.Description = "oci-archive compressed with zstd"
+
+	ociDir := saveAndLoad(func(data test.Data) []string {
+		return []string{"--format=oci-dir", "-o", archive("layout")(data)}
+	}, archive("layout"))
+	ociDir.Description = "oci-dir"
+
+	testCase.SubTests = []*test.Case{
+		ociArchive,
+		gzipArchive,
+		zstdArchive,
+		ociDir,
+		{
+			Description: "oci-dir cannot be compressed",
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("save", "--format=oci-dir", "--compress=gzip", "-o", archive("layout")(data), testutil.CommonImage)
+			},
+			Expected: test.Expects(expect.ExitCodeGenericFail, nil, nil),
+		},
+		{
+			Description: "invalid format",
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("save", "--format=invalid", "-o", archive("out.tar")(data), testutil.CommonImage)
+			},
+			Expected: test.Expects(expect.ExitCodeGenericFail, nil, nil),
+		},
+	}
+
+	testCase.Run(t)
+}
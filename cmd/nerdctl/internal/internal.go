@@ -31,6 +31,8 @@ func Command() *cobra.Command {
 
 	cmd.AddCommand(
 		newInternalOCIHookCommandCommand(),
+		newInternalHealthCheckSupervisorCommand(),
+		newInternalDNSServerCommand(),
 	)
 
 	return cmd
@@ -57,10 +57,14 @@ func internalOCIHookAction(cmd *cobra.Command, args []string) error {
 	cniPath := globalOptions.CNIPath
 	cniNetconfpath := globalOptions.CNINetConfPath
 	bridgeIP := globalOptions.BridgeIP
+	nerdctlCmd, nerdctlArgs := helpers.GlobalFlags(cmd)
 	return ocihook.Run(os.Stdin, os.Stderr, event,
 		dataStore,
 		cniPath,
 		cniNetconfpath,
 		bridgeIP,
+		globalOptions.FirewallBackend,
+		nerdctlCmd,
+		nerdctlArgs,
 	)
 }
@@ -37,6 +37,8 @@ func Command() *cobra.Command {
 		createCommand(),
 		removeCommand(),
 		pruneCommand(),
+		connectCommand(),
+		disconnectCommand(),
 	)
 	return cmd
 }
@@ -0,0 +1,171 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package network
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/containerd/nerdctl/mod/tigron/expect"
+	"github.com/containerd/nerdctl/mod/tigron/test"
+	"github.com/containerd/nerdctl/mod/tigron/tig"
+
+	"github.com/containerd/nerdctl/v2/pkg/testutil"
+	"github.com/containerd/nerdctl/v2/pkg/testutil/nerdtest"
+)
+
+func TestNetworkConnect(t *testing.T) {
+	testCase := nerdtest.Setup()
+
+	testCase.Require = nerdtest.Rootful
+
+	testCase.SubTests = []*test.Case{
+		{
+			Description: "Connect a running container",
+			Setup: func(data test.Data, helpers test.Helpers) {
+				helpers.Ensure("network", "create", "--subnet", "10.5.70.0/24", data.Identifier())
+				helpers.Ensure("run", "-d", "--name", data.Identifier(), testutil.CommonImage, "sleep", nerdtest.Infinity)
+				nerdtest.EnsureContainerStarted(helpers, data.Identifier())
+				helpers.Ensure("network", "connect", "--ip", "10.5.70.42", "--alias", "db", data.Identifier(), data.Identifier())
+			},
+			Cleanup: func(data test.Data, helpers test.Helpers) {
+				helpers.Anyhow("rm", "-f", data.Identifier())
+				helpers.Anyhow("network", "rm", data.Identifier())
+			},
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("exec", data.Identifier(), "ip", "addr", "show", "eth1")
+			},
+			Expected: func(data test.Data, helpers test.Helpers) *test.Expected {
+				return &test.Expected{
+					Output: func(stdout string, t tig.T) {
+						assert.Assert(t, strings.Contains(stdout, "10.5.70.42"), stdout)
+						container := nerdtest.InspectContainer(helpers, data.Identifier())
+						nes, ok := container.NetworkSettings.Networks[data.Identifier()]
+						assert.Assert(t, ok, "network %s not found in inspect output", data.Identifier())
+						assert.Equal(t, nes.IPAddress, "10.5.70.42")
+						assert.DeepEqual(t, nes.Aliases, []string{"db"})
+					},
+				}
+			},
+		},
+		{
+			Description: "Connect a stopped container, attached on start",
+			Setup: func(data test.Data, helpers test.Helpers) {
+				helpers.Ensure("network", "create", "--subnet", "10.5.71.0/24", data.Identifier())
+				helpers.Ensure("create", "--name", data.Identifier(), testutil.CommonImage, "sleep", nerdtest.Infinity)
+				helpers.Ensure("network", "connect", "--ip", "10.5.71.42", data.Identifier(), data.Identifier())
+				helpers.Ensure("start", data.Identifier())
+				nerdtest.EnsureContainerStarted(helpers, data.Identifier())
+			},
+			Cleanup: func(data test.Data, helpers test.Helpers) {
+				helpers.Anyhow("rm", "-f", data.Identifier())
+				helpers.Anyhow("network", "rm", data.Identifier())
+			},
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("exec", data.Identifier(), "ip", "addr", "show", "eth1")
+			},
+			Expected: test.Expects(0, nil, expect.Contains("10.5.71.42")),
+		},
+		{
+			Description: "Connect twice",
+			Setup: func(data test.Data, helpers test.Helpers) {
+				helpers.Ensure("network", "create", data.Identifier())
+				helpers.Ensure("run", "-d", "--net", data.Identifier(), "--name", data.Identifier(), testutil.CommonImage, "sleep", nerdtest.Infinity)
+			},
+			Cleanup: func(data test.Data, helpers test.Helpers) {
+				helpers.Anyhow("rm", "-f", data.Identifier())
+				helpers.Anyhow("network", "rm", data.Identifier())
+			},
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("network", "connect", data.Identifier(), data.Identifier())
+			},
+			Expected: test.Expects(1, []error{errors.New("already connected")}, nil),
+		},
+		{
+			Description: "Connect a host network container",
+			Setup: func(data test.Data, helpers test.Helpers) {
+				helpers.Ensure("network", "create", data.Identifier())
+				helpers.Ensure("run", "-d", "--net", "host", "--name", data.Identifier(), testutil.CommonImage, "sleep", nerdtest.Infinity)
+			},
+			Cleanup: func(data test.Data, helpers test.Helpers) {
+				helpers.Anyhow("rm", "-f", data.Identifier())
+				helpers.Anyhow("network", "rm", data.Identifier())
+			},
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("network", "connect", data.Identifier(), data.Identifier())
+			},
+			Expected: test.Expects(1, []error{errors.New("does not support connecting networks")}, nil),
+		},
+		{
+			Description: "Connect with an IPv4 address as --ip6",
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("network", "connect", "--ip6", "10.5.72.42", "bridge", data.Identifier())
+			},
+			Expected: test.Expects(1, []error{errors.New("invalid IPv6 address")}, nil),
+		},
+	}
+
+	testCase.Run(t)
+}
+
+func TestNetworkDisconnect(t *testing.T) {
+	testCase := nerdtest.Setup()
+
+	testCase.Require = nerdtest.Rootful
+
+	testCase.SubTests = []*test.Case{
+		{
+			Description: "Disconnect a running container",
+			Setup: func(data test.Data, helpers test.Helpers) {
+				helpers.Ensure("network", "create", data.Identifier())
+				helpers.Ensure("run", "-d", "--name", data.Identifier(), testutil.CommonImage, "sleep", nerdtest.Infinity)
+				nerdtest.EnsureContainerStarted(helpers, data.Identifier())
+				helpers.Ensure("network", "connect", data.Identifier(), data.Identifier())
+				helpers.Ensure("network", "disconnect", data.Identifier(), data.Identifier())
+			},
+			Cleanup: func(data test.Data, helpers test.Helpers) {
+				helpers.Anyhow("rm", "-f", data.Identifier())
+				helpers.Anyhow("network", "rm", data.Identifier())
+			},
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				// the network is no longer in use
+				return helpers.Command("network", "rm", data.Identifier())
+			},
+			Expected: test.Expects(0, nil, nil),
+		},
+		{
+			Description: "Disconnect a network the container is not connected to",
+			Setup: func(data test.Data, helpers test.Helpers) {
+				helpers.Ensure("network", "create", data.Identifier())
+				helpers.Ensure("create", "--name", data.Identifier(), testutil.CommonImage)
+			},
+			Cleanup: func(data test.Data, helpers test.Helpers) {
+				helpers.Anyhow("rm", "-f", data.Identifier())
+				helpers.Anyhow("network", "rm", data.Identifier())
+			},
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("network", "disconnect", data.Identifier(), data.Identifier())
+			},
+			Expected: test.Expects(1, []error{errors.New("is not connected")}, nil),
+		},
+	}
+
+	testCase.Run(t)
+}
@@ -24,6 +24,7 @@ import (
 	"github.com/containerd/nerdctl/v2/cmd/nerdctl/completion"
 	"github.com/containerd/nerdctl/v2/cmd/nerdctl/helpers"
 	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/clientutil"
 	"github.com/containerd/nerdctl/v2/pkg/cmd/network"
 	"github.com/containerd/nerdctl/v2/pkg/identifiers"
 	"github.com/containerd/nerdctl/v2/pkg/strutil"
@@ -116,7 +117,14 @@ func createAction(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	return network.Create(types.NetworkCreateOptions{
+	// Networks do not need containerd, so the event is only published when it is reachable
+	client, ctx, cancel, err := clientutil.NewClient(cmd.Context(), globalOptions.Namespace, globalOptions.Address)
+	if err != nil {
+		client, ctx = nil, cmd.Context()
+	} else {
+		defer cancel()
+	}
+	return network.Create(ctx, client, types.NetworkCreateOptions{
 		GOptions:     globalOptions,
 		Name:         name,
 		Driver:       driver,
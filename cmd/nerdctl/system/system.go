@@ -36,6 +36,8 @@ func Command() *cobra.Command {
 		EventsCommand(),
 		InfoCommand(),
 		pruneCommand(),
+		dfCommand(),
+		serveCommand(),
 	)
 	return cmd
 }
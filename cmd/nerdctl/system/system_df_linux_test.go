@@ -0,0 +1,117 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package system
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/containerd/nerdctl/mod/tigron/expect"
+	"github.com/containerd/nerdctl/mod/tigron/require"
+	"github.com/containerd/nerdctl/mod/tigron/test"
+	"github.com/containerd/nerdctl/mod/tigron/tig"
+
+	"github.com/containerd/nerdctl/v2/pkg/testutil"
+	"github.com/containerd/nerdctl/v2/pkg/testutil/nerdtest"
+)
+
+func TestSystemDf(t *testing.T) {
+	testCase := nerdtest.Setup()
+
+	testCase.Setup = func(data test.Data, helpers test.Helpers) {
+		helpers.Ensure("volume", "create", data.Identifier())
+		helpers.Ensure("run", "-d", "--name", data.Identifier(), "-v", data.Identifier()+":/data",
+			testutil.CommonImage, "sh", "-euxc", "head -c 4096 /dev/zero > /data/file; sleep infinity")
+	}
+
+	testCase.Cleanup = func(data test.Data, helpers test.Helpers) {
+		helpers.Anyhow("rm", "-f", data.Identifier())
+		helpers.Anyhow("volume", "rm", data.Identifier())
+	}
+
+	testCase.SubTests = []*test.Case{
+		{
+			Description: "summary",
+			Command:     test.Command("system", "df"),
+			Expected: test.Expects(0, nil, expect.Contains(
+				"TYPE", "TOTAL", "ACTIVE", "SIZE", "RECLAIMABLE",
+				"Images", "Containers", "Local Volumes", "Build Cache",
+			)),
+		},
+		{
+			Description: "format",
+			Command:     test.Command("system", "df", "--format", "{{json .}}"),
+			Expected: test.Expects(0, nil, func(stdout string, t tig.T) {
+				lines := strings.Split(strings.TrimSpace(stdout), "\n")
+				assert.Equal(t, len(lines), 4, stdout)
+				var row map[string]string
+				assert.NilError(t, json.Unmarshal([]byte(lines[0]), &row))
+				assert.Equal(t, row["Type"], "Images")
+				assert.Assert(t, row["Active"] != "0", stdout)
+			}),
+		},
+		{
+			Description: "verbose",
+			Command:     test.Command("system", "df", "-v"),
+			Expected: func(data test.Data, helpers test.Helpers) *test.Expected {
+				return &test.Expected{
+					Output: expect.Contains(
+						"Images space usage:",
+						"Containers space usage:",
+						"Local Volumes space usage:",
+						"Build cache usage:",
+						data.Identifier(),
+					),
+				}
+			},
+		},
+		{
+			Description: "verbose format",
+			// The JSON layout of `docker system df -v` differs
+			Require: require.Not(nerdtest.Docker),
+			Command: test.Command("system", "df", "-v", "--format", "{{json .}}"),
+			Expected: func(data test.Data, helpers test.Helpers) *test.Expected {
+				return &test.Expected{
+					Output: func(stdout string, t tig.T) {
+						var du struct {
+							Volumes []struct {
+								Name  string
+								Links string
+								Size  string
+							}
+						}
+						assert.NilError(t, json.Unmarshal([]byte(stdout), &du), stdout)
+						found := false
+						for _, v := range du.Volumes {
+							if v.Name == data.Identifier() {
+								found = true
+								assert.Equal(t, v.Links, "1")
+								assert.Assert(t, v.Size != "0B", stdout)
+							}
+						}
+						assert.Assert(t, found, stdout)
+					},
+				}
+			},
+		},
+	}
+
+	testCase.Run(t)
+}
@@ -178,3 +178,88 @@ func TestEventFilters(t *testing.T) {
 
 	testCase.Run(t)
 }
+
+func TestEventLifecycle(t *testing.T) {
+	testCase := nerdtest.Setup()
+
+	testCase.Setup = func(data test.Data, helpers test.Helpers) {
+		helpers.Ensure("pull", testutil.CommonImage)
+	}
+
+	testCase.SubTests = []*test.Case{
+		{
+			Description: "die event carries the exit code and the container name",
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				cmd := helpers.Command("events", "--filter", "type=container", "--filter", "container="+data.Identifier(), "--format", "json")
+				cmd.WithTimeout(10 * time.Second)
+				cmd.Background()
+				helpers.Ensure("run", "--name", data.Identifier(), testutil.CommonImage, "sh", "-c", "exit 3")
+				return cmd
+			},
+			Cleanup: func(data test.Data, helpers test.Helpers) {
+				helpers.Anyhow("rm", "-f", data.Identifier())
+			},
+			Expected: func(data test.Data, helpers test.Helpers) *test.Expected {
+				return &test.Expected{
+					ExitCode: expect.ExitCodeTimeout,
+					Output: expect.Contains(
+						"\"Action\":\"create\"",
+						"\"Action\":\"start\"",
+						"\"Action\":\"die\"",
+						"\"exitCode\":\"3\"",
+						"\"name\":\""+data.Identifier()+"\"",
+					),
+				}
+			},
+		},
+		{
+			Description: "kill and stop",
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				helpers.Ensure("run", "-d", "--name", data.Identifier(), testutil.CommonImage, "sleep", "infinity")
+				cmd := helpers.Command("events", "--filter", "container="+data.Identifier(), "--format", "json")
+				cmd.WithTimeout(10 * time.Second)
+				cmd.Background()
+				helpers.Ensure("stop", "-t", "1", data.Identifier())
+				return cmd
+			},
+			Cleanup: func(data test.Data, helpers test.Helpers) {
+				helpers.Anyhow("rm", "-f", data.Identifier())
+			},
+			Expected: test.Expects(expect.ExitCodeTimeout, nil, expect.Contains(
+				"\"Action\":\"kill\"",
+				"\"Action\":\"die\"",
+				"\"Action\":\"stop\"",
+			)),
+		},
+		{
+			Description: "volume events",
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				cmd := helpers.Command("events", "--filter", "type=volume", "--format", "json")
+				cmd.WithTimeout(10 * time.Second)
+				cmd.Background()
+				helpers.Ensure("volume", "create", data.Identifier())
+				helpers.Ensure("volume", "rm", data.Identifier())
+				return cmd
+			},
+			Cleanup: func(data test.Data, helpers test.Helpers) {
+				helpers.Anyhow("volume", "rm", data.Identifier())
+			},
+			Expected: func(data test.Data, helpers test.Helpers) *test.Expected {
+				return &test.Expected{
+					ExitCode: expect.ExitCodeTimeout,
+					Output: expect.All(
+						expect.Contains("\"Type\":\"volume\"", "\"Action\":\"create\"", "\"Action\":\"destroy\"", data.Identifier()),
+						expect.DoesNotContain("\"Type\":\"container\""),
+					),
+				}
+			},
+		},
+		{
+			Description: "until ends the stream",
+			Command:     test.Command("events", "--until", "1s"),
+			Expected:    test.Expects(0, nil, nil),
+		},
+	}
+
+	testCase.Run(t)
+}
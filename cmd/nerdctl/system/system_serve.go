@@ -0,0 +1,127 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package system
+
+import (
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+
+	"github.com/spf13/cobra"
+
+	"github.com/containerd/nerdctl/v2/cmd/nerdctl/container"
+	"github.com/containerd/nerdctl/v2/cmd/nerdctl/helpers"
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/apiserver"
+	"github.com/containerd/nerdctl/v2/pkg/clientutil"
+	"github.com/containerd/nerdctl/v2/pkg/rootlessutil"
+)
+
+func serveCommand() *cobra.Command {
+	shortHelp := "Serve a subset of the Docker Engine API"
+	longHelp := shortHelp + `
+
+The following endpoints are implemented:
+  /_ping, /version, /info, /events
+  /containers/json, /containers/create, /containers/{id}/json, /containers/{id}/start,
+  /containers/{id}/stop, /containers/{id}/logs, DELETE /containers/{id}
+  /images/json, /images/create
+  /networks, /networks/create, /networks/{id}
+  /volumes, /volumes/create, /volumes/{name}
+
+TCP hosts are only served with TLS. Specify --tlscacert to require client certificates signed by the CA.
+
+WARNING: Access to the API is equivalent to root access to the host.`
+	var cmd = &cobra.Command{
+		Use:           "serve",
+		Args:          cobra.NoArgs,
+		Short:         shortHelp,
+		Long:          longHelp,
+		RunE:          serveAction,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+	cmd.Flags().StringP("host", "H", defaultServeHost(), `Address to listen on, "unix://PATH" or "tcp://HOST:PORT"`)
+	cmd.Flags().String("tlscert", "", "Path to the TLS certificate file, required for a TCP host")
+	cmd.Flags().String("tlskey", "", "Path to the TLS key file, required for a TCP host")
+	cmd.Flags().String("tlscacert", "", "Path to the CA certificate file to verify the client certificates with")
+	return cmd
+}
+
+func defaultServeHost() string {
+	if rootlessutil.IsRootless() {
+		if xdr, err := rootlessutil.XDGRuntimeDir(); err == nil {
+			return "unix://" + filepath.Join(xdr, "nerdctl.sock")
+		}
+	}
+	return "unix:///run/nerdctl.sock"
+}
+
+func serveOptions(cmd *cobra.Command) (types.SystemServeOptions, error) {
+	globalOptions, err := helpers.ProcessRootCmdFlags(cmd)
+	if err != nil {
+		return types.SystemServeOptions{}, err
+	}
+	host, err := cmd.Flags().GetString("host")
+	if err != nil {
+		return types.SystemServeOptions{}, err
+	}
+	tlsCert, err := cmd.Flags().GetString("tlscert")
+	if err != nil {
+		return types.SystemServeOptions{}, err
+	}
+	tlsKey, err := cmd.Flags().GetString("tlskey")
+	if err != nil {
+		return types.SystemServeOptions{}, err
+	}
+	tlsCACert, err := cmd.Flags().GetString("tlscacert")
+	if err != nil {
+		return types.SystemServeOptions{}, err
+	}
+	nerdctlCmd, nerdctlArgs := helpers.GlobalFlags(cmd)
+	return types.SystemServeOptions{
+		Stdout:      cmd.OutOrStdout(),
+		GOptions:    globalOptions,
+		Host:        host,
+		TLSCert:     tlsCert,
+		TLSKey:      tlsKey,
+		TLSCACert:   tlsCACert,
+		NerdctlCmd:  nerdctlCmd,
+		NerdctlArgs: nerdctlArgs,
+	}, nil
+}
+
+func serveAction(cmd *cobra.Command, args []string) error {
+	options, err := serveOptions(cmd)
+	if err != nil {
+		return err
+	}
+
+	client, ctx, cancel, err := clientutil.NewClient(cmd.Context(), options.GOptions.Namespace, options.GOptions.Address)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	parseCreateArgs := func(args []string) (types.ContainerCreateOptions, types.NetworkOptions, []string, error) {
+		return container.ParseCreateArgs(cmd.Root(), args)
+	}
+	return apiserver.Serve(ctx, client, options, parseCreateArgs)
+}
@@ -0,0 +1,148 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package system
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/containerd/nerdctl/mod/tigron/expect"
+	"github.com/containerd/nerdctl/mod/tigron/require"
+	"github.com/containerd/nerdctl/mod/tigron/test"
+	"github.com/containerd/nerdctl/mod/tigron/tig"
+
+	"github.com/containerd/nerdctl/v2/pkg/testutil"
+	"github.com/containerd/nerdctl/v2/pkg/testutil/nerdtest"
+)
+
+// apiGet sends a GET request to the API served on the socket, retrying while the server starts.
+func apiGet(t tig.T, sock, path string) (*http.Response, []byte) {
+	client := &http.Client{
+		Timeout: 10 * time.Second,
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				return (&net.Dialer{}).DialContext(ctx, "unix", sock)
+			},
+		},
+	}
+	var (
+		resp *http.Response
+		err  error
+	)
+	for range 10 {
+		resp, err = client.Get("http://localhost" + path)
+		if err == nil {
+			break
+		}
+		time.Sleep(time.Second)
+	}
+	assert.NilError(t, err)
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	assert.NilError(t, err)
+	return resp, body
+}
+
+func TestSystemServe(t *testing.T) {
+	testCase := nerdtest.Setup()
+
+	testCase.Require = require.Not(nerdtest.Docker)
+
+	var server test.TestableCommand
+
+	testCase.Setup = func(data test.Data, helpers test.Helpers) {
+		helpers.Ensure("run", "-d", "--name", data.Identifier(), testutil.CommonImage, "sleep", "infinity")
+		data.Labels().Set("sock", data.Temp().Path("nerdctl.sock"))
+		server = helpers.Command("system", "serve", "--host", "unix://"+data.Labels().Get("sock"))
+		server.WithTimeout(60 * time.Second)
+		server.Background()
+	}
+
+	testCase.Cleanup = func(data test.Data, helpers test.Helpers) {
+		if server != nil {
+			server.Signal(os.Interrupt)
+		}
+		helpers.Anyhow("rm", "-f", data.Identifier())
+	}
+
+	testCase.Command = func(data test.Data, helpers test.Helpers) test.TestableCommand {
+		return helpers.Command("container", "inspect", "--format", "{{.ID}}", data.Identifier())
+	}
+
+	testCase.Expected = func(data test.Data, helpers test.Helpers) *test.Expected {
+		return &test.Expected{
+			Output: func(stdout string, t tig.T) {
+				sock := data.Labels().Get("sock")
+				id := strings.TrimSpace(stdout)
+
+				resp, body := apiGet(t, sock, "/_ping")
+				assert.Equal(t, resp.StatusCode, http.StatusOK)
+				assert.Equal(t, string(body), "OK")
+				assert.Assert(t, resp.Header.Get("Api-Version") != "")
+
+				resp, body = apiGet(t, sock, "/v1.44/containers/json")
+				assert.Equal(t, resp.StatusCode, http.StatusOK)
+				var containers []struct {
+					ID    string `json:"Id"`
+					Names []string
+					State string
+				}
+				assert.NilError(t, json.Unmarshal(body, &containers))
+				found := false
+				for _, c := range containers {
+					if c.ID == id {
+						found = true
+						assert.DeepEqual(t, c.Names, []string{"/" + data.Identifier()})
+						assert.Equal(t, c.State, "running")
+					}
+				}
+				assert.Assert(t, found, "container %s not found in %s", id, string(body))
+
+				resp, body = apiGet(t, sock, "/containers/"+data.Identifier()+"/json")
+				assert.Equal(t, resp.StatusCode, http.StatusOK)
+				assert.Assert(t, strings.Contains(string(body), id))
+
+				resp, _ = apiGet(t, sock, "/containers/"+data.Identifier()+"-missing/json")
+				assert.Equal(t, resp.StatusCode, http.StatusNotFound)
+			},
+		}
+	}
+
+	testCase.Run(t)
+}
+
+func TestSystemServeTCPWithoutTLS(t *testing.T) {
+	testCase := nerdtest.Setup()
+
+	testCase.Require = require.Not(nerdtest.Docker)
+
+	testCase.Command = test.Command("system", "serve", "--host", "tcp://127.0.0.1:0")
+
+	testCase.Expected = test.Expects(expect.ExitCodeGenericFail, []error{errors.New("without TLS")}, nil)
+
+	testCase.Run(t)
+}
@@ -25,6 +25,7 @@ import (
 
 	"github.com/containerd/nerdctl/v2/cmd/nerdctl/helpers"
 	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/clientutil"
 	"github.com/containerd/nerdctl/v2/pkg/cmd/volume"
 )
 
@@ -38,6 +39,8 @@ func createCommand() *cobra.Command {
 		SilenceErrors: true,
 	}
 	cmd.Flags().StringArray("label", nil, "Set a label on the volume")
+	cmd.Flags().StringP("driver", "d", "local", "Specify volume driver name")
+	cmd.Flags().StringArrayP("opt", "o", nil, "Set driver specific options (type, device, o)")
 	return cmd
 }
 
@@ -56,9 +59,20 @@ func createOptions(cmd *cobra.Command) (types.VolumeCreateOptions, error) {
 		}
 	}
 
+	driver, err := cmd.Flags().GetString("driver")
+	if err != nil {
+		return types.VolumeCreateOptions{}, err
+	}
+	opts, err := cmd.Flags().GetStringArray("opt")
+	if err != nil {
+		return types.VolumeCreateOptions{}, err
+	}
+
 	return types.VolumeCreateOptions{
 		GOptions: globalOptions,
 		Labels:   labels,
+		Driver:   driver,
+		Options:  opts,
 		Stdout:   cmd.OutOrStdout(),
 	}, nil
 }
@@ -72,7 +86,13 @@ func createAction(cmd *cobra.Command, args []string) error {
 	if len(args) > 0 {
 		volumeName = args[0]
 	}
-	_, err = volume.Create(volumeName, options)
-
+	// Volumes do not need containerd, so the event is only published when it is reachable
+	client, ctx, cancel, err := clientutil.NewClient(cmd.Context(), options.GOptions.Namespace, options.GOptions.Address)
+	if err != nil {
+		client, ctx = nil, cmd.Context()
+	} else {
+		defer cancel()
+	}
+	_, err = volume.Create(ctx, client, volumeName, options)
 	return err
 }
@@ -0,0 +1,112 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package volume
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/containerd/nerdctl/mod/tigron/expect"
+	"github.com/containerd/nerdctl/mod/tigron/require"
+	"github.com/containerd/nerdctl/mod/tigron/test"
+	"github.com/containerd/nerdctl/mod/tigron/tig"
+
+	"github.com/containerd/nerdctl/v2/pkg/inspecttypes/native"
+	"github.com/containerd/nerdctl/v2/pkg/testutil"
+	"github.com/containerd/nerdctl/v2/pkg/testutil/nerdtest"
+)
+
+func TestVolumeCreateLocalDriverOptions(t *testing.T) {
+	testCase := nerdtest.Setup()
+
+	// Mounting a tmpfs requires privileges on the host
+	testCase.Require = require.Not(nerdtest.Rootless)
+
+	testCase.Setup = func(data test.Data, helpers test.Helpers) {
+		helpers.Ensure("volume", "create", "--driver", "local",
+			"--opt", "type=tmpfs", "--opt", "device=tmpfs", "--opt", "o=size=1m", data.Identifier())
+	}
+
+	testCase.Cleanup = func(data test.Data, helpers test.Helpers) {
+		helpers.Anyhow("rm", "-f", data.Identifier())
+		helpers.Anyhow("volume", "rm", "-f", data.Identifier())
+	}
+
+	testCase.SubTests = []*test.Case{
+		{
+			Description: "options are shown by inspect",
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("volume", "inspect", data.Identifier())
+			},
+			Expected: test.Expects(0, nil, expect.JSON([]native.Volume{}, func(vols []native.Volume, t tig.T) {
+				t.Helper()
+				if len(vols) != 1 || vols[0].Options["type"] != "tmpfs" || vols[0].Options["device"] != "tmpfs" || vols[0].Options["o"] != "size=1m" {
+					t.Log("unexpected volume options")
+					t.FailNow()
+				}
+			})),
+		},
+		{
+			Description: "the device is shared by running containers, and unmounted when they stop",
+			NoParallel:  true,
+			Setup: func(data test.Data, helpers test.Helpers) {
+				helpers.Ensure("run", "-d", "--name", data.Identifier(), "-v", data.Identifier()+":/data",
+					testutil.CommonImage, "sh", "-euxc", "echo hello > /data/file; sleep infinity")
+				nerdtest.EnsureContainerStarted(helpers, data.Identifier())
+				helpers.Command("run", "--rm", "-v", data.Identifier()+":/data", testutil.CommonImage, "sh", "-c", "until [ -f /data/file ]; do sleep 0.1; done; cat /data/file").
+					Run(&test.Expected{Output: expect.Equals("hello\n")})
+				helpers.Ensure("stop", data.Identifier())
+			},
+			Cleanup: func(data test.Data, helpers test.Helpers) {
+				helpers.Anyhow("rm", "-f", data.Identifier())
+			},
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				// The tmpfs of the former containers is gone
+				return helpers.Command("run", "--rm", "-v", data.Identifier()+":/data", testutil.CommonImage, "ls", "/data")
+			},
+			Expected: test.Expects(0, nil, expect.Equals("")),
+		},
+		{
+			Description: "the device is mounted again when the container is restarted by the restart policy",
+			NoParallel:  true,
+			Setup: func(data test.Data, helpers test.Helpers) {
+				helpers.Ensure("run", "-d", "--name", data.Identifier("restarted"), "--restart", "always", "-v", data.Identifier()+":/data",
+					testutil.CommonImage, "sh", "-c", "grep ' /data tmpfs ' /proc/mounts; sleep 1; exit 1")
+			},
+			Cleanup: func(data test.Data, helpers test.Helpers) {
+				helpers.Anyhow("rm", "-f", data.Identifier("restarted"))
+			},
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				// Wait for the restart manager of containerd to restart the container
+				for i := 0; i < 60 && strings.Count(helpers.Capture("logs", data.Identifier("restarted")), " /data tmpfs ") < 2; i++ {
+					time.Sleep(1 * time.Second)
+				}
+				return helpers.Command("logs", data.Identifier("restarted"))
+			},
+			Expected: test.Expects(0, nil, func(stdout string, t tig.T) {
+				t.Helper()
+				if strings.Count(stdout, " /data tmpfs ") < 2 {
+					t.Log("expected the device to be mounted in the restarted container")
+					t.FailNow()
+				}
+			}),
+		},
+	}
+
+	testCase.Run(t)
+}
@@ -42,6 +42,27 @@ type CheckpointRemoveOptions struct {
 	// Checkpoint directory
 	CheckpointDir string
 }
+
+// CheckpointExportOptions specifies options for `nerdctl checkpoint export`.
+type CheckpointExportOptions struct {
+	// Stdout is where the archive is written to
+	Stdout   io.Writer
+	GOptions GlobalCommandOptions
+	// Checkpoint directory
+	CheckpointDir string
+}
+
+// CheckpointImportOptions specifies options for `nerdctl checkpoint import`.
+type CheckpointImportOptions struct {
+	Stdout   io.Writer
+	Stdin    io.Reader
+	GOptions GlobalCommandOptions
+	// Checkpoint directory
+	CheckpointDir string
+	// Input read from tar archive file, instead of STDIN
+	Input string
+}
+
 type CheckpointSummary struct {
 	// Name is the name of the checkpoint.
 	Name string
@@ -34,6 +34,10 @@ type NetworkOptions struct {
 	Hostname string
 	// Domainname specifies the container's domain name
 	Domainname string
+	// NetworkAliases are the network-scoped aliases of the container, keyed by network
+	NetworkAliases map[string][]string
+	// Bandwidth limits the traffic of the container on its bridge networks (e.g., --network-opt ingress-rate=10mbit)
+	Bandwidth cni.BandWidth
 	// DNSServers set custom DNS servers
 	DNSServers []string
 	// DNSResolvConfOptions set DNS options
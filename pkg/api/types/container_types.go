@@ -300,17 +300,33 @@ type ContainerCreateOptions struct {
 	ImagePullOpt ImagePullOptions
 
 	// Healthcheck related fields
-	HealthCmd         string
-	HealthInterval    time.Duration
-	HealthTimeout     time.Duration
-	HealthRetries     int
-	HealthStartPeriod time.Duration
-	NoHealthcheck     bool
+	HealthCmd           string
+	HealthInterval      time.Duration
+	HealthTimeout       time.Duration
+	HealthRetries       int
+	HealthStartPeriod   time.Duration
+	HealthStartInterval time.Duration
+	HealthOnFailure     string
+	NoHealthcheck       bool
 
 	// UserNS name for user namespace mapping of container
 	UserNS string
 }
 
+// ContainerCreateFromCheckpointOptions specifies options for `nerdctl (container) create --from-checkpoint`.
+type ContainerCreateFromCheckpointOptions struct {
+	// Input is the checkpoint archive created by `nerdctl checkpoint export`
+	Input string
+	// CheckpointDir is the checkpoint directory to import the checkpoint into
+	CheckpointDir string
+	// CreateOptions are the options of the container.
+	// The process, the name and the labels of the checkpointed container are used for the options that are not set.
+	CreateOptions ContainerCreateOptions
+	// NetworkOptions are the network options of the container.
+	// The hostname, the networks and the published ports of the checkpointed container are used for the options that are not set.
+	NetworkOptions NetworkOptions
+}
+
 // ContainerStopOptions specifies options for `nerdctl (container) stop`.
 type ContainerStopOptions struct {
 	Stdout io.Writer
@@ -339,6 +355,16 @@ type ContainerRestartOptions struct {
 	NerdctlArgs []string
 }
 
+// ContainerHealthCheckOptions specifies options for `nerdctl container healthcheck`.
+type ContainerHealthCheckOptions struct {
+	// GOptions is the global options
+	GOptions GlobalCommandOptions
+	// NerdctlCmd is the command name of nerdctl
+	NerdctlCmd string
+	// NerdctlArgs is the arguments of nerdctl
+	NerdctlArgs []string
+}
+
 // ContainerPauseOptions specifies options for `nerdctl (container) pause`.
 type ContainerPauseOptions struct {
 	Stdout io.Writer
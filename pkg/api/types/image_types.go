@@ -294,6 +294,41 @@ type ImageSaveOptions struct {
 	Platform []string
 	// Quiet suppresses the progress output.
 	Quiet bool
+	// Format of the saved images (docker-archive|oci-archive|oci-dir)
+	Format string
+	// Compression applied to the archive (none|gzip|zstd)
+	Compression string
+	// Output is the destination directory for --format=oci-dir
+	Output string
+}
+
+// ImageMountOptions specifies options for `nerdctl image mount`.
+type ImageMountOptions struct {
+	Stdout   io.Writer
+	GOptions GlobalCommandOptions
+	// Platform of the image to mount
+	Platform string
+	// Target is the directory to mount the image on.
+	// Defaults to a directory under the data root.
+	Target string
+}
+
+// ImageUnmountOptions specifies options for `nerdctl image unmount`.
+type ImageUnmountOptions struct {
+	Stdout   io.Writer
+	GOptions GlobalCommandOptions
+}
+
+// ImageDiffOptions specifies options for `nerdctl image diff`.
+type ImageDiffOptions struct {
+	Stdout   io.Writer
+	GOptions GlobalCommandOptions
+	// Format the output using the given format (""|json)
+	Format string
+	// Files also compares the filesystem contents of the images
+	Files bool
+	// Platform of the images to compare
+	Platform string
 }
 
 // ImageSignOptions contains options for signing an image. It contains options from
@@ -23,7 +23,7 @@ type ImageLoadOptions struct {
 	Stdout   io.Writer
 	Stdin    io.Reader
 	GOptions GlobalCommandOptions
-	// Input read from tar archive file, instead of STDIN
+	// Input read from tar archive file or OCI layout directory, instead of STDIN
 	Input string
 	// Platform import content for a specific platform
 	Platform []string
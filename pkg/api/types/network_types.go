@@ -88,3 +88,33 @@ type NetworkRemoveOptions struct {
 	// Networks are the networks to be removed
 	Networks []string
 }
+
+// NetworkConnectOptions specifies options for `nerdctl network connect`.
+type NetworkConnectOptions struct {
+	// GOptions is the global options
+	GOptions GlobalCommandOptions
+	// Network is the network to connect the container to
+	Network string
+	// Container is the container to connect
+	Container string
+	// IPAddress is the static IPv4 address of the container on the network
+	IPAddress string
+	// IP6Address is the static IPv6 address of the container on the network
+	IP6Address string
+	// MACAddress is the MAC address of the container on the network
+	MACAddress string
+	// Aliases are the network-scoped aliases of the container
+	Aliases []string
+}
+
+// NetworkDisconnectOptions specifies options for `nerdctl network disconnect`.
+type NetworkDisconnectOptions struct {
+	// GOptions is the global options
+	GOptions GlobalCommandOptions
+	// Network is the network to disconnect the container from
+	Network string
+	// Container is the container to disconnect
+	Container string
+	// Force disconnects the container even if detaching the network fails
+	Force bool
+}
@@ -39,6 +39,10 @@ type SystemEventsOptions struct {
 	Format string
 	// Filter events based on given conditions
 	Filters []string
+	// Since shows events created since timestamp
+	Since string
+	// Until streams events until this timestamp
+	Until string
 }
 
 // SystemPruneOptions specifies options for `nerdctl system prune`.
@@ -56,3 +60,36 @@ type SystemPruneOptions struct {
 	// NetworkDriversToKeep the network drivers which need to keep
 	NetworkDriversToKeep []string
 }
+
+// SystemDfOptions specifies options for `nerdctl system df`.
+type SystemDfOptions struct {
+	Stdout io.Writer
+	Stderr io.Writer
+	// GOptions is the global options
+	GOptions GlobalCommandOptions
+	// Verbose shows the detailed usage of each object
+	Verbose bool
+	// Format the output using the given Go template, e.g, '{{json .}}'
+	Format string
+	// BuildKitHost the address of BuildKit host, empty if BuildKit is not running
+	BuildKitHost string
+}
+
+// SystemServeOptions specifies options for `nerdctl system serve`.
+type SystemServeOptions struct {
+	Stdout io.Writer
+	// GOptions is the global options
+	GOptions GlobalCommandOptions
+	// Host is the address to listen on, e.g., "unix:///run/nerdctl.sock" or "tcp://127.0.0.1:2376"
+	Host string
+	// TLSCert is the TLS certificate file of the server, required for a "tcp://" Host
+	TLSCert string
+	// TLSKey is the TLS key file of the server, required for a "tcp://" Host
+	TLSKey string
+	// TLSCACert is the CA certificate file to verify the client certificates with
+	TLSCACert string
+	// NerdctlCmd is the command name of nerdctl
+	NerdctlCmd string
+	// NerdctlArgs is the arguments of nerdctl
+	NerdctlArgs []string
+}
@@ -0,0 +1,208 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+// Package apiserver implements a subset of the Docker Engine API on top of pkg/cmd.
+package apiserver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+
+	containerd "github.com/containerd/containerd/v2/client"
+	"github.com/containerd/containerd/v2/pkg/namespaces"
+	"github.com/containerd/errdefs"
+	"github.com/containerd/log"
+
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/idutil/containerwalker"
+)
+
+const (
+	// APIVersion is the version of the Docker Engine API served.
+	APIVersion = "1.44"
+	// MinAPIVersion is the oldest version of the Docker Engine API accepted by clients.
+	MinAPIVersion = "1.24"
+)
+
+// versionPrefix matches the optional version prefix of request paths, e.g., "/v1.44".
+var versionPrefix = regexp.MustCompile(`^/v[0-9]+\.[0-9]+`)
+
+// CreateArgsParser parses the arguments of `nerdctl create` to the options of container.Create,
+// and returns the arguments for the image and the command.
+// It is implemented by the CLI, as the defaults of the create options are resolved by its flags.
+type CreateArgsParser func(args []string) (types.ContainerCreateOptions, types.NetworkOptions, []string, error)
+
+// Server serves the Docker Engine API.
+type Server struct {
+	client          *containerd.Client
+	options         types.SystemServeOptions
+	parseCreateArgs CreateArgsParser
+	mux             *http.ServeMux
+}
+
+// New returns a Server, which implements http.Handler.
+func New(client *containerd.Client, options types.SystemServeOptions, parseCreateArgs CreateArgsParser) *Server {
+	s := &Server{
+		client:          client,
+		options:         options,
+		parseCreateArgs: parseCreateArgs,
+		mux:             http.NewServeMux(),
+	}
+	s.mux.HandleFunc("GET /_ping", s.ping)
+	s.mux.HandleFunc("HEAD /_ping", s.ping)
+	s.mux.HandleFunc("GET /version", s.version)
+	s.mux.HandleFunc("GET /info", s.info)
+	s.mux.HandleFunc("GET /events", s.events)
+
+	s.mux.HandleFunc("GET /containers/json", s.containerList)
+	s.mux.HandleFunc("POST /containers/create", s.containerCreate)
+	s.mux.HandleFunc("GET /containers/{id}/json", s.containerInspect)
+	s.mux.HandleFunc("POST /containers/{id}/start", s.containerStart)
+	s.mux.HandleFunc("POST /containers/{id}/stop", s.containerStop)
+	s.mux.HandleFunc("GET /containers/{id}/logs", s.containerLogs)
+	s.mux.HandleFunc("DELETE /containers/{id}", s.containerDelete)
+
+	s.mux.HandleFunc("GET /images/json", s.imageList)
+	s.mux.HandleFunc("POST /images/create", s.imageCreate)
+
+	s.mux.HandleFunc("GET /networks", s.networkList)
+	s.mux.HandleFunc("POST /networks/create", s.networkCreate)
+	s.mux.HandleFunc("GET /networks/{id}", s.networkInspect)
+	s.mux.HandleFunc("DELETE /networks/{id}", s.networkDelete)
+
+	s.mux.HandleFunc("GET /volumes", s.volumeList)
+	s.mux.HandleFunc("POST /volumes/create", s.volumeCreate)
+	s.mux.HandleFunc("GET /volumes/{name}", s.volumeInspect)
+	s.mux.HandleFunc("DELETE /volumes/{name}", s.volumeDelete)
+	return s
+}
+
+// ServeHTTP strips the optional version prefix and dispatches the request.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	log.G(r.Context()).Debugf("%s %s", r.Method, r.URL.Path)
+	if p := versionPrefix.FindString(r.URL.Path); p != "" {
+		r2 := new(http.Request)
+		*r2 = *r
+		r2.URL = new(url.URL)
+		*r2.URL = *r.URL
+		r2.URL.Path = r.URL.Path[len(p):]
+		r2.URL.RawPath = ""
+		r = r2
+	}
+	s.mux.ServeHTTP(w, r)
+}
+
+// context returns the context of the request, bound to the namespace of the server.
+func (s *Server) context(r *http.Request) context.Context {
+	return namespaces.WithNamespace(r.Context(), s.options.GOptions.Namespace)
+}
+
+// findContainer resolves an ID, a prefix of an ID, or a name to a container.
+func (s *Server) findContainer(ctx context.Context, req string) (containerd.Container, error) {
+	var found containerd.Container
+	walker := &containerwalker.ContainerWalker{
+		Client: s.client,
+		OnFound: func(ctx context.Context, f containerwalker.Found) error {
+			if f.MatchCount > 1 {
+				return fmt.Errorf("multiple IDs found with provided prefix: %s: %w", f.Req, errdefs.ErrInvalidArgument)
+			}
+			found = f.Container
+			return nil
+		},
+	}
+	n, err := walker.Walk(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if n == 0 {
+		return nil, fmt.Errorf("no such container: %s: %w", req, errdefs.ErrNotFound)
+	}
+	return found, nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.L.WithError(err).Debug("failed to write the response")
+	}
+}
+
+// errorResponse is the body of an error, like Docker's types.ErrorResponse.
+type errorResponse struct {
+	Message string `json:"message"`
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errdefs.IsNotFound(err):
+		status = http.StatusNotFound
+	case errdefs.IsInvalidArgument(err):
+		status = http.StatusBadRequest
+	case errdefs.IsAlreadyExists(err), errdefs.IsConflict(err), errdefs.IsFailedPrecondition(err):
+		status = http.StatusConflict
+	case errdefs.IsNotImplemented(err):
+		status = http.StatusNotImplemented
+	}
+	writeJSON(w, status, errorResponse{Message: err.Error()})
+}
+
+// boolValue parses a boolean query parameter like Docker: "", "0" and "false" are false.
+func boolValue(r *http.Request, key string) bool {
+	switch r.URL.Query().Get(key) {
+	case "", "0", "false", "False", "FALSE":
+		return false
+	}
+	return true
+}
+
+// filtersValue decodes the "filters" query parameter, e.g., {"label":{"foo=bar":true}} or {"label":["foo=bar"]},
+// into the key=value strings of the `--filter` flags.
+func filtersValue(r *http.Request) ([]string, error) {
+	raw := r.URL.Query().Get("filters")
+	if raw == "" {
+		return nil, nil
+	}
+	var m map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(raw), &m); err != nil {
+		return nil, fmt.Errorf("invalid filters %q: %w", raw, errdefs.ErrInvalidArgument)
+	}
+	var filters []string
+	for key, v := range m {
+		var values []string
+		var set map[string]bool
+		if err := json.Unmarshal(v, &set); err == nil {
+			for value, ok := range set {
+				if ok {
+					values = append(values, value)
+				}
+			}
+		} else if err := json.Unmarshal(v, &values); err != nil {
+			return nil, fmt.Errorf("invalid filter %q: %w", key, errdefs.ErrInvalidArgument)
+		}
+		for _, value := range values {
+			filters = append(filters, key+"="+value)
+		}
+	}
+	sort.Strings(filters)
+	return filters, nil
+}
@@ -0,0 +1,146 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package apiserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/containerd/errdefs"
+
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+)
+
+func TestPing(t *testing.T) {
+	s := New(nil, types.SystemServeOptions{}, nil)
+	for _, path := range []string{"/_ping", "/v1.44/_ping", "/v1.24/_ping"} {
+		rec := httptest.NewRecorder()
+		s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		assert.Equal(t, rec.Code, http.StatusOK, path)
+		assert.Equal(t, rec.Body.String(), "OK", path)
+		assert.Equal(t, rec.Header().Get("Api-Version"), APIVersion, path)
+	}
+
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/_ping", nil))
+	assert.Equal(t, rec.Code, http.StatusMethodNotAllowed)
+}
+
+func TestFiltersValue(t *testing.T) {
+	for _, raw := range []string{
+		`{"label":{"foo=bar":true,"baz":true},"status":{"running":true}}`,
+		`{"label":["foo=bar","baz"],"status":["running"]}`,
+	} {
+		r := httptest.NewRequest(http.MethodGet, "/containers/json?filters="+url.QueryEscape(raw), nil)
+		filters, err := filtersValue(r)
+		assert.NilError(t, err)
+		assert.DeepEqual(t, filters, []string{"label=baz", "label=foo=bar", "status=running"})
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/containers/json?filters=foo", nil)
+	_, err := filtersValue(r)
+	assert.ErrorContains(t, err, "invalid filters")
+}
+
+func TestCreateArgs(t *testing.T) {
+	var req containerCreateRequest
+	assert.NilError(t, json.Unmarshal([]byte(`{
+		"Image": "alpine",
+		"Cmd": ["-c", "echo foo"],
+		"Entrypoint": ["sh"],
+		"Env": ["FOO=bar"],
+		"Labels": {"b": "2", "a": "1"},
+		"Tty": true,
+		"ExposedPorts": {"80/tcp": {}},
+		"HostConfig": {
+			"Binds": ["/tmp:/data:ro"],
+			"PortBindings": {"80/tcp": [{"HostIp": "127.0.0.1", "HostPort": "8080"}], "53/udp": [{"HostPort": "5353"}]},
+			"NetworkMode": "default",
+			"AutoRemove": true,
+			"NanoCpus": 1500000000,
+			"RestartPolicy": {"Name": "on-failure", "MaximumRetryCount": 3}
+		}
+	}`), &req))
+	args, err := createArgs("foo", req)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, args, []string{
+		"--name", "foo", "--tty",
+		"--env", "FOO=bar",
+		"--label", "a=1", "--label", "b=2",
+		"--expose", "80/tcp",
+		"--volume", "/tmp:/data:ro",
+		"--publish", "5353:53/udp", "--publish", "127.0.0.1:8080:80/tcp",
+		"--rm", "--cpus", "1.5", "--restart", "on-failure:3",
+		"--entrypoint", "sh",
+		"--", "alpine", "-c", "echo foo",
+	})
+
+	_, err = createArgs("foo", containerCreateRequest{})
+	assert.ErrorContains(t, err, "no image specified")
+}
+
+func TestNetworkCreateOptions(t *testing.T) {
+	var req networkCreateRequest
+	assert.NilError(t, json.Unmarshal([]byte(`{
+		"Name": "foo",
+		"EnableIPv6": true,
+		"IPAM": {"Config": [{"Subnet": "10.5.0.0/16", "Gateway": "10.5.0.1", "AuxiliaryAddresses": {"b": "10.5.0.3", "a": "10.5.0.2"}}]},
+		"Labels": {"b": "2", "a": "1"}
+	}`), &req))
+	options, err := networkCreateOptions(types.GlobalCommandOptions{Namespace: "test"}, req)
+	assert.NilError(t, err)
+	assert.Equal(t, options.GOptions.Namespace, "test")
+	assert.Equal(t, options.Name, "foo")
+	assert.Equal(t, options.IPAMDriver, "default")
+	assert.Assert(t, options.IPv4 == nil)
+	assert.Equal(t, options.IPv6, true)
+	assert.DeepEqual(t, options.Subnets, []string{"10.5.0.0/16"})
+	assert.DeepEqual(t, options.Gateway, []string{"10.5.0.1"})
+	assert.DeepEqual(t, options.AuxAddresses, []string{"a=10.5.0.2", "b=10.5.0.3"})
+	assert.DeepEqual(t, options.Labels, []string{"a=1", "b=2"})
+
+	_, err = networkCreateOptions(types.GlobalCommandOptions{}, networkCreateRequest{})
+	assert.ErrorIs(t, err, errdefs.ErrInvalidArgument)
+	_, err = networkCreateOptions(types.GlobalCommandOptions{}, networkCreateRequest{Name: "-foo"})
+	assert.ErrorIs(t, err, errdefs.ErrInvalidArgument)
+}
+
+func TestVolumeCreateOptions(t *testing.T) {
+	options := volumeCreateOptions(types.GlobalCommandOptions{Namespace: "test"}, volumeCreateRequest{
+		Name:       "foo",
+		Driver:     "local",
+		DriverOpts: map[string]string{"type": "ext4", "device": "/dev/loop0"},
+		Labels:     map[string]string{"a": "1"},
+	})
+	assert.Equal(t, options.GOptions.Namespace, "test")
+	assert.Equal(t, options.Driver, "local")
+	assert.DeepEqual(t, options.Options, []string{"device=/dev/loop0", "type=ext4"})
+	assert.DeepEqual(t, options.Labels, []string{"a=1"})
+}
+
+func TestListenTCPRequiresTLS(t *testing.T) {
+	_, err := listen(types.SystemServeOptions{Host: "tcp://127.0.0.1:0"})
+	assert.ErrorContains(t, err, "without TLS")
+
+	_, err = listen(types.SystemServeOptions{Host: "tcp://127.0.0.1:0", TLSCert: "cert.pem"})
+	assert.ErrorContains(t, err, "both --tlscert and --tlskey must be specified")
+}
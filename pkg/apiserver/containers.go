@@ -0,0 +1,532 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package apiserver
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	containerd "github.com/containerd/containerd/v2/client"
+	"github.com/containerd/errdefs"
+
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/clientutil"
+	"github.com/containerd/nerdctl/v2/pkg/cmd/container"
+	"github.com/containerd/nerdctl/v2/pkg/containerutil"
+	"github.com/containerd/nerdctl/v2/pkg/portutil"
+)
+
+// containerPort is a port of a container, like Docker's types.Port.
+type containerPort struct {
+	IP          string `json:"IP,omitempty"`
+	PrivatePort uint16 `json:"PrivatePort"`
+	PublicPort  uint16 `json:"PublicPort,omitempty"`
+	Type        string `json:"Type"`
+}
+
+// containerSummary is an entry of GET /containers/json, like Docker's types.Container.
+type containerSummary struct {
+	ID      string `json:"Id"`
+	Names   []string
+	Image   string
+	Command string
+	Created int64
+	Ports   []containerPort
+	Labels  map[string]string
+	State   string
+	Status  string
+}
+
+func (s *Server) containerList(w http.ResponseWriter, r *http.Request) {
+	ctx := s.context(r)
+	filters, err := filtersValue(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	options := types.ContainerListOptions{
+		GOptions: s.options.GOptions,
+		All:      boolValue(r, "all"),
+		Size:     boolValue(r, "size"),
+		Filters:  filters,
+	}
+	if limit := r.URL.Query().Get("limit"); limit != "" {
+		if options.LastN, err = strconv.Atoi(limit); err != nil {
+			writeError(w, fmt.Errorf("invalid limit %q: %w", limit, errdefs.ErrInvalidArgument))
+			return
+		}
+	}
+	items, err := container.List(ctx, s.client, options)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	dataStore, err := clientutil.DataStore(s.options.GOptions.DataRoot, s.options.GOptions.Address)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	res := make([]containerSummary, 0, len(items))
+	for _, item := range items {
+		if item.ID == "" {
+			// removed while listing
+			continue
+		}
+		command, err := strconv.Unquote(item.Command)
+		if err != nil {
+			command = item.Command
+		}
+		c := containerSummary{
+			ID:      item.ID,
+			Names:   []string{"/" + item.Names},
+			Image:   item.Image,
+			Command: command,
+			Created: item.CreatedAt.Unix(),
+			Ports:   []containerPort{},
+			Labels:  item.LabelsMap,
+			State:   containerState(item.Status),
+			Status:  item.Status,
+		}
+		ports, err := portutil.LoadPortMappings(dataStore, s.options.GOptions.Namespace, item.ID, item.LabelsMap)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		for _, p := range ports {
+			c.Ports = append(c.Ports, containerPort{
+				IP:          p.HostIP,
+				PrivatePort: uint16(p.ContainerPort),
+				PublicPort:  uint16(p.HostPort),
+				Type:        p.Protocol,
+			})
+		}
+		res = append(res, c)
+	}
+	writeJSON(w, http.StatusOK, res)
+}
+
+// containerState returns the Docker state of a container from its status, e.g., "running" for "Up".
+func containerState(status string) string {
+	switch {
+	case strings.HasPrefix(status, "Up"):
+		return "running"
+	case strings.HasPrefix(status, "Exited"):
+		return "exited"
+	case strings.HasPrefix(status, "Restarting"):
+		return "restarting"
+	}
+	state, _, _ := strings.Cut(status, " ")
+	return strings.ToLower(state)
+}
+
+// portBinding is a host port of a container port, like Docker's nat.PortBinding.
+type portBinding struct {
+	HostIP   string `json:"HostIp"`
+	HostPort string
+}
+
+// containerCreateRequest is the body of POST /containers/create.
+// Only the fields supported by `nerdctl create` are decoded.
+type containerCreateRequest struct {
+	Hostname     string
+	User         string
+	Tty          bool
+	OpenStdin    bool
+	Env          []string
+	Cmd          []string
+	Entrypoint   []string
+	Image        string
+	WorkingDir   string
+	Labels       map[string]string
+	ExposedPorts map[string]struct{}
+	StopSignal   string
+	HostConfig   struct {
+		Binds         []string
+		PortBindings  map[string][]portBinding
+		NetworkMode   string
+		AutoRemove    bool
+		Privileged    bool
+		CapAdd        []string
+		CapDrop       []string
+		DNS           []string `json:"Dns"`
+		ExtraHosts    []string
+		Memory        int64
+		NanoCPUs      int64 `json:"NanoCpus"`
+		RestartPolicy struct {
+			Name              string
+			MaximumRetryCount int
+		}
+	}
+}
+
+// createArgs translates a create request to the flags and the arguments of `nerdctl create`.
+func createArgs(name string, req containerCreateRequest) ([]string, error) {
+	if req.Image == "" {
+		return nil, fmt.Errorf("no image specified: %w", errdefs.ErrInvalidArgument)
+	}
+	var args []string
+	if name != "" {
+		args = append(args, "--name", name)
+	}
+	if req.Hostname != "" {
+		args = append(args, "--hostname", req.Hostname)
+	}
+	if req.User != "" {
+		args = append(args, "--user", req.User)
+	}
+	if req.Tty {
+		args = append(args, "--tty")
+	}
+	if req.OpenStdin {
+		args = append(args, "--interactive")
+	}
+	if req.WorkingDir != "" {
+		args = append(args, "--workdir", req.WorkingDir)
+	}
+	if req.StopSignal != "" {
+		args = append(args, "--stop-signal", req.StopSignal)
+	}
+	for _, e := range req.Env {
+		args = append(args, "--env", e)
+	}
+	for _, k := range sortedKeys(req.Labels) {
+		args = append(args, "--label", k+"="+req.Labels[k])
+	}
+	for _, p := range sortedKeys(req.ExposedPorts) {
+		args = append(args, "--expose", p)
+	}
+	hc := req.HostConfig
+	for _, b := range hc.Binds {
+		args = append(args, "--volume", b)
+	}
+	for _, p := range sortedKeys(hc.PortBindings) {
+		bindings := hc.PortBindings[p]
+		if len(bindings) == 0 {
+			bindings = []portBinding{{}}
+		}
+		for _, b := range bindings {
+			spec := p
+			if b.HostIP != "" {
+				spec = fmt.Sprintf("%s:%s:%s", b.HostIP, b.HostPort, p)
+			} else if b.HostPort != "" {
+				spec = b.HostPort + ":" + p
+			}
+			args = append(args, "--publish", spec)
+		}
+	}
+	switch hc.NetworkMode {
+	case "", "default":
+	default:
+		args = append(args, "--network", hc.NetworkMode)
+	}
+	if hc.AutoRemove {
+		args = append(args, "--rm")
+	}
+	if hc.Privileged {
+		args = append(args, "--privileged")
+	}
+	for _, c := range hc.CapAdd {
+		args = append(args, "--cap-add", c)
+	}
+	for _, c := range hc.CapDrop {
+		args = append(args, "--cap-drop", c)
+	}
+	for _, d := range hc.DNS {
+		args = append(args, "--dns", d)
+	}
+	for _, h := range hc.ExtraHosts {
+		args = append(args, "--add-host", h)
+	}
+	if hc.Memory > 0 {
+		args = append(args, "--memory", strconv.FormatInt(hc.Memory, 10))
+	}
+	if hc.NanoCPUs > 0 {
+		args = append(args, "--cpus", strconv.FormatFloat(float64(hc.NanoCPUs)/1e9, 'f', -1, 64))
+	}
+	switch policy := hc.RestartPolicy; policy.Name {
+	case "", "no":
+	case "on-failure":
+		if policy.MaximumRetryCount > 0 {
+			args = append(args, "--restart", fmt.Sprintf("on-failure:%d", policy.MaximumRetryCount))
+		} else {
+			args = append(args, "--restart", "on-failure")
+		}
+	default:
+		args = append(args, "--restart", policy.Name)
+	}
+	for _, e := range req.Entrypoint {
+		args = append(args, "--entrypoint", e)
+	}
+	args = append(args, "--", req.Image)
+	return append(args, req.Cmd...), nil
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// containerCreateResponse is the body of POST /containers/create, like Docker's container.CreateResponse.
+type containerCreateResponse struct {
+	ID       string `json:"Id"`
+	Warnings []string
+}
+
+func (s *Server) containerCreate(w http.ResponseWriter, r *http.Request) {
+	var req containerCreateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, fmt.Errorf("invalid request body: %w: %w", err, errdefs.ErrInvalidArgument))
+		return
+	}
+	args, err := createArgs(r.URL.Query().Get("name"), req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if s.parseCreateArgs == nil {
+		writeError(w, fmt.Errorf("creating containers: %w", errdefs.ErrNotImplemented))
+		return
+	}
+	createOpt, netFlags, args, err := s.parseCreateArgs(args)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	// the server options are used, as the parser is not aware of the command line of the server
+	createOpt.Stdout, createOpt.Stderr = io.Discard, io.Discard
+	createOpt.NerdctlCmd, createOpt.NerdctlArgs = s.options.NerdctlCmd, s.options.NerdctlArgs
+
+	ctx := s.context(r)
+	netManager, err := containerutil.NewNetworkingOptionsManager(createOpt.GOptions, netFlags, s.client)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	c, gc, err := container.Create(ctx, s.client, args, netManager, createOpt)
+	if err != nil {
+		if gc != nil {
+			gc()
+		}
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, containerCreateResponse{ID: c.ID(), Warnings: []string{}})
+}
+
+func (s *Server) containerInspect(w http.ResponseWriter, r *http.Request) {
+	ctx := s.context(r)
+	c, err := s.findContainer(ctx, r.PathValue("id"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	res, err := container.Inspect(ctx, s.client, []string{c.ID()}, types.ContainerInspectOptions{
+		GOptions: s.options.GOptions,
+		Mode:     "dockercompat",
+		Size:     boolValue(r, "size"),
+	})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if len(res) == 0 {
+		writeError(w, fmt.Errorf("no such container: %s: %w", r.PathValue("id"), errdefs.ErrNotFound))
+		return
+	}
+	writeJSON(w, http.StatusOK, res[0])
+}
+
+// isRunning returns whether the task of a container is running.
+func isRunning(ctx context.Context, c containerd.Container) bool {
+	task, err := c.Task(ctx, nil)
+	if err != nil {
+		return false
+	}
+	status, err := task.Status(ctx)
+	return err == nil && status.Status == containerd.Running
+}
+
+func (s *Server) containerStart(w http.ResponseWriter, r *http.Request) {
+	ctx := s.context(r)
+	c, err := s.findContainer(ctx, r.PathValue("id"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if isRunning(ctx, c) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	err = container.Start(ctx, s.client, []string{c.ID()}, types.ContainerStartOptions{
+		Stdout:      io.Discard,
+		GOptions:    s.options.GOptions,
+		NerdctlCmd:  s.options.NerdctlCmd,
+		NerdctlArgs: s.options.NerdctlArgs,
+	})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) containerStop(w http.ResponseWriter, r *http.Request) {
+	ctx := s.context(r)
+	c, err := s.findContainer(ctx, r.PathValue("id"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if !isRunning(ctx, c) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	options := types.ContainerStopOptions{
+		Stdout:   io.Discard,
+		Stderr:   io.Discard,
+		GOptions: s.options.GOptions,
+		Signal:   r.URL.Query().Get("signal"),
+	}
+	if t := r.URL.Query().Get("t"); t != "" {
+		sec, err := strconv.Atoi(t)
+		if err != nil {
+			writeError(w, fmt.Errorf("invalid timeout %q: %w", t, errdefs.ErrInvalidArgument))
+			return
+		}
+		timeout := time.Duration(sec) * time.Second
+		options.Timeout = &timeout
+	}
+	if err := container.Stop(ctx, s.client, []string{c.ID()}, options); err != nil {
+		writeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) containerDelete(w http.ResponseWriter, r *http.Request) {
+	ctx := s.context(r)
+	c, err := s.findContainer(ctx, r.PathValue("id"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if err := container.RemoveContainer(ctx, c, s.options.GOptions, boolValue(r, "force"), boolValue(r, "v"), s.client); err != nil {
+		if errors.As(err, &container.ErrContainerStatus{}) {
+			err = fmt.Errorf("%w: stop the container before removing or force remove: %w", err, errdefs.ErrConflict)
+		}
+		writeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Stream types of the multiplexed stream of Docker, see github.com/docker/docker/pkg/stdcopy.
+const (
+	streamStdout byte = 1
+	streamStderr byte = 2
+)
+
+// streamWriter writes a stream of logs, multiplexed when the container has no TTY.
+type streamWriter struct {
+	mu      *sync.Mutex
+	w       http.ResponseWriter
+	stream  byte
+	enabled bool
+}
+
+func (sw *streamWriter) Write(p []byte) (int, error) {
+	if !sw.enabled {
+		return len(p), nil
+	}
+	sw.mu.Lock()
+	defer sw.mu.Unlock()
+	if sw.stream != 0 {
+		header := make([]byte, 8)
+		header[0] = sw.stream
+		binary.BigEndian.PutUint32(header[4:], uint32(len(p)))
+		if _, err := sw.w.Write(header); err != nil {
+			return 0, err
+		}
+	}
+	n, err := sw.w.Write(p)
+	if f, ok := sw.w.(http.Flusher); ok {
+		f.Flush()
+	}
+	return n, err
+}
+
+func (s *Server) containerLogs(w http.ResponseWriter, r *http.Request) {
+	ctx := s.context(r)
+	c, err := s.findContainer(ctx, r.PathValue("id"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	showStdout, showStderr := boolValue(r, "stdout"), boolValue(r, "stderr")
+	if !showStdout && !showStderr {
+		writeError(w, fmt.Errorf("you must choose at least one stream: %w", errdefs.ErrInvalidArgument))
+		return
+	}
+	options := types.ContainerLogsOptions{
+		GOptions:   s.options.GOptions,
+		Follow:     boolValue(r, "follow"),
+		Timestamps: boolValue(r, "timestamps"),
+		Since:      r.URL.Query().Get("since"),
+		Until:      r.URL.Query().Get("until"),
+	}
+	if tail := r.URL.Query().Get("tail"); tail != "" && tail != "all" {
+		n, err := strconv.ParseUint(tail, 10, 32)
+		if err != nil {
+			writeError(w, fmt.Errorf("invalid tail %q: %w", tail, errdefs.ErrInvalidArgument))
+			return
+		}
+		options.Tail = uint(n)
+	}
+	spec, err := c.Spec(ctx)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	mu := &sync.Mutex{}
+	stdout := &streamWriter{mu: mu, w: w, stream: streamStdout, enabled: showStdout}
+	stderr := &streamWriter{mu: mu, w: w, stream: streamStderr, enabled: showStderr}
+	if spec.Process != nil && spec.Process.Terminal {
+		stdout.stream, stderr.stream = 0, 0
+		w.Header().Set("Content-Type", "application/vnd.docker.raw-stream")
+	} else {
+		w.Header().Set("Content-Type", "application/vnd.docker.multiplexed-stream")
+	}
+	options.Stdout, options.Stderr = stdout, stderr
+	w.WriteHeader(http.StatusOK)
+	if err := container.Logs(ctx, s.client, c.ID(), options); err != nil {
+		fmt.Fprintln(stderr, err)
+	}
+}
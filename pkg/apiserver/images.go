@@ -0,0 +1,166 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package apiserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	containerd "github.com/containerd/containerd/v2/client"
+	"github.com/containerd/errdefs"
+	"github.com/containerd/log"
+	"github.com/containerd/platforms"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/cmd/image"
+	"github.com/containerd/nerdctl/v2/pkg/imgutil"
+)
+
+// imageSummary is an entry of GET /images/json, like Docker's image.Summary.
+type imageSummary struct {
+	ID          string `json:"Id"`
+	ParentID    string `json:"ParentId"`
+	RepoTags    []string
+	RepoDigests []string
+	Created     int64
+	Size        int64
+	SharedSize  int64
+	Labels      map[string]string
+	Containers  int64
+}
+
+func (s *Server) imageList(w http.ResponseWriter, r *http.Request) {
+	ctx := s.context(r)
+	filters, err := filtersValue(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	imageList, err := image.List(ctx, s.client, filters, nil)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	// images sharing the same target are reported as one image with several tags, like Docker
+	res := []*imageSummary{}
+	byID := map[string]*imageSummary{}
+	for _, img := range imageList {
+		id := img.Target.Digest.String()
+		summary, ok := byID[id]
+		if !ok {
+			summary = &imageSummary{
+				ID:          id,
+				RepoTags:    []string{},
+				RepoDigests: []string{},
+				Created:     img.CreatedAt.Unix(),
+				SharedSize:  -1,
+				Containers:  -1,
+			}
+			ci := containerd.NewImage(s.client, img)
+			if summary.Size, err = ci.Size(ctx); err != nil {
+				log.G(ctx).WithError(err).Debugf("failed to get the size of image %q", img.Name)
+			}
+			if spec, err := ci.Spec(ctx); err == nil {
+				summary.Labels = spec.Config.Labels
+				if spec.Created != nil {
+					summary.Created = spec.Created.Unix()
+				}
+			}
+			byID[id] = summary
+			res = append(res, summary)
+		}
+		if img.Name == id {
+			// named after the digest of its config by the cri plugin
+			continue
+		}
+		if strings.Contains(img.Name, "@") {
+			summary.RepoDigests = append(summary.RepoDigests, img.Name)
+			continue
+		}
+		summary.RepoTags = append(summary.RepoTags, img.Name)
+		if repo, _ := imgutil.ParseRepoTag(img.Name); repo != "" {
+			summary.RepoDigests = append(summary.RepoDigests, repo+"@"+id)
+		}
+	}
+	writeJSON(w, http.StatusOK, res)
+}
+
+// progressMessage is a message of the progress stream of POST /images/create, like Docker's jsonmessage.JSONMessage.
+type progressMessage struct {
+	Status      string        `json:"status,omitempty"`
+	ID          string        `json:"id,omitempty"`
+	ErrorDetail *errorMessage `json:"errorDetail,omitempty"`
+	Error       string        `json:"error,omitempty"`
+}
+
+type errorMessage struct {
+	Message string `json:"message"`
+}
+
+func (s *Server) imageCreate(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	ref := query.Get("fromImage")
+	if ref == "" {
+		writeError(w, fmt.Errorf("importing an image with fromSrc is not supported: %w", errdefs.ErrNotImplemented))
+		return
+	}
+	if tag := query.Get("tag"); tag != "" {
+		if strings.Contains(tag, ":") {
+			ref += "@" + tag
+		} else {
+			ref += ":" + tag
+		}
+	}
+	options := types.ImagePullOptions{
+		Stdout:        io.Discard,
+		Stderr:        io.Discard,
+		GOptions:      s.options.GOptions,
+		VerifyOptions: types.ImageVerifyOptions{Provider: "none"},
+		Mode:          "always",
+		Quiet:         true,
+	}
+	if platform := query.Get("platform"); platform != "" {
+		p, err := platforms.Parse(platform)
+		if err != nil {
+			writeError(w, fmt.Errorf("invalid platform %q: %w", platform, errdefs.ErrInvalidArgument))
+			return
+		}
+		options.OCISpecPlatform = []ocispec.Platform{p}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	enc := json.NewEncoder(w)
+	send := func(m progressMessage) {
+		if err := enc.Encode(m); err != nil {
+			log.G(r.Context()).WithError(err).Debug("failed to write the progress")
+		}
+		if f, ok := w.(http.Flusher); ok {
+			f.Flush()
+		}
+	}
+	send(progressMessage{Status: "Pulling " + ref})
+	if err := image.Pull(s.context(r), s.client, ref, options); err != nil {
+		send(progressMessage{ErrorDetail: &errorMessage{Message: err.Error()}, Error: err.Error()})
+		return
+	}
+	send(progressMessage{Status: "Status: Downloaded newer image for " + ref})
+}
@@ -0,0 +1,242 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package apiserver
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/containerd/errdefs"
+
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/cmd/network"
+	"github.com/containerd/nerdctl/v2/pkg/identifiers"
+	"github.com/containerd/nerdctl/v2/pkg/netutil"
+)
+
+// inspectNetworks returns the networks in the dockercompat mode of `nerdctl network inspect`.
+func (s *Server) inspectNetworks(r *http.Request, names []string) ([]json.RawMessage, error) {
+	var buf bytes.Buffer
+	err := network.Inspect(s.context(r), s.client, types.NetworkInspectOptions{
+		Stdout:   &buf,
+		GOptions: s.options.GOptions,
+		Mode:     "dockercompat",
+		Networks: names,
+	})
+	if err != nil {
+		return nil, err
+	}
+	var res []json.RawMessage
+	if err := json.Unmarshal(buf.Bytes(), &res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// findNetwork returns whether a network matches the name, ID or prefix of ID.
+func (s *Server) findNetwork(req string) error {
+	e, err := netutil.NewCNIEnv(s.options.GOptions.CNIPath, s.options.GOptions.CNINetConfPath, netutil.WithNamespace(s.options.GOptions.Namespace))
+	if err != nil {
+		return err
+	}
+	matches, errs := e.ListNetworksMatch([]string{req}, false)
+	if len(errs) > 0 {
+		return errs[0]
+	}
+	switch len(matches[req]) {
+	case 0:
+		return fmt.Errorf("network %s not found: %w", req, errdefs.ErrNotFound)
+	case 1:
+		return nil
+	}
+	return fmt.Errorf("multiple IDs found with provided prefix: %s: %w", req, errdefs.ErrInvalidArgument)
+}
+
+func (s *Server) networkList(w http.ResponseWriter, r *http.Request) {
+	filters, err := filtersValue(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	var buf bytes.Buffer
+	err = network.List(s.context(r), types.NetworkListOptions{
+		Stdout:   &buf,
+		GOptions: s.options.GOptions,
+		Format:   "{{.Name}}",
+		Filters:  filters,
+	})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	var names []string
+	for _, name := range strings.Fields(buf.String()) {
+		// the pseudo networks cannot be inspected
+		if name != "host" && name != "none" {
+			names = append(names, name)
+		}
+	}
+	res := []json.RawMessage{}
+	if len(names) > 0 {
+		if res, err = s.inspectNetworks(r, names); err != nil {
+			writeError(w, err)
+			return
+		}
+	}
+	writeJSON(w, http.StatusOK, res)
+}
+
+func (s *Server) networkInspect(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if err := s.findNetwork(id); err != nil {
+		writeError(w, err)
+		return
+	}
+	res, err := s.inspectNetworks(r, []string{id})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, res[0])
+}
+
+// networkCreateRequest is the body of POST /networks/create, like Docker's network.CreateRequest.
+type networkCreateRequest struct {
+	Name       string
+	Driver     string
+	Internal   bool
+	EnableIPv4 *bool
+	EnableIPv6 bool
+	IPAM       struct {
+		Driver string
+		Config []struct {
+			Subnet             string
+			IPRange            string
+			Gateway            string
+			AuxiliaryAddresses map[string]string `json:"AuxiliaryAddresses"`
+		}
+		Options map[string]string
+	}
+	Options map[string]string
+	Labels  map[string]string
+}
+
+// networkCreateOptions translates a create request to the options of `nerdctl network create`.
+func networkCreateOptions(gOptions types.GlobalCommandOptions, req networkCreateRequest) (types.NetworkCreateOptions, error) {
+	if req.Name == "" {
+		return types.NetworkCreateOptions{}, fmt.Errorf("no network name specified: %w", errdefs.ErrInvalidArgument)
+	}
+	if err := identifiers.ValidateDockerCompat(req.Name); err != nil {
+		return types.NetworkCreateOptions{}, fmt.Errorf("invalid network name: %w: %w", err, errdefs.ErrInvalidArgument)
+	}
+	options := types.NetworkCreateOptions{
+		GOptions:    gOptions,
+		Name:        req.Name,
+		Driver:      req.Driver,
+		Options:     req.Options,
+		IPAMDriver:  req.IPAM.Driver,
+		IPAMOptions: req.IPAM.Options,
+		IPv4:        req.EnableIPv4,
+		IPv6:        req.EnableIPv6,
+		Internal:    req.Internal,
+	}
+	// the defaults of the flags of `nerdctl network create`, as for the default network
+	if options.Driver == "" {
+		options.Driver = netutil.DefaultNetworkName
+	}
+	if options.IPAMDriver == "" {
+		options.IPAMDriver = "default"
+	}
+	if options.Options == nil {
+		options.Options = map[string]string{}
+	}
+	if options.IPAMOptions == nil {
+		options.IPAMOptions = map[string]string{}
+	}
+	for _, c := range req.IPAM.Config {
+		if c.Subnet != "" {
+			options.Subnets = append(options.Subnets, c.Subnet)
+		}
+		if c.Gateway != "" {
+			options.Gateway = append(options.Gateway, c.Gateway)
+		}
+		if c.IPRange != "" {
+			options.IPRange = append(options.IPRange, c.IPRange)
+		}
+		for _, k := range sortedKeys(c.AuxiliaryAddresses) {
+			options.AuxAddresses = append(options.AuxAddresses, k+"="+c.AuxiliaryAddresses[k])
+		}
+	}
+	for _, k := range sortedKeys(req.Labels) {
+		options.Labels = append(options.Labels, k+"="+req.Labels[k])
+	}
+	return options, nil
+}
+
+// networkCreateResponse is the body of POST /networks/create, like Docker's network.CreateResponse.
+type networkCreateResponse struct {
+	ID      string `json:"Id"`
+	Warning string
+}
+
+func (s *Server) networkCreate(w http.ResponseWriter, r *http.Request) {
+	var req networkCreateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, fmt.Errorf("invalid request body: %w: %w", err, errdefs.ErrInvalidArgument))
+		return
+	}
+	options, err := networkCreateOptions(s.options.GOptions, req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if err := s.findNetwork(req.Name); err == nil {
+		writeError(w, fmt.Errorf("network with name %s already exists: %w", req.Name, errdefs.ErrAlreadyExists))
+		return
+	} else if !errdefs.IsNotFound(err) {
+		writeError(w, err)
+		return
+	}
+	var buf bytes.Buffer
+	if err := network.Create(s.context(r), s.client, options, &buf); err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, networkCreateResponse{ID: strings.TrimSpace(buf.String())})
+}
+
+func (s *Server) networkDelete(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if err := s.findNetwork(id); err != nil {
+		writeError(w, err)
+		return
+	}
+	err := network.Remove(s.context(r), s.client, types.NetworkRemoveOptions{
+		Stdout:   io.Discard,
+		GOptions: s.options.GOptions,
+		Networks: []string{id},
+	})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
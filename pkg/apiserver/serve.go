@@ -0,0 +1,164 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package apiserver
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+
+	containerd "github.com/containerd/containerd/v2/client"
+	"github.com/containerd/log"
+
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+)
+
+// Serve serves the Docker Engine API on options.Host until ctx is done.
+func Serve(ctx context.Context, client *containerd.Client, options types.SystemServeOptions, parseCreateArgs CreateArgsParser) error {
+	l, err := listen(options)
+	if err != nil {
+		return err
+	}
+	srv := &http.Server{
+		Handler:           New(client, options, parseCreateArgs),
+		ReadHeaderTimeout: 10 * time.Second,
+		BaseContext: func(net.Listener) context.Context {
+			return ctx
+		},
+	}
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- srv.Serve(l)
+	}()
+	fmt.Fprintf(options.Stdout, "API listen on %s\n", options.Host)
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+	}
+	shutdownCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		log.G(ctx).WithError(err).Warn("failed to shut down the API server gracefully")
+		return srv.Close()
+	}
+	return nil
+}
+
+// listen creates the listener of the host, e.g., "unix:///run/nerdctl.sock" or "tcp://127.0.0.1:2376".
+// TCP hosts are only served with TLS.
+func listen(options types.SystemServeOptions) (net.Listener, error) {
+	host := options.Host
+	u, err := url.Parse(host)
+	if err != nil {
+		return nil, fmt.Errorf("invalid host %q: %w", host, err)
+	}
+	switch u.Scheme {
+	case "unix":
+		path := u.Path
+		if path == "" {
+			path = u.Opaque
+		}
+		if path == "" {
+			return nil, fmt.Errorf("invalid host %q: no socket path", host)
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return nil, err
+		}
+		// remove the stale socket of a previous server
+		if fi, err := os.Lstat(path); err == nil {
+			if fi.Mode()&os.ModeSocket == 0 {
+				return nil, fmt.Errorf("invalid host %q: %s exists and is not a socket", host, path)
+			}
+			if err := os.Remove(path); err != nil {
+				return nil, err
+			}
+		} else if !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+		l, err := net.Listen("unix", path)
+		if err != nil {
+			return nil, err
+		}
+		if err := os.Chmod(path, 0660); err != nil {
+			l.Close()
+			return nil, err
+		}
+		return l, nil
+	case "tcp":
+		config, err := tlsConfig(options)
+		if err != nil {
+			return nil, err
+		}
+		if config == nil {
+			return nil, fmt.Errorf("refusing to serve %q without TLS, as anyone who can reach it gets root access to the host: specify --tlscert and --tlskey", host)
+		}
+		if config.ClientAuth != tls.RequireAndVerifyClientCert {
+			log.L.Warnf("the API is served on %q without client authentication, anyone who can reach it gets root access to the host: specify --tlscacert to verify the client certificates", host)
+		}
+		l, err := net.Listen("tcp", u.Host)
+		if err != nil {
+			return nil, err
+		}
+		return tls.NewListener(l, config), nil
+	}
+	return nil, fmt.Errorf("invalid host %q: unsupported scheme %q, only \"unix\" and \"tcp\" are supported", host, u.Scheme)
+}
+
+// tlsConfig returns the TLS configuration of the server, or nil if TLS is not configured.
+// The client certificates are required and verified when options.TLSCACert is set, like `dockerd --tlsverify`.
+func tlsConfig(options types.SystemServeOptions) (*tls.Config, error) {
+	if options.TLSCert == "" && options.TLSKey == "" {
+		if options.TLSCACert != "" {
+			return nil, errors.New("--tlscacert requires --tlscert and --tlskey")
+		}
+		return nil, nil
+	}
+	if options.TLSCert == "" || options.TLSKey == "" {
+		return nil, errors.New("both --tlscert and --tlskey must be specified")
+	}
+	cert, err := tls.LoadX509KeyPair(options.TLSCert, options.TLSKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load the TLS certificate: %w", err)
+	}
+	config := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+	if options.TLSCACert != "" {
+		pem, err := os.ReadFile(options.TLSCACert)
+		if err != nil {
+			return nil, err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificate found in %s", options.TLSCACert)
+		}
+		config.ClientCAs = pool
+		config.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return config, nil
+}
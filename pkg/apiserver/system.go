@@ -0,0 +1,188 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package apiserver
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"runtime"
+
+	"github.com/containerd/log"
+
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/cmd/system"
+	"github.com/containerd/nerdctl/v2/pkg/infoutil"
+	"github.com/containerd/nerdctl/v2/pkg/inspecttypes/dockercompat"
+	"github.com/containerd/nerdctl/v2/pkg/version"
+)
+
+func (s *Server) ping(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Api-Version", APIVersion)
+	w.Header().Set("OSType", runtime.GOOS)
+	w.Header().Set("Cache-Control", "no-cache, no-store, must-revalidate")
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	if r.Method != http.MethodHead {
+		w.Write([]byte("OK"))
+	}
+}
+
+// versionResponse is the body of GET /version, like Docker's types.Version.
+type versionResponse struct {
+	Platform struct {
+		Name string
+	}
+	Components    []dockercompat.ComponentVersion
+	Version       string
+	APIVersion    string `json:"ApiVersion"`
+	MinAPIVersion string `json:"MinAPIVersion"`
+	GitCommit     string
+	GoVersion     string
+	Os            string
+	Arch          string
+}
+
+func (s *Server) version(w http.ResponseWriter, r *http.Request) {
+	sv, err := infoutil.ServerVersion(s.context(r), s.client)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	v := versionResponse{
+		Components: append([]dockercompat.ComponentVersion{{
+			Name:    "nerdctl",
+			Version: version.GetVersion(),
+			Details: map[string]string{"GitCommit": version.GetRevision()},
+		}}, sv.Components...),
+		Version:       version.GetVersion(),
+		APIVersion:    APIVersion,
+		MinAPIVersion: MinAPIVersion,
+		GitCommit:     version.GetRevision(),
+		GoVersion:     runtime.Version(),
+		Os:            runtime.GOOS,
+		Arch:          runtime.GOARCH,
+	}
+	v.Platform.Name = "nerdctl"
+	writeJSON(w, http.StatusOK, v)
+}
+
+func (s *Server) info(w http.ResponseWriter, r *http.Request) {
+	gOptions := s.options.GOptions
+	info, err := infoutil.Info(s.context(r), s.client, gOptions.Snapshotter, gOptions.CgroupManager, gOptions.SelinuxEnabled)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, info)
+}
+
+func (s *Server) events(w http.ResponseWriter, r *http.Request) {
+	filters, err := filtersValue(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if f, ok := w.(http.Flusher); ok {
+		f.Flush()
+	}
+	ctx := s.context(r)
+	err = system.Events(ctx, s.client, types.SystemEventsOptions{
+		Stdout:   &eventWriter{w: w},
+		GOptions: s.options.GOptions,
+		Format:   "{{json .}}",
+		Filters:  filters,
+		Since:    r.URL.Query().Get("since"),
+		Until:    r.URL.Query().Get("until"),
+	})
+	if err != nil && ctx.Err() == nil {
+		log.G(ctx).WithError(err).Warn("failed to stream events")
+	}
+}
+
+// eventMessage is an event, like Docker's events.Message.
+type eventMessage struct {
+	Status   string       `json:"status,omitempty"`
+	ID       string       `json:"id,omitempty"`
+	From     string       `json:"from,omitempty"`
+	Type     string       `json:"Type"`
+	Action   string       `json:"Action"`
+	Actor    system.Actor `json:"Actor"`
+	Scope    string       `json:"scope"`
+	Time     int64        `json:"time"`
+	TimeNano int64        `json:"timeNano"`
+}
+
+// eventWriter converts the JSON lines of system.Events to Docker events.
+type eventWriter struct {
+	w   http.ResponseWriter
+	buf bytes.Buffer
+}
+
+func (ew *eventWriter) Write(p []byte) (int, error) {
+	ew.buf.Write(p)
+	for {
+		line, err := ew.buf.ReadBytes('\n')
+		if err != nil {
+			// keep the incomplete line for the next write
+			ew.buf.Write(line)
+			return len(p), nil
+		}
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 {
+			continue
+		}
+		var e system.EventOut
+		if err := json.Unmarshal(line, &e); err != nil {
+			return 0, err
+		}
+		switch e.Type {
+		case "container", "image", "volume", "network":
+		default:
+			// not an event of a Docker object, e.g., a snapshot event
+			continue
+		}
+		m := eventMessage{
+			Type:     e.Type,
+			Action:   string(e.Action),
+			Actor:    e.Actor,
+			Scope:    "local",
+			Time:     e.Timestamp.Unix(),
+			TimeNano: e.Timestamp.UnixNano(),
+		}
+		if e.Type == "container" {
+			m.Status = m.Action
+			m.ID = e.Actor.ID
+			m.From = e.Actor.Attributes["image"]
+		}
+		b, err := json.Marshal(m)
+		if err != nil {
+			return 0, err
+		}
+		if _, err := ew.w.Write(append(b, '\n')); err != nil {
+			return 0, err
+		}
+		f, ok := ew.w.(http.Flusher)
+		if !ok {
+			return 0, errors.New("streaming is not supported")
+		}
+		f.Flush()
+	}
+}
@@ -0,0 +1,164 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package apiserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+
+	"github.com/containerd/errdefs"
+
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/cmd/volume"
+	"github.com/containerd/nerdctl/v2/pkg/inspecttypes/native"
+)
+
+// volumeResponse is a volume, like Docker's volume.Volume.
+type volumeResponse struct {
+	Name       string
+	Driver     string
+	Mountpoint string
+	Labels     map[string]string
+	Scope      string
+	Options    map[string]string
+}
+
+func volumeFromNative(vol native.Volume) volumeResponse {
+	v := volumeResponse{
+		Name:       vol.Name,
+		Driver:     "local",
+		Mountpoint: vol.Mountpoint,
+		Labels:     map[string]string{},
+		Scope:      "local",
+		Options:    vol.Options,
+	}
+	if vol.Labels != nil {
+		v.Labels = *vol.Labels
+	}
+	return v
+}
+
+// volumeListResponse is the body of GET /volumes, like Docker's volume.ListResponse.
+type volumeListResponse struct {
+	Volumes  []volumeResponse
+	Warnings []string
+}
+
+func (s *Server) volumeList(w http.ResponseWriter, r *http.Request) {
+	filters, err := filtersValue(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	gOptions := s.options.GOptions
+	vols, err := volume.Volumes(gOptions.Namespace, gOptions.DataRoot, gOptions.Address, false, filters)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	res := volumeListResponse{Volumes: []volumeResponse{}, Warnings: []string{}}
+	for _, vol := range vols {
+		res.Volumes = append(res.Volumes, volumeFromNative(vol))
+	}
+	sort.Slice(res.Volumes, func(i, j int) bool {
+		return res.Volumes[i].Name < res.Volumes[j].Name
+	})
+	writeJSON(w, http.StatusOK, res)
+}
+
+// getVolume returns a volume, or an error wrapping errdefs.ErrNotFound.
+func (s *Server) getVolume(name string) (*native.Volume, error) {
+	gOptions := s.options.GOptions
+	volStore, err := volume.Store(gOptions.Namespace, gOptions.DataRoot, gOptions.Address)
+	if err != nil {
+		return nil, err
+	}
+	return volStore.Get(name, false)
+}
+
+func (s *Server) volumeInspect(w http.ResponseWriter, r *http.Request) {
+	vol, err := s.getVolume(r.PathValue("name"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, volumeFromNative(*vol))
+}
+
+// volumeCreateRequest is the body of POST /volumes/create, like Docker's volume.CreateOptions.
+type volumeCreateRequest struct {
+	Name       string
+	Driver     string
+	DriverOpts map[string]string
+	Labels     map[string]string
+}
+
+// volumeCreateOptions translates a create request to the options of `nerdctl volume create`.
+func volumeCreateOptions(gOptions types.GlobalCommandOptions, req volumeCreateRequest) types.VolumeCreateOptions {
+	options := types.VolumeCreateOptions{
+		Stdout:   io.Discard,
+		GOptions: gOptions,
+		Driver:   req.Driver,
+	}
+	for _, k := range sortedKeys(req.DriverOpts) {
+		options.Options = append(options.Options, k+"="+req.DriverOpts[k])
+	}
+	for _, k := range sortedKeys(req.Labels) {
+		options.Labels = append(options.Labels, k+"="+req.Labels[k])
+	}
+	return options
+}
+
+func (s *Server) volumeCreate(w http.ResponseWriter, r *http.Request) {
+	var req volumeCreateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, fmt.Errorf("invalid request body: %w: %w", err, errdefs.ErrInvalidArgument))
+		return
+	}
+	created, err := volume.Create(s.context(r), s.client, req.Name, volumeCreateOptions(s.options.GOptions, req))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	vol, err := s.getVolume(created.Name)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, volumeFromNative(*vol))
+}
+
+func (s *Server) volumeDelete(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	if _, err := s.getVolume(name); err != nil {
+		writeError(w, err)
+		return
+	}
+	err := volume.Remove(s.context(r), s.client, []string{name}, types.VolumeRemoveOptions{
+		Stdout:   io.Discard,
+		GOptions: s.options.GOptions,
+		Force:    boolValue(r, "force"),
+	})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
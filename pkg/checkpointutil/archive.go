@@ -0,0 +1,132 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package checkpointutil
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/opencontainers/runtime-spec/specs-go"
+
+	containerd "github.com/containerd/containerd/v2/client"
+	"github.com/containerd/containerd/v2/core/content"
+	"github.com/containerd/containerd/v2/pkg/archive"
+
+	"github.com/containerd/nerdctl/v2/pkg/identifiers"
+)
+
+const (
+	// ArchiveConfigName is the name of the file that stores ArchiveConfig in a checkpoint archive.
+	ArchiveConfigName = "config.json"
+	// ArchiveCheckpointDir is the name of the directory that stores the CRIU images in a checkpoint archive.
+	ArchiveCheckpointDir = "checkpoint"
+	// ArchiveRootfsDiffName is the name of the file that stores the diff of the container rootfs in a checkpoint archive.
+	ArchiveRootfsDiffName = "rootfs-diff.tar"
+)
+
+// ArchiveConfig is the metadata of the checkpointed container, stored in a checkpoint archive.
+type ArchiveConfig struct {
+	// Checkpoint is the name of the checkpoint
+	Checkpoint string
+	// Image is the name of the image of the container
+	Image string
+	// Labels are the labels of the container, including the nerdctl labels
+	Labels map[string]string
+	// Spec is the OCI runtime spec of the container
+	Spec *specs.Spec
+	// RootfsDiff is the descriptor of the uncompressed diff of the container rootfs from the image
+	RootfsDiff ocispec.Descriptor
+}
+
+// ExtractArchive extracts a checkpoint archive created by `nerdctl checkpoint export` into dir.
+func ExtractArchive(ctx context.Context, r io.Reader, dir string) (*ArchiveConfig, error) {
+	if _, err := archive.Apply(ctx, dir, r); err != nil {
+		return nil, fmt.Errorf("failed to extract checkpoint archive: %w", err)
+	}
+	b, err := os.ReadFile(filepath.Join(dir, ArchiveConfigName))
+	if err != nil {
+		return nil, fmt.Errorf("invalid checkpoint archive: %w", err)
+	}
+	var config ArchiveConfig
+	if err := json.Unmarshal(b, &config); err != nil {
+		return nil, fmt.Errorf("invalid checkpoint archive: failed to parse %s: %w", ArchiveConfigName, err)
+	}
+	if err := identifiers.ValidateDockerCompat(config.Checkpoint); err != nil {
+		return nil, fmt.Errorf("invalid checkpoint archive: invalid checkpoint name: %w", err)
+	}
+	if config.Image == "" || config.Spec == nil || config.Spec.Process == nil || len(config.Spec.Process.Args) == 0 {
+		return nil, errors.New("invalid checkpoint archive: lacks the image or the process")
+	}
+	if stat, err := os.Stat(filepath.Join(dir, ArchiveCheckpointDir)); err != nil || !stat.IsDir() {
+		return nil, fmt.Errorf("invalid checkpoint archive: lacks %s directory", ArchiveCheckpointDir)
+	}
+	return &config, nil
+}
+
+// InstallCheckpoint moves the CRIU images extracted by ExtractArchive into the checkpoint directory,
+// so that the checkpoint can be restored with `nerdctl start --checkpoint`.
+// The path of the installed checkpoint is returned.
+func InstallCheckpoint(dir, checkpointDir string, config *ArchiveConfig) (string, error) {
+	target := filepath.Join(checkpointDir, config.Checkpoint)
+	if _, err := os.Stat(target); err == nil {
+		return "", fmt.Errorf("checkpoint with name %s already exists", config.Checkpoint)
+	} else if !os.IsNotExist(err) {
+		return "", err
+	}
+	if err := os.Rename(filepath.Join(dir, ArchiveCheckpointDir), target); err != nil {
+		return "", err
+	}
+	return target, nil
+}
+
+// ApplyRootfsDiff applies the rootfs diff extracted by ExtractArchive to the snapshot of the container.
+func ApplyRootfsDiff(ctx context.Context, client *containerd.Client, container containerd.Container, dir string, config *ArchiveConfig) error {
+	info, err := container.Info(ctx)
+	if err != nil {
+		return err
+	}
+	ctx, done, err := client.WithLease(ctx)
+	if err != nil {
+		return err
+	}
+	defer done(ctx)
+
+	f, err := os.Open(filepath.Join(dir, ArchiveRootfsDiffName))
+	if err != nil {
+		return fmt.Errorf("invalid checkpoint archive: %w", err)
+	}
+	defer f.Close()
+	ref := "checkpoint-rootfs-diff-" + config.RootfsDiff.Digest.String()
+	if err := content.WriteBlob(ctx, client.ContentStore(), ref, f, config.RootfsDiff); err != nil {
+		return fmt.Errorf("failed to import the rootfs diff: %w", err)
+	}
+
+	mounts, err := client.SnapshotService(info.Snapshotter).Mounts(ctx, info.SnapshotKey)
+	if err != nil {
+		return err
+	}
+	if _, err := client.DiffService().Apply(ctx, config.RootfsDiff, mounts); err != nil {
+		return fmt.Errorf("failed to apply the rootfs diff: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package builder
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os/exec"
+
+	"github.com/containerd/log"
+
+	"github.com/containerd/nerdctl/v2/pkg/buildkitutil"
+)
+
+// DiskUsage returns the build cache records of the BuildKit host.
+func DiskUsage(ctx context.Context, buildKitHost string, stderr io.Writer) ([]buildkitutil.UsageInfo, error) {
+	buildctlBinary, err := buildkitutil.BuildctlBinary()
+	if err != nil {
+		return nil, err
+	}
+	buildctlArgs := buildkitutil.BuildctlBaseArgs(buildKitHost)
+	buildctlArgs = append(buildctlArgs, "du", "--format={{json .}}")
+	buildctlCmd := exec.Command(buildctlBinary, buildctlArgs...)
+	log.G(ctx).Debugf("running %v", buildctlCmd.Args)
+	buildctlCmd.Stderr = stderr
+	out, err := buildctlCmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to run %v: %w", buildctlCmd.Args, err)
+	}
+	// `buildctl du` executes the template on the whole list of records,
+	// while `buildctl prune` executes it on each record. Accept both.
+	dec := json.NewDecoder(bytes.NewReader(out))
+	result := make([]buildkitutil.UsageInfo, 0)
+	for {
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to decode output from %v: %w", buildctlCmd.Args, err)
+		}
+		if bytes.HasPrefix(bytes.TrimSpace(raw), []byte("[")) {
+			var v []buildkitutil.UsageInfo
+			if err := json.Unmarshal(raw, &v); err != nil {
+				return nil, fmt.Errorf("failed to decode output from %v: %w", buildctlCmd.Args, err)
+			}
+			result = append(result, v...)
+			continue
+		}
+		var v buildkitutil.UsageInfo
+		if err := json.Unmarshal(raw, &v); err != nil {
+			return nil, fmt.Errorf("failed to decode output from %v: %w", buildctlCmd.Args, err)
+		}
+		result = append(result, v)
+	}
+	return result, nil
+}
@@ -0,0 +1,178 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package checkpoint
+
+import (
+	"archive/tar"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+
+	containerd "github.com/containerd/containerd/v2/client"
+	"github.com/containerd/containerd/v2/core/content"
+	"github.com/containerd/containerd/v2/core/diff"
+	"github.com/containerd/containerd/v2/pkg/rootfs"
+
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/checkpointutil"
+	"github.com/containerd/nerdctl/v2/pkg/idutil/containerwalker"
+)
+
+// Export writes a checkpoint archive that bundles the CRIU images of the checkpoint,
+// the configuration and the rootfs diff of the container, and the image name.
+// The archive can be restored on another host with `nerdctl container create --from-checkpoint`.
+func Export(ctx context.Context, client *containerd.Client, containerID string, checkpointName string, options types.CheckpointExportOptions) error {
+	var container containerd.Container
+
+	walker := &containerwalker.ContainerWalker{
+		Client: client,
+		OnFound: func(ctx context.Context, found containerwalker.Found) error {
+			if found.MatchCount > 1 {
+				return fmt.Errorf("multiple containers found with provided prefix: %s", found.Req)
+			}
+			container = found.Container
+			return nil
+		},
+	}
+
+	n, err := walker.Walk(ctx, containerID)
+	if err != nil {
+		return err
+	} else if n == 0 {
+		return fmt.Errorf("error exporting checkpoint for container: %s, no such container", containerID)
+	}
+
+	checkpointPath, err := checkpointutil.GetCheckpointDir(options.CheckpointDir, checkpointName, container.ID(), false)
+	if err != nil {
+		return err
+	}
+
+	info, err := container.Info(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get info for container %q: %w", containerID, err)
+	}
+	spec, err := container.Spec(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get spec for container %q: %w", containerID, err)
+	}
+
+	ctx, done, err := client.WithLease(ctx)
+	if err != nil {
+		return err
+	}
+	defer done(ctx)
+
+	diffDesc, err := rootfs.CreateDiff(ctx, info.SnapshotKey, client.SnapshotService(info.Snapshotter), client.DiffService(),
+		diff.WithMediaType(ocispec.MediaTypeImageLayer))
+	if err != nil {
+		return fmt.Errorf("failed to create the rootfs diff of container %q: %w", containerID, err)
+	}
+
+	config := checkpointutil.ArchiveConfig{
+		Checkpoint: checkpointName,
+		Image:      info.Image,
+		Labels:     info.Labels,
+		Spec:       spec,
+		RootfsDiff: diffDesc,
+	}
+	configJSON, err := json.MarshalIndent(config, "", "    ")
+	if err != nil {
+		return err
+	}
+
+	tw := tar.NewWriter(options.Stdout)
+	if err := writeTarFile(tw, checkpointutil.ArchiveConfigName, int64(len(configJSON)), func(w io.Writer) error {
+		_, err := w.Write(configJSON)
+		return err
+	}); err != nil {
+		return err
+	}
+	if err := writeTarDir(tw, checkpointPath, checkpointutil.ArchiveCheckpointDir); err != nil {
+		return err
+	}
+	ra, err := client.ContentStore().ReaderAt(ctx, diffDesc)
+	if err != nil {
+		return err
+	}
+	defer ra.Close()
+	if err := writeTarFile(tw, checkpointutil.ArchiveRootfsDiffName, diffDesc.Size, func(w io.Writer) error {
+		_, err := io.Copy(w, content.NewReader(ra))
+		return err
+	}); err != nil {
+		return err
+	}
+	return tw.Close()
+}
+
+func writeTarFile(tw *tar.Writer, name string, size int64, writeFn func(io.Writer) error) error {
+	if err := tw.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     name,
+		Mode:     0o600,
+		Size:     size,
+		ModTime:  time.Now(),
+	}); err != nil {
+		return err
+	}
+	return writeFn(tw)
+}
+
+// writeTarDir writes the regular files and the directories under root into the archive, with the prefix.
+func writeTarDir(tw *tar.Writer, root, prefix string) error {
+	return filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		name := filepath.ToSlash(filepath.Join(prefix, rel))
+		fi, err := d.Info()
+		if err != nil {
+			return err
+		}
+		switch {
+		case fi.IsDir():
+			return tw.WriteHeader(&tar.Header{
+				Typeflag: tar.TypeDir,
+				Name:     name + "/",
+				Mode:     0o700,
+				ModTime:  fi.ModTime(),
+			})
+		case fi.Mode().IsRegular():
+			return writeTarFile(tw, name, fi.Size(), func(w io.Writer) error {
+				f, err := os.Open(path)
+				if err != nil {
+					return err
+				}
+				defer f.Close()
+				_, err = io.Copy(w, f)
+				return err
+			})
+		default:
+			return fmt.Errorf("unsupported file type in checkpoint %q: %s", path, fi.Mode().Type())
+		}
+	})
+}
@@ -0,0 +1,62 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package checkpoint
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/checkpointutil"
+)
+
+// Import installs the checkpoint in an archive created by `nerdctl checkpoint export` into the checkpoint directory.
+// The rootfs diff in the archive is not applied; use `nerdctl container create --from-checkpoint` to recreate the container.
+func Import(ctx context.Context, options types.CheckpointImportOptions) error {
+	var r io.Reader = options.Stdin
+	if options.Input != "" {
+		f, err := os.Open(options.Input)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r = f
+	}
+
+	// extract into the checkpoint directory, so that the checkpoint can be renamed into place
+	if err := os.MkdirAll(options.CheckpointDir, 0o700); err != nil {
+		return err
+	}
+	dir, err := os.MkdirTemp(options.CheckpointDir, ".import-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	config, err := checkpointutil.ExtractArchive(ctx, r, dir)
+	if err != nil {
+		return err
+	}
+	if _, err := checkpointutil.InstallCheckpoint(dir, options.CheckpointDir, config); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(options.Stdout, "%s\n", config.Checkpoint)
+	return nil
+}
@@ -20,6 +20,7 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"strings"
 
 	containerd "github.com/containerd/containerd/v2/client"
 
@@ -61,7 +62,8 @@ func List(ctx context.Context, client *containerd.Client, containerID string, op
 	}
 
 	for _, d := range dirs {
-		if !d.IsDir() {
+		// skip the temporary directories of `nerdctl checkpoint import`
+		if !d.IsDir() || strings.HasPrefix(d.Name(), ".") {
 			continue
 		}
 		out = append(out, types.CheckpointSummary{Name: d.Name()})
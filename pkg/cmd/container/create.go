@@ -61,6 +61,8 @@ import (
 	"github.com/containerd/nerdctl/v2/pkg/maputil"
 	"github.com/containerd/nerdctl/v2/pkg/mountutil"
 	"github.com/containerd/nerdctl/v2/pkg/namestore"
+	"github.com/containerd/nerdctl/v2/pkg/netutil"
+	"github.com/containerd/nerdctl/v2/pkg/netutil/nettype"
 	"github.com/containerd/nerdctl/v2/pkg/netutil/networkstore"
 	"github.com/containerd/nerdctl/v2/pkg/platformutil"
 	"github.com/containerd/nerdctl/v2/pkg/portutil"
@@ -340,6 +342,38 @@ func Create(ctx context.Context, client *containerd.Client, args []string, netMa
 		return nil, generateRemoveStateDirFunc(ctx, id, internalLabels), fmt.Errorf("failed to verify networking settings: %w", err)
 	}
 
+	// Reserve the host ports for the lifetime of the container, so that they are kept across restarts,
+	// and that the conflicts are reported now rather than when the container starts.
+	// The other network types do not publish ports.
+	networkOpts := netManager.NetworkOptions()
+	netType, err := nettype.Detect(networkOpts.NetworkSlice)
+	if err != nil {
+		return nil, generateRemoveStateDirFunc(ctx, id, internalLabels), err
+	}
+	if netType == nettype.CNI {
+		// The networks are torn down with the firewall backend they are set up with, even if the configuration changes.
+		internalLabels.firewallBackend = options.GOptions.FirewallBackend
+	}
+	if netType == nettype.CNI && len(networkOpts.PortMappings) > 0 {
+		networkOpts.PortMappings, err = portutil.ReservePorts(ctx, client, dataStore, options.GOptions.Namespace, id, networkOpts.PortMappings)
+		if err != nil {
+			return nil, generateRemoveStateDirFunc(ctx, id, internalLabels), err
+		}
+		defer func() {
+			if retErr == nil {
+				return
+			}
+			if err := portutil.ReleasePorts(dataStore, options.GOptions.Namespace, id); err != nil {
+				log.G(ctx).WithError(err).Warnf("failed to release the host ports of container %q", id)
+			}
+		}()
+
+		netManager, err = containerutil.NewNetworkingOptionsManager(options.GOptions, networkOpts, client)
+		if err != nil {
+			return nil, generateRemoveStateDirFunc(ctx, id, internalLabels), err
+		}
+	}
+
 	netOpts, netNewContainerOpts, err := netManager.ContainerNetworkingOpts(ctx, id)
 	if err != nil {
 		return nil, generateRemoveOrphanedDirsFunc(ctx, id, dataStore, internalLabels), fmt.Errorf("failed to generate networking spec options: %w", err)
@@ -406,6 +440,9 @@ func Create(ctx context.Context, client *containerd.Client, args []string, netMa
 	if healthcheckConfig != "" {
 		internalLabels.healthcheck = healthcheckConfig
 	}
+	if options.HealthOnFailure != healthcheck.OnFailureNone {
+		internalLabels.healthOnFailure = options.HealthOnFailure
+	}
 
 	lCOpts, err := withContainerLabels(options.Label, options.LabelFile)
 	if err != nil {
@@ -514,7 +551,7 @@ func generatePublishAllPortMappings(exposedPorts map[string]struct{}) ([]cni.Por
 	var portMappings []cni.PortMapping
 
 	for port := range exposedPorts {
-		pm, err := portutil.ParseFlagP(port)
+		pm, err := portutil.ParseFlagPWithoutAllocation(port)
 		if err != nil {
 			return nil, err
 		}
@@ -819,6 +856,9 @@ type internalLabels struct {
 	dnsServers           []string
 	dnsSearchDomains     []string
 	dnsResolvConfOptions []string
+	networkEndpoints     map[string]netutil.EndpointSettings
+	bandwidth            cni.BandWidth
+	firewallBackend      string
 	// volume
 	mountPoints []*mountutil.Processed
 	anonVolumes []string
@@ -845,6 +885,8 @@ type internalLabels struct {
 
 	healthcheck string
 
+	healthOnFailure string
+
 	privileged bool
 
 	exposedPorts map[string]struct{}
@@ -870,6 +912,23 @@ func withInternalLabels(internalLabels internalLabels) (containerd.NewContainerO
 		return nil, err
 	}
 	m[labels.Networks] = string(networksJSON)
+	if len(internalLabels.networkEndpoints) > 0 {
+		endpointsJSON, err := json.Marshal(internalLabels.networkEndpoints)
+		if err != nil {
+			return nil, err
+		}
+		m[labels.NetworkEndpoints] = string(endpointsJSON)
+	}
+	if internalLabels.bandwidth != (cni.BandWidth{}) {
+		bandwidthJSON, err := json.Marshal(internalLabels.bandwidth)
+		if err != nil {
+			return nil, err
+		}
+		m[labels.Bandwidth] = string(bandwidthJSON)
+	}
+	if internalLabels.firewallBackend != "" {
+		m[labels.FirewallBackend] = internalLabels.firewallBackend
+	}
 	if internalLabels.logURI != "" {
 		m[labels.LogURI] = internalLabels.logURI
 		logConfigJSON, err := json.Marshal(internalLabels.logConfig)
@@ -998,6 +1057,10 @@ func withInternalLabels(internalLabels internalLabels) (containerd.NewContainerO
 		m[labels.HealthCheck] = internalLabels.healthcheck
 	}
 
+	if internalLabels.healthOnFailure != "" {
+		m[labels.HealthOnFailure] = internalLabels.healthOnFailure
+	}
+
 	return containerd.WithAdditionalContainerLabels(m), nil
 }
 
@@ -1042,6 +1105,9 @@ func withHealthcheck(options types.ContainerCreateOptions, ensuredImage *imgutil
 	if options.HealthStartPeriod != 0 {
 		hc.StartPeriod = options.HealthStartPeriod
 	}
+	if options.HealthStartInterval != 0 {
+		hc.StartInterval = options.HealthStartInterval
+	}
 
 	// Apply defaults for any unset values, but only if we have a healthcheck configured
 	if len(hc.Test) > 0 && hc.Test[0] != "NONE" {
@@ -1070,6 +1136,13 @@ func (il *internalLabels) loadNetOpts(opts types.NetworkOptions) {
 	il.dnsServers = opts.DNSServers
 	il.dnsSearchDomains = opts.DNSSearchDomains
 	il.dnsResolvConfOptions = opts.DNSResolvConfOptions
+	if len(opts.NetworkAliases) > 0 {
+		il.networkEndpoints = make(map[string]netutil.EndpointSettings, len(opts.NetworkAliases))
+		for netstr, aliases := range opts.NetworkAliases {
+			il.networkEndpoints[netstr] = netutil.EndpointSettings{Aliases: aliases}
+		}
+	}
+	il.bandwidth = opts.Bandwidth
 }
 
 func dockercompatMounts(mountPoints []*mountutil.Processed) []dockercompat.MountPoint {
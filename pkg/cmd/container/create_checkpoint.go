@@ -0,0 +1,193 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package container
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+
+	containerd "github.com/containerd/containerd/v2/client"
+	"github.com/containerd/log"
+
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/checkpointutil"
+	"github.com/containerd/nerdctl/v2/pkg/containerutil"
+	"github.com/containerd/nerdctl/v2/pkg/inspecttypes/dockercompat"
+	"github.com/containerd/nerdctl/v2/pkg/labels"
+)
+
+// CreateFromCheckpoint creates a container from a checkpoint archive created by `nerdctl checkpoint export`.
+// The checkpoint is imported and the rootfs diff is applied to the container, but the container is not restored:
+// it is restored by starting it with the checkpoint, e.g., `nerdctl start --checkpoint`.
+func CreateFromCheckpoint(ctx context.Context, client *containerd.Client, options types.ContainerCreateFromCheckpointOptions) (_ containerd.Container, retErr error) {
+	createOpt, netOpts := options.CreateOptions, options.NetworkOptions
+	// the mounts must be identical for restoring the checkpoint
+	if len(createOpt.Volume) > 0 || len(createOpt.Mount) > 0 || len(createOpt.Tmpfs) > 0 || len(createOpt.VolumesFrom) > 0 {
+		return nil, errors.New("--volume, --mount, --tmpfs and --volumes-from cannot be specified with --from-checkpoint, the mounts are restored from the checkpoint archive")
+	}
+
+	f, err := os.Open(options.Input)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	// extract into the checkpoint directory, so that the checkpoint can be renamed into place
+	if err := os.MkdirAll(options.CheckpointDir, 0o700); err != nil {
+		return nil, err
+	}
+	dir, err := os.MkdirTemp(options.CheckpointDir, ".import-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+	config, err := checkpointutil.ExtractArchive(ctx, f, dir)
+	if err != nil {
+		return nil, err
+	}
+	args, err := applyCheckpointConfig(config, &createOpt, &netOpts)
+	if err != nil {
+		return nil, err
+	}
+	checkpointPath, err := checkpointutil.InstallCheckpoint(dir, options.CheckpointDir, config)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if retErr != nil {
+			os.RemoveAll(checkpointPath)
+		}
+	}()
+
+	netManager, err := containerutil.NewNetworkingOptionsManager(createOpt.GOptions, netOpts, client)
+	if err != nil {
+		return nil, err
+	}
+	c, gc, err := Create(ctx, client, args, netManager, createOpt)
+	if err != nil {
+		if gc != nil {
+			gc()
+		}
+		return nil, err
+	}
+	// defer setting `nerdctl/error` label in case of error
+	defer func() {
+		if retErr != nil {
+			containerutil.UpdateErrorLabel(ctx, c, retErr)
+		}
+	}()
+
+	if err := checkpointutil.ApplyRootfsDiff(ctx, client, c, dir, config); err != nil {
+		return nil, err
+	}
+	log.G(ctx).Infof("Run `nerdctl start --checkpoint=%s %s` to restore the container", config.Checkpoint, c.ID())
+	return c, nil
+}
+
+// applyCheckpointConfig sets the options that are not set to the configuration of the checkpointed container.
+// The args for the image and the command are returned.
+func applyCheckpointConfig(config *checkpointutil.ArchiveConfig, opt *types.ContainerCreateOptions, netOpts *types.NetworkOptions) ([]string, error) {
+	process := config.Spec.Process
+
+	// the process must be identical for restoring the checkpoint
+	opt.EntrypointChanged = true
+	opt.Entrypoint = process.Args[:1]
+	opt.Env = append(slices.Clone(process.Env), opt.Env...)
+	if opt.Workdir == "" {
+		opt.Workdir = process.Cwd
+	}
+	if opt.User == "" {
+		opt.User = fmt.Sprintf("%d:%d", process.User.UID, process.User.GID)
+	}
+	if !opt.TTY {
+		opt.TTY = process.Terminal
+	}
+	if opt.Name == "" {
+		opt.Name = config.Labels[labels.Name]
+	}
+	if netOpts.Hostname == "" {
+		netOpts.Hostname = config.Spec.Hostname
+	}
+	if len(netOpts.NetworkSlice) == 0 && config.Labels[labels.Networks] != "" {
+		if err := json.Unmarshal([]byte(config.Labels[labels.Networks]), &netOpts.NetworkSlice); err != nil {
+			return nil, fmt.Errorf("invalid checkpoint archive: failed to parse networks: %w", err)
+		}
+	}
+	if len(netOpts.PortMappings) == 0 && config.Labels[labels.Ports] != "" {
+		if err := json.Unmarshal([]byte(config.Labels[labels.Ports]), &netOpts.PortMappings); err != nil {
+			return nil, fmt.Errorf("invalid checkpoint archive: failed to parse ports: %w", err)
+		}
+	}
+
+	// the mounts must be identical for restoring the checkpoint too
+	if config.Labels[labels.Mounts] != "" {
+		var mounts []dockercompat.MountPoint
+		if err := json.Unmarshal([]byte(config.Labels[labels.Mounts]), &mounts); err != nil {
+			return nil, fmt.Errorf("invalid checkpoint archive: failed to parse mounts: %w", err)
+		}
+		for _, m := range mounts {
+			if err := applyCheckpointMount(m, opt); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	// user labels, prepended so that the options take precedence
+	var userLabels []string
+	for _, k := range slices.Sorted(maps.Keys(config.Labels)) {
+		if strings.HasPrefix(k, labels.Prefix) || strings.HasPrefix(k, "io.containerd.") {
+			continue
+		}
+		userLabels = append(userLabels, k+"="+config.Labels[k])
+	}
+	opt.Label = append(userLabels, opt.Label...)
+
+	return append([]string{config.Image}, process.Args[1:]...), nil
+}
+
+// applyCheckpointMount adds the mount of the checkpointed container to the mount options.
+func applyCheckpointMount(m dockercompat.MountPoint, opt *types.ContainerCreateOptions) error {
+	switch m.Type {
+	case "bind", "volume":
+		source := m.Source
+		if m.Type == "volume" {
+			source = m.Name
+		}
+		fields := []string{"type=" + m.Type, "source=" + source, "target=" + m.Destination}
+		if !m.RW {
+			fields = append(fields, "readonly")
+		}
+		if m.Type == "bind" && m.Propagation != "" {
+			fields = append(fields, "bind-propagation="+m.Propagation)
+		}
+		opt.Mount = append(opt.Mount, strings.Join(fields, ","))
+	case "tmpfs":
+		tmpfs := m.Destination
+		if m.Mode != "" {
+			tmpfs += ":" + m.Mode
+		}
+		opt.Tmpfs = append(opt.Tmpfs, tmpfs)
+	default:
+		return fmt.Errorf("the %s mount on %s of the checkpointed container cannot be restored", m.Type, m.Destination)
+	}
+	return nil
+}
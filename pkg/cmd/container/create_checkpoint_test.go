@@ -0,0 +1,73 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package container
+
+import (
+	"testing"
+
+	"github.com/opencontainers/runtime-spec/specs-go"
+	"gotest.tools/v3/assert"
+
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/checkpointutil"
+	"github.com/containerd/nerdctl/v2/pkg/labels"
+)
+
+// TestApplyCheckpointConfig tests that the options that are set take precedence over the checkpointed container.
+func TestApplyCheckpointConfig(t *testing.T) {
+	t.Parallel()
+	config := &checkpointutil.ArchiveConfig{
+		Checkpoint: "checkpoint0",
+		Image:      "alpine",
+		Labels: map[string]string{
+			labels.Name:     "foo",
+			labels.Networks: `["net0"]`,
+			labels.Mounts:   `[{"Type":"volume","Name":"vol0","Destination":"/data","RW":true},{"Type":"tmpfs","Destination":"/tmp","Mode":"size=64m"}]`,
+			"com.example":   "bar",
+		},
+		Spec: &specs.Spec{
+			Hostname: "foo-host",
+			Process: &specs.Process{
+				Args: []string{"sleep", "infinity"},
+				Env:  []string{"PATH=/bin"},
+				Cwd:  "/work",
+				User: specs.User{UID: 1000, GID: 100},
+			},
+		},
+	}
+
+	opt := types.ContainerCreateOptions{
+		Name:  "bar",
+		Env:   []string{"FOO=1"},
+		Label: []string{"com.example=baz"},
+	}
+	var netOpts types.NetworkOptions
+	args, err := applyCheckpointConfig(config, &opt, &netOpts)
+	assert.NilError(t, err)
+
+	assert.DeepEqual(t, args, []string{"alpine", "infinity"})
+	assert.DeepEqual(t, opt.Entrypoint, []string{"sleep"})
+	assert.DeepEqual(t, opt.Env, []string{"PATH=/bin", "FOO=1"})
+	assert.Equal(t, opt.Workdir, "/work")
+	assert.Equal(t, opt.User, "1000:100")
+	assert.Equal(t, opt.Name, "bar")
+	assert.DeepEqual(t, opt.Label, []string{"com.example=bar", "com.example=baz"})
+	assert.DeepEqual(t, opt.Mount, []string{"type=volume,source=vol0,target=/data"})
+	assert.DeepEqual(t, opt.Tmpfs, []string{"/tmp:size=64m"})
+	assert.Equal(t, netOpts.Hostname, "foo-host")
+	assert.DeepEqual(t, netOpts.NetworkSlice, []string{"net0"})
+}
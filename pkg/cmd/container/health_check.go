@@ -18,17 +18,29 @@ package container
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strconv"
+	"syscall"
 	"time"
 
 	containerd "github.com/containerd/containerd/v2/client"
+	"github.com/containerd/containerd/v2/core/runtime/restart"
+	"github.com/containerd/errdefs"
+	"github.com/containerd/log"
 
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/config"
+	"github.com/containerd/nerdctl/v2/pkg/containerutil"
+	"github.com/containerd/nerdctl/v2/pkg/eventutil"
 	"github.com/containerd/nerdctl/v2/pkg/healthcheck"
 	"github.com/containerd/nerdctl/v2/pkg/labels"
+	"github.com/containerd/nerdctl/v2/pkg/ocihook"
 )
 
-// HealthCheck executes the health check command for a container
-func HealthCheck(ctx context.Context, client *containerd.Client, container containerd.Container) error {
+// HealthCheck executes the health check command for a container.
+// When the container becomes unhealthy, the --health-on-failure action of the container is carried out.
+func HealthCheck(ctx context.Context, client *containerd.Client, container containerd.Container, options types.ContainerHealthCheckOptions) error {
 	task, err := container.Task(ctx, nil)
 	if err != nil {
 		return fmt.Errorf("failed to get container task: %w", err)
@@ -74,8 +86,142 @@ func HealthCheck(ctx context.Context, client *containerd.Client, container conta
 		hcConfig.Retries = healthcheck.DefaultProbeRetries
 	}
 
+	// Skip the probes that the systemd timer fires ahead of time, at the start interval
+	if !healthcheck.ScheduledProbeDue(ctx, container, hcConfig) {
+		return nil
+	}
+
 	// Execute the health check
-	return healthcheck.ExecuteHealthCheck(ctx, task, container, hcConfig)
+	onFailure := info.Labels[labels.HealthOnFailure]
+	becameUnhealthy, err := healthcheck.ExecuteHealthCheck(ctx, task, container, hcConfig, onFailure)
+	if becameUnhealthy && onFailure != "" && onFailure != healthcheck.OnFailureNone {
+		if actionErr := healthOnFailure(ctx, client, container, onFailure, options); actionErr != nil {
+			return errors.Join(err, fmt.Errorf("failed to %s unhealthy container %s: %w", onFailure, container.ID(), actionErr))
+		}
+	}
+	return err
+}
+
+// healthOnFailure carries out the --health-on-failure action on a container that has just become unhealthy.
+func healthOnFailure(ctx context.Context, client *containerd.Client, container containerd.Container, action string, options types.ContainerHealthCheckOptions) error {
+	log.G(ctx).Warnf("container %s is unhealthy, health-on-failure action: %s", container.ID(), action)
+	eventutil.Publish(ctx, client.EventService(), eventutil.TopicContainerHealthOnFailure, container.ID(), map[string]string{
+		"action": action,
+	})
+
+	switch action {
+	case healthcheck.OnFailureKill:
+		if err := cleanupNetwork(ctx, container, options.GOptions); err != nil {
+			return err
+		}
+		if err := killContainer(ctx, container, syscall.SIGKILL); err != nil {
+			return err
+		}
+		// Start over from the starting state, so that the container is killed again if it keeps failing
+		// once restarted by its restart policy.
+		if err := healthcheck.ResetHealthState(ctx, container); err != nil {
+			return err
+		}
+		eventutil.Publish(ctx, client.EventService(), eventutil.TopicContainerKill, container.ID(), map[string]string{
+			"signal": strconv.Itoa(int(syscall.SIGKILL)),
+		})
+	case healthcheck.OnFailureStop:
+		if err := cleanupNetwork(ctx, container, options.GOptions); err != nil {
+			return err
+		}
+		if err := healthcheck.RemoveTransientHealthCheckFiles(ctx, container); err != nil {
+			return err
+		}
+		if err := containerutil.Stop(ctx, container, nil, "", client.EventService()); err != nil {
+			return err
+		}
+		eventutil.Publish(ctx, client.EventService(), eventutil.TopicContainerStop, container.ID(), nil)
+		return ocihook.CleanupPortReserverProcess(options.GOptions.Namespace, container.ID())
+	case healthcheck.OnFailureRestart:
+		if err := containerutil.Stop(ctx, container, nil, "", client.EventService()); err != nil {
+			return err
+		}
+		// Start over from the starting state, so that the container is restarted again if it keeps failing.
+		if err := healthcheck.ResetHealthState(ctx, container); err != nil {
+			return err
+		}
+		return containerutil.Start(ctx, container, false, false, client, "", "", (*config.Config)(&options.GOptions), options.NerdctlCmd, options.NerdctlArgs)
+	default:
+		return fmt.Errorf("unknown health-on-failure action %q", action)
+	}
+	return nil
+}
+
+// HealthCheckSupervisor runs the health check of a container immediately and then every health interval
+// (or start interval, during the start period), until ctx is cancelled by RemoveTransientHealthCheckFiles,
+// or the container is removed. While the container is not running, the supervisor keeps polling as long as
+// its restart policy may bring it back, so that the probes resume after containerd restarts the task.
+// It is used in place of the transient systemd timer when the latter is unavailable,
+// so that the health status is recorded the same way in every mode.
+func HealthCheckSupervisor(ctx context.Context, client *containerd.Client, container containerd.Container, options types.ContainerHealthCheckOptions) error {
+	info, err := container.Info(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get container info: %w", err)
+	}
+	hcConfigJSON, ok := info.Labels[labels.HealthCheck]
+	if !ok {
+		return fmt.Errorf("container has no health check configured")
+	}
+	hcConfig, err := healthcheck.HealthCheckFromJSON(hcConfigJSON)
+	if err != nil {
+		return fmt.Errorf("invalid health check configuration: %w", err)
+	}
+
+	for {
+		l, err := container.Labels(ctx)
+		if errdefs.IsNotFound(err) {
+			log.G(ctx).Debugf("container %s has been removed, stopping healthcheck supervisor", container.ID())
+			return nil
+		}
+		if err != nil {
+			log.G(ctx).WithError(err).Debugf("failed to get labels of container %s", container.ID())
+		} else if status, err := taskStatus(ctx, container); err != nil {
+			log.G(ctx).WithError(err).Debugf("failed to get status of container %s", container.ID())
+		} else {
+			switch status.Status {
+			case containerd.Running:
+				if err := HealthCheck(ctx, client, container, options); err != nil {
+					log.G(ctx).WithError(err).Debugf("health check of container %s failed", container.ID())
+				}
+			case containerd.Paused, containerd.Pausing:
+				// Probes are suspended while the container is paused, as with the systemd timer.
+			default:
+				if !mayBeRestarted(l, status) {
+					log.G(ctx).Debugf("container %s is not running and will not be restarted, stopping healthcheck supervisor", container.ID())
+					return nil
+				}
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(healthcheck.NextProbeInterval(ctx, container, hcConfig)):
+		}
+	}
+}
+
+// taskStatus returns the status of the task of a container, or a zero status when the container has no task.
+func taskStatus(ctx context.Context, container containerd.Container) (containerd.Status, error) {
+	task, err := container.Task(ctx, nil)
+	if err != nil {
+		if errdefs.IsNotFound(err) {
+			return containerd.Status{}, nil
+		}
+		return containerd.Status{}, err
+	}
+	return task.Status(ctx)
+}
+
+// mayBeRestarted reports whether the containerd restart monitor will start a container that is not running,
+// following the same rules as the monitor.
+func mayBeRestarted(l map[string]string, status containerd.Status) bool {
+	return l[restart.StatusLabel] == string(containerd.Running) && restart.Reconcile(status, l)
 }
 
 // If configuredValue is zero, use defaultValue instead.
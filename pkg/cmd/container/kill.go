@@ -21,6 +21,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"strconv"
 	"strings"
 	"syscall"
 
@@ -35,6 +36,7 @@ import (
 	"github.com/containerd/nerdctl/v2/pkg/api/types"
 	"github.com/containerd/nerdctl/v2/pkg/clientutil"
 	"github.com/containerd/nerdctl/v2/pkg/containerutil"
+	"github.com/containerd/nerdctl/v2/pkg/eventutil"
 	"github.com/containerd/nerdctl/v2/pkg/healthcheck"
 	"github.com/containerd/nerdctl/v2/pkg/idutil/containerwalker"
 	"github.com/containerd/nerdctl/v2/pkg/labels"
@@ -71,6 +73,9 @@ func Kill(ctx context.Context, client *containerd.Client, reqs []string, options
 				}
 				return err
 			}
+			eventutil.Publish(ctx, client.EventService(), eventutil.TopicContainerKill, found.Container.ID(), map[string]string{
+				"signal": strconv.Itoa(int(parsedSignal)),
+			})
 			_, err := fmt.Fprintln(options.Stdout, found.Container.ID())
 			return err
 		},
@@ -166,19 +171,39 @@ func cleanupNetwork(ctx context.Context, container containerd.Container, globalO
 		case nettype.Host, nettype.None, nettype.Container, nettype.Namespace:
 			// NOP
 		case nettype.CNI:
-			e, err := netutil.NewCNIEnv(globalOpts.CNIPath, globalOpts.CNINetConfPath, netutil.WithNamespace(globalOpts.Namespace), netutil.WithDefaultNetwork(globalOpts.BridgeIP))
+			firewallBackend := netutil.ContainerFirewallBackend(spec.Annotations, globalOpts.FirewallBackend)
+			e, err := netutil.NewCNIEnv(globalOpts.CNIPath, globalOpts.CNINetConfPath, netutil.WithNamespace(globalOpts.Namespace), netutil.WithFirewallBackend(firewallBackend), netutil.WithDefaultNetwork(globalOpts.BridgeIP))
 			if err != nil {
 				return err
 			}
+			endpoints, err := netutil.ParseEndpoints(spec.Annotations[labels.NetworkEndpoints])
+			if err != nil {
+				return err
+			}
+			namespace := spec.Annotations[labels.Namespace]
+			fullID := namespace + "-" + container.ID()
 			cniOpts := []cni.Opt{
 				cni.WithPluginDir([]string{globalOpts.CNIPath}),
 			}
+			var cniNetworks int
 			var netw *netutil.NetworkConfig
 			for _, netstr := range networks {
 				if netw, err = e.NetworkByNameOrID(netstr); err != nil {
 					return err
 				}
+				// Networks with a pinned interface name are not numbered by go-cni, see netutil.EndpointSettings
+				if ep := endpoints[netstr]; ep.IfName != "" {
+					if err := netutil.DetachNetwork(ctx, globalOpts.CNIPath, netw, fullID, "", ep, ports); err != nil {
+						log.L.WithError(err).Errorf("failed to detach network %q", netstr)
+						return err
+					}
+					continue
+				}
 				cniOpts = append(cniOpts, cni.WithConfListBytes(netw.Bytes))
+				cniNetworks++
+			}
+			if cniNetworks == 0 {
+				return nil
 			}
 			cniObj, err := cni.New(cniOpts...)
 			if err != nil {
@@ -187,8 +212,6 @@ func cleanupNetwork(ctx context.Context, container containerd.Container, globalO
 
 			var namespaceOpts []cni.NamespaceOpts
 			namespaceOpts = append(namespaceOpts, portMappings...)
-			namespace := spec.Annotations[labels.Namespace]
-			fullID := namespace + "-" + container.ID()
 			if err := cniObj.Remove(ctx, fullID, "", namespaceOpts...); err != nil {
 				log.L.WithError(err).Errorf("failed to call cni.Remove")
 				return err
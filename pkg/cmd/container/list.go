@@ -44,7 +44,7 @@ import (
 
 // List prints containers according to `options`.
 func List(ctx context.Context, client *containerd.Client, options types.ContainerListOptions) ([]ListItem, error) {
-	containers, cMap, err := filterContainers(ctx, client, options.Filters, options.LastN, options.All)
+	containers, cMap, err := filterContainers(ctx, client, options.Filters, options.LastN, options.All, options.GOptions)
 	if err != nil {
 		return nil, err
 	}
@@ -57,12 +57,12 @@ func List(ctx context.Context, client *containerd.Client, options types.Containe
 //   - all means showing all containers (default shows just running).
 //   - lastN means only showing n last created containers (includes all states). Non-positive values are ignored.
 //     In other words, if lastN is positive, all will be set to true.
-func filterContainers(ctx context.Context, client *containerd.Client, filters []string, lastN int, all bool) ([]containerd.Container, map[string]string, error) {
+func filterContainers(ctx context.Context, client *containerd.Client, filters []string, lastN int, all bool, globalOptions types.GlobalCommandOptions) ([]containerd.Container, map[string]string, error) {
 	containers, err := client.Containers(ctx)
 	if err != nil {
 		return nil, nil, err
 	}
-	filterCtx, err := foldContainerFilters(ctx, containers, filters)
+	filterCtx, err := foldContainerFilters(ctx, client, containers, filters, globalOptions)
 	if err != nil {
 		return nil, nil, err
 	}
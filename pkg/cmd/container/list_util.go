@@ -18,38 +18,62 @@ package container
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/opencontainers/go-digest"
+
 	containerd "github.com/containerd/containerd/v2/client"
 	"github.com/containerd/containerd/v2/core/containers"
 	"github.com/containerd/errdefs"
 	"github.com/containerd/log"
 
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/clientutil"
 	"github.com/containerd/nerdctl/v2/pkg/containerutil"
+	"github.com/containerd/nerdctl/v2/pkg/healthcheck"
+	"github.com/containerd/nerdctl/v2/pkg/idutil/imagewalker"
+	"github.com/containerd/nerdctl/v2/pkg/labels"
+	"github.com/containerd/nerdctl/v2/pkg/portutil"
 )
 
-func foldContainerFilters(ctx context.Context, containers []containerd.Container, filters []string) (*containerFilterContext, error) {
-	filterCtx := &containerFilterContext{containers: containers}
+func foldContainerFilters(ctx context.Context, client *containerd.Client, containers []containerd.Container, filters []string, globalOptions types.GlobalCommandOptions) (*containerFilterContext, error) {
+	filterCtx := &containerFilterContext{
+		client:        client,
+		containers:    containers,
+		globalOptions: globalOptions,
+		imageRootFS:   map[string][]digest.Digest{},
+	}
 	err := filterCtx.foldFilters(ctx, filters)
 	return filterCtx, err
 }
 
 type containerFilterContext struct {
-	containers []containerd.Container
-
-	idFilterFuncs      []func(string) bool
-	nameFilterFuncs    []func(string) bool
-	exitedFilterFuncs  []func(int) bool
-	beforeFilterFuncs  []func(t time.Time) bool
-	sinceFilterFuncs   []func(t time.Time) bool
-	statusFilterFuncs  []func(containerd.ProcessStatus) bool
-	labelFilterFuncs   []func(map[string]string) bool
-	volumeFilterFuncs  []func([]*containerutil.ContainerVolume) bool
-	networkFilterFuncs []func([]string) bool
+	client        *containerd.Client
+	containers    []containerd.Container
+	globalOptions types.GlobalCommandOptions
+	// imageRootFS caches the diff IDs of the images of the containers, by image name
+	imageRootFS map[string][]digest.Digest
+
+	idFilterFuncs       []func(string) bool
+	nameFilterFuncs     []func(string) bool
+	exitedFilterFuncs   []func(int) bool
+	beforeFilterFuncs   []func(t time.Time) bool
+	sinceFilterFuncs    []func(t time.Time) bool
+	statusFilterFuncs   []func(containerd.ProcessStatus) bool
+	labelFilterFuncs    []func(map[string]string) bool
+	volumeFilterFuncs   []func([]*containerutil.ContainerVolume) bool
+	networkFilterFuncs  []func([]string) bool
+	ancestorFilterFuncs []func(image string, rootFS []digest.Digest) bool
+	healthFilterFuncs   []func(healthcheck.HealthStatus) bool
+	publishFilterFuncs  []func(ports map[string]struct{}) bool
+	exposeFilterFuncs   []func(ports map[string]struct{}) bool
+	isTaskFilterFuncs   []func(isTask bool) bool
 
 	all bool
 }
@@ -76,9 +100,12 @@ func (cl *containerFilterContext) foldFilters(ctx context.Context, filters []str
 	}{
 		{"id", cl.foldIDFilter}, {"name", cl.foldNameFilter},
 		{"before", cl.foldBeforeFilter}, {"since", cl.foldSinceFilter},
-		{"network", cl.foldNetworkFilter}, {"label", cl.foldLabelFilter},
-		{"volume", cl.foldVolumeFilter}, {"status", cl.foldStatusFilter},
-		{"exited", cl.foldExitedFilter},
+		{"network", cl.foldNetworkFilter}, {"label!", cl.foldLabelNotFilter},
+		{"label", cl.foldLabelFilter}, {"volume", cl.foldVolumeFilter},
+		{"status", cl.foldStatusFilter}, {"exited", cl.foldExitedFilter},
+		{"ancestor", cl.foldAncestorFilter}, {"health", cl.foldHealthFilter},
+		{"publish", cl.foldPublishFilter}, {"expose", cl.foldExposeFilter},
+		{"is-task", cl.foldIsTaskFilter},
 	}
 	for _, filter := range filters {
 		invalidFilter := true
@@ -179,12 +206,27 @@ func (cl *containerFilterContext) foldNameFilter(_ context.Context, filter, valu
 }
 
 func (cl *containerFilterContext) foldLabelFilter(_ context.Context, filter, value string) error {
+	cl.labelFilterFuncs = append(cl.labelFilterFuncs, labelMatcher(value))
+	return nil
+}
+
+// foldLabelNotFilter folds `label!=key` and `label!=key=value`, which match the containers
+// without the label, or with the label set to another value.
+func (cl *containerFilterContext) foldLabelNotFilter(_ context.Context, filter, value string) error {
+	matchesLabel := labelMatcher(value)
+	cl.labelFilterFuncs = append(cl.labelFilterFuncs, func(labels map[string]string) bool {
+		return !matchesLabel(labels)
+	})
+	return nil
+}
+
+func labelMatcher(value string) func(map[string]string) bool {
 	k, v, hasValue := value, "", false
 	if subs := strings.SplitN(value, "=", 2); len(subs) == 2 {
 		hasValue = true
 		k, v = subs[0], subs[1]
 	}
-	cl.labelFilterFuncs = append(cl.labelFilterFuncs, func(labels map[string]string) bool {
+	return func(labels map[string]string) bool {
 		if labels == nil {
 			return false
 		}
@@ -193,8 +235,7 @@ func (cl *containerFilterContext) foldLabelFilter(_ context.Context, filter, val
 			return false
 		}
 		return true
-	})
-	return nil
+	}
 }
 
 func (cl *containerFilterContext) foldVolumeFilter(_ context.Context, filter, value string) error {
@@ -225,13 +266,16 @@ func (cl *containerFilterContext) foldNetworkFilter(_ context.Context, filter, v
 
 func (cl *containerFilterContext) matchesInfoFilters(ctx context.Context, container containerd.Container) bool {
 	if len(cl.idFilterFuncs)+len(cl.nameFilterFuncs)+len(cl.beforeFilterFuncs)+
-		len(cl.sinceFilterFuncs)+len(cl.labelFilterFuncs)+len(cl.volumeFilterFuncs)+len(cl.networkFilterFuncs) == 0 {
+		len(cl.sinceFilterFuncs)+len(cl.labelFilterFuncs)+len(cl.volumeFilterFuncs)+len(cl.networkFilterFuncs)+
+		len(cl.ancestorFilterFuncs)+len(cl.healthFilterFuncs)+len(cl.publishFilterFuncs)+len(cl.exposeFilterFuncs)+
+		len(cl.isTaskFilterFuncs) == 0 {
 		return true
 	}
 	info, _ := container.Info(ctx, containerd.WithoutRefreshedMetadata)
 	return cl.matchesIDFilter(info) && cl.matchesNameFilter(info) && cl.matchesBeforeFilter(info) &&
 		cl.matchesSinceFilter(info) && cl.matchesLabelFilter(info) && cl.matchesVolumeFilter(info) &&
-		cl.matchesNetworkFilter(info)
+		cl.matchesNetworkFilter(info) && cl.matchesAncestorFilter(ctx, info) && cl.matchesHealthFilter(info) &&
+		cl.matchesPortFilters(info) && cl.matchesIsTaskFilter()
 }
 
 func (cl *containerFilterContext) matchesTaskFilters(ctx context.Context, container containerd.Container) bool {
@@ -257,6 +301,133 @@ func (cl *containerFilterContext) matchesTaskFilters(ctx context.Context, contai
 	return cl.matchesExitedFilter(status) && cl.matchesStatusFilter(status)
 }
 
+// foldAncestorFilter folds `ancestor=IMAGE`, which matches the containers created from the image,
+// or from an image built on top of it, i.e., an image whose layers start with the layers of the image.
+func (cl *containerFilterContext) foldAncestorFilter(ctx context.Context, filter, value string) error {
+	type ancestor struct {
+		name   string
+		rootFS []digest.Digest
+	}
+	var ancestors []ancestor
+	walker := &imagewalker.ImageWalker{
+		Client: cl.client,
+		OnFound: func(ctx context.Context, found imagewalker.Found) error {
+			rootFS, err := containerd.NewImage(cl.client, found.Image).RootFS(ctx)
+			if err != nil {
+				log.G(ctx).WithError(err).Debugf("failed to get the layers of image %q", found.Image.Name)
+			}
+			ancestors = append(ancestors, ancestor{name: found.Image.Name, rootFS: rootFS})
+			return nil
+		},
+	}
+	n, err := walker.Walk(ctx, value)
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return fmt.Errorf("no such image: %s: %w", value, errdefs.ErrNotFound)
+	}
+	cl.ancestorFilterFuncs = append(cl.ancestorFilterFuncs, func(image string, rootFS []digest.Digest) bool {
+		for _, a := range ancestors {
+			if image == a.name {
+				return true
+			}
+			if len(a.rootFS) > 0 && len(rootFS) >= len(a.rootFS) && slices.Equal(rootFS[:len(a.rootFS)], a.rootFS) {
+				return true
+			}
+		}
+		return false
+	})
+	return nil
+}
+
+func (cl *containerFilterContext) foldHealthFilter(_ context.Context, filter, value string) error {
+	status := healthcheck.HealthStatus(value)
+	switch status {
+	case healthcheck.Starting, healthcheck.Healthy, healthcheck.Unhealthy, healthcheck.NoHealthcheck:
+	default:
+		return fmt.Errorf("unrecognised filter value for health: %s", value)
+	}
+	cl.healthFilterFuncs = append(cl.healthFilterFuncs, func(s healthcheck.HealthStatus) bool {
+		return s == status
+	})
+	return nil
+}
+
+// foldPublishFilter folds `publish=PORT[/PROTO]` and `publish=START-END[/PROTO]`, which match
+// the containers publishing a container port in the range to the host.
+func (cl *containerFilterContext) foldPublishFilter(_ context.Context, filter, value string) error {
+	matchesPort, err := portMatcher(value)
+	if err != nil {
+		return err
+	}
+	cl.publishFilterFuncs = append(cl.publishFilterFuncs, matchesPort)
+	return nil
+}
+
+// foldExposeFilter folds `expose=PORT[/PROTO]` and `expose=START-END[/PROTO]`, which match
+// the containers exposing or publishing a container port in the range.
+func (cl *containerFilterContext) foldExposeFilter(_ context.Context, filter, value string) error {
+	matchesPort, err := portMatcher(value)
+	if err != nil {
+		return err
+	}
+	cl.exposeFilterFuncs = append(cl.exposeFilterFuncs, matchesPort)
+	return nil
+}
+
+// foldIsTaskFilter folds `is-task=true|false`. There are no Swarm tasks in nerdctl.
+func (cl *containerFilterContext) foldIsTaskFilter(_ context.Context, filter, value string) error {
+	want, err := strconv.ParseBool(value)
+	if err != nil {
+		return fmt.Errorf("invalid filter '%s'", filter)
+	}
+	cl.isTaskFilterFuncs = append(cl.isTaskFilterFuncs, func(isTask bool) bool {
+		return isTask == want
+	})
+	return nil
+}
+
+// portMatcher returns a function matching the sets of ports (e.g., {"80/tcp"}) that contain one of
+// the ports of a range like `8080`, `8080/udp` or `8000-8080/tcp`.
+func portMatcher(value string) (func(ports map[string]struct{}) bool, error) {
+	portRange, proto, _ := strings.Cut(value, "/")
+	if proto == "" {
+		proto = "tcp"
+	}
+	start, end, err := parsePortRange(portRange)
+	if err != nil {
+		return nil, fmt.Errorf("invalid port %q: %w", value, err)
+	}
+	return func(ports map[string]struct{}) bool {
+		for port := start; port <= end; port++ {
+			if _, ok := ports[fmt.Sprintf("%d/%s", port, proto)]; ok {
+				return true
+			}
+		}
+		return false
+	}, nil
+}
+
+func parsePortRange(portRange string) (uint64, uint64, error) {
+	startStr, endStr, isRange := strings.Cut(portRange, "-")
+	start, err := strconv.ParseUint(startStr, 10, 16)
+	if err != nil {
+		return 0, 0, err
+	}
+	if !isRange {
+		return start, start, nil
+	}
+	end, err := strconv.ParseUint(endStr, 10, 16)
+	if err != nil {
+		return 0, 0, err
+	}
+	if end < start {
+		return 0, 0, fmt.Errorf("invalid range %q", portRange)
+	}
+	return start, end, nil
+}
+
 func (cl *containerFilterContext) matchesExitedFilter(status containerd.Status) bool {
 	if len(cl.exitedFilterFuncs) == 0 {
 		return true
@@ -377,6 +548,126 @@ func (cl *containerFilterContext) matchesNetworkFilter(info containers.Container
 	return false
 }
 
+func (cl *containerFilterContext) matchesAncestorFilter(ctx context.Context, info containers.Container) bool {
+	if len(cl.ancestorFilterFuncs) == 0 {
+		return true
+	}
+	rootFS, ok := cl.imageRootFS[info.Image]
+	if !ok {
+		if img, err := cl.client.ImageService().Get(ctx, info.Image); err == nil {
+			rootFS, err = containerd.NewImage(cl.client, img).RootFS(ctx)
+			if err != nil {
+				log.G(ctx).WithError(err).Debugf("failed to get the layers of image %q", info.Image)
+			}
+		}
+		cl.imageRootFS[info.Image] = rootFS
+	}
+	for _, ancestorFilterFunc := range cl.ancestorFilterFuncs {
+		if !ancestorFilterFunc(info.Image, rootFS) {
+			continue
+		}
+		return true
+	}
+	return false
+}
+
+func (cl *containerFilterContext) matchesHealthFilter(info containers.Container) bool {
+	if len(cl.healthFilterFuncs) == 0 {
+		return true
+	}
+	status := containerHealthStatus(info.Labels)
+	for _, healthFilterFunc := range cl.healthFilterFuncs {
+		if !healthFilterFunc(status) {
+			continue
+		}
+		return true
+	}
+	return false
+}
+
+func (cl *containerFilterContext) matchesPortFilters(info containers.Container) bool {
+	if len(cl.publishFilterFuncs)+len(cl.exposeFilterFuncs) == 0 {
+		return true
+	}
+	published, exposed := cl.containerPorts(info)
+	return matchesAnyPortFilter(cl.publishFilterFuncs, published) && matchesAnyPortFilter(cl.exposeFilterFuncs, exposed)
+}
+
+func matchesAnyPortFilter(portFilterFuncs []func(map[string]struct{}) bool, ports map[string]struct{}) bool {
+	if len(portFilterFuncs) == 0 {
+		return true
+	}
+	for _, portFilterFunc := range portFilterFuncs {
+		if !portFilterFunc(ports) {
+			continue
+		}
+		return true
+	}
+	return false
+}
+
+func (cl *containerFilterContext) matchesIsTaskFilter() bool {
+	for _, isTaskFilterFunc := range cl.isTaskFilterFuncs {
+		if !isTaskFilterFunc(false) {
+			return false
+		}
+	}
+	return true
+}
+
+// containerPorts returns the published and the exposed container ports of a container, like "80/tcp".
+// As with Docker, a port is published by `-p 8080:80` as "80/tcp", regardless of the host port.
+func (cl *containerFilterContext) containerPorts(info containers.Container) (published, exposed map[string]struct{}) {
+	published, exposed = map[string]struct{}{}, map[string]struct{}{}
+	if exposedJSON := info.Labels[labels.ExposedPorts]; exposedJSON != "" {
+		var exposedPorts map[string]struct{}
+		if err := json.Unmarshal([]byte(exposedJSON), &exposedPorts); err != nil {
+			log.L.WithError(err).Debugf("failed to parse the exposed ports of container %s", info.ID)
+		}
+		for port := range exposedPorts {
+			portRange, proto, _ := strings.Cut(port, "/")
+			if proto == "" {
+				proto = "tcp"
+			}
+			start, end, err := parsePortRange(portRange)
+			if err != nil {
+				continue
+			}
+			for p := start; p <= end; p++ {
+				exposed[fmt.Sprintf("%d/%s", p, proto)] = struct{}{}
+			}
+		}
+	}
+	dataStore, err := clientutil.DataStore(cl.globalOptions.DataRoot, cl.globalOptions.Address)
+	if err != nil {
+		log.L.WithError(err).Debug("failed to get the data store")
+		return published, exposed
+	}
+	ports, err := portutil.LoadPortMappings(dataStore, cl.globalOptions.Namespace, info.ID, info.Labels)
+	if err != nil {
+		log.L.WithError(err).Debugf("failed to load the port mappings of container %s", info.ID)
+		return published, exposed
+	}
+	for _, p := range ports {
+		published[fmt.Sprintf("%d/%s", p.ContainerPort, p.Protocol)] = struct{}{}
+		exposed[fmt.Sprintf("%d/%s", p.ContainerPort, p.Protocol)] = struct{}{}
+	}
+	return published, exposed
+}
+
+// containerHealthStatus returns the health status of a container, or "none" when it has no health check.
+func containerHealthStatus(containerLabels map[string]string) healthcheck.HealthStatus {
+	hc, err := healthcheck.HealthCheckFromJSON(containerLabels[labels.HealthCheck])
+	if err != nil || hc == nil || len(hc.Test) == 0 || hc.Test[0] == healthcheck.CmdNone {
+		return healthcheck.NoHealthcheck
+	}
+	state, err := healthcheck.HealthStateFromJSON(containerLabels[labels.HealthState])
+	if err != nil || state == nil || state.Status == "" {
+		return healthcheck.Starting
+	}
+	return state.Status
+}
+
 func idOrNameFilter(ctx context.Context, containers []containerd.Container, value string) (*containers.Container, error) {
 	for _, container := range containers {
 		info, err := container.Info(ctx, containerd.WithoutRefreshedMetadata)
@@ -32,6 +32,7 @@ import (
 
 	"github.com/containerd/nerdctl/v2/pkg/api/types"
 	"github.com/containerd/nerdctl/v2/pkg/clientutil"
+	"github.com/containerd/nerdctl/v2/pkg/composer/serviceparser"
 	"github.com/containerd/nerdctl/v2/pkg/containerutil"
 	"github.com/containerd/nerdctl/v2/pkg/dnsutil/hostsstore"
 	"github.com/containerd/nerdctl/v2/pkg/healthcheck"
@@ -266,6 +267,26 @@ func RemoveContainer(ctx context.Context, c containerd.Container, globalOptions
 			log.G(ctx).WithError(err).Warnf("failed to remove hosts file for container %q", id)
 		}
 
+		// Remove the secrets and the configs materialized by compose for the container - soft failure
+		if project := containerLabels[labels.ComposeProject]; project != "" && name != "" {
+			if dir, err := serviceparser.FilesDir(containerNamespace, project, name); err != nil {
+				log.G(ctx).WithError(err).Warnf("failed to get the secrets and configs directory of container %q", id)
+			} else if err = os.RemoveAll(dir); err != nil {
+				log.G(ctx).WithError(err).Warnf("failed to remove the secrets and configs of container %q", id)
+			}
+		}
+
+		// Unmount the devices of the volumes that are no longer used by any container - soft failure.
+		// This is normally done by the postStop hook already, unless the hook could not run.
+		if err = volumestore.UnmountVolumes(dataStore, globalOptions.Namespace, id, labels.GetVolumeNames(containerLabels)); err != nil {
+			log.G(ctx).WithError(err).Warnf("failed to unmount the volumes of container %q", id)
+		}
+
+		// Release the host ports reserved by the container - soft failure
+		if err = portutil.ReleasePorts(dataStore, containerNamespace, id); err != nil {
+			log.G(ctx).WithError(err).Warnf("failed to release the host ports of container %q", id)
+		}
+
 		// Volume removal is not handled by the poststop hook lifecycle because it depends on removeAnonVolumes option
 		// Note that the anonymous volume list has been obtained earlier, without locking the volume store.
 		// Technically, a concurrent operation MAY have deleted these anonymous volumes already at this point, which
@@ -45,7 +45,7 @@ func Restart(ctx context.Context, client *containerd.Client, containers []string
 			if _, ok := info.Labels[k8slabels.ContainerType]; ok {
 				log.L.Warnf("nerdctl does not support restarting container %s created by Kubernetes", info.ID)
 			}
-			if err := containerutil.Stop(ctx, found.Container, options.Timeout, options.Signal); err != nil {
+			if err := containerutil.Stop(ctx, found.Container, options.Timeout, options.Signal, client.EventService()); err != nil {
 				return err
 			}
 
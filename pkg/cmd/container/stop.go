@@ -25,6 +25,7 @@ import (
 
 	"github.com/containerd/nerdctl/v2/pkg/api/types"
 	"github.com/containerd/nerdctl/v2/pkg/containerutil"
+	"github.com/containerd/nerdctl/v2/pkg/eventutil"
 	"github.com/containerd/nerdctl/v2/pkg/healthcheck"
 	"github.com/containerd/nerdctl/v2/pkg/idutil/containerwalker"
 	"github.com/containerd/nerdctl/v2/pkg/ocihook"
@@ -44,13 +45,14 @@ func Stop(ctx context.Context, client *containerd.Client, reqs []string, opt typ
 			if err := healthcheck.RemoveTransientHealthCheckFiles(ctx, found.Container); err != nil {
 				return fmt.Errorf("unable to cleanup healthcheck timer for container: %s: %w", found.Req, err)
 			}
-			if err := containerutil.Stop(ctx, found.Container, opt.Timeout, opt.Signal); err != nil {
+			if err := containerutil.Stop(ctx, found.Container, opt.Timeout, opt.Signal, client.EventService()); err != nil {
 				if errdefs.IsNotFound(err) {
 					fmt.Fprintf(opt.Stderr, "No such container: %s\n", found.Req)
 					return nil
 				}
 				return err
 			}
+			eventutil.Publish(ctx, client.EventService(), eventutil.TopicContainerStop, found.Container.ID(), nil)
 			if err := ocihook.CleanupPortReserverProcess(opt.GOptions.Namespace, found.Container.ID()); err != nil {
 				return fmt.Errorf("unable to cleanup port reserver process for container: %s: %w", found.Req, err)
 			}
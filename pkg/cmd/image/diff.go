@@ -0,0 +1,347 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package image
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/opencontainers/go-digest"
+	"github.com/opencontainers/image-spec/identity"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+
+	containerd "github.com/containerd/containerd/v2/client"
+	"github.com/containerd/containerd/v2/core/leases"
+	"github.com/containerd/containerd/v2/core/mount"
+	"github.com/containerd/continuity/fs"
+	"github.com/containerd/platforms"
+
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/idgen"
+	"github.com/containerd/nerdctl/v2/pkg/imgutil"
+	"github.com/containerd/nerdctl/v2/pkg/platformutil"
+	"github.com/containerd/nerdctl/v2/pkg/rootlessutil"
+)
+
+// Kinds of the differences reported by Diff.
+const (
+	DiffAdded   = "added"
+	DiffRemoved = "removed"
+	DiffChanged = "changed"
+)
+
+// ImageDiff holds the differences between two images.
+type ImageDiff struct {
+	Layers []LayerDiff  `json:"Layers"`
+	Config []ConfigDiff `json:"Config"`
+	// Files is only set when the filesystem contents are compared.
+	Files []FileDiff `json:"Files,omitempty"`
+}
+
+// LayerDiff is a layer that differs at the same position of both images.
+type LayerDiff struct {
+	Kind   string        `json:"Kind"`
+	Index  int           `json:"Index"`
+	Before digest.Digest `json:"Before,omitempty"`
+	After  digest.Digest `json:"After,omitempty"`
+}
+
+// ConfigDiff is a difference in the image configuration.
+// Key is set for the fields holding several entries (e.g., the name of an environment variable).
+type ConfigDiff struct {
+	Kind   string `json:"Kind"`
+	Field  string `json:"Field"`
+	Key    string `json:"Key,omitempty"`
+	Before string `json:"Before,omitempty"`
+	After  string `json:"After,omitempty"`
+}
+
+// FileDiff is a path added, removed or changed in the second image.
+type FileDiff struct {
+	Kind string `json:"Kind"`
+	Path string `json:"Path"`
+}
+
+// Diff compares the layers, the configuration, and optionally the filesystem contents of two images,
+// and prints the differences of the second image from the first one.
+func Diff(ctx context.Context, client *containerd.Client, rawRef1, rawRef2 string, options types.ImageDiffOptions) error {
+	switch options.Format {
+	case "", "json":
+	default:
+		return fmt.Errorf("unsupported format %q, supported formats: json", options.Format)
+	}
+
+	var platforms []string
+	if options.Platform != "" {
+		platforms = []string{options.Platform}
+	}
+	platMC, err := platformutil.NewMatchComparer(false, platforms)
+	if err != nil {
+		return err
+	}
+
+	var imgs [2]containerd.Image
+	var manifests [2]*ocispec.Manifest
+	var configs [2]ocispec.Image
+	for i, rawRef := range []string{rawRef1, rawRef2} {
+		img, err := resolveImage(ctx, client, rawRef)
+		if err != nil {
+			return err
+		}
+		imgs[i] = containerd.NewImageWithPlatform(client, img, platMC)
+		if manifests[i], _, err = imgutil.ReadManifest(ctx, imgs[i]); err != nil {
+			return fmt.Errorf("failed to read the manifest of %q: %w", rawRef, err)
+		}
+		if configs[i], _, err = imgutil.ReadImageConfig(ctx, imgs[i]); err != nil {
+			return fmt.Errorf("failed to read the config of %q: %w", rawRef, err)
+		}
+	}
+
+	diff := ImageDiff{
+		Layers: diffLayers(manifests[0].Layers, manifests[1].Layers),
+		Config: diffConfigs(configs[0], configs[1]),
+	}
+	if options.Files {
+		if rootlessutil.IsRootless() {
+			return errors.New("comparing the filesystem contents is not supported in rootless mode")
+		}
+		diff.Files, err = diffFiles(ctx, client, imgs, configs, platMC, options.GOptions)
+		if err != nil {
+			return err
+		}
+	}
+
+	if options.Format == "json" {
+		b, err := json.MarshalIndent(diff, "", "    ")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(options.Stdout, string(b))
+		return nil
+	}
+	printImageDiff(options.Stdout, diff)
+	return nil
+}
+
+func diffLayers(before, after []ocispec.Descriptor) []LayerDiff {
+	var res []LayerDiff
+	for i := 0; i < max(len(before), len(after)); i++ {
+		switch {
+		case i >= len(before):
+			res = append(res, LayerDiff{Kind: DiffAdded, Index: i, After: after[i].Digest})
+		case i >= len(after):
+			res = append(res, LayerDiff{Kind: DiffRemoved, Index: i, Before: before[i].Digest})
+		case before[i].Digest != after[i].Digest:
+			res = append(res, LayerDiff{Kind: DiffChanged, Index: i, Before: before[i].Digest, After: after[i].Digest})
+		}
+	}
+	return res
+}
+
+func diffConfigs(before, after ocispec.Image) []ConfigDiff {
+	var res []ConfigDiff
+	res = append(res, diffMaps("Env", envMap(before.Config.Env), envMap(after.Config.Env))...)
+	res = append(res, diffValues("Entrypoint", before.Config.Entrypoint, after.Config.Entrypoint)...)
+	res = append(res, diffValues("Cmd", before.Config.Cmd, after.Config.Cmd)...)
+	res = append(res, diffValues("WorkingDir", before.Config.WorkingDir, after.Config.WorkingDir)...)
+	res = append(res, diffValues("User", before.Config.User, after.Config.User)...)
+	res = append(res, diffValues("StopSignal", before.Config.StopSignal, after.Config.StopSignal)...)
+	res = append(res, diffMaps("Labels", before.Config.Labels, after.Config.Labels)...)
+	res = append(res, diffMaps("ExposedPorts", setMap(before.Config.ExposedPorts), setMap(after.Config.ExposedPorts))...)
+	res = append(res, diffMaps("Volumes", setMap(before.Config.Volumes), setMap(after.Config.Volumes))...)
+	return res
+}
+
+// diffValues compares values that are replaced as a whole, such as Entrypoint.
+func diffValues[T any](field string, before, after T) []ConfigDiff {
+	b, a := formatValue(before), formatValue(after)
+	if b == a {
+		return nil
+	}
+	return []ConfigDiff{{Kind: DiffChanged, Field: field, Before: b, After: a}}
+}
+
+func formatValue(v any) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case []string:
+		if len(v) == 0 {
+			return ""
+		}
+	}
+	b, _ := json.Marshal(v)
+	return string(b)
+}
+
+// diffMaps compares the entries of fields such as Labels, sorted by key.
+func diffMaps(field string, before, after map[string]string) []ConfigDiff {
+	var keys []string
+	for k := range before {
+		keys = append(keys, k)
+	}
+	for k := range after {
+		if _, ok := before[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	slices.Sort(keys)
+
+	var res []ConfigDiff
+	for _, k := range keys {
+		b, inBefore := before[k]
+		a, inAfter := after[k]
+		switch {
+		case !inBefore:
+			res = append(res, ConfigDiff{Kind: DiffAdded, Field: field, Key: k, After: a})
+		case !inAfter:
+			res = append(res, ConfigDiff{Kind: DiffRemoved, Field: field, Key: k, Before: b})
+		case a != b:
+			res = append(res, ConfigDiff{Kind: DiffChanged, Field: field, Key: k, Before: b, After: a})
+		}
+	}
+	return res
+}
+
+func envMap(env []string) map[string]string {
+	m := make(map[string]string, len(env))
+	for _, e := range env {
+		k, v, _ := strings.Cut(e, "=")
+		m[k] = v
+	}
+	return m
+}
+
+func setMap(set map[string]struct{}) map[string]string {
+	m := make(map[string]string, len(set))
+	for k := range set {
+		m[k] = ""
+	}
+	return m
+}
+
+// diffFiles compares the unpacked root filesystems of the images.
+func diffFiles(ctx context.Context, client *containerd.Client, imgs [2]containerd.Image, configs [2]ocispec.Image, platMC platforms.MatchComparer, gOptions types.GlobalCommandOptions) ([]FileDiff, error) {
+	// Don't gc me and clean the dirty data after 1 hour!
+	ctx, done, err := client.WithLease(ctx, leases.WithRandomID(), leases.WithExpiration(1*time.Hour))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create lease for diff: %w", err)
+	}
+	defer done(ctx)
+
+	snapshotter := gOptions.Snapshotter
+	sn := client.SnapshotService(snapshotter)
+	var mounts [2][]mount.Mount
+	for i, img := range imgs {
+		unpacked, err := img.IsUnpacked(ctx, snapshotter)
+		if err != nil {
+			return nil, err
+		}
+		if !unpacked {
+			// The layers of a lazily pulled image may be missing from the content store.
+			if err := EnsureAllContent(ctx, client, img.Name(), platMC, gOptions); err != nil {
+				return nil, err
+			}
+			if err := img.Unpack(ctx, snapshotter); err != nil {
+				return nil, fmt.Errorf("failed to unpack %q: %w", img.Name(), err)
+			}
+		}
+		key := idgen.GenerateID()
+		mounts[i], err = sn.View(ctx, key, identity.ChainID(configs[i].RootFS.DiffIDs).String())
+		if err != nil {
+			return nil, err
+		}
+		defer sn.Remove(ctx, key)
+	}
+
+	var changes []FileDiff
+	err = mount.WithReadonlyTempMount(ctx, mounts[0], func(lower string) error {
+		return mount.WithReadonlyTempMount(ctx, mounts[1], func(upper string) error {
+			return fs.Changes(ctx, lower, upper, func(ck fs.ChangeKind, p string, _ os.FileInfo, err error) error {
+				if err != nil {
+					return err
+				}
+				var kind string
+				switch ck {
+				case fs.ChangeKindAdd:
+					kind = DiffAdded
+				case fs.ChangeKindDelete:
+					kind = DiffRemoved
+				case fs.ChangeKindModify:
+					kind = DiffChanged
+				default:
+					return nil
+				}
+				changes = append(changes, FileDiff{Kind: kind, Path: p})
+				return nil
+			})
+		})
+	})
+	return changes, err
+}
+
+var diffKindLetters = map[string]string{
+	DiffAdded:   "A",
+	DiffRemoved: "D",
+	DiffChanged: "C",
+}
+
+func printImageDiff(w io.Writer, diff ImageDiff) {
+	if len(diff.Layers) > 0 {
+		fmt.Fprintln(w, "LAYERS")
+		for _, l := range diff.Layers {
+			fmt.Fprintf(w, "%s %d %s\n", diffKindLetters[l.Kind], l.Index, formatChange(l.Kind, l.Before.String(), l.After.String()))
+		}
+	}
+	if len(diff.Config) > 0 {
+		fmt.Fprintln(w, "CONFIG")
+		for _, c := range diff.Config {
+			name := c.Field
+			if c.Key != "" {
+				name += " " + c.Key
+			}
+			if change := formatChange(c.Kind, c.Before, c.After); change != "" {
+				name += ": " + change
+			}
+			fmt.Fprintln(w, diffKindLetters[c.Kind], name)
+		}
+	}
+	if len(diff.Files) > 0 {
+		fmt.Fprintln(w, "FILES")
+		for _, f := range diff.Files {
+			fmt.Fprintln(w, diffKindLetters[f.Kind], f.Path)
+		}
+	}
+}
+
+func formatChange(kind, before, after string) string {
+	switch kind {
+	case DiffAdded:
+		return after
+	case DiffRemoved:
+		return before
+	default:
+		return before + " -> " + after
+	}
+}
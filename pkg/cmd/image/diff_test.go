@@ -0,0 +1,91 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package image
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"gotest.tools/v3/assert"
+)
+
+func TestDiffLayers(t *testing.T) {
+	desc := func(s string) ocispec.Descriptor {
+		return ocispec.Descriptor{Digest: digest.FromString(s)}
+	}
+	before := []ocispec.Descriptor{desc("base"), desc("app-v1"), desc("extra")}
+	after := []ocispec.Descriptor{desc("base"), desc("app-v2")}
+
+	assert.DeepEqual(t, diffLayers(before, after), []LayerDiff{
+		{Kind: DiffChanged, Index: 1, Before: digest.FromString("app-v1"), After: digest.FromString("app-v2")},
+		{Kind: DiffRemoved, Index: 2, Before: digest.FromString("extra")},
+	})
+	assert.DeepEqual(t, diffLayers(after, before), []LayerDiff{
+		{Kind: DiffChanged, Index: 1, Before: digest.FromString("app-v2"), After: digest.FromString("app-v1")},
+		{Kind: DiffAdded, Index: 2, After: digest.FromString("extra")},
+	})
+	assert.Assert(t, len(diffLayers(before, before)) == 0)
+}
+
+func TestDiffConfigs(t *testing.T) {
+	before := ocispec.Image{Config: ocispec.ImageConfig{
+		Env:          []string{"PATH=/usr/bin", "FOO=1"},
+		Entrypoint:   []string{"/app"},
+		Labels:       map[string]string{"version": "1", "removed": "x"},
+		ExposedPorts: map[string]struct{}{"80/tcp": {}},
+	}}
+	after := ocispec.Image{Config: ocispec.ImageConfig{
+		Env:          []string{"PATH=/usr/bin", "FOO=2", "BAR=3"},
+		Entrypoint:   []string{"/app", "--verbose"},
+		Labels:       map[string]string{"version": "2"},
+		ExposedPorts: map[string]struct{}{"80/tcp": {}, "443/tcp": {}},
+		WorkingDir:   "/srv",
+	}}
+
+	assert.DeepEqual(t, diffConfigs(before, after), []ConfigDiff{
+		{Kind: DiffAdded, Field: "Env", Key: "BAR", After: "3"},
+		{Kind: DiffChanged, Field: "Env", Key: "FOO", Before: "1", After: "2"},
+		{Kind: DiffChanged, Field: "Entrypoint", Before: `["/app"]`, After: `["/app","--verbose"]`},
+		{Kind: DiffChanged, Field: "WorkingDir", After: "/srv"},
+		{Kind: DiffRemoved, Field: "Labels", Key: "removed", Before: "x"},
+		{Kind: DiffChanged, Field: "Labels", Key: "version", Before: "1", After: "2"},
+		{Kind: DiffAdded, Field: "ExposedPorts", Key: "443/tcp"},
+	})
+	assert.Assert(t, len(diffConfigs(before, before)) == 0)
+}
+
+func TestPrintImageDiff(t *testing.T) {
+	var buf bytes.Buffer
+	printImageDiff(&buf, ImageDiff{
+		Layers: []LayerDiff{{Kind: DiffAdded, Index: 2, After: "sha256:aaa"}},
+		Config: []ConfigDiff{
+			{Kind: DiffChanged, Field: "Env", Key: "FOO", Before: "1", After: "2"},
+			{Kind: DiffAdded, Field: "ExposedPorts", Key: "443/tcp"},
+		},
+		Files: []FileDiff{{Kind: DiffRemoved, Path: "/etc/foo"}},
+	})
+	assert.Equal(t, buf.String(), `LAYERS
+A 2 sha256:aaa
+CONFIG
+C Env FOO: 1 -> 2
+A ExposedPorts 443/tcp
+FILES
+D /etc/foo
+`)
+}
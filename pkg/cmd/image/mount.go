@@ -0,0 +1,291 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package image
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/opencontainers/image-spec/identity"
+
+	containerd "github.com/containerd/containerd/v2/client"
+	"github.com/containerd/containerd/v2/core/images"
+	"github.com/containerd/containerd/v2/core/leases"
+	"github.com/containerd/containerd/v2/core/mount"
+	"github.com/containerd/containerd/v2/core/snapshots"
+	"github.com/containerd/errdefs"
+	"github.com/containerd/log"
+
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/clientutil"
+	"github.com/containerd/nerdctl/v2/pkg/idutil/imagewalker"
+	"github.com/containerd/nerdctl/v2/pkg/labels"
+	"github.com/containerd/nerdctl/v2/pkg/platformutil"
+	"github.com/containerd/nerdctl/v2/pkg/rootlessutil"
+)
+
+const (
+	// imageMountKeyPrefix is the prefix of the view snapshot keys (and of the lease IDs) of mounted images.
+	imageMountKeyPrefix = "nerdctl-image-mount-"
+	// imageMountPointLabel is the snapshot label holding the path the image is mounted on.
+	imageMountPointLabel = labels.Prefix + "image-mount.mountpoint"
+	// imageMountDigestLabel is the snapshot label holding the digest of the mounted image.
+	imageMountDigestLabel = labels.Prefix + "image-mount.digest"
+	// imageMountDefaultTargetLabel is set when the mount point was created by nerdctl under the data root.
+	imageMountDefaultTargetLabel = labels.Prefix + "image-mount.default-target"
+)
+
+// Mount mounts the unpacked rootfs of an image read-only, and prints the mount point.
+// The view snapshot is protected from the garbage collection by a lease until Unmount is called.
+func Mount(ctx context.Context, client *containerd.Client, rawRef string, options types.ImageMountOptions) error {
+	if rootlessutil.IsRootless() {
+		return errors.New("image mount is not supported in rootless mode")
+	}
+
+	img, err := resolveImage(ctx, client, rawRef)
+	if err != nil {
+		return err
+	}
+
+	var platforms []string
+	if options.Platform != "" {
+		platforms = []string{options.Platform}
+	}
+	platMC, err := platformutil.NewMatchComparer(false, platforms)
+	if err != nil {
+		return err
+	}
+	image := containerd.NewImageWithPlatform(client, img, platMC)
+
+	snapshotter := options.GOptions.Snapshotter
+	unpacked, err := image.IsUnpacked(ctx, snapshotter)
+	if err != nil {
+		return err
+	}
+	if !unpacked {
+		if err := EnsureAllContent(ctx, client, img.Name, platMC, options.GOptions); err != nil {
+			return err
+		}
+		if err := image.Unpack(ctx, snapshotter); err != nil {
+			return err
+		}
+	}
+
+	diffIDs, err := image.RootFS(ctx)
+	if err != nil {
+		return err
+	}
+	chainID := identity.ChainID(diffIDs)
+	key := imageMountKeyPrefix + chainID.Encoded()
+
+	sn := client.SnapshotService(snapshotter)
+	if info, err := sn.Stat(ctx, key); err == nil {
+		// Already mounted, possibly by another name of the same image.
+		mountPoint := info.Labels[imageMountPointLabel]
+		if options.Target != "" && !samePath(options.Target, mountPoint) {
+			return fmt.Errorf("image %q is already mounted on %q", rawRef, mountPoint)
+		}
+		if !isMountPoint(mountPoint) {
+			// The mount was lost, e.g., on reboot.
+			if err := mountSnapshot(ctx, sn, key, mountPoint); err != nil {
+				return err
+			}
+		}
+		fmt.Fprintln(options.Stdout, mountPoint)
+		return nil
+	} else if !errdefs.IsNotFound(err) {
+		return err
+	}
+
+	mountPoint := options.Target
+	defaultTarget := mountPoint == ""
+	if defaultTarget {
+		dataStore, err := clientutil.DataStore(options.GOptions.DataRoot, options.GOptions.Address)
+		if err != nil {
+			return err
+		}
+		mountPoint = filepath.Join(dataStore, "image-mounts", options.GOptions.Namespace, chainID.Encoded())
+	}
+	mountPoint, err = filepath.Abs(mountPoint)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(mountPoint, 0o755); err != nil {
+		return err
+	}
+
+	ls := client.LeasesService()
+	lease, err := ls.Create(ctx, leases.WithID(key))
+	if err != nil {
+		return fmt.Errorf("failed to create lease for image mount: %w", err)
+	}
+	snLabels := map[string]string{
+		imageMountPointLabel:  mountPoint,
+		imageMountDigestLabel: img.Target.Digest.String(),
+	}
+	if defaultTarget {
+		snLabels[imageMountDefaultTargetLabel] = "true"
+	}
+	if _, err = sn.View(leases.WithLease(ctx, lease.ID), key, chainID.String(), snapshots.WithLabels(snLabels)); err == nil {
+		err = mountSnapshot(ctx, sn, key, mountPoint)
+	}
+	if err != nil {
+		if rmErr := sn.Remove(ctx, key); rmErr != nil && !errdefs.IsNotFound(rmErr) {
+			log.G(ctx).WithError(rmErr).Warnf("failed to remove snapshot %q", key)
+		}
+		if delErr := ls.Delete(ctx, lease); delErr != nil {
+			log.G(ctx).WithError(delErr).Warnf("failed to delete lease %q", lease.ID)
+		}
+		if defaultTarget {
+			os.Remove(mountPoint)
+		}
+		return err
+	}
+
+	fmt.Fprintln(options.Stdout, mountPoint)
+	return nil
+}
+
+// Unmount unmounts images mounted by Mount, and releases their view snapshots.
+// Each request is either an image reference or a mount point.
+func Unmount(ctx context.Context, client *containerd.Client, reqs []string, options types.ImageUnmountOptions) error {
+	sn := client.SnapshotService(options.GOptions.Snapshotter)
+	mounted := make(map[string]snapshots.Info)
+	if err := sn.Walk(ctx, func(_ context.Context, info snapshots.Info) error {
+		mounted[info.Name] = info
+		return nil
+	}, fmt.Sprintf("labels.%q", imageMountPointLabel)); err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, req := range reqs {
+		var keys []string
+		for key, info := range mounted {
+			if samePath(req, info.Labels[imageMountPointLabel]) {
+				keys = append(keys, key)
+			}
+		}
+		if len(keys) == 0 {
+			img, err := resolveImage(ctx, client, req)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			for key, info := range mounted {
+				if info.Labels[imageMountDigestLabel] == img.Target.Digest.String() {
+					keys = append(keys, key)
+				}
+			}
+			if len(keys) == 0 {
+				errs = append(errs, fmt.Errorf("image %q is not mounted", req))
+				continue
+			}
+		}
+		var failed bool
+		for _, key := range keys {
+			if err := unmountSnapshot(ctx, client, sn, mounted[key]); err != nil {
+				errs = append(errs, err)
+				failed = true
+				continue
+			}
+			delete(mounted, key)
+		}
+		if !failed {
+			fmt.Fprintln(options.Stdout, req)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func mountSnapshot(ctx context.Context, sn snapshots.Snapshotter, key, mountPoint string) error {
+	mounts, err := sn.Mounts(ctx, key)
+	if err != nil {
+		return err
+	}
+	if err := mount.All(mounts, mountPoint); err != nil {
+		return fmt.Errorf("failed to mount image on %q: %w", mountPoint, err)
+	}
+	return nil
+}
+
+func unmountSnapshot(ctx context.Context, client *containerd.Client, sn snapshots.Snapshotter, info snapshots.Info) error {
+	mountPoint := info.Labels[imageMountPointLabel]
+	if isMountPoint(mountPoint) {
+		if err := mount.UnmountAll(mountPoint, 0); err != nil {
+			return fmt.Errorf("failed to unmount %q: %w", mountPoint, err)
+		}
+	}
+	if err := sn.Remove(ctx, info.Name); err != nil && !errdefs.IsNotFound(err) {
+		return err
+	}
+	if err := client.LeasesService().Delete(ctx, leases.Lease{ID: info.Name}); err != nil && !errdefs.IsNotFound(err) {
+		return err
+	}
+	if info.Labels[imageMountDefaultTargetLabel] == "true" {
+		if err := os.Remove(mountPoint); err != nil && !os.IsNotExist(err) {
+			log.G(ctx).WithError(err).Warnf("failed to remove mount point %q", mountPoint)
+		}
+	}
+	return nil
+}
+
+// resolveImage returns the single image matching the name or the (short) ID.
+func resolveImage(ctx context.Context, client *containerd.Client, req string) (images.Image, error) {
+	var found []images.Image
+	walker := &imagewalker.ImageWalker{
+		Client: client,
+		OnFound: func(ctx context.Context, f imagewalker.Found) error {
+			if f.NameMatchIndex != -1 {
+				if f.NameMatchIndex == f.MatchIndex {
+					found = []images.Image{f.Image}
+				}
+				return nil
+			}
+			if f.UniqueImages > 1 {
+				return fmt.Errorf("multiple IDs found with provided prefix: %s", f.Req)
+			}
+			if len(found) == 0 {
+				found = append(found, f.Image)
+			}
+			return nil
+		},
+	}
+	if _, err := walker.Walk(ctx, req); err != nil {
+		return images.Image{}, err
+	}
+	if len(found) == 0 {
+		return images.Image{}, fmt.Errorf("no such image: %s", req)
+	}
+	return found[0], nil
+}
+
+func isMountPoint(path string) bool {
+	info, err := mount.Lookup(path)
+	return err == nil && info.Mountpoint == filepath.Clean(path)
+}
+
+func samePath(a, b string) bool {
+	absA, err := filepath.Abs(a)
+	if err != nil {
+		return false
+	}
+	return absA == filepath.Clean(b)
+}
@@ -29,20 +29,54 @@ import (
 	"github.com/containerd/containerd/v2/core/transfer"
 	tarchive "github.com/containerd/containerd/v2/core/transfer/archive"
 	transferimage "github.com/containerd/containerd/v2/core/transfer/image"
+	"github.com/containerd/containerd/v2/pkg/archive/compression"
 	"github.com/containerd/platforms"
 
 	"github.com/containerd/nerdctl/v2/pkg/api/types"
 	"github.com/containerd/nerdctl/v2/pkg/platformutil"
 	"github.com/containerd/nerdctl/v2/pkg/strutil"
+	"github.com/containerd/nerdctl/v2/pkg/tarutil"
 	"github.com/containerd/nerdctl/v2/pkg/transferutil"
 )
 
-// Save exports `images` to a `io.Writer` (e.g., a file writer, or os.Stdout) specified by `options.Stdout`.
+const (
+	// SaveFormatDockerArchive is an archive implementing both Docker Image Spec v1.2 and OCI Image Spec v1.0.
+	SaveFormatDockerArchive = "docker-archive"
+	// SaveFormatOCIArchive is a tar archive of an OCI image layout, without the Docker compatibility manifest.
+	SaveFormatOCIArchive = "oci-archive"
+	// SaveFormatOCIDir is an OCI image layout written to a directory.
+	SaveFormatOCIDir = "oci-dir"
+)
+
+// Save exports `images` to a `io.Writer` (e.g., a file writer, or os.Stdout) specified by `options.Stdout`,
+// or to the directory specified by `options.Output` for the oci-dir format.
 func Save(ctx context.Context, client *containerd.Client, images []string, options types.ImageSaveOptions) error {
 	images = strutil.DedupeStrSlice(images)
 
 	var exportOpts []tarchive.ExportOpt
 
+	switch options.Format {
+	case "", SaveFormatDockerArchive:
+	case SaveFormatOCIArchive, SaveFormatOCIDir:
+		exportOpts = append(exportOpts, tarchive.WithSkipCompatibilityManifest)
+	default:
+		return fmt.Errorf("unsupported format %q, supported formats: %s, %s, %s",
+			options.Format, SaveFormatDockerArchive, SaveFormatOCIArchive, SaveFormatOCIDir)
+	}
+
+	comp, err := parseCompression(options.Compression)
+	if err != nil {
+		return err
+	}
+	if options.Format == SaveFormatOCIDir {
+		if comp != compression.Uncompressed {
+			return fmt.Errorf("compression cannot be used with format %q", SaveFormatOCIDir)
+		}
+		if options.Output == "" {
+			return fmt.Errorf("format %q requires an output directory", SaveFormatOCIDir)
+		}
+	}
+
 	if len(options.Platform) > 0 {
 		for _, ps := range options.Platform {
 			p, err := platforms.Parse(ps)
@@ -101,8 +135,6 @@ func Save(ctx context.Context, client *containerd.Client, images []string, optio
 		storeOpts = append(storeOpts, transferimage.WithExtraReference(imageRef))
 	}
 
-	w := nopWriteCloser{options.Stdout}
-
 	progressOutput := io.Writer(os.Stderr)
 	if options.Quiet {
 		progressOutput = io.Discard
@@ -110,11 +142,63 @@ func Save(ctx context.Context, client *containerd.Client, images []string, optio
 	pf, done := transferutil.ProgressHandler(ctx, progressOutput)
 	defer done()
 
-	return client.Transfer(ctx,
-		transferimage.NewStore("", storeOpts...),
-		tarchive.NewImageExportStream(w, "", exportOpts...),
-		transfer.WithProgress(pf),
-	)
+	export := func(w io.Writer) error {
+		return client.Transfer(ctx,
+			transferimage.NewStore("", storeOpts...),
+			tarchive.NewImageExportStream(nopWriteCloser{w}, "", exportOpts...),
+			transfer.WithProgress(pf),
+		)
+	}
+
+	if options.Format == SaveFormatOCIDir {
+		return exportToDir(export, options.Output)
+	}
+
+	w, err := compression.CompressStream(options.Stdout, comp)
+	if err != nil {
+		return err
+	}
+	if err := export(w); err != nil {
+		w.Close()
+		return err
+	}
+	// Close flushes the compressed stream; it does not close options.Stdout.
+	return w.Close()
+}
+
+// exportToDir runs export and extracts the resulting archive into dir.
+func exportToDir(export func(io.Writer) error, dir string) error {
+	pr, pw := io.Pipe()
+	extracted := make(chan error, 1)
+	go func() {
+		err := tarutil.ExtractDir(pr, dir)
+		if err == nil {
+			// Consume any trailing padding after the end-of-archive marker.
+			_, err = io.Copy(io.Discard, pr)
+		}
+		// Unblock the exporter if the extraction failed midway.
+		pr.CloseWithError(err)
+		extracted <- err
+	}()
+	err := export(pw)
+	pw.CloseWithError(err)
+	if extractErr := <-extracted; err == nil {
+		err = extractErr
+	}
+	return err
+}
+
+func parseCompression(s string) (compression.Compression, error) {
+	switch s {
+	case "", "none":
+		return compression.Uncompressed, nil
+	case "gzip":
+		return compression.Gzip, nil
+	case "zstd":
+		return compression.Zstd, nil
+	default:
+		return compression.Unknown, fmt.Errorf("unsupported compression %q, supported compressions: none, gzip, zstd", s)
+	}
 }
 
 type nopWriteCloser struct {
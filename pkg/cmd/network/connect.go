@@ -0,0 +1,396 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package network
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+
+	containerd "github.com/containerd/containerd/v2/client"
+	"github.com/containerd/containerd/v2/pkg/oci"
+	"github.com/containerd/errdefs"
+	"github.com/containerd/log"
+
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/clientutil"
+	"github.com/containerd/nerdctl/v2/pkg/dnsutil/hostsstore"
+	"github.com/containerd/nerdctl/v2/pkg/eventutil"
+	"github.com/containerd/nerdctl/v2/pkg/idutil/containerwalker"
+	"github.com/containerd/nerdctl/v2/pkg/internal/filesystem"
+	"github.com/containerd/nerdctl/v2/pkg/labels"
+	"github.com/containerd/nerdctl/v2/pkg/netutil"
+	"github.com/containerd/nerdctl/v2/pkg/netutil/nettype"
+	"github.com/containerd/nerdctl/v2/pkg/ocihook"
+	"github.com/containerd/nerdctl/v2/pkg/ocihook/state"
+	"github.com/containerd/nerdctl/v2/pkg/portutil"
+	"github.com/containerd/nerdctl/v2/pkg/rootlessutil"
+)
+
+// Connect connects a container to a network.
+// If the container is running, the network is attached right away, otherwise on the next start.
+func Connect(ctx context.Context, client *containerd.Client, options types.NetworkConnectOptions) error {
+	if options.IPAddress != "" && net.ParseIP(options.IPAddress).To4() == nil {
+		return fmt.Errorf("invalid IPv4 address: %q", options.IPAddress)
+	}
+	if ip6 := net.ParseIP(options.IP6Address); options.IP6Address != "" && (ip6 == nil || ip6.To4() != nil) {
+		return fmt.Errorf("invalid IPv6 address: %q", options.IP6Address)
+	}
+	if options.MACAddress != "" {
+		if _, err := net.ParseMAC(options.MACAddress); err != nil {
+			return err
+		}
+	}
+	cniEnv, err := netutil.NewCNIEnv(options.GOptions.CNIPath, options.GOptions.CNINetConfPath, netutil.WithNamespace(options.GOptions.Namespace), netutil.WithFirewallBackend(options.GOptions.FirewallBackend), netutil.WithDefaultNetwork(options.GOptions.BridgeIP))
+	if err != nil {
+		return err
+	}
+	netw, err := cniEnv.NetworkByNameOrID(options.Network)
+	if err != nil {
+		return err
+	}
+	return walkContainer(ctx, client, options.Container, func(ctx context.Context, container containerd.Container) error {
+		cn, err := loadContainerNetworks(ctx, container)
+		if err != nil {
+			return err
+		}
+		if _, ok := cn.find(cniEnv, netw.Name); ok {
+			return fmt.Errorf("container %s is already connected to network %s", options.Container, netw.Name)
+		}
+		ep := netutil.EndpointSettings{
+			IfName:     netutil.FreeInterfaceName(cn.endpoints),
+			IPAddress:  options.IPAddress,
+			IP6Address: options.IP6Address,
+			MACAddress: options.MACAddress,
+			Aliases:    options.Aliases,
+		}
+		cn.networks = append(cn.networks, netw.Name)
+		cn.endpoints[netw.Name] = ep
+
+		if nsPath, err := cn.netNSPath(ctx, container); err != nil {
+			return err
+		} else if nsPath != "" {
+			cnetw, err := cn.network(options.GOptions, netw.Name)
+			if err != nil {
+				return err
+			}
+			if err := attachRunning(ctx, container, cn, options.GOptions, cnetw, ep, nsPath); err != nil {
+				return err
+			}
+		}
+		if err := cn.save(ctx, container); err != nil {
+			return err
+		}
+		eventutil.Publish(ctx, client.EventService(), eventutil.TopicNetworkConnect, netw.Name, map[string]string{"container": container.ID()})
+		return nil
+	})
+}
+
+// Disconnect disconnects a container from a network.
+// If the container is running, the network is detached right away.
+func Disconnect(ctx context.Context, client *containerd.Client, options types.NetworkDisconnectOptions) error {
+	cniEnv, err := netutil.NewCNIEnv(options.GOptions.CNIPath, options.GOptions.CNINetConfPath, netutil.WithNamespace(options.GOptions.Namespace), netutil.WithFirewallBackend(options.GOptions.FirewallBackend), netutil.WithDefaultNetwork(options.GOptions.BridgeIP))
+	if err != nil {
+		return err
+	}
+	netw, err := cniEnv.NetworkByNameOrID(options.Network)
+	if err != nil && !options.Force {
+		return err
+	}
+	netName := options.Network
+	if netw != nil {
+		netName = netw.Name
+	}
+	return walkContainer(ctx, client, options.Container, func(ctx context.Context, container containerd.Container) error {
+		cn, err := loadContainerNetworks(ctx, container)
+		if err != nil {
+			return err
+		}
+		idx, ok := cn.find(cniEnv, netName)
+		if !ok {
+			return fmt.Errorf("container %s is not connected to network %s", options.Container, options.Network)
+		}
+		netstr := cn.networks[idx]
+		ep := cn.endpoints[netstr]
+		cn.networks = slices.Delete(cn.networks, idx, idx+1)
+		delete(cn.endpoints, netstr)
+
+		if nsPath, err := cn.netNSPath(ctx, container); err != nil {
+			return err
+		} else if nsPath != "" {
+			var cnetw *netutil.NetworkConfig
+			if netw != nil {
+				if cnetw, err = cn.network(options.GOptions, netw.Name); err != nil && !options.Force {
+					return err
+				}
+			}
+			if err := detachRunning(ctx, container, cn, options.GOptions, cnetw, netstr, ep, nsPath, options.Force); err != nil {
+				return err
+			}
+		}
+		if err := cn.save(ctx, container); err != nil {
+			return err
+		}
+		eventutil.Publish(ctx, client.EventService(), eventutil.TopicNetworkDisconnect, netName, map[string]string{"container": container.ID()})
+		return nil
+	})
+}
+
+func walkContainer(ctx context.Context, client *containerd.Client, req string, fn func(context.Context, containerd.Container) error) error {
+	walker := &containerwalker.ContainerWalker{
+		Client: client,
+		OnFound: func(ctx context.Context, found containerwalker.Found) error {
+			if found.MatchCount > 1 {
+				return fmt.Errorf("multiple IDs found with provided prefix: %s", found.Req)
+			}
+			return fn(ctx, found.Container)
+		},
+	}
+	n, err := walker.Walk(ctx, req)
+	if err != nil {
+		return err
+	} else if n == 0 {
+		return fmt.Errorf("no such container %s", req)
+	}
+	return nil
+}
+
+// containerNetworks is the network configuration of a container, as stored in its labels and spec annotations.
+type containerNetworks struct {
+	labels    map[string]string
+	spec      *oci.Spec
+	networks  []string
+	endpoints map[string]netutil.EndpointSettings
+}
+
+func loadContainerNetworks(ctx context.Context, container containerd.Container) (*containerNetworks, error) {
+	lab, err := container.Labels(ctx)
+	if err != nil {
+		return nil, err
+	}
+	spec, err := container.Spec(ctx)
+	if err != nil {
+		return nil, err
+	}
+	cn := &containerNetworks{
+		labels: lab,
+		spec:   spec,
+	}
+	if err := json.Unmarshal([]byte(lab[labels.Networks]), &cn.networks); err != nil {
+		return nil, fmt.Errorf("failed to parse networks of container %s: %w", container.ID(), err)
+	}
+	netType, err := nettype.Detect(cn.networks)
+	if err != nil {
+		return nil, err
+	}
+	if netType != nettype.CNI {
+		return nil, fmt.Errorf("container %s uses network mode %q, which does not support connecting networks", container.ID(), cn.networks[0])
+	}
+	if cn.endpoints, err = netutil.ParseEndpoints(lab[labels.NetworkEndpoints]); err != nil {
+		return nil, err
+	}
+	// Once the set of networks changes, go-cni can no longer derive the interface names from
+	// the network positions, so pin the names the container was started with.
+	netutil.PinInterfaceNames(cn.networks, cn.endpoints, lab[labels.IPAddress], lab[labels.IP6Address], lab[labels.MACAddress])
+	return cn, nil
+}
+
+// find returns the index of the network with the given name, resolving the names and IDs stored in the labels.
+func (cn *containerNetworks) find(cniEnv *netutil.CNIEnv, name string) (int, bool) {
+	for i, netstr := range cn.networks {
+		if netstr == name {
+			return i, true
+		}
+		if netw, err := cniEnv.NetworkByNameOrID(netstr); err == nil && netw.Name == name {
+			return i, true
+		}
+	}
+	return -1, false
+}
+
+// network returns the configuration of a network for the container, converted for the firewall backend
+// that the networks of the container are set up with.
+func (cn *containerNetworks) network(globalOptions types.GlobalCommandOptions, name string) (*netutil.NetworkConfig, error) {
+	firewallBackend := netutil.ContainerFirewallBackend(cn.labels, globalOptions.FirewallBackend)
+	cniEnv, err := netutil.NewCNIEnv(globalOptions.CNIPath, globalOptions.CNINetConfPath, netutil.WithNamespace(globalOptions.Namespace), netutil.WithFirewallBackend(firewallBackend), netutil.WithDefaultNetwork(globalOptions.BridgeIP))
+	if err != nil {
+		return nil, err
+	}
+	return cniEnv.NetworkByNameOrID(name)
+}
+
+// netNSPath returns the network namespace of the container, or an empty string if the container is not running.
+func (cn *containerNetworks) netNSPath(ctx context.Context, container containerd.Container) (string, error) {
+	task, err := container.Task(ctx, nil)
+	if err != nil {
+		if errdefs.IsNotFound(err) {
+			return "", nil
+		}
+		return "", err
+	}
+	status, err := task.Status(ctx)
+	if err != nil {
+		return "", err
+	}
+	if status.Status != containerd.Running && status.Status != containerd.Paused {
+		return "", nil
+	}
+	if runtime.GOOS != "linux" {
+		return "", fmt.Errorf("connecting networks to a running container is not supported on %s", runtime.GOOS)
+	}
+	if nsPath, ok := cn.spec.Annotations[ocihook.NetworkNamespace]; ok {
+		return nsPath, nil
+	}
+	return fmt.Sprintf("/proc/%d/ns/net", task.Pid()), nil
+}
+
+func (cn *containerNetworks) marshal() (map[string]string, error) {
+	networksJSON, err := json.Marshal(cn.networks)
+	if err != nil {
+		return nil, err
+	}
+	endpointsJSON, err := json.Marshal(cn.endpoints)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]string{
+		labels.Networks:         string(networksJSON),
+		labels.NetworkEndpoints: string(endpointsJSON),
+	}, nil
+}
+
+// save stores the networks in the container labels and in the spec annotations read by the OCI hooks on the next start.
+func (cn *containerNetworks) save(ctx context.Context, container containerd.Container) error {
+	m, err := cn.marshal()
+	if err != nil {
+		return err
+	}
+	return container.Update(ctx,
+		containerd.UpdateContainerOpts(containerd.WithAdditionalContainerLabels(m)),
+		containerd.UpdateContainerOpts(containerd.WithSpec(cn.spec, oci.WithAnnotations(m))),
+	)
+}
+
+// saveRuntime stores the networks in the container state, so that the postStop hook of the running task
+// detaches the networks that are actually attached.
+func (cn *containerNetworks) saveRuntime() error {
+	m, err := cn.marshal()
+	if err != nil {
+		return err
+	}
+	lf, err := state.New(cn.labels[labels.StateDir])
+	if err != nil {
+		return err
+	}
+	return lf.Transform(func(lf *state.Store) error {
+		lf.Networks = m[labels.Networks]
+		lf.NetworkEndpoints = m[labels.NetworkEndpoints]
+		return nil
+	})
+}
+
+func attachRunning(ctx context.Context, container containerd.Container, cn *containerNetworks, globalOptions types.GlobalCommandOptions, netw *netutil.NetworkConfig, ep netutil.EndpointSettings, nsPath string) error {
+	dataStore, err := clientutil.DataStore(globalOptions.DataRoot, globalOptions.Address)
+	if err != nil {
+		return err
+	}
+	hs, err := hostsstore.New(dataStore, globalOptions.Namespace)
+	if err != nil {
+		return err
+	}
+	bandwidth, err := netutil.ParseBandwidthLabel(cn.labels[labels.Bandwidth])
+	if err != nil {
+		return err
+	}
+	// Pass the published ports, so that the portmap plugin publishes them on this network too,
+	// as the OCI hook does for the networks of the container when it starts
+	ports, err := portutil.LoadPortMappings(dataStore, globalOptions.Namespace, container.ID(), cn.labels)
+	if err != nil {
+		return err
+	}
+	fullID := globalOptions.Namespace + "-" + container.ID()
+	return withCNILock(globalOptions.CNINetConfPath, func() error {
+		if err := netw.SetupNFTablesIsolation(); err != nil {
+			return fmt.Errorf("failed to isolate network %q: %w", netw.Name, err)
+		}
+		res, err := netutil.AttachNetwork(ctx, globalOptions.CNIPath, netw, fullID, nsPath, ep, ports, bandwidth, map[string]string{
+			"NERDCTL_CNI_DHCP_HOSTNAME": cn.labels[labels.Hostname],
+		})
+		if err != nil {
+			return err
+		}
+		if err = hs.ConnectNetwork(container.ID(), netw.Name, res, ep.Aliases); err == nil {
+			err = cn.saveRuntime()
+		}
+		if err != nil {
+			_ = hs.DisconnectNetwork(container.ID(), netw.Name)
+			_ = netutil.DetachNetwork(ctx, globalOptions.CNIPath, netw, fullID, nsPath, ep, ports)
+		}
+		return err
+	})
+}
+
+func detachRunning(ctx context.Context, container containerd.Container, cn *containerNetworks, globalOptions types.GlobalCommandOptions, netw *netutil.NetworkConfig, netstr string, ep netutil.EndpointSettings, nsPath string, force bool) error {
+	dataStore, err := clientutil.DataStore(globalOptions.DataRoot, globalOptions.Address)
+	if err != nil {
+		return err
+	}
+	hs, err := hostsstore.New(dataStore, globalOptions.Namespace)
+	if err != nil {
+		return err
+	}
+	fullID := globalOptions.Namespace + "-" + container.ID()
+	err = withCNILock(globalOptions.CNINetConfPath, func() error {
+		if netw == nil {
+			return fmt.Errorf("network %s not found", netstr)
+		}
+		// Pass the published ports, so that the portmap plugin removes its rules
+		ports, err := portutil.LoadPortMappings(dataStore, globalOptions.Namespace, container.ID(), cn.labels)
+		if err != nil {
+			return err
+		}
+		return netutil.DetachNetwork(ctx, globalOptions.CNIPath, netw, fullID, nsPath, ep, ports)
+	})
+	if err != nil {
+		if !force {
+			return err
+		}
+		log.G(ctx).WithError(err).Warnf("failed to detach network %s, ignoring as --force is set", netstr)
+	}
+	if err := hs.DisconnectNetwork(container.ID(), netstr); err != nil {
+		log.G(ctx).WithError(err).Warnf("failed to remove network %s from the hosts entries of container %s", netstr, container.ID())
+	}
+	return cn.saveRuntime()
+}
+
+// withCNILock runs fn holding the lock taken by the OCI hooks around CNI operations.
+func withCNILock(cniNetconfPath string, fn func() error) error {
+	if err := os.MkdirAll(cniNetconfPath, 0o700); err != nil {
+		return err
+	}
+	lock, err := filesystem.Lock(filepath.Join(cniNetconfPath, ".cni-concurrency.lock"))
+	if err != nil {
+		return err
+	}
+	defer filesystem.Unlock(lock)
+	return rootlessutil.WithDetachedNetNSIfAny(fn)
+}
@@ -17,17 +17,22 @@
 package network
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"sort"
 
+	containerd "github.com/containerd/containerd/v2/client"
 	"github.com/containerd/errdefs"
 
 	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/eventutil"
 	"github.com/containerd/nerdctl/v2/pkg/netutil"
 )
 
-func Create(options types.NetworkCreateOptions, stdout io.Writer) error {
+// Create creates a network, and publishes its create event to client.
+// Networks do not need containerd, so client may be nil when it is not reachable, and then no event is published.
+func Create(ctx context.Context, client *containerd.Client, options types.NetworkCreateOptions, stdout io.Writer) error {
 	// A nil IPv4 defaults to enabled.
 	ipv4 := options.IPv4 == nil || *options.IPv4
 	// At least one address family must be enabled, matching docker which
@@ -75,6 +80,9 @@ func Create(options types.NetworkCreateOptions, stdout io.Writer) error {
 		}
 		return err
 	}
+	if client != nil {
+		eventutil.Publish(ctx, client.EventService(), eventutil.TopicNetworkCreate, options.Name, nil)
+	}
 	_, err = fmt.Fprintln(stdout, *net.NerdctlID)
 	return err
 }
@@ -17,6 +17,7 @@
 package network
 
 import (
+	"context"
 	"io"
 	"testing"
 
@@ -28,7 +29,7 @@ import (
 // TestCreateAuxAddressWithoutSubnet verifies that an aux-address given without
 // any subnet is rejected the same way Docker rejects it, before any CNI setup.
 func TestCreateAuxAddressWithoutSubnet(t *testing.T) {
-	err := Create(types.NetworkCreateOptions{
+	err := Create(context.Background(), nil, types.NetworkCreateOptions{
 		AuxAddresses: []string{"host=10.9.0.5"},
 	}, io.Discard)
 	assert.ErrorContains(t, err, "no matching subnet for aux-address 10.9.0.5")
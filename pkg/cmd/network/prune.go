@@ -24,12 +24,13 @@ import (
 	"github.com/containerd/log"
 
 	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/eventutil"
 	"github.com/containerd/nerdctl/v2/pkg/netutil"
 	"github.com/containerd/nerdctl/v2/pkg/strutil"
 )
 
 func Prune(ctx context.Context, client *containerd.Client, options types.NetworkPruneOptions) error {
-	e, err := netutil.NewCNIEnv(options.GOptions.CNIPath, options.GOptions.CNINetConfPath, netutil.WithNamespace(options.GOptions.Namespace))
+	e, err := netutil.NewCNIEnv(options.GOptions.CNIPath, options.GOptions.CNINetConfPath, netutil.WithNamespace(options.GOptions.Namespace), netutil.WithFirewallBackend(options.GOptions.FirewallBackend))
 	if err != nil {
 		return err
 	}
@@ -60,6 +61,7 @@ func Prune(ctx context.Context, client *containerd.Client, options types.Network
 			continue
 		}
 		removedNetworks = append(removedNetworks, net.Name)
+		eventutil.Publish(ctx, client.EventService(), eventutil.TopicNetworkDelete, net.Name, nil)
 	}
 
 	if len(removedNetworks) > 0 {
@@ -25,11 +25,12 @@ import (
 	"github.com/containerd/log"
 
 	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/eventutil"
 	"github.com/containerd/nerdctl/v2/pkg/netutil"
 )
 
 func Remove(ctx context.Context, client *containerd.Client, options types.NetworkRemoveOptions) error {
-	cniEnv, err := netutil.NewCNIEnv(options.GOptions.CNIPath, options.GOptions.CNINetConfPath, netutil.WithNamespace(options.GOptions.Namespace))
+	cniEnv, err := netutil.NewCNIEnv(options.GOptions.CNIPath, options.GOptions.CNINetConfPath, netutil.WithNamespace(options.GOptions.Namespace), netutil.WithFirewallBackend(options.GOptions.FirewallBackend))
 	if err != nil {
 		return err
 	}
@@ -72,6 +73,7 @@ func Remove(ctx context.Context, client *containerd.Client, options types.Networ
 			errs = append(errs, err)
 		} else {
 			result = append(result, req)
+			eventutil.Publish(ctx, client.EventService(), eventutil.TopicNetworkDelete, network.Name, nil)
 		}
 	}
 	for _, unErr := range errs {
@@ -0,0 +1,569 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package system
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+
+	"github.com/docker/go-units"
+	"github.com/opencontainers/go-digest"
+	"github.com/opencontainers/image-spec/identity"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+
+	containerd "github.com/containerd/containerd/v2/client"
+	"github.com/containerd/containerd/v2/core/content"
+	"github.com/containerd/containerd/v2/core/images"
+	"github.com/containerd/containerd/v2/core/snapshots"
+	"github.com/containerd/errdefs"
+	"github.com/containerd/log"
+	"github.com/containerd/platforms"
+
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/buildkitutil"
+	"github.com/containerd/nerdctl/v2/pkg/cmd/builder"
+	"github.com/containerd/nerdctl/v2/pkg/cmd/volume"
+	"github.com/containerd/nerdctl/v2/pkg/containerdutil"
+	"github.com/containerd/nerdctl/v2/pkg/containerutil"
+	"github.com/containerd/nerdctl/v2/pkg/formatter"
+	"github.com/containerd/nerdctl/v2/pkg/imgutil"
+	"github.com/containerd/nerdctl/v2/pkg/inspecttypes/dockercompat"
+	"github.com/containerd/nerdctl/v2/pkg/labels"
+	"github.com/containerd/nerdctl/v2/pkg/mountutil"
+)
+
+// diskUsageSummary is a row of `nerdctl system df`.
+type diskUsageSummary struct {
+	Type        string
+	TotalCount  string
+	Active      string
+	Size        string
+	Reclaimable string
+}
+
+// diskUsageVerbose is the result of `nerdctl system df -v`.
+type diskUsageVerbose struct {
+	Images     []imageUsagePrintable
+	Containers []containerUsagePrintable
+	Volumes    []volumeUsagePrintable
+	BuildCache []buildCacheUsagePrintable
+}
+
+type imageUsagePrintable struct {
+	Repository   string
+	Tag          string
+	ID           string
+	CreatedSince string
+	Size         string
+	SharedSize   string
+	UniqueSize   string
+	Containers   string
+}
+
+type containerUsagePrintable struct {
+	ID           string
+	Image        string
+	Command      string
+	LocalVolumes string
+	Size         string
+	RunningFor   string
+	Status       string
+	Names        string
+}
+
+type volumeUsagePrintable struct {
+	Name  string
+	Links string
+	Size  string
+}
+
+type buildCacheUsagePrintable struct {
+	ID            string
+	CacheType     string
+	Size          string
+	CreatedSince  string
+	LastUsedSince string
+	UsageCount    string
+	Shared        string
+}
+
+// usageTotals holds the totals of a kind of object.
+type usageTotals struct {
+	count       int
+	active      int
+	size        int64
+	reclaimable int64
+}
+
+// DiskUsage shows the disk space used by images, containers, local volumes and build cache.
+func DiskUsage(ctx context.Context, client *containerd.Client, options types.SystemDfOptions) error {
+	containersVerbose, containersTotals, imagesInUse, err := containersDiskUsage(ctx, client)
+	if err != nil {
+		return err
+	}
+	imagesVerbose, imagesTotals, err := imagesDiskUsage(ctx, client, options.GOptions.Snapshotter, imagesInUse)
+	if err != nil {
+		return err
+	}
+	volumesVerbose, volumesTotals, err := volumesDiskUsage(ctx, client, options.GOptions)
+	if err != nil {
+		return err
+	}
+	buildCacheVerbose, buildCacheTotals := buildCacheDiskUsage(ctx, options)
+
+	if options.Verbose {
+		return printDiskUsageVerbose(options, diskUsageVerbose{
+			Images:     imagesVerbose,
+			Containers: containersVerbose,
+			Volumes:    volumesVerbose,
+			BuildCache: buildCacheVerbose,
+		}, buildCacheTotals)
+	}
+	return printDiskUsageSummary(options, []diskUsageSummary{
+		newDiskUsageSummary("Images", imagesTotals, true),
+		newDiskUsageSummary("Containers", containersTotals, true),
+		newDiskUsageSummary("Local Volumes", volumesTotals, true),
+		newDiskUsageSummary("Build Cache", buildCacheTotals, false),
+	})
+}
+
+func newDiskUsageSummary(typ string, totals usageTotals, percentage bool) diskUsageSummary {
+	reclaimable := units.HumanSize(float64(totals.reclaimable))
+	if percentage && totals.size > 0 {
+		reclaimable = fmt.Sprintf("%s (%d%%)", reclaimable, totals.reclaimable*100/totals.size)
+	}
+	return diskUsageSummary{
+		Type:        typ,
+		TotalCount:  strconv.Itoa(totals.count),
+		Active:      strconv.Itoa(totals.active),
+		Size:        units.HumanSize(float64(totals.size)),
+		Reclaimable: reclaimable,
+	}
+}
+
+// containersDiskUsage returns the size of the writable snapshot of each container.
+// Containers that are not running are reclaimable.
+// It also returns the number of containers using each image, keyed by image name.
+func containersDiskUsage(ctx context.Context, client *containerd.Client) ([]containerUsagePrintable, usageTotals, map[string]int, error) {
+	var (
+		res         []containerUsagePrintable
+		totals      usageTotals
+		imagesInUse = make(map[string]int)
+		snapshotter = make(map[string]snapshots.Snapshotter)
+	)
+	containers, err := client.Containers(ctx)
+	if err != nil {
+		return nil, totals, nil, err
+	}
+	for _, c := range containers {
+		info, err := c.Info(ctx, containerd.WithoutRefreshedMetadata)
+		if err != nil {
+			if errdefs.IsNotFound(err) {
+				log.G(ctx).Debugf("container %q is gone - ignoring", c.ID())
+				continue
+			}
+			return nil, totals, nil, err
+		}
+		spec, err := c.Spec(ctx)
+		if err != nil {
+			if errdefs.IsNotFound(err) {
+				log.G(ctx).Debugf("container %q is gone - ignoring", c.ID())
+				continue
+			}
+			return nil, totals, nil, err
+		}
+		var size int64
+		if info.SnapshotKey != "" {
+			sn, ok := snapshotter[info.Snapshotter]
+			if !ok {
+				sn = containerdutil.SnapshotService(client, info.Snapshotter)
+				snapshotter[info.Snapshotter] = sn
+			}
+			usage, err := sn.Usage(ctx, info.SnapshotKey)
+			if err != nil {
+				log.G(ctx).WithError(err).Warnf("failed to get the size of container %q", c.ID())
+			}
+			size = usage.Size
+		}
+		status := formatter.ContainerStatus(ctx, c)
+
+		totals.count++
+		totals.size += size
+		if strings.HasPrefix(status, "Up") {
+			totals.active++
+		} else {
+			totals.reclaimable += size
+		}
+		if info.Image != "" {
+			imagesInUse[info.Image]++
+		}
+		id := c.ID()
+		if len(id) > 12 {
+			id = id[:12]
+		}
+		res = append(res, containerUsagePrintable{
+			ID:           id,
+			Image:        info.Image,
+			Command:      formatter.InspectContainerCommand(spec, true, true),
+			LocalVolumes: strconv.Itoa(len(containerVolumes(info.Labels))),
+			Size:         units.HumanSize(float64(size)),
+			RunningFor:   formatter.TimeSinceInHuman(info.CreatedAt),
+			Status:       status,
+			Names:        containerutil.GetContainerName(info.Labels),
+		})
+	}
+	return res, totals, imagesInUse, nil
+}
+
+// imagesDiskUsage returns the size of each image, i.e., the size of its blobs in the content store
+// plus the size of its unpacked snapshots.
+// Blobs and snapshots shared by several images are only counted once in the totals.
+// Blobs and snapshots that are not used by any image of a container are reclaimable.
+func imagesDiskUsage(ctx context.Context, client *containerd.Client, snapshotterName string, imagesInUse map[string]int) ([]imageUsagePrintable, usageTotals, error) {
+	var totals usageTotals
+	imageList, err := client.ImageService().List(ctx)
+	if err != nil {
+		return nil, totals, err
+	}
+	var (
+		cs         = client.ContentStore()
+		sn         = containerdutil.SnapshotService(client, snapshotterName)
+		sizes      = make(map[string]int64)                   // blob or snapshot -> size
+		refs       = make(map[string]int)                     // blob or snapshot -> number of images using it
+		resources  = make(map[digest.Digest]map[string]int64) // image target -> blobs and snapshots
+		containers = make(map[digest.Digest]int)              // image target -> number of containers
+		active     = make(map[string]struct{})                // blobs and snapshots used by containers
+	)
+	for _, img := range imageList {
+		containers[img.Target.Digest] += imagesInUse[img.Name]
+		if _, ok := resources[img.Target.Digest]; ok {
+			continue
+		}
+		r, err := imageResources(ctx, client, cs, sn, img)
+		if err != nil {
+			log.G(ctx).WithError(err).Warnf("failed to get the size of image %q", img.Name)
+		}
+		resources[img.Target.Digest] = r
+		for k, size := range r {
+			sizes[k] = size
+			refs[k]++
+		}
+	}
+	for target, r := range resources {
+		totals.count++
+		if containers[target] > 0 {
+			totals.active++
+			for k := range r {
+				active[k] = struct{}{}
+			}
+		}
+	}
+	for k, size := range sizes {
+		totals.size += size
+		if _, ok := active[k]; !ok {
+			totals.reclaimable += size
+		}
+	}
+
+	res := make([]imageUsagePrintable, 0, len(imageList))
+	for _, img := range imageList {
+		var size, shared int64
+		for k, s := range resources[img.Target.Digest] {
+			size += s
+			if refs[k] > 1 {
+				shared += s
+			}
+		}
+		var repository, tag string
+		// cri plugin will create an image named digest of image's config, skip parsing.
+		if img.Target.Digest.String() != img.Name {
+			repository, tag = imgutil.ParseRepoTag(img.Name)
+		}
+		if repository == "" {
+			repository = "<none>"
+		}
+		if tag == "" {
+			tag = "<none>"
+		}
+		res = append(res, imageUsagePrintable{
+			Repository:   repository,
+			Tag:          tag,
+			ID:           img.Target.Digest.Encoded()[:12],
+			CreatedSince: formatter.TimeSinceInHuman(img.CreatedAt),
+			Size:         units.HumanSize(float64(size)),
+			SharedSize:   units.HumanSize(float64(shared)),
+			UniqueSize:   units.HumanSize(float64(size - shared)),
+			Containers:   strconv.Itoa(containers[img.Target.Digest]),
+		})
+	}
+	return res, totals, nil
+}
+
+// imageResources returns the blobs and the snapshots of the image for the default platform, with their size.
+// Blobs are keyed by their digest, snapshots by their chain ID prefixed with "snapshot:".
+func imageResources(ctx context.Context, client *containerd.Client, cs content.Store, sn snapshots.Snapshotter, img images.Image) (map[string]int64, error) {
+	res := make(map[string]int64)
+	platform := platforms.Default()
+	handler := images.HandlerFunc(func(ctx context.Context, desc ocispec.Descriptor) ([]ocispec.Descriptor, error) {
+		info, err := cs.Info(ctx, desc.Digest)
+		if err != nil {
+			if errdefs.IsNotFound(err) {
+				// e.g., layers of a lazy-pulled image
+				return nil, nil
+			}
+			return nil, err
+		}
+		res[desc.Digest.String()] = info.Size
+		return images.Children(ctx, cs, desc)
+	})
+	if err := images.Walk(ctx, images.LimitManifests(images.FilterPlatforms(handler, platform), platform, 1), img.Target); err != nil {
+		return res, err
+	}
+
+	diffIDs, err := containerd.NewImage(client, img).RootFS(ctx)
+	if err != nil {
+		return res, err
+	}
+	for _, chainID := range identity.ChainIDs(diffIDs) {
+		usage, err := sn.Usage(ctx, chainID.String())
+		if err != nil {
+			if errdefs.IsNotFound(err) {
+				// not unpacked
+				break
+			}
+			return res, err
+		}
+		res["snapshot:"+chainID.String()] = usage.Size
+	}
+	return res, nil
+}
+
+// volumesDiskUsage returns the size of each volume.
+// Volumes that are not used by any container are reclaimable.
+func volumesDiskUsage(ctx context.Context, client *containerd.Client, globalOptions types.GlobalCommandOptions) ([]volumeUsagePrintable, usageTotals, error) {
+	var totals usageTotals
+	vols, err := volume.Volumes(globalOptions.Namespace, globalOptions.DataRoot, globalOptions.Address, true, nil)
+	if err != nil {
+		return nil, totals, err
+	}
+	containers, err := client.Containers(ctx)
+	if err != nil {
+		return nil, totals, err
+	}
+	links := make(map[string]int)
+	for _, c := range containers {
+		l, err := c.Labels(ctx)
+		if err != nil {
+			if errdefs.IsNotFound(err) {
+				log.G(ctx).Debugf("container %q is gone - ignoring", c.ID())
+				continue
+			}
+			return nil, totals, err
+		}
+		for _, name := range containerVolumes(l) {
+			links[name]++
+		}
+	}
+	res := make([]volumeUsagePrintable, 0, len(vols))
+	for _, v := range vols {
+		totals.count++
+		totals.size += v.Size
+		if links[v.Name] > 0 {
+			totals.active++
+		} else {
+			totals.reclaimable += v.Size
+		}
+		res = append(res, volumeUsagePrintable{
+			Name:  v.Name,
+			Links: strconv.Itoa(links[v.Name]),
+			Size:  units.HumanSize(float64(v.Size)),
+		})
+	}
+	return res, totals, nil
+}
+
+// containerVolumes returns the names of the volumes mounted in the container.
+func containerVolumes(containerLabels map[string]string) []string {
+	mountsJSON := labels.GetMount(containerLabels)
+	if mountsJSON == "" {
+		return nil
+	}
+	var mounts []dockercompat.MountPoint
+	if err := json.Unmarshal([]byte(mountsJSON), &mounts); err != nil {
+		log.L.Warn(err)
+		return nil
+	}
+	var names []string
+	for _, m := range mounts {
+		if m.Type == mountutil.Volume {
+			names = append(names, m.Name)
+		}
+	}
+	return names
+}
+
+// buildCacheDiskUsage returns the build cache records of BuildKit.
+// Build cache that is neither in use nor shared is reclaimable.
+func buildCacheDiskUsage(ctx context.Context, options types.SystemDfOptions) ([]buildCacheUsagePrintable, usageTotals) {
+	var totals usageTotals
+	if options.BuildKitHost == "" {
+		return nil, totals
+	}
+	records, err := builder.DiskUsage(ctx, options.BuildKitHost, options.Stderr)
+	if err != nil {
+		log.G(ctx).WithError(err).Warn("failed to get the build cache usage")
+		return nil, totals
+	}
+	res := make([]buildCacheUsagePrintable, 0, len(records))
+	for _, r := range records {
+		totals.count++
+		if r.InUse {
+			totals.active++
+		}
+		if !r.Shared {
+			totals.size += r.Size
+			if !r.InUse {
+				totals.reclaimable += r.Size
+			}
+		}
+		res = append(res, newBuildCacheUsagePrintable(r))
+	}
+	return res, totals
+}
+
+func newBuildCacheUsagePrintable(r buildkitutil.UsageInfo) buildCacheUsagePrintable {
+	id := r.ID
+	if len(id) > 12 {
+		id = id[:12]
+	}
+	if r.Mutable {
+		id += "*"
+	}
+	lastUsed := ""
+	if r.LastUsedAt != nil {
+		lastUsed = formatter.TimeSinceInHuman(*r.LastUsedAt)
+	}
+	return buildCacheUsagePrintable{
+		ID:            id,
+		CacheType:     string(r.RecordType),
+		Size:          units.HumanSize(float64(r.Size)),
+		CreatedSince:  formatter.TimeSinceInHuman(r.CreatedAt),
+		LastUsedSince: lastUsed,
+		UsageCount:    strconv.Itoa(r.UsageCount),
+		Shared:        strconv.FormatBool(r.Shared),
+	}
+}
+
+func printDiskUsageSummary(options types.SystemDfOptions, rows []diskUsageSummary) error {
+	w := options.Stdout
+	var tmpl *template.Template
+	switch options.Format {
+	case "", "table":
+		w = tabwriter.NewWriter(w, 4, 8, 4, ' ', 0)
+		fmt.Fprintln(w, "TYPE\tTOTAL\tACTIVE\tSIZE\tRECLAIMABLE")
+	case "raw":
+		return errors.New("unsupported format: \"raw\"")
+	default:
+		var err error
+		tmpl, err = formatter.ParseTemplate(options.Format)
+		if err != nil {
+			return err
+		}
+	}
+	for _, r := range rows {
+		if tmpl != nil {
+			if err := executeTemplate(w, tmpl, r); err != nil {
+				return err
+			}
+			continue
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", r.Type, r.TotalCount, r.Active, r.Size, r.Reclaimable)
+	}
+	if f, ok := w.(formatter.Flusher); ok {
+		return f.Flush()
+	}
+	return nil
+}
+
+func printDiskUsageVerbose(options types.SystemDfOptions, du diskUsageVerbose, buildCacheTotals usageTotals) error {
+	switch options.Format {
+	case "", "table":
+	case "raw":
+		return errors.New("unsupported format: \"raw\"")
+	default:
+		tmpl, err := formatter.ParseTemplate(options.Format)
+		if err != nil {
+			return err
+		}
+		return executeTemplate(options.Stdout, tmpl, du)
+	}
+
+	fmt.Fprint(options.Stdout, "Images space usage:\n\n")
+	w := tabwriter.NewWriter(options.Stdout, 4, 8, 4, ' ', 0)
+	fmt.Fprintln(w, "REPOSITORY\tTAG\tIMAGE ID\tCREATED\tSIZE\tSHARED SIZE\tUNIQUE SIZE\tCONTAINERS")
+	for _, x := range du.Images {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n", x.Repository, x.Tag, x.ID, x.CreatedSince, x.Size, x.SharedSize, x.UniqueSize, x.Containers)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Fprint(options.Stdout, "\nContainers space usage:\n\n")
+	w = tabwriter.NewWriter(options.Stdout, 4, 8, 4, ' ', 0)
+	fmt.Fprintln(w, "CONTAINER ID\tIMAGE\tCOMMAND\tLOCAL VOLUMES\tSIZE\tCREATED\tSTATUS\tNAMES")
+	for _, x := range du.Containers {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n", x.ID, x.Image, x.Command, x.LocalVolumes, x.Size, x.RunningFor, x.Status, x.Names)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Fprint(options.Stdout, "\nLocal Volumes space usage:\n\n")
+	w = tabwriter.NewWriter(options.Stdout, 4, 8, 4, ' ', 0)
+	fmt.Fprintln(w, "VOLUME NAME\tLINKS\tSIZE")
+	for _, x := range du.Volumes {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", x.Name, x.Links, x.Size)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(options.Stdout, "\nBuild cache usage: %s\n\n", units.HumanSize(float64(buildCacheTotals.size)))
+	w = tabwriter.NewWriter(options.Stdout, 4, 8, 4, ' ', 0)
+	fmt.Fprintln(w, "CACHE ID\tCACHE TYPE\tSIZE\tCREATED\tLAST USED\tUSAGE\tSHARED")
+	for _, x := range du.BuildCache {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", x.ID, x.CacheType, x.Size, x.CreatedSince, x.LastUsedSince, x.UsageCount, x.Shared)
+	}
+	return w.Flush()
+}
+
+func executeTemplate(w io.Writer, tmpl *template.Template, x any) error {
+	var b bytes.Buffer
+	if err := tmpl.Execute(&b, x); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintln(w, b.String())
+	return err
+}
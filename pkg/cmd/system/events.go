@@ -22,18 +22,25 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"maps"
+	"strconv"
 	"strings"
 	"text/template"
 	"time"
 
-	_ "github.com/containerd/containerd/api/events" // Register grpc event types
+	eventstypes "github.com/containerd/containerd/api/events" // Register grpc event types
 	containerd "github.com/containerd/containerd/v2/client"
+	"github.com/containerd/containerd/v2/core/containers"
 	"github.com/containerd/containerd/v2/core/events"
 	"github.com/containerd/log"
 	"github.com/containerd/typeurl/v2"
 
 	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/eventutil"
 	"github.com/containerd/nerdctl/v2/pkg/formatter"
+	"github.com/containerd/nerdctl/v2/pkg/healthcheck"
+	"github.com/containerd/nerdctl/v2/pkg/labels"
+	"github.com/containerd/nerdctl/v2/pkg/timestamp"
 )
 
 // EventOut contains information about an event.
@@ -42,22 +49,90 @@ type EventOut struct {
 	ID        string
 	Namespace string
 	Topic     string
-	Action    Action
-	Event     string
-	Labels    map[string]string
+	// Type is the type of the object of the event, e.g., "container", "image", "volume" or "network"
+	Type   string
+	Action Action
+	Actor  Actor
+	Event  string
+	Labels map[string]string
+}
+
+// Actor describes the object of an event, like Docker's events.Actor.
+type Actor struct {
+	// ID is the ID of a container, or the name of an image, a volume or a network
+	ID         string
+	Attributes map[string]string
 }
 
 type Action string
 
 const (
-	START   Action = "start"
-	UNKNOWN Action = "unknown"
+	CREATE      Action = "create"
+	START       Action = "start"
+	DIE         Action = "die"
+	STOP        Action = "stop"
+	KILL        Action = "kill"
+	PAUSE       Action = "pause"
+	UNPAUSE     Action = "unpause"
+	DESTROY     Action = "destroy"
+	UPDATE      Action = "update"
+	RENAME      Action = "rename"
+	OOM         Action = "oom"
+	EXEC_CREATE Action = "exec_create"
+	EXEC_START  Action = "exec_start"
+	EXEC_DIE    Action = "exec_die"
+	// HEALTH_STATUS is followed by the new status, e.g., "health_status: healthy"
+	HEALTH_STATUS Action = "health_status"
+	// HEALTH_ON_FAILURE is emitted when the --health-on-failure action is taken on an unhealthy container
+	HEALTH_ON_FAILURE Action = "health_on_failure"
+	PULL              Action = "pull"
+	TAG               Action = "tag"
+	DELETE            Action = "delete"
+	CONNECT           Action = "connect"
+	DISCONNECT        Action = "disconnect"
+	UNKNOWN           Action = "unknown"
 )
 
-var actions = [...]Action{START, UNKNOWN}
+var actions = [...]Action{
+	CREATE, START, DIE, STOP, KILL, PAUSE, UNPAUSE, DESTROY, UPDATE, RENAME, OOM,
+	EXEC_CREATE, EXEC_START, EXEC_DIE, HEALTH_STATUS, HEALTH_ON_FAILURE, PULL, TAG, DELETE, CONNECT, DISCONNECT, UNKNOWN,
+}
+
+// topicActions maps the topics of containerd and nerdctl to their default action.
+// Some actions are refined from the event payload, see eventTranslator.
+var topicActions = map[string]Action{
+	"/containers/create":                    CREATE,
+	"/containers/update":                    UPDATE,
+	"/containers/delete":                    DESTROY,
+	"/tasks/start":                          START,
+	"/tasks/exit":                           DIE,
+	"/tasks/oom":                            OOM,
+	"/tasks/exec-added":                     EXEC_CREATE,
+	"/tasks/exec-started":                   EXEC_START,
+	"/tasks/paused":                         PAUSE,
+	"/tasks/resumed":                        UNPAUSE,
+	"/images/create":                        PULL,
+	"/images/update":                        PULL,
+	"/images/delete":                        DELETE,
+	eventutil.TopicContainerKill:            KILL,
+	eventutil.TopicContainerStop:            STOP,
+	eventutil.TopicContainerHealthOnFailure: HEALTH_ON_FAILURE,
+	eventutil.TopicVolumeCreate:             CREATE,
+	eventutil.TopicVolumeDelete:             DESTROY,
+	eventutil.TopicNetworkCreate:            CREATE,
+	eventutil.TopicNetworkDelete:            DESTROY,
+	eventutil.TopicNetworkConnect:           CONNECT,
+	eventutil.TopicNetworkDisconnect:        DISCONNECT,
+}
+
+// actionBase strips the status of an action like "health_status: healthy".
+func actionBase(action string) string {
+	base, _, _ := strings.Cut(action, ":")
+	return base
+}
 
 func isAction(action string) bool {
-	action = strings.ToLower(action)
+	action = strings.ToLower(actionBase(action))
 
 	for _, supportedAction := range actions {
 		if string(supportedAction) == action {
@@ -69,13 +144,30 @@ func isAction(action string) bool {
 }
 
 func TopicToAction(topic string) Action {
-	if strings.Contains(strings.ToLower(topic), string(START)) {
-		return START
+	if action, ok := topicActions[topic]; ok {
+		return action
 	}
 
 	return UNKNOWN
 }
 
+// topicToType returns the type of the object of the events of the topic,
+// e.g., "container" for "/tasks/start" or "volume" for "/nerdctl/volumes/create".
+func topicToType(topic string) string {
+	component, _, _ := strings.Cut(strings.TrimPrefix(strings.TrimPrefix(topic, "/nerdctl"), "/"), "/")
+	switch component {
+	case "containers", "tasks":
+		return "container"
+	case "images":
+		return "image"
+	case "volumes":
+		return "volume"
+	case "networks":
+		return "network"
+	}
+	return component
+}
+
 // EventFilter for filtering events
 type EventFilter func(*EventOut) bool
 
@@ -89,7 +181,34 @@ func generateEventFilter(filter, filterValue string) (func(e *EventOut) bool, er
 				return false
 			}
 
-			return strings.EqualFold(string(e.Action), filterValue)
+			return strings.EqualFold(actionBase(string(e.Action)), actionBase(filterValue))
+		}, nil
+	case "TYPE":
+		return func(e *EventOut) bool {
+			return e.Type == filterValue
+		}, nil
+	case "CONTAINER":
+		return func(e *EventOut) bool {
+			if e.Type != "container" {
+				return false
+			}
+			return e.Actor.ID == filterValue || e.Actor.Attributes["name"] == filterValue ||
+				(len(filterValue) >= 3 && strings.HasPrefix(e.Actor.ID, filterValue))
+		}, nil
+	case "IMAGE":
+		return func(e *EventOut) bool {
+			switch e.Type {
+			case "image":
+				return e.Actor.ID == filterValue
+			case "container":
+				return e.Actor.Attributes["image"] == filterValue
+			}
+			return false
+		}, nil
+	case "VOLUME", "NETWORK":
+		typ := strings.ToLower(filter)
+		return func(e *EventOut) bool {
+			return e.Type == typ && e.Actor.ID == filterValue
 		}, nil
 	case "LABEL":
 		parts := strings.SplitN(filterValue, "=", 2)
@@ -172,8 +291,6 @@ func generateEventFilters(filters []string) (map[string][]EventFilter, error) {
 
 // Events is from https://github.com/containerd/containerd/blob/v1.4.3/cmd/ctr/commands/events/events.go
 func Events(ctx context.Context, client *containerd.Client, options types.SystemEventsOptions) error {
-	eventsClient := client.EventService()
-	eventsCh, errCh := eventsClient.Subscribe(ctx)
 	var tmpl *template.Template
 	switch options.Format {
 	case "":
@@ -187,30 +304,47 @@ func Events(ctx context.Context, client *containerd.Client, options types.System
 			return err
 		}
 	}
-	labelFilterEnabled := false
-	for _, f := range options.Filters {
-		if strings.HasPrefix(strings.ToLower(f), "label=") {
-			labelFilterEnabled = true
-			break
-		}
-	}
 	filterMap, err := generateEventFilters(options.Filters)
 	if err != nil {
 		return err
 	}
+	now := time.Now()
+	since, err := parseEventTime(options.Since, now)
+	if err != nil {
+		return fmt.Errorf("invalid value for \"since\": %w", err)
+	}
+	if !since.IsZero() && since.Before(now) {
+		log.G(ctx).Warnf("past events are not replayed: only the events received from now on are shown")
+	}
+	until, err := parseEventTime(options.Until, now)
+	if err != nil {
+		return fmt.Errorf("invalid value for \"until\": %w", err)
+	}
+	var untilCh <-chan time.Time
+	if !until.IsZero() {
+		untilCh = time.After(time.Until(until))
+	}
+
+	eventsClient := client.EventService()
+	eventsCh, errCh := eventsClient.Subscribe(ctx)
+	translator, err := newEventTranslator(ctx, client)
+	if err != nil {
+		return err
+	}
 	for {
 		var e *events.Envelope
 		select {
 		case e = <-eventsCh:
 		case err := <-errCh:
 			return err
+		case <-untilCh:
+			return nil
 		}
 		if e != nil {
 			var out []byte
-			var id string
-			labels := map[string]string{}
+			var v any
 			if e.Event != nil {
-				v, err := typeurl.UnmarshalAny(e.Event)
+				v, err = typeurl.UnmarshalAny(e.Event)
 				if err != nil {
 					log.G(ctx).WithError(err).Warn("cannot unmarshal an event from Any")
 					continue
@@ -221,26 +355,10 @@ func Events(ctx context.Context, client *containerd.Client, options types.System
 					continue
 				}
 			}
-			var data map[string]interface{}
-			err := json.Unmarshal(out, &data)
-			if err != nil {
-				log.G(ctx).WithError(err).Warn("cannot marshal Any into JSON")
-			} else {
-				_, ok := data["container_id"]
-				if ok {
-					if containerID, ok := data["container_id"].(string); ok {
-						id = containerID
-					}
-				}
+			eOut := translator.translate(ctx, e, v, out)
+			if eOut.Timestamp.Before(since) || (!until.IsZero() && eOut.Timestamp.After(until)) {
+				continue
 			}
-			if labelFilterEnabled && id != "" {
-				if container, err := client.ContainerService().Get(ctx, id); err != nil {
-					log.G(ctx).WithError(err).WithField("containerID", id).Debug("failed to retrieve container labels")
-				} else {
-					labels = container.Labels
-				}
-			}
-			eOut := EventOut{e.Timestamp, id, e.Namespace, e.Topic, TopicToAction(e.Topic), string(out), labels}
 			match := applyFilters(&eOut, filterMap)
 			if match {
 				if tmpl != nil {
@@ -267,3 +385,182 @@ func Events(ctx context.Context, client *containerd.Client, options types.System
 		}
 	}
 }
+
+// parseEventTime parses the value of --since or --until.
+// The zero time is returned for an empty value.
+func parseEventTime(value string, now time.Time) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	ts, err := timestamp.GetTimestamp(value, now)
+	if err != nil {
+		return time.Time{}, err
+	}
+	sec, nsec, err := timestamp.ParseTimestamps(ts, 0)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(sec, nsec), nil
+}
+
+// eventTranslator converts containerd and nerdctl events into Docker-style events.
+// It caches the containers, so that the name and the image of a container are still known
+// when it is deleted, and so that renames and health status changes can be told apart from other updates.
+type eventTranslator struct {
+	client     *containerd.Client
+	containers map[string]containers.Container
+}
+
+func newEventTranslator(ctx context.Context, client *containerd.Client) (*eventTranslator, error) {
+	list, err := client.ContainerService().List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	t := &eventTranslator{
+		client:     client,
+		containers: make(map[string]containers.Container, len(list)),
+	}
+	for _, c := range list {
+		t.containers[c.ID] = c
+	}
+	return t, nil
+}
+
+// container returns the container with the given ID, from containerd if it still exists, or from the cache.
+func (t *eventTranslator) container(ctx context.Context, id string) containers.Container {
+	c, err := t.client.ContainerService().Get(ctx, id)
+	if err != nil {
+		log.G(ctx).WithError(err).WithField("containerID", id).Debug("failed to retrieve container")
+		return t.containers[id]
+	}
+	t.containers[id] = c
+	return c
+}
+
+// imagePullOrTag tells whether the image was pulled, or tagged from an existing image.
+// containerd does not distinguish them, so an image is considered tagged when another image has the same target.
+func (t *eventTranslator) imagePullOrTag(ctx context.Context, name string) Action {
+	imageService := t.client.ImageService()
+	img, err := imageService.Get(ctx, name)
+	if err != nil {
+		return PULL
+	}
+	sameTarget, err := imageService.List(ctx, "target.digest=="+img.Target.Digest.String())
+	if err != nil || len(sameTarget) < 2 {
+		return PULL
+	}
+	return TAG
+}
+
+func (t *eventTranslator) translate(ctx context.Context, e *events.Envelope, v any, out []byte) EventOut {
+	eOut := EventOut{
+		Timestamp: e.Timestamp,
+		Namespace: e.Namespace,
+		Topic:     e.Topic,
+		Type:      topicToType(e.Topic),
+		Action:    TopicToAction(e.Topic),
+		Event:     string(out),
+		Labels:    map[string]string{},
+	}
+	attributes := map[string]string{}
+	switch ev := v.(type) {
+	case *eventstypes.ContainerCreate:
+		eOut.ID = ev.ID
+	case *eventstypes.ContainerUpdate:
+		eOut.ID = ev.ID
+		old, ok := t.containers[ev.ID]
+		if ok && old.Labels[labels.Name] != ev.Labels[labels.Name] {
+			eOut.Action = RENAME
+			attributes["oldName"] = old.Labels[labels.Name]
+		} else if status := healthStatus(ev.Labels); ok && status != "" && status != healthStatus(old.Labels) {
+			eOut.Action = Action(fmt.Sprintf("%s: %s", HEALTH_STATUS, status))
+		}
+	case *eventstypes.ContainerDelete:
+		eOut.ID = ev.ID
+	case *eventstypes.TaskStart:
+		eOut.ID = ev.ContainerID
+	case *eventstypes.TaskExit:
+		eOut.ID = ev.ContainerID
+		attributes["exitCode"] = strconv.FormatUint(uint64(ev.ExitStatus), 10)
+		if ev.ID != "" && ev.ID != ev.ContainerID {
+			eOut.Action = EXEC_DIE
+			attributes["execID"] = ev.ID
+		}
+	case *eventstypes.TaskOOM:
+		eOut.ID = ev.ContainerID
+	case *eventstypes.TaskExecAdded:
+		eOut.ID = ev.ContainerID
+		attributes["execID"] = ev.ExecID
+	case *eventstypes.TaskExecStarted:
+		eOut.ID = ev.ContainerID
+		attributes["execID"] = ev.ExecID
+	case *eventstypes.TaskPaused:
+		eOut.ID = ev.ContainerID
+	case *eventstypes.TaskResumed:
+		eOut.ID = ev.ContainerID
+	case *eventstypes.ImageCreate:
+		eOut.Actor.ID = ev.Name
+		eOut.Action = t.imagePullOrTag(ctx, ev.Name)
+	case *eventstypes.ImageUpdate:
+		eOut.Actor.ID = ev.Name
+		eOut.Action = t.imagePullOrTag(ctx, ev.Name)
+	case *eventstypes.ImageDelete:
+		eOut.Actor.ID = ev.Name
+	case *eventutil.Event:
+		if eOut.Type == "container" {
+			eOut.ID = ev.ID
+		} else {
+			eOut.Actor.ID = ev.ID
+		}
+		maps.Copy(attributes, ev.Attributes)
+	default:
+		var data map[string]interface{}
+		if err := json.Unmarshal(out, &data); err == nil {
+			if containerID, ok := data["container_id"].(string); ok {
+				eOut.ID = containerID
+			}
+		}
+	}
+
+	if eOut.Type == "container" && eOut.ID != "" {
+		eOut.Actor.ID = eOut.ID
+		c := t.container(ctx, eOut.ID)
+		if c.Labels != nil {
+			eOut.Labels = c.Labels
+		}
+		for k, v := range c.Labels {
+			// like Docker, the attributes of containers include their labels
+			if !strings.HasPrefix(k, labels.Prefix) {
+				attributes[k] = v
+			}
+		}
+		if name := c.Labels[labels.Name]; name != "" {
+			attributes["name"] = name
+		}
+		if c.Image != "" {
+			attributes["image"] = c.Image
+		}
+		if eOut.Action == DESTROY {
+			delete(t.containers, eOut.ID)
+		}
+	}
+	if eOut.Type == "image" && eOut.Actor.ID != "" {
+		attributes["name"] = eOut.Actor.ID
+	}
+	if eOut.Actor.ID != "" || len(attributes) > 0 {
+		eOut.Actor.Attributes = attributes
+	}
+	return eOut
+}
+
+// healthStatus returns the health status stored in the labels of a container, if any.
+func healthStatus(containerLabels map[string]string) string {
+	if containerLabels[labels.HealthState] == "" {
+		return ""
+	}
+	state, err := healthcheck.HealthStateFromJSON(containerLabels[labels.HealthState])
+	if err != nil {
+		return ""
+	}
+	return state.Status
+}
@@ -17,17 +17,24 @@
 package volume
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/moby/moby/client/pkg/stringid"
 
+	containerd "github.com/containerd/containerd/v2/client"
+	"github.com/containerd/errdefs"
+
 	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/eventutil"
 	"github.com/containerd/nerdctl/v2/pkg/inspecttypes/native"
 	"github.com/containerd/nerdctl/v2/pkg/labels"
 	"github.com/containerd/nerdctl/v2/pkg/strutil"
 )
 
-func Create(name string, options types.VolumeCreateOptions) (*native.Volume, error) {
+// Create creates a volume, and publishes its create event to client.
+// Volumes do not need containerd, so client may be nil when it is not reachable, and then no event is published.
+func Create(ctx context.Context, client *containerd.Client, name string, options types.VolumeCreateOptions) (*native.Volume, error) {
 	if name == "" {
 		name = stringid.GenerateRandomID()
 		options.Labels = append(options.Labels, labels.AnonymousVolumes+"=")
@@ -36,11 +43,21 @@ func Create(name string, options types.VolumeCreateOptions) (*native.Volume, err
 	if err != nil {
 		return nil, err
 	}
+	if options.Driver != "" && options.Driver != "local" {
+		return nil, fmt.Errorf("unsupported volume driver %q, only \"local\" is supported: %w", options.Driver, errdefs.ErrInvalidArgument)
+	}
+	var driverOpts map[string]string
+	if len(options.Options) > 0 {
+		driverOpts = strutil.ConvertKVStringsToMap(options.Options)
+	}
 	labels := strutil.DedupeStrSlice(options.Labels)
-	vol, err := volStore.Create(name, labels)
+	vol, err := volStore.Create(name, labels, driverOpts)
 	if err != nil {
 		return nil, err
 	}
+	if client != nil {
+		eventutil.Publish(ctx, client.EventService(), eventutil.TopicVolumeCreate, vol.Name, nil)
+	}
 	fmt.Fprintln(options.Stdout, name)
 	return vol, nil
 }
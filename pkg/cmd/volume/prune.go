@@ -24,6 +24,7 @@ import (
 	containerd "github.com/containerd/containerd/v2/client"
 
 	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/eventutil"
 	"github.com/containerd/nerdctl/v2/pkg/inspecttypes/native"
 	"github.com/containerd/nerdctl/v2/pkg/labels"
 )
@@ -74,6 +75,9 @@ func Prune(ctx context.Context, client *containerd.Client, options types.VolumeP
 		return err
 	}
 
+	for _, name := range toRemove {
+		eventutil.Publish(ctx, client.EventService(), eventutil.TopicVolumeDelete, name, nil)
+	}
 	if len(toRemove) > 0 {
 		fmt.Fprintln(options.Stdout, "Deleted Volumes:")
 		fmt.Fprintln(options.Stdout, strings.Join(toRemove, "\n"))
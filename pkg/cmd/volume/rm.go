@@ -27,6 +27,7 @@ import (
 	"github.com/containerd/log"
 
 	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/eventutil"
 	"github.com/containerd/nerdctl/v2/pkg/inspecttypes/dockercompat"
 	"github.com/containerd/nerdctl/v2/pkg/labels"
 	"github.com/containerd/nerdctl/v2/pkg/mountutil"
@@ -68,6 +69,7 @@ func Remove(ctx context.Context, client *containerd.Client, volumes []string, op
 	// Otherwise, output on stdout whatever was successful
 	for _, name := range removedNames {
 		fmt.Fprintln(options.Stdout, name)
+		eventutil.Publish(ctx, client.EventService(), eventutil.TopicVolumeDelete, name, nil)
 	}
 	// Log the rest
 	for _, volErr := range cannotRemove {
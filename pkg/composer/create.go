@@ -180,6 +180,12 @@ func (c *Composer) createServiceContainer(ctx context.Context, service *servicep
 		log.G(ctx).Infof("Creating container %s", container.Name)
 	}
 
+	filesArgs, err := c.writeContainerFiles(ctx, container)
+	if err != nil {
+		return "", err
+	}
+	container.RunArgs = append(filesArgs, container.RunArgs...)
+
 	tempDir, err := os.MkdirTemp(os.TempDir(), "compose-")
 	if err != nil {
 		return "", fmt.Errorf("error while creating/re-creating container %s: %w", container.Name, err)
@@ -26,6 +26,7 @@ import (
 	containerd "github.com/containerd/containerd/v2/client"
 	"github.com/containerd/log"
 
+	"github.com/containerd/nerdctl/v2/pkg/composer/serviceparser"
 	"github.com/containerd/nerdctl/v2/pkg/labels"
 )
 
@@ -38,16 +39,53 @@ type RestartOptions struct {
 // `nerdctl restart CONTAINER_ID` to do the actual job.
 func (c *Composer) Restart(ctx context.Context, opt RestartOptions, services []string) error {
 	// in dependency order
-	return c.project.ForEachService(services, func(name string, svc *types.ServiceConfig) error {
+	return c.project.ForEachService(c.withRestartDependents(services), func(name string, svc *types.ServiceConfig) error {
 		containers, err := c.Containers(ctx, svc.Name)
 		if err != nil {
 			return err
 		}
 
+		// The secrets and the configs on tmpfs are lost when the host reboots
+		ps, err := serviceparser.Parse(c.project, *svc)
+		if err != nil {
+			return err
+		}
+		if err := c.RestoreContainerFiles(ctx, ps.Containers); err != nil {
+			return err
+		}
+
 		return c.restartContainers(ctx, containers, opt)
 	})
 }
 
+// withRestartDependents appends the services that depend on `services` with `restart: true`.
+func (c *Composer) withRestartDependents(services []string) []string {
+	if len(services) == 0 {
+		return services
+	}
+	restarted := make(map[string]bool, len(services))
+	for _, svc := range services {
+		restarted[svc] = true
+	}
+	for found := true; found; {
+		found = false
+		for _, name := range c.project.ServiceNames() {
+			if restarted[name] {
+				continue
+			}
+			for depName, dep := range c.project.Services[name].DependsOn {
+				if dep.Restart && restarted[depName] {
+					restarted[name] = true
+					services = append(services, name)
+					found = true
+					break
+				}
+			}
+		}
+	}
+	return services
+}
+
 func (c *Composer) restartContainers(ctx context.Context, containers []containerd.Container, opt RestartOptions) error {
 	var timeoutArg string
 	if opt.Timeout != nil {
@@ -0,0 +1,71 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package composer
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"gotest.tools/v3/assert"
+)
+
+func TestWithRestartDependents(t *testing.T) {
+	c := &Composer{
+		project: &types.Project{
+			Services: types.Services{
+				"db":    {Name: "db"},
+				"cache": {Name: "cache"},
+				"app": {Name: "app", DependsOn: types.DependsOnConfig{
+					"db":    {Condition: types.ServiceConditionHealthy, Restart: true, Required: true},
+					"cache": {Condition: types.ServiceConditionStarted, Required: true},
+				}},
+				"proxy": {Name: "proxy", DependsOn: types.DependsOnConfig{
+					"app": {Condition: types.ServiceConditionStarted, Restart: true, Required: true},
+				}},
+			},
+		},
+	}
+
+	testCases := []struct {
+		services []string
+		expected []string
+	}{
+		{nil, nil},
+		{[]string{"db"}, []string{"app", "db", "proxy"}},
+		{[]string{"cache"}, []string{"cache"}},
+		{[]string{"app"}, []string{"app", "proxy"}},
+		{[]string{"proxy"}, []string{"proxy"}},
+	}
+	for _, tc := range testCases {
+		got := c.withRestartDependents(tc.services)
+		sort.Strings(got)
+		assert.DeepEqual(t, tc.expected, got)
+	}
+}
+
+func TestRestartsWithDependencies(t *testing.T) {
+	app := &types.ServiceConfig{Name: "app", DependsOn: types.DependsOnConfig{
+		"db":    {Condition: types.ServiceConditionHealthy, Restart: true, Required: true},
+		"cache": {Condition: types.ServiceConditionStarted, Required: true},
+	}}
+
+	assert.Assert(t, !restartsWithDependencies(app, map[string]bool{}))
+	assert.Assert(t, restartsWithDependencies(app, map[string]bool{"db": true}))
+	assert.Assert(t, !restartsWithDependencies(app, map[string]bool{"cache": true}))
+	assert.Assert(t, !restartsWithDependencies(&types.ServiceConfig{Name: "db"}, map[string]bool{"db": true}))
+}
@@ -29,9 +29,11 @@ import (
 	"time"
 
 	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/opencontainers/go-digest"
 
 	"github.com/containerd/log"
 
+	"github.com/containerd/nerdctl/v2/pkg/defaults"
 	"github.com/containerd/nerdctl/v2/pkg/healthcheck"
 	"github.com/containerd/nerdctl/v2/pkg/identifiers"
 	"github.com/containerd/nerdctl/v2/pkg/reflectutil"
@@ -47,6 +49,7 @@ const (
 	ComposeCosignCertificateIdentityRegexp   = "x-nerdctl-cosign-certificate-identity-regexp"
 	ComposeCosignCertificateOidcIssuer       = "x-nerdctl-cosign-certificate-oidc-issuer"
 	ComposeCosignCertificateOidcIssuerRegexp = "x-nerdctl-cosign-certificate-oidc-issuer-regexp"
+	ComposeHealthOnFailure                   = "x-nerdctl-health-on-failure"
 )
 
 // Separator is used for naming components (e.g., service image or container)
@@ -69,6 +72,7 @@ func warnUnknownFields(svc types.ServiceConfig) {
 		"ContainerName",
 		"DependsOn",
 		"Deploy",
+		"Develop", // handled by `nerdctl compose watch`
 		"Devices",
 		"Dockerfile", // handled by the loader (normalizer)
 		"DNS",
@@ -131,8 +135,8 @@ func warnUnknownFields(svc types.ServiceConfig) {
 			"Retries",
 			"StartPeriod",
 			"Disable",
+			"StartInterval",
 			"Extensions",
-			// TODO: add support 'StartInterval'
 		); len(unknown) > 0 {
 			log.L.Warnf("Ignoring: service %s: healthcheck: %+v", svc.Name, unknown)
 		}
@@ -141,11 +145,13 @@ func warnUnknownFields(svc types.ServiceConfig) {
 	for depName, dep := range svc.DependsOn {
 		if unknown := reflectutil.UnknownNonEmptyFields(&dep,
 			"Condition",
+			"Restart",
+			"Required",
 		); len(unknown) > 0 {
 			log.L.Warnf("Ignoring: service %s: depends_on: %s: %+v", svc.Name, depName, unknown)
 		}
 		switch dep.Condition {
-		case "", types.ServiceConditionStarted:
+		case "", types.ServiceConditionStarted, types.ServiceConditionHealthy, types.ServiceConditionCompletedSuccessfully:
 			// NOP
 		default:
 			log.L.Warnf("Ignoring: service %s: depends_on: %s: condition %s", svc.Name, depName, dep.Condition)
@@ -208,6 +214,18 @@ type Container struct {
 	Name    string   // e.g., "compose-wordpress_wordpress_1"
 	RunArgs []string // {"--pull=never", ...}
 	Mkdir   []string // For Bind.CreateHostPath
+	Files   []File   // For secrets and configs, not included in RunArgs as their host paths are resolved by the composer
+}
+
+// File is a secret or a config that is materialized on the host before bind-mounting it into the container.
+type File struct {
+	Path    string // relative to FilesDir, keyed by the target, e.g., "secrets/<sha256 of the target>"
+	Target  string // e.g., "/run/secrets/db_password"
+	Source  string // path of the host file to copy, empty when Content is used
+	Content string
+	UID     int // -1 for not changing the owner
+	GID     int // -1 for not changing the group
+	Mode    os.FileMode
 }
 
 type Build struct {
@@ -624,6 +642,13 @@ func newContainer(project *types.Project, parsed *Service, i int) (*Container, e
 			if value != nil && value.MacAddress != "" {
 				c.RunArgs = append(c.RunArgs, "--mac-address="+value.MacAddress)
 			}
+			// Like Docker Compose, the service name is an alias of the containers on all the networks of the service
+			c.RunArgs = append(c.RunArgs, fmt.Sprintf("--network-alias=%s:%s", net.fullName, svc.Name))
+			if value != nil {
+				for _, alias := range value.Aliases {
+					c.RunArgs = append(c.RunArgs, fmt.Sprintf("--network-alias=%s:%s", net.fullName, alias))
+				}
+			}
 		}
 	}
 
@@ -736,20 +761,20 @@ func newContainer(project *types.Project, parsed *Service, i int) (*Container, e
 
 	for _, config := range svc.Configs {
 		fileRef := types.FileReferenceConfig(config)
-		vStr, err := fileReferenceConfigToFlagV(fileRef, project, false)
+		f, err := fileReferenceConfigToFile(fileRef, project, false)
 		if err != nil {
 			return nil, err
 		}
-		c.RunArgs = append(c.RunArgs, "-v="+vStr)
+		c.Files = append(c.Files, *f)
 	}
 
 	for _, secret := range svc.Secrets {
 		fileRef := types.FileReferenceConfig(secret)
-		vStr, err := fileReferenceConfigToFlagV(fileRef, project, true)
+		f, err := fileReferenceConfigToFile(fileRef, project, true)
 		if err != nil {
 			return nil, err
 		}
-		c.RunArgs = append(c.RunArgs, "-v="+vStr)
+		c.Files = append(c.Files, *f)
 	}
 
 	for _, tmpfs := range svc.Tmpfs {
@@ -764,6 +789,7 @@ func newContainer(project *types.Project, parsed *Service, i int) (*Container, e
 		c.RunArgs = append(c.RunArgs, "-w="+svc.WorkingDir)
 	}
 
+	healthcheckDisabled := false
 	if svc.HealthCheck != nil {
 		hc := svc.HealthCheck
 		disabled := hc.Disable
@@ -786,6 +812,7 @@ func newContainer(project *types.Project, parsed *Service, i int) (*Container, e
 				}
 			}
 		}
+		healthcheckDisabled = disabled
 		if disabled {
 			c.RunArgs = append(c.RunArgs, "--no-healthcheck")
 		} else {
@@ -801,6 +828,24 @@ func newContainer(project *types.Project, parsed *Service, i int) (*Container, e
 			if hc.StartPeriod != nil {
 				c.RunArgs = append(c.RunArgs, fmt.Sprintf("--health-start-period=%s", time.Duration(*hc.StartPeriod).String()))
 			}
+			if hc.StartInterval != nil {
+				c.RunArgs = append(c.RunArgs, fmt.Sprintf("--health-start-interval=%s", time.Duration(*hc.StartInterval).String()))
+			}
+		}
+	}
+
+	if v, ok := svc.Extensions[ComposeHealthOnFailure]; ok {
+		action, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("service %s: %s must be a string, got %T", svc.Name, ComposeHealthOnFailure, v)
+		}
+		if err := healthcheck.ValidateOnFailureAction(action); err != nil {
+			return nil, fmt.Errorf("service %s: %s: %w", svc.Name, ComposeHealthOnFailure, err)
+		}
+		if healthcheckDisabled {
+			log.L.Warnf("service %s: %s is ignored, as the healthcheck is disabled", svc.Name, ComposeHealthOnFailure)
+		} else {
+			c.RunArgs = append(c.RunArgs, "--health-on-failure="+action)
 		}
 	}
 
@@ -942,10 +987,20 @@ func serviceVolumeConfigToFlagV(c types.ServiceVolumeConfig, project *types.Proj
 	return s, mkdir, nil
 }
 
-func fileReferenceConfigToFlagV(c types.FileReferenceConfig, project *types.Project, secret bool) (string, error) {
-	objType := "config"
+// FilesDir returns the host directory where the secrets and the configs of the container are materialized.
+// The directory is expected to be on tmpfs, so that the secrets are never written to a persistent disk.
+func FilesDir(namespace, projectName, containerName string) (string, error) {
+	dir, err := defaults.ComposeRuntimeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, namespace, projectName, containerName), nil
+}
+
+func fileReferenceConfigToFile(c types.FileReferenceConfig, project *types.Project, secret bool) (*File, error) {
+	objType, objDir := "config", "configs"
 	if secret {
-		objType = "secret"
+		objType, objDir = "secret", "secrets"
 	}
 	if unknown := reflectutil.UnknownNonEmptyFields(&c,
 		"Source", "Target", "UID", "GID", "Mode",
@@ -954,28 +1009,50 @@ func fileReferenceConfigToFlagV(c types.FileReferenceConfig, project *types.Proj
 	}
 
 	if err := identifiers.ValidateDockerCompat(c.Source); err != nil {
-		return "", fmt.Errorf("invalid source name for %s: %w", objType, err)
+		return nil, fmt.Errorf("invalid source name for %s: %w", objType, err)
 	}
 
 	var obj types.FileObjectConfig
 	if secret {
 		secret, ok := project.Secrets[c.Source]
 		if !ok {
-			return "", fmt.Errorf("secret %s is undefined", c.Source)
+			return nil, fmt.Errorf("secret %s is undefined", c.Source)
 		}
 		obj = types.FileObjectConfig(secret)
 	} else {
 		config, ok := project.Configs[c.Source]
 		if !ok {
-			return "", fmt.Errorf("config %s is undefined", c.Source)
+			return nil, fmt.Errorf("config %s is undefined", c.Source)
 		}
 		obj = types.FileObjectConfig(config)
 	}
-	src := project.RelativePath(obj.File)
-	var err error
-	src, err = filepath.Abs(src)
-	if err != nil {
-		return "", fmt.Errorf("%s %s: invalid relative path %q: %w", objType, c.Source, src, err)
+
+	f := &File{
+		UID:  -1,
+		GID:  -1,
+		Mode: 0o444,
+	}
+	switch {
+	case obj.File != "":
+		src, err := filepath.Abs(project.RelativePath(obj.File))
+		if err != nil {
+			return nil, fmt.Errorf("%s %s: invalid relative path %q: %w", objType, c.Source, obj.File, err)
+		}
+		f.Source = src
+	case obj.Content != "":
+		f.Content = obj.Content
+	case obj.Environment != "":
+		// compose-go resolves the environment of secrets into an extension field,
+		// while the environment of configs is resolved into the Content field.
+		if v, ok := obj.Extensions[types.SecretConfigXValue].(string); ok {
+			f.Content = v
+		} else if v, ok := project.Environment[obj.Environment]; ok {
+			f.Content = v
+		} else {
+			return nil, fmt.Errorf("%s %s: environment variable %q is not set", objType, c.Source, obj.Environment)
+		}
+	default:
+		return nil, fmt.Errorf("%s %s: lacks file, environment, or content", objType, c.Source)
 	}
 
 	target := c.Target
@@ -991,24 +1068,33 @@ func fileReferenceConfigToFlagV(c types.FileReferenceConfig, project *types.Proj
 			if secret {
 				target = filepath.Join("/run/secrets", target)
 			} else {
-				return "", fmt.Errorf("config %s: target %q must be an absolute path", c.Source, c.Target)
+				return nil, fmt.Errorf("config %s: target %q must be an absolute path", c.Source, c.Target)
 			}
 		}
 	}
+	f.Target = target
 
+	var err error
 	if c.UID != "" {
-		// Raise an error rather than ignoring the value, for avoiding any security issue
-		return "", fmt.Errorf("%s %s: unsupported field: UID", objType, c.Source)
+		if f.UID, err = strconv.Atoi(c.UID); err != nil || f.UID < 0 {
+			return nil, fmt.Errorf("%s %s: invalid uid %q", objType, c.Source, c.UID)
+		}
 	}
 	if c.GID != "" {
-		return "", fmt.Errorf("%s %s: unsupported field: GID", objType, c.Source)
+		if f.GID, err = strconv.Atoi(c.GID); err != nil || f.GID < 0 {
+			return nil, fmt.Errorf("%s %s: invalid gid %q", objType, c.Source, c.GID)
+		}
 	}
 	if c.Mode != nil {
-		return "", fmt.Errorf("%s %s: unsupported field: Mode", objType, c.Source)
+		if *c.Mode < 0 || *c.Mode > 0o777 {
+			return nil, fmt.Errorf("%s %s: invalid mode %o", objType, c.Source, *c.Mode)
+		}
+		f.Mode = os.FileMode(*c.Mode)
 	}
 
-	s := fmt.Sprintf("%s:%s:ro", src, target)
-	return s, nil
+	// The same secret may be mounted to several targets with different uid, gid and mode
+	f.Path = filepath.Join(objDir, digest.FromString(target).Encoded())
+	return f, nil
 }
 
 // DefaultImageName returns the image name following compose naming logic.
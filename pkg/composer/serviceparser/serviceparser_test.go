@@ -26,6 +26,7 @@ import (
 	"testing"
 
 	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/opencontainers/go-digest"
 	"gotest.tools/v3/assert"
 
 	"github.com/containerd/nerdctl/v2/pkg/internal/filesystem"
@@ -545,10 +546,19 @@ services:
       target: secret2-foo
     - source: secret3
       target: /mnt/secret3-foo
+      uid: "1000"
+      gid: "1001"
+      mode: 0400
+    - secret4
+    - source: secret1
+      target: /mnt/secret1-bar
+      mode: 0400
     configs:
     - config1
     - source: config2
       target: /mnt/config2-foo
+    - source: config3
+      target: /mnt/config3-foo
 secrets:
   secret1:
     file: ./secret1
@@ -556,16 +566,20 @@ secrets:
     file: ./secret2
   secret3:
     file: ./secret3
+  secret4:
+    environment: SECRET4
 configs:
   config1:
     file: ./config1
   config2:
     file: ./config2
+  config3:
+    content: content-config3
 `
 	comp := testutil.NewComposeDir(t, dockerComposeYAML)
 	defer comp.CleanUp()
 
-	project, err := testutil.LoadProject(comp.YAMLFullPath(), comp.ProjectName(), nil)
+	project, err := testutil.LoadProject(comp.YAMLFullPath(), comp.ProjectName(), map[string]string{"SECRET4": "content-secret4"})
 	assert.NilError(t, err)
 
 	for _, f := range []string{"secret1", "secret2", "secret3", "config1", "config2"} {
@@ -581,11 +595,43 @@ configs:
 
 	t.Logf("foo: %+v", foo)
 	for _, c := range foo.Containers {
-		assert.Assert(t, in(c.RunArgs, fmt.Sprintf("-v=%s:/run/secrets/secret1:ro", filepath.Join(project.WorkingDir, "secret1"))))
-		assert.Assert(t, in(c.RunArgs, fmt.Sprintf("-v=%s:/run/secrets/secret2-foo:ro", filepath.Join(project.WorkingDir, "secret2"))))
-		assert.Assert(t, in(c.RunArgs, fmt.Sprintf("-v=%s:/mnt/secret3-foo:ro", filepath.Join(project.WorkingDir, "secret3"))))
-		assert.Assert(t, in(c.RunArgs, fmt.Sprintf("-v=%s:/config1:ro", filepath.Join(project.WorkingDir, "config1"))))
-		assert.Assert(t, in(c.RunArgs, fmt.Sprintf("-v=%s:/mnt/config2-foo:ro", filepath.Join(project.WorkingDir, "config2"))))
+		files := make(map[string]File)
+		for _, f := range c.Files {
+			files[f.Target] = f
+		}
+		assert.Equal(t, len(files), 8)
+		assert.Equal(t, files["/run/secrets/secret2-foo"].Path, filepath.Join("secrets", digest.FromString("/run/secrets/secret2-foo").Encoded()))
+		assert.Equal(t, files["/run/secrets/secret4"].Path, filepath.Join("secrets", digest.FromString("/run/secrets/secret4").Encoded()))
+		assert.Equal(t, files["/config1"].Path, filepath.Join("configs", digest.FromString("/config1").Encoded()))
+		assert.Equal(t, files["/mnt/config2-foo"].Path, filepath.Join("configs", digest.FromString("/mnt/config2-foo").Encoded()))
+		assert.Equal(t, files["/mnt/config3-foo"].Path, filepath.Join("configs", digest.FromString("/mnt/config3-foo").Encoded()))
+		assert.DeepEqual(t, files["/run/secrets/secret1"], File{
+			Path:   filepath.Join("secrets", digest.FromString("/run/secrets/secret1").Encoded()),
+			Target: "/run/secrets/secret1",
+			Source: filepath.Join(project.WorkingDir, "secret1"),
+			UID:    -1,
+			GID:    -1,
+			Mode:   0o444,
+		})
+		// The same secret mounted to another target does not share the file
+		assert.DeepEqual(t, files["/mnt/secret1-bar"], File{
+			Path:   filepath.Join("secrets", digest.FromString("/mnt/secret1-bar").Encoded()),
+			Target: "/mnt/secret1-bar",
+			Source: filepath.Join(project.WorkingDir, "secret1"),
+			UID:    -1,
+			GID:    -1,
+			Mode:   0o400,
+		})
+		assert.DeepEqual(t, files["/mnt/secret3-foo"], File{
+			Path:   filepath.Join("secrets", digest.FromString("/mnt/secret3-foo").Encoded()),
+			Target: "/mnt/secret3-foo",
+			Source: filepath.Join(project.WorkingDir, "secret3"),
+			UID:    1000,
+			GID:    1001,
+			Mode:   0o400,
+		})
+		assert.Equal(t, files["/run/secrets/secret4"].Content, "content-secret4")
+		assert.Equal(t, files["/mnt/config3-foo"].Content, "content-config3")
 	}
 }
 
@@ -638,6 +684,7 @@ services:
       timeout: 10s
       retries: 3
       start_period: 5s
+      start_interval: 1s
   cmd_exec:
     image: alpine:3.14
     healthcheck:
@@ -652,6 +699,16 @@ services:
     image: alpine:3.14
     healthcheck:
       test: ["NONE"]
+  on_failure:
+    image: alpine:3.14
+    healthcheck:
+      test: ["CMD-SHELL", "exit 1"]
+    x-nerdctl-health-on-failure: restart
+  on_failure_disabled:
+    image: alpine:3.14
+    healthcheck:
+      disable: true
+    x-nerdctl-health-on-failure: kill
 `
 	comp := testutil.NewComposeDir(t, dockerComposeYAML)
 	defer comp.CleanUp()
@@ -667,6 +724,7 @@ services:
 	assert.Assert(t, in(c.RunArgs, "--health-timeout=10s"))
 	assert.Assert(t, in(c.RunArgs, "--health-retries=3"))
 	assert.Assert(t, in(c.RunArgs, "--health-start-period=5s"))
+	assert.Assert(t, in(c.RunArgs, "--health-start-interval=1s"))
 
 	c = getContainersFromService(t, project, "cmd_exec")[0]
 	assert.Assert(t, in(c.RunArgs, "--health-cmd=curl -f http://localhost"))
@@ -680,4 +738,75 @@ services:
 
 	c = getContainersFromService(t, project, "disabled_none")[0]
 	assert.Assert(t, in(c.RunArgs, "--no-healthcheck"))
+
+	c = getContainersFromService(t, project, "on_failure")[0]
+	assert.Assert(t, in(c.RunArgs, "--health-on-failure=restart"))
+
+	c = getContainersFromService(t, project, "on_failure_disabled")[0]
+	assert.Assert(t, in(c.RunArgs, "--no-healthcheck"))
+	assert.Assert(t, !in(c.RunArgs, "--health-on-failure=kill"))
+}
+
+func TestParseHealthOnFailureInvalid(t *testing.T) {
+	t.Parallel()
+	const dockerComposeYAML = `
+services:
+  foo:
+    image: alpine:3.14
+    x-nerdctl-health-on-failure: reboot
+`
+	comp := testutil.NewComposeDir(t, dockerComposeYAML)
+	defer comp.CleanUp()
+
+	project, err := testutil.LoadProject(comp.YAMLFullPath(), comp.ProjectName(), nil)
+	assert.NilError(t, err)
+
+	svc, err := project.GetService("foo")
+	assert.NilError(t, err)
+	_, err = Parse(project, svc)
+	assert.ErrorContains(t, err, "invalid health-on-failure action")
+}
+
+func TestParseNetworkAliases(t *testing.T) {
+	t.Parallel()
+	const dockerComposeYAML = `
+services:
+  foo:
+    image: nginx:alpine
+    hostname: custom
+    networks:
+      front:
+        aliases:
+          - web
+          - www
+      back:
+  bar:
+    image: alpine:3.14
+    network_mode: host
+networks:
+  front:
+  back:
+`
+	comp := testutil.NewComposeDir(t, dockerComposeYAML)
+	defer comp.CleanUp()
+
+	project, err := testutil.LoadProject(comp.YAMLFullPath(), comp.ProjectName(), nil)
+	assert.NilError(t, err)
+
+	for _, c := range getContainersFromService(t, project, "foo") {
+		t.Logf("foo: %+v", c.RunArgs)
+		front := fmt.Sprintf("%s_front", project.Name)
+		back := fmt.Sprintf("%s_back", project.Name)
+		assert.Assert(t, in(c.RunArgs, "--network-alias="+front+":foo"))
+		assert.Assert(t, in(c.RunArgs, "--network-alias="+front+":web"))
+		assert.Assert(t, in(c.RunArgs, "--network-alias="+front+":www"))
+		assert.Assert(t, in(c.RunArgs, "--network-alias="+back+":foo"))
+		assert.Assert(t, !in(c.RunArgs, "--network-alias="+back+":web"))
+	}
+
+	for _, c := range getContainersFromService(t, project, "bar") {
+		for _, arg := range c.RunArgs {
+			assert.Assert(t, !strings.HasPrefix(arg, "--network-alias"), arg)
+		}
+	}
 }
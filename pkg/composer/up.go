@@ -71,7 +71,7 @@ func (c *Composer) Up(ctx context.Context, uo UpOptions, services []string) erro
 
 	for shortName, secret := range c.project.Secrets {
 		obj := types.FileObjectConfig(secret)
-		if err := validateFileObjectConfig(obj, shortName, "service", c.project); err != nil {
+		if err := validateFileObjectConfig(obj, shortName, "secret", c.project); err != nil {
 			return err
 		}
 	}
@@ -124,16 +124,20 @@ func (c *Composer) Up(ctx context.Context, uo UpOptions, services []string) erro
 }
 
 func validateFileObjectConfig(obj types.FileObjectConfig, shortName, objType string, project *types.Project) error {
-	if unknown := reflectutil.UnknownNonEmptyFields(&obj, "Name", "External", "File"); len(unknown) > 0 {
+	if unknown := reflectutil.UnknownNonEmptyFields(&obj, "Name", "External", "File", "Environment", "Content", "Extensions"); len(unknown) > 0 {
 		log.L.Warnf("Ignoring: %s %s: %+v", objType, shortName, unknown)
 	}
 
-	if obj.File == "" {
-		return fmt.Errorf("%s %q: lacks file path", objType, shortName)
-	}
-	fullPath := project.RelativePath(obj.File)
-	if _, err := os.Stat(fullPath); err != nil {
-		return fmt.Errorf("%s %q: failed to open file %q: %w", objType, shortName, fullPath, err)
+	switch {
+	case obj.File != "":
+		fullPath := project.RelativePath(obj.File)
+		if _, err := os.Stat(fullPath); err != nil {
+			return fmt.Errorf("%s %q: failed to open file %q: %w", objType, shortName, fullPath, err)
+		}
+	case obj.Environment != "", obj.Content != "":
+		// NOP: resolved in serviceparser
+	default:
+		return fmt.Errorf("%s %q: lacks file path, environment, or content", objType, shortName)
 	}
 	return nil
 }
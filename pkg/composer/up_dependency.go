@@ -0,0 +1,220 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package composer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/compose-spec/compose-go/v2/types"
+
+	containerd "github.com/containerd/containerd/v2/client"
+	"github.com/containerd/errdefs"
+	"github.com/containerd/log"
+
+	"github.com/containerd/nerdctl/v2/pkg/healthcheck"
+	"github.com/containerd/nerdctl/v2/pkg/labels"
+)
+
+// dependencyPollInterval is the interval for polling the state of the dependency containers.
+const dependencyPollInterval = 500 * time.Millisecond
+
+// waitDependencies blocks until the dependencies of the service satisfy the conditions of `depends_on`.
+// An unsatisfied dependency with `required: false` is reported as a warning.
+func (c *Composer) waitDependencies(ctx context.Context, svc *types.ServiceConfig) error {
+	for _, depName := range sortedMapKeys(svc.DependsOn) {
+		dep := svc.DependsOn[depName]
+		var waitFn func(context.Context, containerd.Container) error
+		switch dep.Condition {
+		case types.ServiceConditionHealthy:
+			waitFn = waitContainerHealthy
+		case types.ServiceConditionCompletedSuccessfully:
+			waitFn = waitContainerCompleted
+		default:
+			// "service_started" is satisfied by starting the services in dependency order
+			continue
+		}
+
+		err := c.waitDependency(ctx, depName, waitFn)
+		if err != nil {
+			if !dep.Required {
+				log.G(ctx).WithError(err).Warnf("service %s: ignoring the optional dependency %s", svc.Name, depName)
+				continue
+			}
+			return fmt.Errorf("service %s: dependency %s failed: %w", svc.Name, depName, err)
+		}
+	}
+	return nil
+}
+
+func (c *Composer) waitDependency(ctx context.Context, depName string, waitFn func(context.Context, containerd.Container) error) error {
+	containers, err := c.Containers(ctx, depName)
+	if err != nil {
+		return err
+	}
+	if len(containers) == 0 {
+		return fmt.Errorf("service %s has no container", depName)
+	}
+	for _, container := range containers {
+		if err := waitFn(ctx, container); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// waitContainerHealthy waits for the container to become healthy.
+// The timeout is derived from the healthcheck configuration, i.e., the time needed for
+// the start period and the retries to elapse.
+func waitContainerHealthy(ctx context.Context, container containerd.Container) error {
+	containerLabels, err := container.Labels(ctx)
+	if err != nil {
+		return err
+	}
+	name := containerLabels[labels.Name]
+	if containerLabels[labels.HealthCheck] == "" {
+		return fmt.Errorf("container %s has no healthcheck configured", name)
+	}
+	hc, err := healthcheck.HealthCheckFromJSON(containerLabels[labels.HealthCheck])
+	if err != nil {
+		return fmt.Errorf("container %s: failed to parse healthcheck: %w", name, err)
+	}
+	if len(hc.Test) == 0 || hc.Test[0] == healthcheck.CmdNone {
+		return fmt.Errorf("container %s has no healthcheck configured", name)
+	}
+	hc.ApplyDefaults()
+	timeout := hc.StartPeriod + time.Duration(hc.Retries+1)*(hc.Interval+hc.Timeout)
+
+	log.G(ctx).Infof("Waiting for container %s to be healthy", name)
+	return pollContainer(ctx, timeout, func() (bool, error) {
+		containerLabels, err := container.Labels(ctx)
+		if err != nil {
+			return false, err
+		}
+		if status, err := containerTaskStatus(ctx, container); err != nil {
+			return false, err
+		} else if status.Status != containerd.Running {
+			return false, fmt.Errorf("container %s is not running (%s)", name, status.Status)
+		}
+		if containerLabels[labels.HealthState] == "" {
+			return false, nil
+		}
+		state, err := healthcheck.HealthStateFromJSON(containerLabels[labels.HealthState])
+		if err != nil {
+			return false, fmt.Errorf("container %s: failed to parse health state: %w", name, err)
+		}
+		switch state.Status {
+		case healthcheck.Healthy:
+			return true, nil
+		case healthcheck.Unhealthy:
+			return false, fmt.Errorf("container %s is unhealthy", name)
+		default:
+			return false, nil
+		}
+	}, func() error {
+		return fmt.Errorf("timed out after %s waiting for container %s to become healthy", timeout, name)
+	})
+}
+
+// waitContainerCompleted waits for the container to exit with status 0.
+// The dependency containers are started before waiting, so a container without a task has completed
+// and its task has been deleted, or it has been removed (e.g., `--rm`). As the exit status is not
+// recorded anywhere else, such a container is regarded as completed unless it failed to start.
+func waitContainerCompleted(ctx context.Context, container containerd.Container) error {
+	info, err := container.Info(ctx, containerd.WithoutRefreshedMetadata)
+	if err != nil {
+		return err
+	}
+	name := info.Labels[labels.Name]
+
+	log.G(ctx).Infof("Waiting for container %s to complete", name)
+	return pollContainer(ctx, 0, func() (bool, error) {
+		task, err := container.Task(ctx, nil)
+		if errdefs.IsNotFound(err) {
+			containerLabels, err := container.Labels(ctx)
+			if errdefs.IsNotFound(err) {
+				log.G(ctx).Debugf("container %s has been removed, regarding it as completed", name)
+				return true, nil
+			} else if err != nil {
+				return false, err
+			}
+			if msg := containerLabels[labels.Error]; msg != "" {
+				return false, fmt.Errorf("container %s failed: %s", name, msg)
+			}
+			log.G(ctx).Debugf("container %s has no task, regarding it as completed", name)
+			return true, nil
+		} else if err != nil {
+			return false, err
+		}
+		status, err := task.Status(ctx)
+		if err != nil {
+			return false, err
+		}
+		switch status.Status {
+		case containerd.Stopped:
+			if status.ExitStatus != 0 {
+				return false, fmt.Errorf("container %s exited with status %d", name, status.ExitStatus)
+			}
+			return true, nil
+		case containerd.Created:
+			return false, fmt.Errorf("container %s is not started", name)
+		default:
+			return false, nil
+		}
+	}, nil)
+}
+
+// containerTaskStatus returns the status of the task of the container.
+// A container without a task is regarded as not started yet.
+func containerTaskStatus(ctx context.Context, container containerd.Container) (containerd.Status, error) {
+	task, err := container.Task(ctx, nil)
+	if err != nil {
+		if errdefs.IsNotFound(err) {
+			return containerd.Status{Status: containerd.Created}, nil
+		}
+		return containerd.Status{}, err
+	}
+	return task.Status(ctx)
+}
+
+// pollContainer calls checkFn until it returns true or an error.
+// When timeout is non-zero, the error returned by timeoutErrFn is returned after the timeout.
+func pollContainer(ctx context.Context, timeout time.Duration, checkFn func() (bool, error), timeoutErrFn func() error) error {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	ticker := time.NewTicker(dependencyPollInterval)
+	defer ticker.Stop()
+	for {
+		ok, err := checkFn()
+		if err != nil || ok {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) && timeoutErrFn != nil {
+				return timeoutErrFn()
+			}
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
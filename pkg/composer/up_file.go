@@ -0,0 +1,115 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package composer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"syscall"
+
+	"github.com/containerd/log"
+
+	"github.com/containerd/nerdctl/v2/pkg/composer/serviceparser"
+	"github.com/containerd/nerdctl/v2/pkg/rootlessutil"
+)
+
+// writeContainerFiles materializes the secrets and the configs of the container on the host,
+// and returns the flags to bind-mount them into the container.
+// The files are written into a private directory instead of bind-mounting the source files directly,
+// so that the owner and the mode of the source files are not exposed to the container.
+func (c *Composer) writeContainerFiles(ctx context.Context, container serviceparser.Container) ([]string, error) {
+	if len(container.Files) == 0 {
+		return nil, nil
+	}
+	dir, err := serviceparser.FilesDir(c.config.Namespace, c.project.Name, container.Name)
+	if err != nil {
+		return nil, err
+	}
+	var args []string
+	for _, f := range container.Files {
+		p := filepath.Join(dir, f.Path)
+		log.G(ctx).Debugf("Writing %q for %q", p, f.Target)
+		if err := writeContainerFile(ctx, p, f); err != nil {
+			return nil, fmt.Errorf("failed to write %q for container %s: %w", f.Target, container.Name, err)
+		}
+		args = append(args, fmt.Sprintf("-v=%s:%s:ro", p, f.Target))
+	}
+	return args, nil
+}
+
+// RestoreContainerFiles writes again the secrets and the configs of the existing containers
+// that are missing on the host, typically because the tmpfs was cleared by a reboot,
+// so that the containers can be started again.
+// The files that are still there are kept as is, like the container.
+func (c *Composer) RestoreContainerFiles(ctx context.Context, containers []serviceparser.Container) error {
+	for _, container := range containers {
+		if len(container.Files) == 0 {
+			continue
+		}
+		dir, err := serviceparser.FilesDir(c.config.Namespace, c.project.Name, container.Name)
+		if err != nil {
+			return err
+		}
+		for _, f := range container.Files {
+			p := filepath.Join(dir, f.Path)
+			if _, err := os.Stat(p); !os.IsNotExist(err) {
+				continue
+			}
+			log.G(ctx).Debugf("Restoring %q for %q", p, f.Target)
+			if err := writeContainerFile(ctx, p, f); err != nil {
+				return fmt.Errorf("failed to write %q for container %s: %w", f.Target, container.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+func writeContainerFile(ctx context.Context, p string, f serviceparser.File) error {
+	content := []byte(f.Content)
+	if f.Source != "" {
+		var err error
+		content, err = os.ReadFile(f.Source)
+		if err != nil {
+			return err
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0o700); err != nil {
+		return err
+	}
+	// Remove the stale file, as os.WriteFile does not change the mode of an existing file
+	if err := os.Remove(p); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if err := os.WriteFile(p, content, 0o600); err != nil {
+		return err
+	}
+	if f.UID >= 0 || f.GID >= 0 {
+		if err := os.Chown(p, f.UID, f.GID); err != nil {
+			// In rootless mode, the IDs are those of the user namespace of RootlessKit, which the containers share.
+			// IDs outside of the subordinate ID ranges cannot be mapped, so the file is left owned by the root of the containers.
+			if !rootlessutil.IsRootless() || !errors.Is(err, syscall.EINVAL) {
+				return err
+			}
+			log.G(ctx).WithError(err).Warnf("ignoring uid %d and gid %d of %q, as they are not mapped in the user namespace", f.UID, f.GID, f.Target)
+		}
+	}
+	// os.WriteFile is subject to umask
+	return os.Chmod(p, f.Mode)
+}
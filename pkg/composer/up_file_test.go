@@ -0,0 +1,35 @@
+package composer
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/containerd/nerdctl/v2/pkg/composer/serviceparser"
+)
+
+func TestWriteContainerFile(t *testing.T) {
+	p := filepath.Join(t.TempDir(), "project-foo-1", "secrets", "foo")
+	f := serviceparser.File{Target: "/run/secrets/foo", Content: "bar", UID: -1, GID: -1, Mode: 0o400}
+
+	assert.NilError(t, writeContainerFile(context.Background(), p, f))
+	b, err := os.ReadFile(p)
+	assert.NilError(t, err)
+	assert.Equal(t, string(b), "bar")
+	st, err := os.Stat(p)
+	assert.NilError(t, err)
+	assert.Equal(t, st.Mode().Perm(), os.FileMode(0o400))
+
+	// The stale file is replaced, including its mode
+	f.Content, f.Mode = "baz", 0o444
+	assert.NilError(t, writeContainerFile(context.Background(), p, f))
+	b, err = os.ReadFile(p)
+	assert.NilError(t, err)
+	assert.Equal(t, string(b), "baz")
+	st, err = os.Stat(p)
+	assert.NilError(t, err)
+	assert.Equal(t, st.Mode().Perm(), os.FileMode(0o444))
+}
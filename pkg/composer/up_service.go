@@ -26,6 +26,7 @@ import (
 	"strings"
 	"sync"
 
+	"github.com/compose-spec/compose-go/v2/types"
 	"golang.org/x/sync/errgroup"
 
 	"github.com/containerd/log"
@@ -52,21 +53,36 @@ func (c *Composer) upServices(ctx context.Context, parsedServices []*servicepars
 	var (
 		containers   = make(map[string]serviceparser.Container) // key: container ID
 		services     = []string{}
+		changed      = make(map[string]bool) // services with a container (re-)created or restarted
 		containersMu sync.Mutex
 	)
 	for _, ps := range parsedServices {
 		ps := ps
+		if err := c.waitDependencies(ctx, ps.Unparsed); err != nil {
+			return err
+		}
+		restart := restartsWithDependencies(ps.Unparsed, changed)
 		var runEG errgroup.Group
 		services = append(services, ps.Unparsed.Name)
 		for _, container := range ps.Containers {
 			container := container
 			runEG.Go(func() error {
-				id, err := c.upServiceContainer(ctx, ps, container, recreate)
+				id, created, err := c.upServiceContainer(ctx, ps, container, recreate)
 				if err != nil {
 					return err
 				}
+				if !created && restart {
+					log.G(ctx).Infof("Restarting container %s, as a dependency has been re-created or restarted", container.Name)
+					if err := c.runNerdctlCmd(ctx, "restart", id); err != nil {
+						return fmt.Errorf("error while restarting container %s: %w", container.Name, err)
+					}
+					created = true
+				}
 				containersMu.Lock()
 				containers[id] = container
+				if created {
+					changed[ps.Unparsed.Name] = true
+				}
 				containersMu.Unlock()
 				return nil
 			})
@@ -102,6 +118,17 @@ func (c *Composer) upServices(ctx context.Context, parsedServices []*servicepars
 	return nil
 }
 
+// restartsWithDependencies returns whether the existing containers of the service have to be restarted,
+// as a dependency with `restart: true` has been re-created or restarted.
+func restartsWithDependencies(svc *types.ServiceConfig, changed map[string]bool) bool {
+	for name, dep := range svc.DependsOn {
+		if dep.Restart && changed[name] {
+			return true
+		}
+	}
+	return false
+}
+
 func (c *Composer) ensureServiceImage(ctx context.Context, ps *serviceparser.Service, allowBuild, forceBuild bool, bo BuildOptions, quiet bool, pullModeArg string) error {
 	if ps.Build != nil && allowBuild {
 		if ps.Build.Force || forceBuild {
@@ -125,17 +152,17 @@ func (c *Composer) ensureServiceImage(ctx context.Context, ps *serviceparser.Ser
 }
 
 // upServiceContainer must be called after ensureServiceImage
-// upServiceContainer returns container ID
-func (c *Composer) upServiceContainer(ctx context.Context, service *serviceparser.Service, container serviceparser.Container, recreate string) (string, error) {
+// upServiceContainer returns container ID, and whether the container has been created (or re-created)
+func (c *Composer) upServiceContainer(ctx context.Context, service *serviceparser.Service, container serviceparser.Container, recreate string) (string, bool, error) {
 	// check if container already exists
 	existingCid, err := c.containerID(ctx, container.Name, service.Unparsed.Name)
 	if err != nil {
-		return "", fmt.Errorf("error while checking for containers with name %q: %w", container.Name, err)
+		return "", false, fmt.Errorf("error while checking for containers with name %q: %w", container.Name, err)
 	}
 
 	// FIXME
 	if service.Unparsed.StdinOpen != service.Unparsed.Tty {
-		return "", fmt.Errorf("currently StdinOpen(-i) and Tty(-t) should be same")
+		return "", false, fmt.Errorf("currently StdinOpen(-i) and Tty(-t) should be same")
 	}
 
 	var runFlagD bool
@@ -146,11 +173,14 @@ func (c *Composer) upServiceContainer(ctx context.Context, service *serviceparse
 
 	// start the existing container and exit early
 	if existingCid != "" && recreate == RecreateNever {
+		if err := c.RestoreContainerFiles(ctx, []serviceparser.Container{container}); err != nil {
+			return "", false, err
+		}
 		cmd := c.createNerdctlCmd(ctx, append([]string{"start"}, existingCid)...)
 		if err := c.executeUpCmd(ctx, cmd, container.Name, runFlagD, service.Unparsed.StdinOpen); err != nil {
-			return "", fmt.Errorf("error while starting existing container %s: %w", container.Name, err)
+			return "", false, fmt.Errorf("error while starting existing container %s: %w", container.Name, err)
 		}
-		return existingCid, nil
+		return existingCid, false, nil
 	}
 
 	// delete container if it already exists
@@ -159,28 +189,31 @@ func (c *Composer) upServiceContainer(ctx context.Context, service *serviceparse
 		if recreate == RecreateDiverged {
 			currentHash, err := ServiceHash(*service.Unparsed)
 			if err != nil {
-				return "", fmt.Errorf("failed computing service hash for %s: %w", container.Name, err)
+				return "", false, fmt.Errorf("failed computing service hash for %s: %w", container.Name, err)
 			}
 			con, err := c.client.LoadContainer(ctx, existingCid)
 			if err != nil {
-				return "", fmt.Errorf("failed to load container %s: %w", existingCid, err)
+				return "", false, fmt.Errorf("failed to load container %s: %w", existingCid, err)
 			}
 			lbls, err := con.Labels(ctx)
 			if err != nil {
-				return "", fmt.Errorf("failed to read labels for %s: %w", existingCid, err)
+				return "", false, fmt.Errorf("failed to read labels for %s: %w", existingCid, err)
 			}
 			if lbls[labels.ComposeConfigHash] == currentHash {
+				if err := c.RestoreContainerFiles(ctx, []serviceparser.Container{container}); err != nil {
+					return "", false, err
+				}
 				cmd := c.createNerdctlCmd(ctx, append([]string{"start"}, existingCid)...)
 				if err := c.executeUpCmd(ctx, cmd, container.Name, runFlagD, service.Unparsed.StdinOpen); err != nil {
-					return "", fmt.Errorf("error while starting existing container %s: %w", container.Name, err)
+					return "", false, fmt.Errorf("error while starting existing container %s: %w", container.Name, err)
 				}
-				return existingCid, nil
+				return existingCid, false, nil
 			}
 		}
 		log.G(ctx).Debugf("Container %q already exists, deleting", container.Name)
 		delCmd := c.createNerdctlCmd(ctx, "rm", "-f", container.Name)
 		if err = delCmd.Run(); err != nil {
-			return "", fmt.Errorf("could not delete container %q: %w", container.Name, err)
+			return "", false, fmt.Errorf("could not delete container %q: %w", container.Name, err)
 		}
 		log.G(ctx).Infof("Re-creating container %s", container.Name)
 	} else {
@@ -190,13 +223,19 @@ func (c *Composer) upServiceContainer(ctx context.Context, service *serviceparse
 	for _, f := range container.Mkdir {
 		log.G(ctx).Debugf("Creating a directory %q", f)
 		if err = os.MkdirAll(f, 0o755); err != nil {
-			return "", fmt.Errorf("failed to create a directory %q: %w", f, err)
+			return "", false, fmt.Errorf("failed to create a directory %q: %w", f, err)
 		}
 	}
 
+	filesArgs, err := c.writeContainerFiles(ctx, container)
+	if err != nil {
+		return "", false, err
+	}
+	container.RunArgs = append(filesArgs, container.RunArgs...)
+
 	tempDir, err := os.MkdirTemp(os.TempDir(), "compose-")
 	if err != nil {
-		return "", fmt.Errorf("error while creating/re-creating container %s: %w", container.Name, err)
+		return "", false, fmt.Errorf("error while creating/re-creating container %s: %w", container.Name, err)
 	}
 	defer os.RemoveAll(tempDir)
 	cidFilename := filepath.Join(tempDir, "cid")
@@ -208,7 +247,7 @@ func (c *Composer) upServiceContainer(ctx context.Context, service *serviceparse
 	//add metadata labels to container https://github.com/compose-spec/compose-spec/blob/master/spec.md#labels
 	currentHash, err := ServiceHash(*service.Unparsed)
 	if err != nil {
-		return "", fmt.Errorf("failed computing service hash for %s: %w", container.Name, err)
+		return "", false, fmt.Errorf("failed computing service hash for %s: %w", container.Name, err)
 	}
 	container.RunArgs = append([]string{
 		"--cidfile=" + cidFilename,
@@ -223,14 +262,14 @@ func (c *Composer) upServiceContainer(ctx context.Context, service *serviceparse
 	}
 
 	if err := c.executeUpCmd(ctx, cmd, container.Name, runFlagD, service.Unparsed.StdinOpen); err != nil {
-		return "", fmt.Errorf("error while creating container %s: %w", container.Name, err)
+		return "", false, fmt.Errorf("error while creating container %s: %w", container.Name, err)
 	}
 
 	cid, err := filesystem.ReadFile(cidFilename)
 	if err != nil {
-		return "", fmt.Errorf("error while creating container %s: %w", container.Name, err)
+		return "", false, fmt.Errorf("error while creating container %s: %w", container.Name, err)
 	}
-	return strings.TrimSpace(string(cid)), nil
+	return strings.TrimSpace(string(cid)), true, nil
 }
 
 func (c *Composer) executeUpCmd(ctx context.Context, cmd *exec.Cmd, containerName string, runFlagD, stdinOpen bool) error {
@@ -0,0 +1,432 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package composer
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/fsnotify/fsnotify"
+
+	containerd "github.com/containerd/containerd/v2/client"
+	"github.com/containerd/containerd/v2/pkg/cio"
+	"github.com/containerd/log"
+
+	"github.com/containerd/nerdctl/v2/pkg/composer/serviceparser"
+	"github.com/containerd/nerdctl/v2/pkg/idgen"
+)
+
+// watchDebounce is the delay to batch the file changes, e.g., of a `git checkout`
+const watchDebounce = 500 * time.Millisecond
+
+// WatchOptions stores all option input from `nerdctl compose watch`
+type WatchOptions struct {
+	// NoUp does not build and start the services before watching
+	NoUp bool
+}
+
+// watchTrigger is a `develop.watch` entry of a service.
+type watchTrigger struct {
+	service string
+	types.Trigger
+}
+
+// Watch watches the `develop.watch` paths of `services`, and syncs the changed files into the containers,
+// restarts them, or rebuilds and recreates them, until ctx is done.
+func (c *Composer) Watch(ctx context.Context, wo WatchOptions, services []string) error {
+	var triggers []*watchTrigger
+	err := c.project.ForEachService(services, func(name string, svc *types.ServiceConfig) error {
+		if svc.Develop == nil {
+			return nil
+		}
+		for _, t := range svc.Develop.Watch {
+			if t.Action == types.WatchActionRebuild && svc.Build == nil {
+				return fmt.Errorf("service %q: develop.watch: action %q requires a build section", name, t.Action)
+			}
+			triggers = append(triggers, &watchTrigger{service: name, Trigger: t})
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if len(triggers) == 0 {
+		return errors.New("none of the selected services is configured for watch, consider setting a 'develop' section")
+	}
+
+	if !wo.NoUp {
+		if err := c.Up(ctx, UpOptions{Detach: true}, services); err != nil {
+			return err
+		}
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	defer watcher.Close()
+	for _, t := range triggers {
+		if err := addWatch(watcher, t); err != nil {
+			return err
+		}
+		if t.InitialSync && strings.HasPrefix(string(t.Action), "sync") {
+			if err := c.syncFiles(ctx, t, []string{t.Path}); err != nil {
+				log.G(ctx).WithError(err).Warnf("service %q: failed to sync %q", t.service, t.Path)
+			}
+		}
+		log.G(ctx).Infof("Watching %s (service %s, action %s)", t.Path, t.service, t.Action)
+	}
+
+	timer := time.NewTimer(watchDebounce)
+	timer.Stop()
+	pending := map[*watchTrigger]map[string]struct{}{}
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			log.G(ctx).WithError(err).Warn("error while watching files")
+		case ev, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			if ev.Has(fsnotify.Chmod) {
+				continue
+			}
+			if ev.Has(fsnotify.Create) {
+				if st, err := os.Stat(ev.Name); err == nil && st.IsDir() {
+					for _, t := range triggers {
+						if t.matches(ev.Name) {
+							if err := addWatchDir(watcher, t, ev.Name); err != nil {
+								log.G(ctx).WithError(err).Warnf("failed to watch %q", ev.Name)
+							}
+						}
+					}
+				}
+			}
+			for _, t := range triggers {
+				if !t.matches(ev.Name) {
+					continue
+				}
+				if pending[t] == nil {
+					pending[t] = map[string]struct{}{}
+				}
+				pending[t][ev.Name] = struct{}{}
+				timer.Reset(watchDebounce)
+			}
+		case <-timer.C:
+			c.applyTriggers(ctx, pending)
+			pending = map[*watchTrigger]map[string]struct{}{}
+		}
+	}
+}
+
+// addWatch watches the path of the trigger, recursively.
+func addWatch(watcher *fsnotify.Watcher, t *watchTrigger) error {
+	st, err := os.Stat(t.Path)
+	if err != nil {
+		return fmt.Errorf("service %q: develop.watch: %w", t.service, err)
+	}
+	if !st.IsDir() {
+		return watcher.Add(filepath.Dir(t.Path))
+	}
+	return addWatchDir(watcher, t, t.Path)
+}
+
+func addWatchDir(watcher *fsnotify.Watcher, t *watchTrigger, dir string) error {
+	return filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if p != t.Path && !t.matches(p) {
+			return filepath.SkipDir
+		}
+		return watcher.Add(p)
+	})
+}
+
+// matches returns whether the changed path p is in the path of the trigger, and is not ignored.
+func (t *watchTrigger) matches(p string) bool {
+	rel, err := filepath.Rel(t.Path, p)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return false
+	}
+	if rel == "." {
+		return true
+	}
+	rel = filepath.ToSlash(rel)
+	if len(t.Include) > 0 && !matchPatterns(t.Include, rel) {
+		// directories are traversed to find the included files
+		if st, err := os.Stat(p); err != nil || !st.IsDir() {
+			return false
+		}
+	}
+	return !matchPatterns(t.Ignore, rel)
+}
+
+// matchPatterns returns whether rel matches the patterns, the last matching pattern wins.
+// A pattern prefixed with "!" excludes the paths matched by the previous patterns.
+func matchPatterns(patterns []string, rel string) bool {
+	matched := false
+	for _, pattern := range patterns {
+		negative := strings.HasPrefix(pattern, "!")
+		if matchPattern(strings.TrimPrefix(pattern, "!"), rel) {
+			matched = !negative
+		}
+	}
+	return matched
+}
+
+// matchPattern matches a slash-separated relative path with a pattern like .dockerignore.
+// A pattern without a slash, e.g., "*.tmp" or "node_modules", matches a file or a directory at any depth.
+// A pattern with a slash is relative to the watched path, and "**" matches any number of directories.
+// The files in a matched directory are matched too.
+func matchPattern(pattern, rel string) bool {
+	pattern = strings.Trim(path.Clean("/"+filepath.ToSlash(pattern)), "/")
+	parts := strings.Split(rel, "/")
+	if !strings.Contains(pattern, "/") {
+		for _, part := range parts {
+			if ok, _ := path.Match(pattern, part); ok {
+				return true
+			}
+		}
+		return false
+	}
+	patternParts := strings.Split(pattern, "/")
+	for i := 1; i <= len(parts); i++ {
+		if matchSegments(patternParts, parts[:i]) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchSegments(pattern, parts []string) bool {
+	if len(pattern) == 0 {
+		return len(parts) == 0
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(parts); i++ {
+			if matchSegments(pattern[1:], parts[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(parts) == 0 {
+		return false
+	}
+	ok, _ := path.Match(pattern[0], parts[0])
+	return ok && matchSegments(pattern[1:], parts[1:])
+}
+
+// applyTriggers applies the actions of the triggers to the changed paths.
+// A service is rebuilt once, even if several of its rebuild triggers are fired.
+func (c *Composer) applyTriggers(ctx context.Context, pending map[*watchTrigger]map[string]struct{}) {
+	rebuild := map[string]struct{}{}
+	for t := range pending {
+		if t.Action == types.WatchActionRebuild {
+			rebuild[t.service] = struct{}{}
+		}
+	}
+	for service := range rebuild {
+		log.G(ctx).Infof("Rebuilding service %q after changes were detected", service)
+		if err := c.rebuildService(ctx, service); err != nil {
+			log.G(ctx).WithError(err).Errorf("service %q: failed to rebuild", service)
+		}
+	}
+	for t, set := range pending {
+		if _, ok := rebuild[t.service]; ok {
+			continue
+		}
+		paths := make([]string, 0, len(set))
+		for p := range set {
+			paths = append(paths, p)
+		}
+		sort.Strings(paths)
+		if err := c.applyTrigger(ctx, t, paths); err != nil {
+			log.G(ctx).WithError(err).Errorf("service %q: failed to apply the %q action", t.service, t.Action)
+		}
+	}
+}
+
+func (c *Composer) applyTrigger(ctx context.Context, t *watchTrigger, paths []string) error {
+	containers, err := c.Containers(ctx, t.service)
+	if err != nil {
+		return err
+	}
+	if len(containers) == 0 {
+		return fmt.Errorf("no container found for service %q", t.service)
+	}
+	if strings.HasPrefix(string(t.Action), "sync") {
+		if err := c.syncFiles(ctx, t, paths); err != nil {
+			return err
+		}
+	}
+	switch t.Action {
+	case types.WatchActionRestart, types.WatchActionSyncRestart:
+		return c.restartContainers(ctx, containers, RestartOptions{})
+	case types.WatchActionSyncExec:
+		for _, container := range containers {
+			if err := c.runNerdctlCmd(ctx, hookExecArgs(t.Exec, container)...); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// hookExecArgs returns the arguments of `nerdctl exec` to run the hook in the container.
+func hookExecArgs(hook types.ServiceHook, container containerd.Container) []string {
+	args := []string{"exec"}
+	if hook.User != "" {
+		args = append(args, "--user", hook.User)
+	}
+	if hook.Privileged {
+		args = append(args, "--privileged")
+	}
+	if hook.WorkingDir != "" {
+		args = append(args, "--workdir", hook.WorkingDir)
+	}
+	for _, k := range sortedMapKeys(hook.Environment) {
+		if v := hook.Environment[k]; v != nil {
+			args = append(args, "--env", k+"="+*v)
+		} else {
+			args = append(args, "--env", k)
+		}
+	}
+	args = append(args, container.ID())
+	return append(args, hook.Command...)
+}
+
+func sortedMapKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// syncFiles copies the changed paths into the containers of the service, and removes the deleted paths from them.
+func (c *Composer) syncFiles(ctx context.Context, t *watchTrigger, paths []string) error {
+	containers, err := c.Containers(ctx, t.service)
+	if err != nil {
+		return err
+	}
+	for _, p := range paths {
+		rel, err := filepath.Rel(t.Path, p)
+		if err != nil {
+			return err
+		}
+		target := path.Join(t.Target, filepath.ToSlash(rel))
+		st, statErr := os.Stat(p)
+		for _, container := range containers {
+			var err error
+			switch {
+			case errors.Is(statErr, fs.ErrNotExist):
+				log.G(ctx).Infof("Removing %s from service %s", target, t.service)
+				err = execInContainer(ctx, container, []string{"rm", "-rf", target})
+			case statErr != nil:
+				return statErr
+			case st.IsDir():
+				// copy the content of the directory, not the directory itself into an existing one
+				log.G(ctx).Infof("Syncing %s to %s of service %s", p, target, t.service)
+				err = c.copyToContainer(ctx, container, p+string(filepath.Separator)+".", target)
+			default:
+				log.G(ctx).Infof("Syncing %s to %s of service %s", p, target, t.service)
+				err = c.copyToContainer(ctx, container, p, target)
+			}
+			if err != nil {
+				return fmt.Errorf("service %q: container %s: %w", t.service, container.ID(), err)
+			}
+		}
+	}
+	return nil
+}
+
+// execInContainer runs args in the running task of the container, with the process spec of the container.
+func execInContainer(ctx context.Context, container containerd.Container, args []string) error {
+	spec, err := container.Spec(ctx)
+	if err != nil {
+		return err
+	}
+	task, err := container.Task(ctx, nil)
+	if err != nil {
+		return err
+	}
+	pspec := spec.Process
+	pspec.Terminal = false
+	pspec.Args = args
+
+	var out bytes.Buffer
+	process, err := task.Exec(ctx, "exec-"+idgen.GenerateID(), pspec, cio.NewCreator(cio.WithStreams(nil, &out, &out)))
+	if err != nil {
+		return err
+	}
+	defer process.Delete(ctx)
+	statusC, err := process.Wait(ctx)
+	if err != nil {
+		return err
+	}
+	if err := process.Start(ctx); err != nil {
+		return err
+	}
+	status := <-statusC
+	process.IO().Wait()
+	code, _, err := status.Result()
+	if err != nil {
+		return err
+	}
+	if code != 0 {
+		return fmt.Errorf("%v failed with exit code %d: %q", args, code, out.String())
+	}
+	return nil
+}
+
+// rebuildService builds the image of the service, and recreates its containers.
+func (c *Composer) rebuildService(ctx context.Context, service string) error {
+	var ps *serviceparser.Service
+	err := c.project.ForEachService([]string{service}, func(name string, svc *types.ServiceConfig) error {
+		var err error
+		ps, err = serviceparser.Parse(c.project, *svc)
+		return err
+	}, types.IgnoreDependencies)
+	if err != nil {
+		return err
+	}
+	if err := c.buildServiceImage(ctx, ps.Image, ps.Build, ps.Unparsed.Platform, BuildOptions{}); err != nil {
+		return err
+	}
+	return c.upServices(ctx, []*serviceparser.Service{ps}, UpOptions{Detach: true, NoBuild: true, ForceRecreate: true})
+}
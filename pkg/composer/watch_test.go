@@ -0,0 +1,49 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package composer
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestMatchPatterns(t *testing.T) {
+	testCases := []struct {
+		patterns []string
+		rel      string
+		expected bool
+	}{
+		{[]string{"*.tmp"}, "foo.tmp", true},
+		{[]string{"*.tmp"}, "dir/foo.tmp", true},
+		{[]string{"*.tmp"}, "foo.txt", false},
+		{[]string{"node_modules/"}, "node_modules/foo/bar.js", true},
+		{[]string{"node_modules"}, "app/node_modules", true},
+		{[]string{"build/out"}, "build/out/foo", true},
+		{[]string{"build/out"}, "src/build/out", false},
+		{[]string{"**/out"}, "src/build/out/foo", true},
+		{[]string{"src/**/*.go"}, "src/a/b/c.go", true},
+		{[]string{"src/**/*.go"}, "src/c.go", true},
+		{[]string{"src/**/*.go"}, "lib/c.go", false},
+		{[]string{"*.log", "!keep.log"}, "keep.log", false},
+		{[]string{"*.log", "!keep.log"}, "drop.log", true},
+		{nil, "foo", false},
+	}
+	for _, tc := range testCases {
+		assert.Equal(t, matchPatterns(tc.patterns, tc.rel), tc.expected, "patterns=%v rel=%q", tc.patterns, tc.rel)
+	}
+}
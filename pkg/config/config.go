@@ -48,6 +48,8 @@ type Config struct {
 	DNSSearch        []string `toml:"dns_search,omitempty"`
 	DisableHCSystemd bool     `toml:"disable_hc_systemd"`
 	SelinuxEnabled   bool     `toml:"selinux_enabled"`
+	EmbeddedDNS      bool     `toml:"embedded_dns"`
+	FirewallBackend  string   `toml:"firewall_backend"`
 }
 
 // New creates a default Config object statically,
@@ -75,5 +77,7 @@ func New() *Config {
 		DNSOpts:          []string{},
 		DNSSearch:        []string{},
 		DisableHCSystemd: false,
+		EmbeddedDNS:      false,
+		FirewallBackend:  "iptables",
 	}
 }
@@ -19,20 +19,25 @@ package containerutil
 import (
 	"context"
 	"errors"
+	"fmt"
 	"io/fs"
 	"path/filepath"
+	"slices"
 
 	containerd "github.com/containerd/containerd/v2/client"
 	"github.com/containerd/containerd/v2/pkg/oci"
+	"github.com/containerd/go-cni"
 	"github.com/containerd/log"
 
 	"github.com/containerd/nerdctl/v2/pkg/api/types"
 	"github.com/containerd/nerdctl/v2/pkg/clientutil"
 	"github.com/containerd/nerdctl/v2/pkg/dnsutil"
 	"github.com/containerd/nerdctl/v2/pkg/dnsutil/hostsstore"
+	"github.com/containerd/nerdctl/v2/pkg/labels"
 	"github.com/containerd/nerdctl/v2/pkg/netutil"
 	"github.com/containerd/nerdctl/v2/pkg/resolvconf"
 	"github.com/containerd/nerdctl/v2/pkg/rootlessutil"
+	"github.com/containerd/nerdctl/v2/pkg/strutil"
 )
 
 type cniNetworkManagerPlatform struct {
@@ -52,6 +57,22 @@ func (m *cniNetworkManager) VerifyNetworkOptions(_ context.Context) error {
 		}
 	}
 
+	if m.netOpts.Bandwidth != (cni.BandWidth{}) {
+		networks, err := verifyNetworkTypes(e, m.netOpts.NetworkSlice, []string{"bridge"})
+		if err != nil {
+			return err
+		}
+		for netstr, netw := range networks {
+			capable, limited := netw.BandwidthLimits()
+			if !capable {
+				return fmt.Errorf("network %q does not support the bandwidth limits of the containers, re-create it to add them", netstr)
+			}
+			if limited {
+				log.L.Warnf("the bandwidth limits of network %q take precedence over the ones of the container", netstr)
+			}
+		}
+	}
+
 	return validateUtsSettings(m.netOpts)
 }
 
@@ -93,8 +114,18 @@ func (m *cniNetworkManager) ContainerNetworkingOpts(_ context.Context, container
 		return nil, nil, err
 	}
 
+	embeddedDNSNetwork, embeddedDNSServer, err := m.embeddedDNS()
+	if err != nil {
+		return nil, nil, err
+	}
+	if embeddedDNSNetwork != "" {
+		cOpts = append(cOpts, containerd.WithAdditionalContainerLabels(map[string]string{
+			labels.EmbeddedDNS: embeddedDNSNetwork,
+		}))
+	}
+
 	resolvConfPath := filepath.Join(stateDir, "resolv.conf")
-	if err := m.buildResolvConf(resolvConfPath); err != nil {
+	if err := m.buildResolvConf(resolvConfPath, embeddedDNSServer); err != nil {
 		return nil, nil, err
 	}
 
@@ -137,10 +168,40 @@ func (m *cniNetworkManager) ContainerNetworkingOpts(_ context.Context, container
 	return opts, cOpts, nil
 }
 
-func (m *cniNetworkManager) buildResolvConf(resolvConfPath string) error {
+// embeddedDNS returns the first network of the container that can serve the embedded DNS,
+// and the address of its server, when the embedded DNS is enabled.
+// The embedded DNS is not used for the containers with their own DNS servers (`--dns`).
+func (m *cniNetworkManager) embeddedDNS() (string, string, error) {
+	if !m.globalOptions.EmbeddedDNS {
+		return "", "", nil
+	}
+	if len(m.netOpts.DNSServers) > 0 && !slices.Equal(m.netOpts.DNSServers, strutil.DedupeStrSlice(m.globalOptions.DNS)) {
+		return "", "", nil
+	}
+	e, err := netutil.NewCNIEnv(m.globalOptions.CNIPath, m.globalOptions.CNINetConfPath, netutil.WithNamespace(m.globalOptions.Namespace), netutil.WithDefaultNetwork(m.globalOptions.BridgeIP))
+	if err != nil {
+		return "", "", err
+	}
+	for _, netstr := range m.netOpts.NetworkSlice {
+		netw, err := e.NetworkByNameOrID(netstr)
+		if err != nil {
+			return "", "", err
+		}
+		if gateway := netw.BridgeGatewayIPv4(); gateway != nil {
+			return netw.Name, gateway.String(), nil
+		}
+	}
+	log.L.Debugf("none of the networks %v supports the embedded DNS", m.netOpts.NetworkSlice)
+	return "", "", nil
+}
+
+// buildResolvConf writes the resolv.conf of the container.
+// When embeddedDNSServer is set, it is the only nameserver, as it forwards the other queries to the
+// resolvers of the host.
+func (m *cniNetworkManager) buildResolvConf(resolvConfPath, embeddedDNSServer string) error {
 	var err error
 	slirp4Dns := []string{}
-	if rootlessutil.IsRootlessChild() {
+	if rootlessutil.IsRootlessChild() && embeddedDNSServer == "" {
 		slirp4Dns, err = dnsutil.GetSlirp4netnsDNS()
 		if err != nil {
 			return err
@@ -152,6 +213,9 @@ func (m *cniNetworkManager) buildResolvConf(resolvConfPath string) error {
 		searchDomains = m.netOpts.DNSSearchDomains
 		dnsOptions    = m.netOpts.DNSResolvConfOptions
 	)
+	if embeddedDNSServer != "" {
+		nameServers = []string{embeddedDNSServer}
+	}
 
 	// Use host defaults if any DNS settings are missing:
 	if len(nameServers) == 0 || len(searchDomains) == 0 || len(dnsOptions) == 0 {
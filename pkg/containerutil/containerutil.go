@@ -37,6 +37,7 @@ import (
 	"github.com/containerd/console"
 	containerd "github.com/containerd/containerd/v2/client"
 	"github.com/containerd/containerd/v2/core/containers"
+	"github.com/containerd/containerd/v2/core/events"
 	"github.com/containerd/containerd/v2/core/runtime/restart"
 	"github.com/containerd/containerd/v2/pkg/cio"
 	"github.com/containerd/containerd/v2/pkg/oci"
@@ -44,14 +45,17 @@ import (
 	"github.com/containerd/go-cni"
 	"github.com/containerd/log"
 
+	"github.com/containerd/nerdctl/v2/pkg/clientutil"
 	"github.com/containerd/nerdctl/v2/pkg/config"
 	"github.com/containerd/nerdctl/v2/pkg/consoleutil"
 	"github.com/containerd/nerdctl/v2/pkg/errutil"
+	"github.com/containerd/nerdctl/v2/pkg/eventutil"
 	"github.com/containerd/nerdctl/v2/pkg/formatter"
 	"github.com/containerd/nerdctl/v2/pkg/healthcheck"
 	"github.com/containerd/nerdctl/v2/pkg/ipcutil"
 	"github.com/containerd/nerdctl/v2/pkg/labels"
 	"github.com/containerd/nerdctl/v2/pkg/labels/k8slabels"
+	"github.com/containerd/nerdctl/v2/pkg/mountutil/volumestore"
 	"github.com/containerd/nerdctl/v2/pkg/rootlessutil"
 	"github.com/containerd/nerdctl/v2/pkg/signalutil"
 	"github.com/containerd/nerdctl/v2/pkg/strutil"
@@ -269,6 +273,13 @@ func Start(ctx context.Context, container containerd.Container, isAttach bool, i
 		// source: https://github.com/containerd/nerdctl/blob/main/docs/command-reference.md#whale-nerdctl-start
 		attachStreamOpt = []string{"STDOUT", "STDERR"}
 	}
+	dataStore, err := clientutil.DataStore(cfg.DataRoot, cfg.Address)
+	if err != nil {
+		return err
+	}
+	if err := volumestore.MountVolumes(dataStore, namespace, container.ID(), labels.GetVolumeNames(lab)); err != nil {
+		return err
+	}
 	task, err := taskutil.NewTask(ctx, client, container, taskutil.TaskOptions{
 		AttachStreamOpt: attachStreamOpt,
 		IsInteractive:   isInteractive,
@@ -282,6 +293,9 @@ func Start(ctx context.Context, container containerd.Container, isAttach bool, i
 		CheckpointDir:   checkpointDir,
 	})
 	if err != nil {
+		if uerr := volumestore.UnmountVolumes(dataStore, namespace, container.ID(), labels.GetVolumeNames(lab)); uerr != nil {
+			log.G(ctx).WithError(uerr).Warn("failed to unmount volumes")
+		}
 		return err
 	}
 	statusC, err := task.Wait(ctx)
@@ -289,6 +303,9 @@ func Start(ctx context.Context, container containerd.Container, isAttach bool, i
 		return err
 	}
 	if err := task.Start(ctx); err != nil {
+		if uerr := volumestore.UnmountVolumes(dataStore, namespace, container.ID(), labels.GetVolumeNames(lab)); uerr != nil {
+			log.G(ctx).WithError(uerr).Warn("failed to unmount volumes")
+		}
 		return err
 	}
 
@@ -348,7 +365,8 @@ func Start(ctx context.Context, container containerd.Container, isAttach bool, i
 }
 
 // Stop stops `container` by sending SIGTERM. If the container is not stopped after `timeout`, it sends a SIGKILL.
-func Stop(ctx context.Context, container containerd.Container, timeout *time.Duration, signalValue string) (err error) {
+// A kill event is published to publisher, if not nil, for each signal sent, as with Docker.
+func Stop(ctx context.Context, container containerd.Container, timeout *time.Duration, signalValue string, publisher events.Publisher) (err error) {
 	// defer the storage of stop error in the dedicated label
 	defer func() {
 		if err != nil {
@@ -439,6 +457,7 @@ func Stop(ctx context.Context, container containerd.Container, timeout *time.Dur
 			return err
 		}
 
+		publishKill(ctx, publisher, container.ID(), sig)
 		if err := task.Kill(ctx, sig); err != nil {
 			return err
 		}
@@ -461,6 +480,7 @@ func Stop(ctx context.Context, container containerd.Container, timeout *time.Dur
 		return err
 	}
 
+	publishKill(ctx, publisher, container.ID(), sig)
 	if err := task.Kill(ctx, sig); err != nil {
 		return err
 	}
@@ -468,6 +488,14 @@ func Stop(ctx context.Context, container containerd.Container, timeout *time.Dur
 	return waitContainerStop(ctx, task, exitCh, container.ID())
 }
 
+func publishKill(ctx context.Context, publisher events.Publisher, id string, sig syscall.Signal) {
+	if publisher != nil {
+		eventutil.Publish(ctx, publisher, eventutil.TopicContainerKill, id, map[string]string{
+			"signal": strconv.Itoa(int(sig)),
+		})
+	}
+}
+
 func getSignal(signalValue string, containerLabels map[string]string) (syscall.Signal, error) {
 	if signalValue != "" {
 		return signal.ParseSignal(signalValue)
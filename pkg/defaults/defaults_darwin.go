@@ -36,6 +36,10 @@ func CNIRuntimeDir() (string, error) {
 	return "/var/run/cni", nil
 }
 
+func ComposeRuntimeDir() (string, error) {
+	return "/var/run/nerdctl/compose", nil
+}
+
 func CNINetConfPath() string {
 	return gocni.DefaultNetDir
 }
@@ -43,6 +43,10 @@ func CNIRuntimeDir() (string, error) {
 	return "/var/run/cni", nil
 }
 
+func ComposeRuntimeDir() (string, error) {
+	return "/var/run/nerdctl/compose", nil
+}
+
 func CgroupManager() string {
 	return ""
 }
@@ -102,6 +102,22 @@ func CNIRuntimeDir() (string, error) {
 	return filepath.Join(xdr, "cni"), nil
 }
 
+// ComposeRuntimeDir returns the directory where compose materializes secrets and configs.
+// The directory is expected to be on tmpfs.
+func ComposeRuntimeDir() (string, error) {
+	if !rootlessutil.IsRootless() {
+		return "/run/nerdctl/compose", nil
+	}
+	xdr, err := rootlessutil.XDGRuntimeDir()
+	if err != nil {
+		if rootlessutil.IsRootlessChild() {
+			return "", err
+		}
+		xdr = fmt.Sprintf("/run/user/%d", os.Geteuid())
+	}
+	return filepath.Join(xdr, "nerdctl/compose"), nil
+}
+
 func NerdctlTOML() string {
 	if !rootlessutil.IsRootless() {
 		return "/etc/nerdctl/nerdctl.toml"
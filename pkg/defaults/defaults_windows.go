@@ -43,6 +43,10 @@ func CNIRuntimeDir() (string, error) {
 	return "", nil
 }
 
+func ComposeRuntimeDir() (string, error) {
+	return filepath.Join(os.TempDir(), "nerdctl", "compose"), nil
+}
+
 func IsSystemdAvailable() bool {
 	return false
 }
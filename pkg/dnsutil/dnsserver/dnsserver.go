@@ -0,0 +1,538 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+// Package dnsserver implements the embedded DNS server of bridge networks.
+//
+// A server runs per network, on the gateway address of the bridge, and is used as the nameserver of the
+// containers created with `--embedded-dns`. It answers the names of the containers that share a network with
+// the client (the same names as the ones written to /etc/hosts: hostnames, container names and aliases),
+// and forwards the other queries to the resolvers of the host.
+package dnsserver
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+
+	"github.com/containerd/log"
+
+	"github.com/containerd/nerdctl/v2/pkg/dnsutil/hostsstore"
+)
+
+// Command is the name of the hidden `nerdctl internal` subcommand that runs the server of a network.
+const Command = "dns-server"
+
+const (
+	// ttl is the TTL of the records of the containers, in seconds, the same as Docker.
+	ttl = 600
+	// maxUDPSize is the maximum size of a response sent over UDP to a client that does not use EDNS(0).
+	maxUDPSize = 512
+	// reloadInterval is how long the containers are cached before being read again from the hosts store.
+	reloadInterval = time.Second
+	// forwardTimeout is the timeout of a query forwarded to an upstream resolver.
+	forwardTimeout = 5 * time.Second
+	// tcpIdleTimeout is how long a TCP connection is kept open without receiving a query.
+	tcpIdleTimeout = 10 * time.Second
+)
+
+// Network is the network served by a server.
+type Network struct {
+	Name string
+	// ID is the nerdctl ID of the network, which may also be used to refer to the network.
+	ID string
+}
+
+func (n Network) is(key string) bool {
+	if key == n.Name {
+		return true
+	}
+	return n.ID != "" && (key == n.ID || (len(n.ID) > 12 && key == n.ID[:12]))
+}
+
+// containers maps the namespaces to the metadata of their running containers.
+type containers map[string][]*hostsstore.Meta
+
+// loadContainers reads the metadata of the running containers of all the namespaces from the hosts store.
+func loadContainers(dataStore string) (containers, error) {
+	namespaces, err := hostsstore.Namespaces(dataStore)
+	if err != nil {
+		return nil, err
+	}
+	c := make(containers, len(namespaces))
+	for _, ns := range namespaces {
+		hs, err := hostsstore.New(dataStore, ns)
+		if err != nil {
+			return nil, err
+		}
+		metas, err := hs.List()
+		if err != nil {
+			return nil, err
+		}
+		c[ns] = metas
+	}
+	return c, nil
+}
+
+// attached reports whether any running container is attached to the network.
+func (c containers) attached(network Network) bool {
+	for _, metas := range c {
+		for _, meta := range metas {
+			for key := range meta.Networks {
+				if network.is(key) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// client returns the namespace and the metadata of the container that has the address ip on the network.
+func (c containers) client(network Network, ip net.IP) (string, *hostsstore.Meta) {
+	for ns, metas := range c {
+		for _, meta := range metas {
+			for key, res := range meta.Networks {
+				if !network.is(key) || res == nil {
+					continue
+				}
+				for _, ipc := range res.IPs {
+					if ipc.Address.IP.Equal(ip) {
+						return ns, meta
+					}
+				}
+			}
+		}
+	}
+	return "", nil
+}
+
+// lookup returns the addresses of the containers named name on the networks of the client.
+// found is false when name is not the name of any of these containers.
+func (c containers) lookup(network Network, client net.IP, name string) (ips []net.IP, found bool) {
+	ns, self := c.client(network, client)
+	if self == nil {
+		return nil, false
+	}
+	name = strings.TrimSuffix(name, ".")
+	for _, meta := range c[ns] {
+		for key, res := range meta.Networks {
+			if _, ok := self.Networks[key]; !ok || res == nil {
+				continue
+			}
+			for _, n := range hostsstore.Names(key, meta) {
+				if !strings.EqualFold(n, name) {
+					continue
+				}
+				found = true
+				for _, ipc := range res.IPs {
+					if ip := ipc.Address.IP; ip != nil && !ip.IsLoopback() && !ip.IsUnspecified() {
+						ips = append(ips, ip)
+					}
+				}
+				break
+			}
+		}
+	}
+	return ips, found
+}
+
+// Server is the DNS server of a network.
+type Server struct {
+	network Network
+	load    func() (containers, error)
+
+	mu        sync.Mutex
+	cache     containers
+	loadedAt  time.Time
+	upstreams []string
+}
+
+// New returns the server of the network, resolving the containers recorded in the hosts store of dataStore
+// and forwarding the other queries to upstreams ("host:port").
+func New(dataStore string, network Network, upstreams []string) *Server {
+	return &Server{
+		network: network,
+		load: func() (containers, error) {
+			return loadContainers(dataStore)
+		},
+		upstreams: upstreams,
+	}
+}
+
+// SetUpstreams replaces the resolvers the queries are forwarded to.
+func (s *Server) SetUpstreams(upstreams []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.upstreams = upstreams
+}
+
+func (s *Server) getUpstreams() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.upstreams
+}
+
+// containers returns the running containers, reading them again if the cache is stale.
+func (s *Server) containers() containers {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.cache == nil || time.Since(s.loadedAt) > reloadInterval {
+		c, err := s.load()
+		if err != nil {
+			log.L.WithError(err).Warn("failed to read the running containers")
+		} else {
+			s.cache = c
+			s.loadedAt = time.Now()
+		}
+	}
+	return s.cache
+}
+
+// Attached reports whether any running container is attached to the network of the server.
+func (s *Server) Attached() (bool, error) {
+	c, err := s.load()
+	if err != nil {
+		return false, err
+	}
+	return c.attached(s.network), nil
+}
+
+// Serve answers the queries received on udp and tcp until ctx is done.
+func (s *Server) Serve(ctx context.Context, udp net.PacketConn, tcp net.Listener) error {
+	go func() {
+		<-ctx.Done()
+		udp.Close()
+		tcp.Close()
+	}()
+	errCh := make(chan error, 2)
+	go func() {
+		errCh <- s.serveUDP(ctx, udp)
+	}()
+	go func() {
+		errCh <- s.serveTCP(ctx, tcp)
+	}()
+	err := errors.Join(<-errCh, <-errCh)
+	if ctx.Err() != nil {
+		return nil
+	}
+	return err
+}
+
+func (s *Server) serveUDP(ctx context.Context, conn net.PacketConn) error {
+	buf := make([]byte, 65535)
+	for {
+		n, addr, err := conn.ReadFrom(buf)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		req := append([]byte{}, buf[:n]...)
+		go func() {
+			if resp := s.handle(ctx, "udp", req, addrIP(addr)); resp != nil {
+				if _, err := conn.WriteTo(resp, addr); err != nil {
+					log.L.WithError(err).Debugf("failed to answer %s", addr)
+				}
+			}
+		}()
+	}
+}
+
+func (s *Server) serveTCP(ctx context.Context, l net.Listener) error {
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		go func() {
+			defer conn.Close()
+			client := addrIP(conn.RemoteAddr())
+			for {
+				_ = conn.SetDeadline(time.Now().Add(tcpIdleTimeout))
+				req, err := readTCPMessage(conn)
+				if err != nil {
+					return
+				}
+				resp := s.handle(ctx, "tcp", req, client)
+				if resp == nil {
+					return
+				}
+				if err := writeTCPMessage(conn, resp); err != nil {
+					return
+				}
+			}
+		}()
+	}
+}
+
+func addrIP(addr net.Addr) net.IP {
+	switch a := addr.(type) {
+	case *net.UDPAddr:
+		return a.IP
+	case *net.TCPAddr:
+		return a.IP
+	}
+	return nil
+}
+
+func readTCPMessage(r io.Reader) ([]byte, error) {
+	var l uint16
+	if err := binary.Read(r, binary.BigEndian, &l); err != nil {
+		return nil, err
+	}
+	msg := make([]byte, l)
+	if _, err := io.ReadFull(r, msg); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+func writeTCPMessage(w io.Writer, msg []byte) error {
+	buf := make([]byte, 2+len(msg))
+	binary.BigEndian.PutUint16(buf, uint16(len(msg)))
+	copy(buf[2:], msg)
+	_, err := w.Write(buf)
+	return err
+}
+
+// handle returns the response to the query req received from client over proto ("udp" or "tcp"),
+// or nil if the query has to be dropped.
+func (s *Server) handle(ctx context.Context, proto string, req []byte, client net.IP) []byte {
+	resp, ok := s.answer(req, client, proto == "udp")
+	if ok {
+		return resp
+	}
+	resp, err := s.forward(ctx, proto, req)
+	if err != nil {
+		log.L.WithError(err).Debug("failed to forward the query")
+		return failure(req, dnsmessage.RCodeServerFailure)
+	}
+	return resp
+}
+
+// answer answers the queries for the names of the containers.
+// It returns false for the queries that have to be forwarded.
+func (s *Server) answer(req []byte, client net.IP, udp bool) ([]byte, bool) {
+	var p dnsmessage.Parser
+	h, err := p.Start(req)
+	if err != nil || h.Response || h.OpCode != 0 {
+		return nil, false
+	}
+	q, err := p.Question()
+	if err != nil || q.Class != dnsmessage.ClassINET {
+		return nil, false
+	}
+	ips, found := s.containers().lookup(s.network, client, q.Name.String())
+	if !found {
+		return nil, false
+	}
+
+	var answers []net.IP
+	for _, ip := range ips {
+		switch {
+		case q.Type == dnsmessage.TypeA && ip.To4() != nil:
+			answers = append(answers, ip.To4())
+		case q.Type == dnsmessage.TypeAAAA && ip.To4() == nil:
+			answers = append(answers, ip.To16())
+		}
+	}
+	// Round-robin across the replicas sharing a name
+	rand.Shuffle(len(answers), func(i, j int) {
+		answers[i], answers[j] = answers[j], answers[i]
+	})
+
+	maxSize := 0
+	if udp {
+		maxSize = maxUDPSize
+		if size := ednsSize(&p); size > maxSize {
+			maxSize = size
+		}
+	}
+	resp, err := buildResponse(h, q, answers, maxSize)
+	if err != nil {
+		log.L.WithError(err).Warnf("failed to build the response for %q", q.Name.String())
+		return failure(req, dnsmessage.RCodeServerFailure), true
+	}
+	return resp, true
+}
+
+// ednsSize returns the UDP payload size advertised by the EDNS(0) record of the query, if any.
+func ednsSize(p *dnsmessage.Parser) int {
+	if err := p.SkipAllQuestions(); err != nil {
+		return 0
+	}
+	if err := p.SkipAllAnswers(); err != nil {
+		return 0
+	}
+	if err := p.SkipAllAuthorities(); err != nil {
+		return 0
+	}
+	for {
+		h, err := p.AdditionalHeader()
+		if err != nil {
+			return 0
+		}
+		if h.Type == dnsmessage.TypeOPT {
+			return int(h.Class)
+		}
+		if err := p.SkipAdditional(); err != nil {
+			return 0
+		}
+	}
+}
+
+// buildResponse builds the answer to the question q with the addresses ips.
+// The response is truncated when it is larger than maxSize, unless maxSize is 0.
+func buildResponse(reqHeader dnsmessage.Header, q dnsmessage.Question, ips []net.IP, maxSize int) ([]byte, error) {
+	build := func(truncated bool) ([]byte, error) {
+		b := dnsmessage.NewBuilder(nil, dnsmessage.Header{
+			ID:                 reqHeader.ID,
+			Response:           true,
+			Authoritative:      true,
+			Truncated:          truncated,
+			RecursionDesired:   reqHeader.RecursionDesired,
+			RecursionAvailable: true,
+		})
+		b.EnableCompression()
+		if err := b.StartQuestions(); err != nil {
+			return nil, err
+		}
+		if err := b.Question(q); err != nil {
+			return nil, err
+		}
+		if truncated {
+			return b.Finish()
+		}
+		if err := b.StartAnswers(); err != nil {
+			return nil, err
+		}
+		rh := dnsmessage.ResourceHeader{Name: q.Name, Class: dnsmessage.ClassINET, TTL: ttl}
+		for _, ip := range ips {
+			var err error
+			if ip4 := ip.To4(); ip4 != nil {
+				err = b.AResource(rh, dnsmessage.AResource{A: [4]byte(ip4)})
+			} else {
+				err = b.AAAAResource(rh, dnsmessage.AAAAResource{AAAA: [16]byte(ip.To16())})
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+		return b.Finish()
+	}
+	resp, err := build(false)
+	if err != nil {
+		return nil, err
+	}
+	if maxSize > 0 && len(resp) > maxSize {
+		return build(true)
+	}
+	return resp, nil
+}
+
+// failure returns an empty response to req with the error code rcode.
+func failure(req []byte, rcode dnsmessage.RCode) []byte {
+	var p dnsmessage.Parser
+	h, err := p.Start(req)
+	if err != nil {
+		return nil
+	}
+	b := dnsmessage.NewBuilder(nil, dnsmessage.Header{
+		ID:                 h.ID,
+		Response:           true,
+		OpCode:             h.OpCode,
+		RecursionDesired:   h.RecursionDesired,
+		RecursionAvailable: true,
+		RCode:              rcode,
+	})
+	if err := b.StartQuestions(); err != nil {
+		return nil
+	}
+	if q, err := p.Question(); err == nil {
+		if err := b.Question(q); err != nil {
+			return nil
+		}
+	}
+	resp, err := b.Finish()
+	if err != nil {
+		return nil
+	}
+	return resp
+}
+
+// forward sends req to the upstream resolvers in turn over proto, and returns the first response.
+func (s *Server) forward(ctx context.Context, proto string, req []byte) ([]byte, error) {
+	upstreams := s.getUpstreams()
+	if len(upstreams) == 0 {
+		return nil, errors.New("no upstream resolver")
+	}
+	var errs []error
+	for _, upstream := range upstreams {
+		resp, err := exchange(ctx, proto, upstream, req)
+		if err == nil {
+			return resp, nil
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", upstream, err))
+	}
+	return nil, errors.Join(errs...)
+}
+
+func exchange(ctx context.Context, proto, upstream string, req []byte) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, forwardTimeout)
+	defer cancel()
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, proto, upstream)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+	if proto == "tcp" {
+		if err := writeTCPMessage(conn, req); err != nil {
+			return nil, err
+		}
+		return readTCPMessage(conn)
+	}
+	if _, err := conn.Write(req); err != nil {
+		return nil, err
+	}
+	buf := make([]byte, 65535)
+	for {
+		n, err := conn.Read(buf)
+		if err != nil {
+			return nil, err
+		}
+		// Ignore the datagrams that do not answer this query
+		if n >= 2 && len(req) >= 2 && buf[0] == req[0] && buf[1] == req[1] {
+			return buf[:n], nil
+		}
+	}
+}
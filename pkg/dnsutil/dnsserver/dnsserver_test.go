@@ -0,0 +1,233 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package dnsserver
+
+import (
+	"context"
+	"net"
+	"sort"
+	"strings"
+	"testing"
+
+	types100 "github.com/containernetworking/cni/pkg/types/100"
+	"golang.org/x/net/dns/dnsmessage"
+	"gotest.tools/v3/assert"
+
+	"github.com/containerd/nerdctl/v2/pkg/dnsutil/hostsstore"
+)
+
+func newMeta(id, name, hostname string, ips map[string]string, aliases map[string][]string) *hostsstore.Meta {
+	meta := &hostsstore.Meta{
+		ID:       id,
+		Name:     name,
+		Hostname: hostname,
+		Networks: map[string]*types100.Result{},
+		Aliases:  aliases,
+	}
+	for network, ip := range ips {
+		meta.Networks[network] = &types100.Result{
+			IPs: []*types100.IPConfig{{Address: net.IPNet{IP: net.ParseIP(ip)}}},
+		}
+	}
+	return meta
+}
+
+func testServer(t *testing.T, c containers, upstreams ...string) *Server {
+	t.Helper()
+	return &Server{
+		network: Network{Name: "n1", ID: "0123456789abcdef"},
+		load: func() (containers, error) {
+			return c, nil
+		},
+		upstreams: upstreams,
+	}
+}
+
+func testContainers() containers {
+	return containers{
+		"default": {
+			newMeta("c1", "client", "client", map[string]string{"n1": "10.4.1.2"}, nil),
+			newMeta("w1", "web-1", "web", map[string]string{"n1": "10.4.1.3"}, nil),
+			newMeta("w2", "web-2", "web", map[string]string{"n1": "10.4.1.4"}, map[string][]string{"n1": {"frontend"}}),
+			newMeta("d1", "db", "db", map[string]string{"n2": "10.4.2.2"}, nil),
+			newMeta("b1", "both", "both", map[string]string{"0123456789ab": "10.4.1.5", "n2": "10.4.2.3"}, nil),
+		},
+		"other": {
+			newMeta("o1", "other", "other", map[string]string{"n1": "10.4.1.6"}, nil),
+		},
+	}
+}
+
+func TestLookup(t *testing.T) {
+	c := testContainers()
+	network := Network{Name: "n1", ID: "0123456789abcdef"}
+	client := net.ParseIP("10.4.1.2")
+
+	type testCase struct {
+		name     string
+		expected []string
+		found    bool
+	}
+	testCases := []testCase{
+		{name: "web", expected: []string{"10.4.1.3", "10.4.1.4"}, found: true},
+		{name: "WEB.", expected: []string{"10.4.1.3", "10.4.1.4"}, found: true},
+		{name: "web-1.n1", expected: []string{"10.4.1.3"}, found: true},
+		{name: "frontend", expected: []string{"10.4.1.4"}, found: true},
+		// not on a network of the client
+		{name: "db", found: false},
+		// the client refers to the network by name, the container by short ID
+		{name: "both", found: false},
+		// in another namespace
+		{name: "other", found: false},
+		{name: "example.com", found: false},
+	}
+	for _, tc := range testCases {
+		ips, found := c.lookup(network, client, tc.name)
+		assert.Equal(t, tc.found, found, tc.name)
+		var actual []string
+		for _, ip := range ips {
+			actual = append(actual, ip.String())
+		}
+		sort.Strings(actual)
+		assert.DeepEqual(t, tc.expected, actual)
+	}
+
+	// a client attached to the network by its ID
+	ips, found := c.lookup(network, net.ParseIP("10.4.1.5"), "db")
+	assert.Assert(t, found)
+	assert.Equal(t, "10.4.2.2", ips[0].String())
+
+	// unknown clients only get forwarded queries
+	_, found = c.lookup(network, net.ParseIP("10.4.1.100"), "web")
+	assert.Assert(t, !found)
+
+	assert.Assert(t, c.attached(network))
+	assert.Assert(t, !c.attached(Network{Name: "n3"}))
+}
+
+func query(t *testing.T, name string, typ dnsmessage.Type) []byte {
+	t.Helper()
+	b := dnsmessage.NewBuilder(nil, dnsmessage.Header{ID: 42, RecursionDesired: true})
+	assert.NilError(t, b.StartQuestions())
+	assert.NilError(t, b.Question(dnsmessage.Question{
+		Name:  dnsmessage.MustNewName(name),
+		Type:  typ,
+		Class: dnsmessage.ClassINET,
+	}))
+	req, err := b.Finish()
+	assert.NilError(t, err)
+	return req
+}
+
+func parse(t *testing.T, resp []byte) dnsmessage.Message {
+	t.Helper()
+	var msg dnsmessage.Message
+	assert.NilError(t, msg.Unpack(resp))
+	assert.Equal(t, uint16(42), msg.ID)
+	assert.Assert(t, msg.Response)
+	return msg
+}
+
+func TestAnswer(t *testing.T) {
+	s := testServer(t, testContainers())
+	client := net.ParseIP("10.4.1.2")
+
+	resp, ok := s.answer(query(t, "web.", dnsmessage.TypeA), client, true)
+	assert.Assert(t, ok)
+	msg := parse(t, resp)
+	assert.Equal(t, dnsmessage.RCodeSuccess, msg.RCode)
+	assert.Equal(t, 2, len(msg.Answers))
+	for _, answer := range msg.Answers {
+		assert.Equal(t, uint32(ttl), answer.Header.TTL)
+	}
+
+	// the name exists, but not with this type
+	resp, ok = s.answer(query(t, "web.", dnsmessage.TypeAAAA), client, true)
+	assert.Assert(t, ok)
+	msg = parse(t, resp)
+	assert.Equal(t, dnsmessage.RCodeSuccess, msg.RCode)
+	assert.Equal(t, 0, len(msg.Answers))
+
+	_, ok = s.answer(query(t, "example.com.", dnsmessage.TypeA), client, true)
+	assert.Assert(t, !ok)
+}
+
+func TestAnswerRoundRobin(t *testing.T) {
+	s := testServer(t, testContainers())
+	client := net.ParseIP("10.4.1.2")
+	first := map[string]bool{}
+	for range 100 {
+		resp, ok := s.answer(query(t, "web.", dnsmessage.TypeA), client, true)
+		assert.Assert(t, ok)
+		msg := parse(t, resp)
+		first[net.IP(msg.Answers[0].Body.(*dnsmessage.AResource).A[:]).String()] = true
+	}
+	assert.Equal(t, 2, len(first))
+}
+
+func TestAnswerTruncated(t *testing.T) {
+	c := testContainers()
+	for i := range 50 {
+		ip := net.IPv4(10, 4, 1, byte(10+i)).String()
+		c["default"] = append(c["default"], newMeta("r"+ip, "", "replica", map[string]string{"n1": ip}, nil))
+	}
+	s := testServer(t, c)
+	client := net.ParseIP("10.4.1.2")
+
+	resp, ok := s.answer(query(t, "replica.", dnsmessage.TypeA), client, true)
+	assert.Assert(t, ok)
+	assert.Assert(t, len(resp) <= maxUDPSize)
+	msg := parse(t, resp)
+	assert.Assert(t, msg.Truncated)
+	assert.Equal(t, 0, len(msg.Answers))
+
+	resp, ok = s.answer(query(t, "replica.", dnsmessage.TypeA), client, false)
+	assert.Assert(t, ok)
+	msg = parse(t, resp)
+	assert.Assert(t, !msg.Truncated)
+	assert.Equal(t, 50, len(msg.Answers))
+}
+
+func TestForward(t *testing.T) {
+	upstream, err := net.ListenPacket("udp4", "127.0.0.1:0")
+	assert.NilError(t, err)
+	defer upstream.Close()
+	go func() {
+		buf := make([]byte, 65535)
+		for {
+			n, addr, err := upstream.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			if resp := failure(buf[:n], dnsmessage.RCodeNameError); resp != nil {
+				_, _ = upstream.WriteTo(resp, addr)
+			}
+		}
+	}()
+
+	s := testServer(t, testContainers(), upstream.LocalAddr().String())
+	resp := s.handle(context.Background(), "udp", query(t, "example.com.", dnsmessage.TypeA), net.ParseIP("10.4.1.2"))
+	msg := parse(t, resp)
+	assert.Equal(t, dnsmessage.RCodeNameError, msg.RCode)
+	assert.Assert(t, strings.HasPrefix(msg.Questions[0].Name.String(), "example.com"))
+
+	// no upstream
+	s = testServer(t, testContainers())
+	resp = s.handle(context.Background(), "udp", query(t, "example.com.", dnsmessage.TypeA), net.ParseIP("10.4.1.2"))
+	msg = parse(t, resp)
+	assert.Equal(t, dnsmessage.RCodeServerFailure, msg.RCode)
+}
@@ -0,0 +1,243 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package dnsserver
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/containerd/log"
+
+	"github.com/containerd/nerdctl/v2/pkg/dnsutil"
+	"github.com/containerd/nerdctl/v2/pkg/internal/filesystem"
+	"github.com/containerd/nerdctl/v2/pkg/resolvconf"
+	"github.com/containerd/nerdctl/v2/pkg/rootlessutil"
+)
+
+const (
+	// stateDirBasename is the base name of <DATASTORE>/dns, which holds a <NETWORK> directory per server
+	// with its pid file and its log. The directory is also locked while a server is started or stopped.
+	stateDirBasename = "dns"
+	pidFileName      = "dns-server.pid"
+	logFileName      = "dns-server.log"
+	// watchInterval is how often the server checks whether containers are still attached to the network,
+	// and reloads the upstream resolvers.
+	watchInterval = 5 * time.Second
+	// the sockets passed from Ensure to the server, after stdin, stdout and stderr
+	udpFD = 3
+	tcpFD = 4
+)
+
+func stateDir(dataStore, network string) string {
+	return filepath.Join(dataStore, stateDirBasename, network)
+}
+
+// Ensure starts the server of the network on the gateway address, unless it is already running.
+// The server runs as a detached `nerdctl internal dns-server` process, which exits on its own
+// once no running container is attached to the network anymore.
+func Ensure(dataStore, network string, gateway net.IP, nerdctlCmd string, nerdctlArgs []string) error {
+	dir := stateDir(dataStore, network)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return err
+	}
+	return filesystem.WithLock(dir, func() error {
+		if isRunning(dir, network) {
+			return nil
+		}
+
+		// Bind the sockets here, so that errors (like the port being in use) are reported to the caller
+		addr := net.JoinHostPort(gateway.String(), "53")
+		udp, err := net.ListenPacket("udp4", addr)
+		if err != nil {
+			return err
+		}
+		defer udp.Close()
+		tcp, err := net.Listen("tcp4", addr)
+		if err != nil {
+			return err
+		}
+		defer tcp.Close()
+		udpFile, err := udp.(*net.UDPConn).File()
+		if err != nil {
+			return err
+		}
+		defer udpFile.Close()
+		tcpFile, err := tcp.(*net.TCPListener).File()
+		if err != nil {
+			return err
+		}
+		defer tcpFile.Close()
+
+		logFile, err := os.OpenFile(filepath.Join(dir, logFileName), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+		if err != nil {
+			return err
+		}
+		defer logFile.Close()
+
+		args := append([]string{}, nerdctlArgs...)
+		args = append(args, "internal", Command, network)
+		log.L.Debugf("starting the DNS server of network %q on %s: %s %s", network, addr, nerdctlCmd, strings.Join(args, " "))
+		cmd := exec.Command(nerdctlCmd, args...)
+		cmd.ExtraFiles = []*os.File{udpFile, tcpFile}
+		cmd.Stdout = logFile
+		cmd.Stderr = logFile
+		// Detach from the session of the caller, as the server outlives it
+		cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+		if err := cmd.Start(); err != nil {
+			return fmt.Errorf("failed to start the DNS server: %w", err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, pidFileName), []byte(strconv.Itoa(cmd.Process.Pid)), 0o600); err != nil {
+			_ = cmd.Process.Kill()
+			_, _ = cmd.Process.Wait()
+			return fmt.Errorf("failed to write the DNS server pid file: %w", err)
+		}
+		return cmd.Process.Release()
+	})
+}
+
+// isRunning checks whether the pid file in dir refers to the running server of the network.
+func isRunning(dir, network string) bool {
+	b, err := os.ReadFile(filepath.Join(dir, pidFileName))
+	if err != nil {
+		return false
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(b)))
+	if err != nil || pid <= 0 {
+		return false
+	}
+	// The PID may have been recycled since the server exited
+	cmdline, err := os.ReadFile(filepath.Join("/proc", strconv.Itoa(pid), "cmdline"))
+	if err != nil {
+		return false
+	}
+	args := bytes.Split(bytes.TrimRight(cmdline, "\x00"), []byte{0})
+	if len(args) < 2 {
+		return false
+	}
+	return string(args[len(args)-2]) == Command && string(args[len(args)-1]) == network
+}
+
+// Run runs the server of the network started by Ensure, on the sockets it inherited.
+// It returns when no running container is attached to the network anymore, or when ctx is done.
+// globalDNS, if set, replaces the resolvers of the host as the upstream resolvers.
+func Run(ctx context.Context, dataStore string, network Network, globalDNS []string) (err error) {
+	udp, err := net.FilePacketConn(os.NewFile(udpFD, "udp"))
+	if err != nil {
+		return fmt.Errorf("failed to get the UDP socket: %w", err)
+	}
+	tcp, err := net.FileListener(os.NewFile(tcpFD, "tcp"))
+	if err != nil {
+		udp.Close()
+		return fmt.Errorf("failed to get the TCP socket: %w", err)
+	}
+	gateway := addrIP(udp.LocalAddr())
+	dir := stateDir(dataStore, network.Name)
+
+	s := New(dataStore, network, upstreams(globalDNS, gateway))
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- s.Serve(ctx, udp, tcp)
+	}()
+	log.L.Infof("serving DNS for network %q on %s", network.Name, udp.LocalAddr())
+
+	// stop stops serving and removes the pid file, under the lock so that Ensure does not
+	// mistake the server for running in the meantime.
+	stop := func(force bool) (bool, error) {
+		stopped := false
+		err := filesystem.WithLock(dir, func() error {
+			if !force {
+				if attached, err := s.Attached(); err != nil || attached {
+					return err
+				}
+			}
+			cancel()
+			stopped = true
+			return errors.Join(<-errCh, os.Remove(filepath.Join(dir, pidFileName)))
+		})
+		return stopped, err
+	}
+
+	ticker := time.NewTicker(watchInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			_, err := stop(true)
+			return err
+		case err := <-errCh:
+			_ = os.Remove(filepath.Join(dir, pidFileName))
+			return err
+		case <-ticker.C:
+			s.SetUpstreams(upstreams(globalDNS, gateway))
+			stopped, err := stop(false)
+			if err != nil {
+				log.L.WithError(err).Warn("failed to check the containers attached to the network")
+			}
+			if stopped {
+				log.L.Infof("no container is attached to network %q anymore, exiting", network.Name)
+				return err
+			}
+		}
+	}
+}
+
+// upstreams returns the addresses of the resolvers the queries that are not for containers are forwarded to.
+func upstreams(globalDNS []string, gateway net.IP) []string {
+	nameservers := globalDNS
+	if len(nameservers) == 0 {
+		if rootlessutil.IsRootlessChild() {
+			dns, err := dnsutil.GetSlirp4netnsDNS()
+			if err != nil {
+				log.L.WithError(err).Warn("failed to get the DNS server of the rootless network")
+			}
+			nameservers = dns
+		} else {
+			conf, err := resolvconf.Get()
+			if err != nil {
+				if !errors.Is(err, os.ErrNotExist) {
+					log.L.WithError(err).Warn("failed to read resolv.conf")
+				}
+				conf = &resolvconf.File{}
+			}
+			// The server runs in the network namespace of the host, so local resolvers can be reached
+			if conf, err = resolvconf.FilterResolvDNSWithLocalhostOption(conf.Content, true, true); err == nil {
+				nameservers = resolvconf.GetNameservers(conf.Content, resolvconf.IP)
+			}
+		}
+	}
+	var res []string
+	for _, ns := range nameservers {
+		ip := net.ParseIP(ns)
+		if ip == nil || ip.Equal(gateway) {
+			continue
+		}
+		res = append(res, net.JoinHostPort(ip.String(), "53"))
+	}
+	return res
+}
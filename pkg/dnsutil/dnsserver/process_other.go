@@ -0,0 +1,35 @@
+//go:build !linux
+
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package dnsserver
+
+import (
+	"context"
+	"errors"
+	"net"
+)
+
+// Ensure is only supported on Linux.
+func Ensure(dataStore, network string, gateway net.IP, nerdctlCmd string, nerdctlArgs []string) error {
+	return errors.New("the embedded DNS server is only supported on Linux")
+}
+
+// Run is only supported on Linux.
+func Run(ctx context.Context, dataStore string, network Network, globalDNS []string) error {
+	return errors.New("the embedded DNS server is only supported on Linux")
+}
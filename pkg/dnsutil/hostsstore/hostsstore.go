@@ -77,6 +77,24 @@ func New(dataStore string, namespace string) (retStore Store, err error) {
 	}, nil
 }
 
+// Namespaces returns the namespaces that have a hosts store in dataStore.
+func Namespaces(dataStore string) ([]string, error) {
+	entries, err := os.ReadDir(filepath.Join(dataStore, hostsDirBasename))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, errors.Join(ErrHostsStore, err)
+	}
+	var namespaces []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			namespaces = append(namespaces, entry.Name())
+		}
+	}
+	return namespaces, nil
+}
+
 type Meta struct {
 	ID         string
 	Networks   map[string]*types100.Result
@@ -84,15 +102,23 @@ type Meta struct {
 	ExtraHosts map[string]string // host:ip
 	Name       string
 	Domainname string
+	// Aliases are the network-scoped aliases of the container, keyed by network name
+	Aliases map[string][]string `json:",omitempty"`
+	// EmbeddedDNS is set when the container resolves the other containers through the embedded DNS server.
+	// The hosts file of such a container only holds its own entries.
+	EmbeddedDNS bool `json:",omitempty"`
 }
 
 type Store interface {
 	Acquire(Meta) error
 	Release(id string) error
 	Update(id, newName string) error
+	ConnectNetwork(id, netName string, result *types100.Result, aliases []string) error
+	DisconnectNetwork(id, netName string) error
 	HostsPath(id string) (location string, err error)
 	Delete(id string) (err error)
 	AllocHostsFile(id string, content []byte) (location string, err error)
+	List() ([]*Meta, error)
 }
 
 type hostsStore struct {
@@ -217,6 +243,37 @@ func (x *hostsStore) Delete(id string) (err error) {
 	return err
 }
 
+// List returns the metadata of all the running containers.
+func (x *hostsStore) List() (metas []*Meta, err error) {
+	defer func() {
+		if err != nil {
+			err = errors.Join(ErrHostsStore, err)
+		}
+	}()
+
+	err = x.safeStore.WithLock(func() error {
+		entries, err := x.safeStore.List()
+		if err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			content, err := x.safeStore.Get(entry, metaJSON)
+			if err != nil {
+				// Stopped containers only retain their hosts file
+				continue
+			}
+			meta := &Meta{}
+			if err = json.Unmarshal(content, meta); err != nil {
+				log.L.WithError(err).Warnf("unable to unmarshal %q", entry)
+				continue
+			}
+			metas = append(metas, meta)
+		}
+		return nil
+	})
+	return metas, err
+}
+
 func (x *hostsStore) HostsPath(id string) (location string, err error) {
 	defer func() {
 		if err != nil {
@@ -259,6 +316,68 @@ func (x *hostsStore) Update(id, newName string) (err error) {
 	})
 }
 
+// ConnectNetwork adds a network to the entries of a running container.
+func (x *hostsStore) ConnectNetwork(id, netName string, result *types100.Result, aliases []string) (err error) {
+	defer func() {
+		if err != nil {
+			err = errors.Join(ErrHostsStore, err)
+		}
+	}()
+
+	return x.updateMeta(id, func(meta *Meta) {
+		if meta.Networks == nil {
+			meta.Networks = make(map[string]*types100.Result)
+		}
+		meta.Networks[netName] = result
+		if len(aliases) > 0 {
+			if meta.Aliases == nil {
+				meta.Aliases = make(map[string][]string)
+			}
+			meta.Aliases[netName] = aliases
+		}
+	})
+}
+
+// DisconnectNetwork removes a network from the entries of a running container.
+func (x *hostsStore) DisconnectNetwork(id, netName string) (err error) {
+	defer func() {
+		if err != nil {
+			err = errors.Join(ErrHostsStore, err)
+		}
+	}()
+
+	return x.updateMeta(id, func(meta *Meta) {
+		delete(meta.Networks, netName)
+		delete(meta.Aliases, netName)
+	})
+}
+
+func (x *hostsStore) updateMeta(id string, fun func(meta *Meta)) error {
+	return x.safeStore.WithLock(func() error {
+		content, err := x.safeStore.Get(id, metaJSON)
+		if err != nil {
+			return err
+		}
+
+		meta := &Meta{}
+		if err = json.Unmarshal(content, meta); err != nil {
+			return err
+		}
+
+		fun(meta)
+		content, err = json.Marshal(meta)
+		if err != nil {
+			return err
+		}
+
+		if err = x.safeStore.Set(content, id, metaJSON); err != nil {
+			return err
+		}
+
+		return x.updateAllHosts()
+	})
+}
+
 func (x *hostsStore) updateAllHosts() (err error) {
 	entries, err := x.safeStore.List()
 	if err != nil {
@@ -339,6 +458,10 @@ func (x *hostsStore) updateAllHosts() (err error) {
 
 		for ip, netName := range networkNameByIP {
 			meta := metasByIP[ip]
+			if myMeta.EmbeddedDNS && meta.ID != myMeta.ID {
+				// The other containers are resolved by the embedded DNS server
+				continue
+			}
 			if line := createLine(netName, meta, myNetworks); len(line) != 0 {
 				buf.WriteString(fmt.Sprintf("%-15s %s\n", ip, strings.Join(line, " ")))
 			}
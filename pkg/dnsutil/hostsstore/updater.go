@@ -20,6 +20,12 @@ import (
 	"github.com/containerd/nerdctl/v2/pkg/netutil"
 )
 
+// Names returns the names that resolve to the address of the container on the network,
+// in the same order as in the hosts files.
+func Names(netName string, meta *Meta) []string {
+	return createLine(netName, meta, map[string]struct{}{netName: {}})
+}
+
 // createLine returns a line string slice.
 // line is like "bar bar.nw0 foo foo.nw0\n"
 // for `nerdctl --name=foo --hostname=bar --network=nw0`.
@@ -27,6 +33,8 @@ import (
 // line is line "bar.example.com bar bar.nw0 foo foo.nw0\n"
 // for  `nerdctl --name=foo --hostname=bar --domainname=example.com --network=n0`.
 //
+// Aliases of the container on the network are appended to the line.
+//
 // May return an empty string slice
 func createLine(thatNetwork string, meta *Meta, myNetworks map[string]struct{}) []string {
 	line := []string{}
@@ -52,5 +60,7 @@ func createLine(thatNetwork string, meta *Meta, myNetworks map[string]struct{})
 			line = append(line, baseHostname+"."+thatNetwork)
 		}
 	}
+	// aliases are only resolvable from the network they were set on
+	line = append(line, meta.Aliases[thatNetwork]...)
 	return line
 }
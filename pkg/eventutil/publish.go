@@ -0,0 +1,57 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package eventutil
+
+import (
+	"context"
+
+	"github.com/containerd/containerd/v2/core/events"
+	"github.com/containerd/log"
+	"github.com/containerd/typeurl/v2"
+)
+
+// Topics of the events published by nerdctl, for the actions that containerd does not know about.
+const (
+	TopicContainerKill            = "/nerdctl/containers/kill"
+	TopicContainerStop            = "/nerdctl/containers/stop"
+	TopicContainerHealthOnFailure = "/nerdctl/containers/health-on-failure"
+	TopicVolumeCreate             = "/nerdctl/volumes/create"
+	TopicVolumeDelete             = "/nerdctl/volumes/delete"
+	TopicNetworkCreate            = "/nerdctl/networks/create"
+	TopicNetworkDelete            = "/nerdctl/networks/delete"
+	TopicNetworkConnect           = "/nerdctl/networks/connect"
+	TopicNetworkDisconnect        = "/nerdctl/networks/disconnect"
+)
+
+// Event is the payload of the events published by nerdctl.
+type Event struct {
+	// ID is the ID of a container, or the name of a volume or a network.
+	ID         string            `json:"id"`
+	Attributes map[string]string `json:"attributes,omitempty"`
+}
+
+func init() {
+	typeurl.Register(&Event{}, "nerdctl", "events", "Event")
+}
+
+// Publish publishes an event to containerd, so that it is received by `nerdctl events`.
+// Events are informative, so a failure to publish is only logged.
+func Publish(ctx context.Context, publisher events.Publisher, topic, id string, attributes map[string]string) {
+	if err := publisher.Publish(ctx, topic, &Event{ID: id, Attributes: attributes}); err != nil {
+		log.G(ctx).WithError(err).Debugf("failed to publish event %q for %q", topic, id)
+	}
+}
@@ -32,35 +32,38 @@ import (
 	"github.com/containerd/nerdctl/v2/pkg/idgen"
 )
 
-// ExecuteHealthCheck executes the health check command for a container
-func ExecuteHealthCheck(ctx context.Context, task containerd.Task, container containerd.Container, hc *Healthcheck) error {
+// ExecuteHealthCheck executes the health check command for a container.
+// It reports whether the container has just become unhealthy. In that case, the onFailure action
+// (see --health-on-failure) is recorded in the health log, and it is up to the caller to carry it out.
+func ExecuteHealthCheck(ctx context.Context, task containerd.Task, container containerd.Container, hc *Healthcheck, onFailure string) (bool, error) {
 	// Prepare process spec for health check command
 	processSpec, err := prepareProcessSpec(ctx, container, hc)
 	if err != nil {
-		return err
+		return false, err
 	}
 	if processSpec == nil {
-		return nil
+		return false, nil
 	}
 
 	startTime := time.Now()
 	result, err := probeHealthCheck(ctx, task, hc, processSpec)
 	if err != nil {
-		_ = updateHealthStatus(ctx, container, hc, &HealthcheckResult{
+		becameUnhealthy, _ := updateHealthStatus(ctx, container, hc, &HealthcheckResult{
 			Start:    startTime,
 			End:      time.Now(),
 			ExitCode: -1,
 			Output:   err.Error(),
-		})
-		return fmt.Errorf("health check probe failed: %w", err)
+		}, onFailure)
+		return becameUnhealthy, fmt.Errorf("health check probe failed: %w", err)
 	}
 
 	// Success case, update health status
 	result.Start = startTime
-	if err := updateHealthStatus(ctx, container, hc, result); err != nil {
-		return fmt.Errorf("failed to update health status: %w", err)
+	becameUnhealthy, err := updateHealthStatus(ctx, container, hc, result, onFailure)
+	if err != nil {
+		return becameUnhealthy, fmt.Errorf("failed to update health status: %w", err)
 	}
-	return nil
+	return becameUnhealthy, nil
 }
 
 // probeHealthCheck executes the health check command inside the container context
@@ -122,12 +125,13 @@ func probeHealthCheck(ctx context.Context, task containerd.Task, hc *Healthcheck
 	}
 }
 
-// updateHealthStatus updates the health status based on the health check result
-func updateHealthStatus(ctx context.Context, container containerd.Container, hcConfig *Healthcheck, hcResult *HealthcheckResult) error {
+// updateHealthStatus updates the health status based on the health check result.
+// It reports whether the container has transitioned to unhealthy.
+func updateHealthStatus(ctx context.Context, container containerd.Container, hcConfig *Healthcheck, hcResult *HealthcheckResult, onFailure string) (bool, error) {
 	// Get current health state from labels
 	currentHealth, err := readHealthStateFromLabels(ctx, container)
 	if err != nil {
-		return fmt.Errorf("failed to read health state from labels: %w", err)
+		return false, fmt.Errorf("failed to read health state from labels: %w", err)
 	}
 	if currentHealth == nil {
 		// Determine if we should start in the start period workflow
@@ -142,13 +146,14 @@ func updateHealthStatus(ctx context.Context, container containerd.Container, hcC
 	// Get container info for start period check
 	info, err := container.Info(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to get container info: %w", err)
+		return false, fmt.Errorf("failed to get container info: %w", err)
 	}
 	containerCreated := info.CreatedAt
 
 	// Check if we're in start period workflow
 	inStartPeriodTime := hcResult.Start.Sub(containerCreated) < hcConfig.StartPeriod
 	inStartPeriodState := currentHealth.InStartPeriod
+	becameUnhealthy := false
 
 	if inStartPeriodTime && inStartPeriodState {
 		// Start Period Workflow
@@ -170,20 +175,76 @@ func updateHealthStatus(ctx context.Context, container containerd.Container, hcC
 			currentHealth.FailingStreak++
 			if currentHealth.FailingStreak >= hcConfig.Retries && currentHealth.Status != Unhealthy {
 				currentHealth.Status = Unhealthy
+				becameUnhealthy = true
 			}
 		}
 	}
 
+	currentHealth.LastProbe = hcResult.End
+
 	// Write updated health state back to labels
 	if err := writeHealthStateToLabels(ctx, container, currentHealth); err != nil {
-		return fmt.Errorf("failed to write health state to labels: %w", err)
+		return false, fmt.Errorf("failed to write health state to labels: %w", err)
+	}
+
+	// Record the action that is about to be taken along with the result that triggered it
+	if becameUnhealthy && onFailure != "" && onFailure != OnFailureNone {
+		note := fmt.Sprintf("container is unhealthy, health-on-failure action: %s", onFailure)
+		if out := strings.TrimRight(hcResult.Output, "\n"); out != "" {
+			note = out + "\n" + note
+		}
+		hcResult.Output = note
 	}
 
 	// Store the latest health check result in the log file
 	if err := writeHealthLog(ctx, container, hcResult); err != nil {
-		return fmt.Errorf("failed to write health log: %w", err)
+		return becameUnhealthy, fmt.Errorf("failed to write health log: %w", err)
+	}
+	return becameUnhealthy, nil
+}
+
+// NextProbeInterval returns the time to wait before the next probe of the container:
+// StartInterval while the container is starting within its start period, Interval otherwise.
+func NextProbeInterval(ctx context.Context, container containerd.Container, hc *Healthcheck) time.Duration {
+	interval := durationWithDefault(hc.Interval, DefaultProbeInterval)
+	if hc.StartPeriod <= 0 {
+		return interval
+	}
+	info, err := container.Info(ctx)
+	if err != nil || time.Since(info.CreatedAt) >= hc.StartPeriod {
+		return interval
+	}
+	state, err := readHealthStateFromLabels(ctx, container)
+	if err != nil || (state != nil && !state.InStartPeriod) {
+		return interval
+	}
+	return durationWithDefault(hc.StartInterval, DefaultStartInterval)
+}
+
+// probeDue tells whether the next probe of the container is due, based on the end of the last probe.
+// Probes are considered due up to tolerance early, to absorb the jitter of the scheduler.
+func probeDue(ctx context.Context, container containerd.Container, hc *Healthcheck, tolerance time.Duration) bool {
+	state, err := readHealthStateFromLabels(ctx, container)
+	if err != nil || state == nil || state.LastProbe.IsZero() {
+		return true
+	}
+	return time.Since(state.LastProbe)+tolerance >= NextProbeInterval(ctx, container, hc)
+}
+
+// timerInterval returns the period of a scheduler that has to honor both the start interval and the interval.
+func timerInterval(hc *Healthcheck) time.Duration {
+	interval := durationWithDefault(hc.Interval, DefaultProbeInterval)
+	if hc.StartPeriod <= 0 {
+		return interval
+	}
+	return min(interval, durationWithDefault(hc.StartInterval, DefaultStartInterval))
+}
+
+func durationWithDefault(configuredValue, defaultValue time.Duration) time.Duration {
+	if configuredValue == 0 {
+		return defaultValue
 	}
-	return nil
+	return configuredValue
 }
 
 // prepareProcessSpec prepares the process spec for health check execution
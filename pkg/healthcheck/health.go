@@ -18,6 +18,7 @@ package healthcheck
 
 import (
 	"encoding/json"
+	"fmt"
 	"time"
 )
 
@@ -43,6 +44,7 @@ const (
 	DefaultProbeInterval   = 30 * time.Second // Default interval between probe runs. Also applies before the first probe.
 	DefaultProbeTimeout    = 30 * time.Second // Max duration a single probe run may take before it's considered failed.
 	DefaultStartPeriod     = 0 * time.Second  // Grace period for container startup before health checks count as failures.
+	DefaultStartInterval   = 5 * time.Second  // Default interval between probe runs during the start period, until the container is healthy.
 	DefaultProbeRetries    = 3                // Number of consecutive failures before marking container as unhealthy.
 	MaxLogEntries          = 5                // Maximum number of health check log entries to keep.
 	MaxOutputLenForInspect = 4096             // Max output length (in bytes) stored in health check logs during inspect. Longer outputs are truncated.
@@ -50,6 +52,18 @@ const (
 	HealthLogFilename      = "health.json"    // HealthLogFilename is the name of the file used to persist health check status for a container.
 )
 
+// Actions taken by --health-on-failure when a container becomes unhealthy
+const (
+	OnFailureNone    = "none"
+	OnFailureKill    = "kill"
+	OnFailureRestart = "restart"
+	OnFailureStop    = "stop"
+)
+
+// SupervisorCommand is the name of the hidden `nerdctl internal` subcommand that
+// drives the health probes of a container when systemd timers are not used.
+const SupervisorCommand = "healthcheck-supervisor"
+
 // NOTE: Health, HealthcheckResult and Healthcheck types are kept Docker-compatible.
 // See: https://github.com/moby/moby/blob/9d1b069a4bfdcee368e67767978eff596b696d4c/api/types/container/health.go
 // Health stores information about the container's healthcheck results
@@ -74,6 +88,9 @@ type Healthcheck struct {
 	Timeout     time.Duration `json:"Timeout,omitempty"`     // Timeout is the time to wait before considering the check to have hung
 	Retries     int           `json:"Retries,omitempty"`     // Retries is the number of consecutive failures needed to consider a container as unhealthy
 	StartPeriod time.Duration `json:"StartPeriod,omitempty"` // StartPeriod is the period for the container to initialize before the health check starts
+
+	// StartInterval is the time to wait between checks during the start period
+	StartInterval time.Duration `json:"StartInterval,omitempty"`
 }
 
 // HealthState stores the current health state of a container
@@ -81,6 +98,7 @@ type HealthState struct {
 	Status        HealthStatus // Status is one of [Starting], [Healthy] or [Unhealthy]
 	FailingStreak int          // FailingStreak is the number of consecutive failures
 	InStartPeriod bool         // InStartPeriod indicates if we're in the start period workflow
+	LastProbe     time.Time    // LastProbe is the time the last health check ended
 }
 
 // ToJSONString serializes HealthState to a JSON string for label storage
@@ -148,7 +166,21 @@ func (hc *Healthcheck) ApplyDefaults() {
 	if hc.StartPeriod == 0 {
 		hc.StartPeriod = DefaultStartPeriod
 	}
+	// The start interval is only meaningful when there is a start period
+	if hc.StartInterval == 0 && hc.StartPeriod > 0 {
+		hc.StartInterval = DefaultStartInterval
+	}
 	if hc.Retries == 0 {
 		hc.Retries = DefaultProbeRetries
 	}
 }
+
+// ValidateOnFailureAction checks that action is a valid --health-on-failure value.
+func ValidateOnFailureAction(action string) error {
+	switch action {
+	case "", OnFailureNone, OnFailureKill, OnFailureRestart, OnFailureStop:
+		return nil
+	}
+	return fmt.Errorf("invalid health-on-failure action %q: must be one of %q, %q, %q or %q",
+		action, OnFailureNone, OnFailureKill, OnFailureRestart, OnFailureStop)
+}
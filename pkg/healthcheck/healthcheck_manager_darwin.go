@@ -48,3 +48,8 @@ func CleanupStaleHealthcheckTimer(ctx context.Context, containerID string) {}
 func ForceRemoveTransientHealthCheckFiles(ctx context.Context, containerID string) error {
 	return nil
 }
+
+// ScheduledProbeDue tells whether the probe about to be run is due. Probes are always due, as there is no scheduler.
+func ScheduledProbeDue(ctx context.Context, container containerd.Container, hc *Healthcheck) bool {
+	return true
+}
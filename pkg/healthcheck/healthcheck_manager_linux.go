@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"strconv"
 	"strings"
 	"time"
 
@@ -35,15 +36,39 @@ import (
 	"github.com/containerd/nerdctl/v2/pkg/rootlessutil"
 )
 
+// timerAccuracy is the accuracy of the transient systemd timers.
+const timerAccuracy = time.Second
+
+// scheduler describes how the periodic health probes of a container are driven.
+type scheduler int
+
+const (
+	// schedulerNone means that the container has no health probes to run.
+	schedulerNone scheduler = iota
+	// schedulerSystemd runs the probes from a transient systemd timer and service.
+	schedulerSystemd
+	// schedulerSupervisor runs the probes from a detached per-container supervisor process.
+	schedulerSupervisor
+)
+
 // CreateTimer sets up the transient systemd timer and service for healthchecks.
+// When systemd timers cannot be used (rootless, no systemd, or disable_hc_systemd),
+// a per-container supervisor process is started instead.
 func CreateTimer(ctx context.Context, container containerd.Container, cfg *config.Config, nerdctlCmd string, nerdctlArgs []string) error {
 	hc := extractHealthcheck(ctx, container)
 	if hc == nil {
 		return nil
 	}
-	if shouldSkipHealthCheckSystemd(hc, cfg) {
+	// The scheduler is already in place when the container is restarted by --health-on-failure.
+	if isSchedulerProcess(ctx, container) {
 		return nil
 	}
+	switch healthCheckScheduler(hc, cfg) {
+	case schedulerNone:
+		return nil
+	case schedulerSupervisor:
+		return startSupervisor(ctx, container, nerdctlCmd, nerdctlArgs)
+	}
 
 	containerID := container.ID()
 	log.G(ctx).Debugf("Creating healthcheck timer unit: %s", containerID)
@@ -62,14 +87,15 @@ func CreateTimer(ctx context.Context, container containerd.Container, cfg *confi
 		cmdOpts = append(cmdOpts, "--setenv=BUILDKIT_HOST="+buildKitHost)
 	}
 
-	// Always use health-interval for timer frequency
+	// The timer fires at the start interval when there is a start period, in which case
+	// the probes that are not due yet are skipped (see ScheduledProbeDue).
 	//
 	// --collect:
 	// Even when the healthcheck fails with the error "container is not running" after the container has
 	// stopped, and the transient service unit enters a failed state, it will still be subject to garbage
 	// collection due to the --collect option. Without this option, `systemctl reset-failed` would explicitly be needed.
 	// See: https://www.freedesktop.org/software/systemd/man/latest/systemd-run.html#-G
-	cmdOpts = append(cmdOpts, "--unit", containerID, "--on-unit-inactive="+hc.Interval.String(), "--timer-property=AccuracySec=1s", "--collect")
+	cmdOpts = append(cmdOpts, "--unit", containerID, "--on-unit-inactive="+timerInterval(hc).String(), "--timer-property=AccuracySec="+timerAccuracy.String(), "--collect")
 
 	cmdOpts = append(cmdOpts, nerdctlCmd)
 	cmdOpts = append(cmdOpts, nerdctlArgs...)
@@ -106,12 +132,13 @@ func createDbusConn(ctx context.Context) (*dbus.Conn, error) {
 }
 
 // StartTimer starts the healthcheck timer unit.
+// The supervisor process needs no explicit start, as it runs the first probe as soon as it is spawned.
 func StartTimer(ctx context.Context, container containerd.Container, cfg *config.Config) error {
 	hc := extractHealthcheck(ctx, container)
 	if hc == nil {
 		return nil
 	}
-	if shouldSkipHealthCheckSystemd(hc, cfg) {
+	if healthCheckScheduler(hc, cfg) != schedulerSystemd || isSchedulerProcess(ctx, container) {
 		return nil
 	}
 
@@ -133,12 +160,25 @@ func StartTimer(ctx context.Context, container containerd.Container, cfg *config
 	return nil
 }
 
-// RemoveTransientHealthCheckFiles stops and cleans up the transient timer and service.
+// RemoveTransientHealthCheckFiles stops and cleans up the transient timer and service,
+// or the supervisor process when the healthchecks are not driven by systemd.
 func RemoveTransientHealthCheckFiles(ctx context.Context, container containerd.Container) error {
 	hc := extractHealthcheck(ctx, container)
 	if hc == nil {
 		return nil
 	}
+	// Do not let the scheduler terminate itself when it stops the container for --health-on-failure.
+	// It cleans up on its own once it finds the container stopped.
+	if isSchedulerProcess(ctx, container) {
+		return nil
+	}
+
+	pidFile, err := supervisorPIDFile(ctx, container)
+	if err != nil {
+		log.G(ctx).WithError(err).Debugf("could not locate healthcheck supervisor of container %s", container.ID())
+	} else if stopSupervisor(ctx, pidFile, container.ID()) {
+		return nil
+	}
 
 	return ForceRemoveTransientHealthCheckFiles(ctx, container.ID())
 }
@@ -254,6 +294,36 @@ func ForceRemoveTransientHealthCheckFiles(ctx context.Context, containerID strin
 	return nil
 }
 
+// isSchedulerProcess reports whether the current process is the healthcheck scheduler of the container,
+// i.e., either its supervisor process or a process of its transient systemd service.
+func isSchedulerProcess(ctx context.Context, container containerd.Container) bool {
+	if pidFile, err := supervisorPIDFile(ctx, container); err == nil {
+		if b, err := os.ReadFile(pidFile); err == nil && strings.TrimSpace(string(b)) == strconv.Itoa(os.Getpid()) {
+			return true
+		}
+	}
+	return isSystemdUnitProcess(container.ID())
+}
+
+// isSystemdUnitProcess reports whether the current process runs in the transient systemd service of the container.
+func isSystemdUnitProcess(containerID string) bool {
+	cgroup, err := os.ReadFile("/proc/self/cgroup")
+	if err != nil {
+		return false
+	}
+	return strings.Contains(string(cgroup), "/"+containerID+".service")
+}
+
+// ScheduledProbeDue tells whether the probe about to be run is due. It only matters for the probes run by the
+// transient systemd service, whose timer fires at the start interval as long as the container may be in its start period.
+// Probes run manually or by the supervisor process are always due.
+func ScheduledProbeDue(ctx context.Context, container containerd.Container, hc *Healthcheck) bool {
+	if !isSystemdUnitProcess(container.ID()) {
+		return true
+	}
+	return probeDue(ctx, container, hc, timerAccuracy)
+}
+
 func extractHealthcheck(ctx context.Context, container containerd.Container) *Healthcheck {
 	l, err := container.Labels(ctx)
 	if err != nil {
@@ -272,16 +342,18 @@ func extractHealthcheck(ctx context.Context, container containerd.Container) *He
 	return hc
 }
 
-// shouldSkipHealthCheckSystemd determines if healthcheck timers should be skipped.
-func shouldSkipHealthCheckSystemd(hc *Healthcheck, cfg *config.Config) bool {
-	// Don't proceed if systemd is unavailable or disabled
-	if !defaults.IsSystemdAvailable() || cfg.DisableHCSystemd || rootlessutil.IsRootless() {
-		return true
-	}
-
+// healthCheckScheduler determines how the healthchecks of the container are scheduled.
+func healthCheckScheduler(hc *Healthcheck, cfg *config.Config) scheduler {
 	// Don't proceed if health check is nil, empty or explicitly NONE.
 	if hc == nil || len(hc.Test) == 0 || hc.Test[0] == "NONE" {
-		return true
+		return schedulerNone
+	}
+
+	// Fall back to the supervisor process if systemd is unavailable or disabled.
+	// Rootless mode uses the supervisor too, as nerdctl runs inside the user namespace of RootlessKit,
+	// from where the systemd user manager cannot be reliably reached.
+	if !defaults.IsSystemdAvailable() || cfg.DisableHCSystemd || rootlessutil.IsRootless() {
+		return schedulerSupervisor
 	}
-	return false
+	return schedulerSystemd
 }
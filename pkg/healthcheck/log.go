@@ -161,6 +161,12 @@ func writeHealthStateToLabels(ctx context.Context, container containerd.Containe
 	return nil
 }
 
+// ResetHealthState resets the health state of the container to starting, e.g. after it has been restarted
+// by --health-on-failure=restart, so that it can become unhealthy (and be acted upon) again.
+func ResetHealthState(ctx context.Context, container containerd.Container) error {
+	return writeHealthStateToLabels(ctx, container, &HealthState{Status: Starting})
+}
+
 // readHealthStateFromLabels reads the health state from container labels
 func readHealthStateFromLabels(ctx context.Context, container containerd.Container) (*HealthState, error) {
 	lbs, err := container.Labels(ctx)
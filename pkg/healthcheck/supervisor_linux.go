@@ -0,0 +1,120 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package healthcheck
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+
+	containerd "github.com/containerd/containerd/v2/client"
+	"github.com/containerd/log"
+
+	"github.com/containerd/nerdctl/v2/pkg/labels"
+)
+
+// supervisorPIDFileName is the name of the file, stored in the container state directory,
+// that holds the PID of the running healthcheck supervisor.
+const supervisorPIDFileName = "healthcheck-supervisor.pid"
+
+func supervisorPIDFile(ctx context.Context, container containerd.Container) (string, error) {
+	l, err := container.Labels(ctx)
+	if err != nil {
+		return "", err
+	}
+	stateDir := l[labels.StateDir]
+	if stateDir == "" {
+		return "", fmt.Errorf("container %s has no state directory", container.ID())
+	}
+	return filepath.Join(stateDir, supervisorPIDFileName), nil
+}
+
+// startSupervisor spawns a detached `nerdctl internal healthcheck-supervisor` process for the container.
+// The supervisor probes the container immediately and then every health interval, until the container stops.
+func startSupervisor(ctx context.Context, container containerd.Container, nerdctlCmd string, nerdctlArgs []string) error {
+	pidFile, err := supervisorPIDFile(ctx, container)
+	if err != nil {
+		return err
+	}
+	// Defensively stop a supervisor that may have leaked from a previous run.
+	stopSupervisor(ctx, pidFile, container.ID())
+
+	args := append([]string{}, nerdctlArgs...)
+	args = append(args, "internal", SupervisorCommand, container.ID())
+	log.G(ctx).Debugf("starting healthcheck supervisor: %s %s", nerdctlCmd, strings.Join(args, " "))
+	cmd := exec.Command(nerdctlCmd, args...)
+	// Detach from the session of the caller, so that the supervisor outlives `nerdctl run -d`
+	// and is not hit by signals sent to the terminal.
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start healthcheck supervisor: %w", err)
+	}
+	if err := os.WriteFile(pidFile, []byte(strconv.Itoa(cmd.Process.Pid)), 0o600); err != nil {
+		_ = cmd.Process.Kill()
+		_, _ = cmd.Process.Wait()
+		return fmt.Errorf("failed to write healthcheck supervisor pid file: %w", err)
+	}
+	return cmd.Process.Release()
+}
+
+// stopSupervisor terminates the supervisor recorded in pidFile, if it is still running,
+// and removes the pid file. It reports whether a pid file was found.
+func stopSupervisor(ctx context.Context, pidFile, containerID string) bool {
+	b, err := os.ReadFile(pidFile)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			log.G(ctx).WithError(err).Warnf("failed to read healthcheck supervisor pid file %s", pidFile)
+		}
+		return false
+	}
+	defer os.Remove(pidFile)
+
+	pid, err := strconv.Atoi(strings.TrimSpace(string(b)))
+	if err != nil || pid <= 0 {
+		log.G(ctx).Warnf("invalid healthcheck supervisor pid file %s", pidFile)
+		return true
+	}
+	// The PID may have been recycled since the supervisor exited on its own.
+	if !isSupervisorProcess(pid, containerID) {
+		return true
+	}
+	log.G(ctx).Debugf("stopping healthcheck supervisor %d of container %s", pid, containerID)
+	if err := syscall.Kill(pid, syscall.SIGTERM); err != nil && !errors.Is(err, syscall.ESRCH) {
+		log.G(ctx).WithError(err).Warnf("failed to stop healthcheck supervisor %d", pid)
+	}
+	return true
+}
+
+// isSupervisorProcess checks whether pid is the healthcheck supervisor of the container.
+func isSupervisorProcess(pid int, containerID string) bool {
+	cmdline, err := os.ReadFile(filepath.Join("/proc", strconv.Itoa(pid), "cmdline"))
+	if err != nil {
+		return false
+	}
+	args := bytes.Split(bytes.TrimRight(cmdline, "\x00"), []byte{0})
+	if len(args) < 2 {
+		return false
+	}
+	return string(args[len(args)-2]) == SupervisorCommand && string(args[len(args)-1]) == containerID
+}
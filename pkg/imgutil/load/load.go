@@ -20,6 +20,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"strings"
 	"time"
@@ -29,22 +30,38 @@ import (
 	"github.com/containerd/containerd/v2/core/transfer"
 	tarchive "github.com/containerd/containerd/v2/core/transfer/archive"
 	transferimage "github.com/containerd/containerd/v2/core/transfer/image"
+	"github.com/containerd/containerd/v2/pkg/archive/compression"
 	"github.com/containerd/platforms"
 
 	"github.com/containerd/nerdctl/v2/pkg/api/types"
 	"github.com/containerd/nerdctl/v2/pkg/platformutil"
+	"github.com/containerd/nerdctl/v2/pkg/tarutil"
 	"github.com/containerd/nerdctl/v2/pkg/transferutil"
 )
 
 // FromArchive loads and unpacks the images from the tar archive specified in image load options.
+// The archive may be compressed with gzip or zstd. An OCI image layout directory is accepted as the input too.
 func FromArchive(ctx context.Context, client *containerd.Client, options types.ImageLoadOptions) ([]images.Image, error) {
 	if options.Input != "" {
-		f, err := os.Open(options.Input)
+		st, err := os.Stat(options.Input)
 		if err != nil {
 			return nil, err
 		}
-		defer f.Close()
-		options.Stdin = f
+		if st.IsDir() {
+			pr, pw := io.Pipe()
+			go func() {
+				pw.CloseWithError(tarutil.CreateDir(pw, options.Input))
+			}()
+			defer pr.Close()
+			options.Stdin = pr
+		} else {
+			f, err := os.Open(options.Input)
+			if err != nil {
+				return nil, err
+			}
+			defer f.Close()
+			options.Stdin = f
+		}
 	} else {
 		// check if stdin is empty.
 		stdinStat, err := os.Stdin.Stat()
@@ -86,9 +103,16 @@ func FromArchive(ctx context.Context, client *containerd.Client, options types.I
 	storeOpts = append(storeOpts, transferimage.WithDigestRef("import", true, true))
 	storeOpts = append(storeOpts, transferimage.WithNamedPrefix(fmt.Sprintf("import-%s", time.Now().Format("2006-01-02")), true))
 
+	// Transparently decompress gzip and zstd archives; uncompressed ones are passed through.
+	input, err := compression.DecompressStream(options.Stdin)
+	if err != nil {
+		return nil, err
+	}
+	defer input.Close()
+
 	pf, done, loadedImages := transferutil.ProgressHandlerLoadImage(ctx, client, beforeSet, options)
 	err = client.Transfer(ctx,
-		tarchive.NewImageImportStream(options.Stdin, ""),
+		tarchive.NewImageImportStream(input, ""),
 		transferimage.NewStore("", storeOpts...),
 		transfer.WithProgress(pf),
 	)
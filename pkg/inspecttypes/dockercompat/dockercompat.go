@@ -49,6 +49,9 @@ import (
 	"github.com/containerd/nerdctl/v2/pkg/inspecttypes/native"
 	"github.com/containerd/nerdctl/v2/pkg/ipcutil"
 	"github.com/containerd/nerdctl/v2/pkg/labels"
+	"github.com/containerd/nerdctl/v2/pkg/logging"
+	"github.com/containerd/nerdctl/v2/pkg/netutil"
+	"github.com/containerd/nerdctl/v2/pkg/netutil/nettype"
 	"github.com/containerd/nerdctl/v2/pkg/ocihook/state"
 )
 
@@ -247,6 +250,9 @@ type ContainerState struct {
 	StartedAt  string
 	FinishedAt string
 	Health     *healthcheck.Health `json:",omitempty"`
+	// LogDroppedMessages is the number of log messages dropped by the
+	// non-blocking log mode (nerdctl extension)
+	LogDroppedMessages uint64 `json:",omitempty"`
 }
 
 type NetworkSettings struct {
@@ -306,7 +312,7 @@ type NetworkEndpointSettings struct {
 	// Configurations
 	// TODO IPAMConfig *EndpointIPAMConfig
 	// TODO Links      []string
-	// TODO Aliases    []string
+	Aliases []string
 	// Operational data
 	// TODO NetworkID           string
 	// TODO EndpointID          string
@@ -471,6 +477,13 @@ func ContainerFromNative(n *native.Container) (*Container, error) {
 	cs := new(ContainerState)
 	cs.Restarting = n.Labels[restart.StatusLabel] == string(containerd.Running)
 	cs.Error = n.Labels[labels.Error]
+	if nerdctlStateDir := n.Labels[labels.StateDir]; nerdctlStateDir != "" {
+		if logStats, err := logging.LoadLogStats(nerdctlStateDir); err != nil {
+			log.L.WithError(err).Warn("failed retrieving log stats")
+		} else {
+			cs.LogDroppedMessages = logStats.DroppedMessages
+		}
+	}
 	if n.Process != nil {
 		cs.Status = statusFromNative(n.Process.Status, n.Labels)
 		cs.Running = n.Process.Status.Status == containerd.Running
@@ -788,7 +801,32 @@ func statusFromNative(x containerd.Status, labels map[string]string) string {
 	}
 }
 
-func networkSettingsFromNative(n *native.NetNS, _ *specs.Spec) (*NetworkSettings, error) {
+// networksFromSpec returns the CNI networks of the container keyed by interface name,
+// along with their endpoint settings.
+func networksFromSpec(sp *specs.Spec) (map[string]string, map[string]netutil.EndpointSettings) {
+	if sp == nil || sp.Annotations[labels.Networks] == "" {
+		return nil, nil
+	}
+	var networks []string
+	if err := json.Unmarshal([]byte(sp.Annotations[labels.Networks]), &networks); err != nil {
+		return nil, nil
+	}
+	if netType, err := nettype.Detect(networks); err != nil || netType != nettype.CNI {
+		return nil, nil
+	}
+	endpoints, err := netutil.ParseEndpoints(sp.Annotations[labels.NetworkEndpoints])
+	if err != nil {
+		log.L.WithError(err).Warn("failed to parse network endpoints")
+		return nil, nil
+	}
+	byIfName := make(map[string]string, len(networks))
+	for netstr, ifName := range netutil.InterfaceNames(networks, endpoints) {
+		byIfName[ifName] = netstr
+	}
+	return byIfName, endpoints
+}
+
+func networkSettingsFromNative(n *native.NetNS, sp *specs.Spec) (*NetworkSettings, error) {
 	res := &NetworkSettings{
 		Networks: make(map[string]*NetworkEndpointSettings),
 	}
@@ -798,6 +836,7 @@ func networkSettingsFromNative(n *native.NetNS, _ *specs.Spec) (*NetworkSettings
 		return res, nil
 	}
 
+	networkNames, endpoints := networksFromSpec(sp)
 	var primary *NetworkEndpointSettings
 	for _, x := range n.Interfaces {
 		if x.Interface.Flags&net.FlagLoopback != 0 {
@@ -827,9 +866,13 @@ func networkSettingsFromNative(n *native.NetNS, _ *specs.Spec) (*NetworkSettings
 				nes.GlobalIPv6PrefixLen = ones
 			}
 		}
-		// TODO: set CNI name when possible
-		fakeDockerNetworkName := fmt.Sprintf("unknown-%s", x.Name)
-		res.Networks[fakeDockerNetworkName] = nes
+		if netName, ok := networkNames[x.Name]; ok {
+			nes.Aliases = endpoints[netName].Aliases
+			res.Networks[netName] = nes
+		} else {
+			fakeDockerNetworkName := fmt.Sprintf("unknown-%s", x.Name)
+			res.Networks[fakeDockerNetworkName] = nes
+		}
 
 		nports, err := convertToNatPort(n.PortMappings)
 		if err != nil {
@@ -22,4 +22,6 @@ type Volume struct {
 	Mountpoint string             `json:"Mountpoint"`
 	Labels     *map[string]string `json:"Labels,omitempty"`
 	Size       int64              `json:"Size,omitempty"`
+	// Options are the options of the `local` driver, e.g., {"type": "tmpfs", "device": "tmpfs", "o": "size=100m"}
+	Options map[string]string `json:"Options,omitempty"`
 }
@@ -60,6 +60,16 @@ const (
 	// Currently, the length of the slice must be 1.
 	Networks = Prefix + "networks"
 
+	// NetworkEndpoints is a JSON-marshalled string of map[string]netutil.EndpointSettings,
+	// keyed by the entries of Networks. Set by `nerdctl network connect` and `nerdctl network disconnect`.
+	NetworkEndpoints = Prefix + "network-endpoints"
+
+	// Bandwidth is a JSON-marshalled string of cni.BandWidth, the traffic limits set with `--network-opt`.
+	Bandwidth = Prefix + "bandwidth"
+
+	// FirewallBackend is the firewall backend ("iptables" or "nftables") the CNI networks of the container are set up with.
+	FirewallBackend = Prefix + "firewall-backend"
+
 	// DEPRECATED : https://github.com/containerd/nerdctl/pull/4290
 	// Ports is a JSON-marshalled string of []cni.PortMapping .
 	Ports = Prefix + "ports"
@@ -134,6 +144,9 @@ const (
 	// DNSSettings sets the dockercompat DNS config values
 	DNSSetting = Prefix + "dns"
 
+	// EmbeddedDNS is the name of the network whose embedded DNS server is the nameserver of the container.
+	EmbeddedDNS = Prefix + "embedded-dns"
+
 	// User is the username of the container
 	User = Prefix + "user"
 
@@ -143,6 +156,9 @@ const (
 	// HealthState stores the current health state (status and failing streak).
 	HealthState = Prefix + "healthstate"
 
+	// HealthOnFailure is the action taken when the container becomes unhealthy (none, kill, restart or stop).
+	HealthOnFailure = Prefix + "health-on-failure"
+
 	// Privileged indicates whether the container was created with --privileged.
 	Privileged = Prefix + "privileged"
 	// ExposedPorts is a JSON-marshalled string of nat.PortSet.
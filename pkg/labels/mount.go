@@ -19,6 +19,7 @@ package labels
 import (
 	"encoding/json"
 	"fmt"
+	"slices"
 	"strings"
 )
 
@@ -68,3 +69,34 @@ func GetMount(containerLabels map[string]string) string {
 
 	return ""
 }
+
+// GetVolumeNames returns the names of the named and anonymous volumes mounted in a container
+func GetVolumeNames(containerLabels map[string]string) []string {
+	var names []string
+	add := func(name string) {
+		if name != "" && !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+
+	var mounts []struct {
+		Type string
+		Name string
+	}
+	if err := json.Unmarshal([]byte(GetMount(containerLabels)), &mounts); err == nil {
+		for _, m := range mounts {
+			if m.Type == "volume" {
+				add(m.Name)
+			}
+		}
+	}
+
+	var anonymous []string
+	if err := json.Unmarshal([]byte(containerLabels[AnonymousVolumes]), &anonymous); err == nil {
+		for _, name := range anonymous {
+			add(name)
+		}
+	}
+
+	return names
+}
@@ -0,0 +1,164 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package logging
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+
+	"github.com/containerd/containerd/v2/core/runtime/v2/logging"
+	"github.com/containerd/log"
+
+	"github.com/containerd/nerdctl/v2/pkg/logging/local"
+)
+
+// Log options of the local cache of drivers that cannot read logs back,
+// as with Docker's "dual logging".
+const (
+	CacheDisabled = "cache-disabled"
+	CacheMaxSize  = "cache-max-size"
+	CacheMaxFile  = "cache-max-file"
+	CacheCompress = "cache-compress"
+)
+
+// cacheLogOpts maps the cache log options to the corresponding options of the
+// local driver.
+var cacheLogOpts = map[string]string{
+	CacheMaxSize:  MaxSize,
+	CacheMaxFile:  MaxFile,
+	CacheCompress: localCompress,
+}
+
+// usesLogCache returns true when logs of the driver are cached locally, i.e.
+// when the driver has no log viewer to read them back.
+func usesLogCache(driverName string) bool {
+	if driverName == "none" {
+		return false
+	}
+	_, ok := logViewers[driverName]
+	return !ok
+}
+
+// splitCacheLogOpts separates the cache log options from the driver's own
+// options. The returned cache options use the keys of the local driver.
+func splitCacheLogOpts(logOptMap map[string]string) (driverOpts map[string]string, cacheOpts map[string]string, disabled bool, err error) {
+	driverOpts = make(map[string]string, len(logOptMap))
+	cacheOpts = make(map[string]string)
+	for key, value := range logOptMap {
+		if key == CacheDisabled {
+			if disabled, err = strconv.ParseBool(value); err != nil {
+				return nil, nil, false, fmt.Errorf("invalid value for %s: %w", CacheDisabled, err)
+			}
+		} else if localKey, ok := cacheLogOpts[key]; ok {
+			cacheOpts[localKey] = value
+		} else {
+			driverOpts[key] = value
+		}
+	}
+	if _, err := parseLocalLogOpts(cacheOpts); err != nil {
+		return nil, nil, false, fmt.Errorf("invalid log cache options: %w", err)
+	}
+	return driverOpts, cacheOpts, disabled, nil
+}
+
+// DualLogger wraps a driver that cannot read logs back, to also write the logs
+// to a local cache that `nerdctl logs` reads from.
+type DualLogger struct {
+	Driver
+	cacheOpts map[string]string
+	cache     *local.Writer
+}
+
+func (dualLogger *DualLogger) Init(dataStore, ns, id string) error {
+	if err := os.MkdirAll(filepath.Dir(local.CachePath(dataStore, ns, id)), 0700); err != nil {
+		return err
+	}
+	return dualLogger.Driver.Init(dataStore, ns, id)
+}
+
+func (dualLogger *DualLogger) PreProcess(ctx context.Context, dataStore string, config *logging.Config) error {
+	opts, err := parseLocalLogOpts(dualLogger.cacheOpts)
+	if err != nil {
+		return err
+	}
+	cachePath := local.CachePath(dataStore, config.Namespace, config.ID)
+	if err := os.MkdirAll(filepath.Dir(cachePath), 0700); err != nil {
+		return err
+	}
+	if dualLogger.cache, err = local.NewWriter(cachePath, opts.maxSize, opts.maxFile, opts.compress); err != nil {
+		return fmt.Errorf("failed to open log cache: %w", err)
+	}
+	return dualLogger.Driver.PreProcess(ctx, dataStore, config)
+}
+
+func (dualLogger *DualLogger) Process(stdout <-chan string, stderr <-chan string) error {
+	var wg sync.WaitGroup
+	wg.Add(2)
+	// tee writes each entry to the cache before forwarding it to the driver,
+	// so that the cache is complete even when the driver falls behind.
+	tee := func(dataChan <-chan string, stream string) <-chan string {
+		out := make(chan string, cap(dataChan))
+		go func() {
+			defer wg.Done()
+			defer close(out)
+			for line := range dataChan {
+				if err := dualLogger.cache.WriteEntry(stream, line); err != nil {
+					log.L.WithError(err).Error("failed to write log entry to the log cache")
+				}
+				out <- line
+			}
+		}()
+		return out
+	}
+	err := dualLogger.Driver.Process(tee(stdout, streamStdout), tee(stderr, streamStderr))
+	wg.Wait()
+	return err
+}
+
+func (dualLogger *DualLogger) PostProcess() error {
+	err := dualLogger.Driver.PostProcess()
+	if dualLogger.cache != nil {
+		err = errors.Join(err, dualLogger.cache.Close())
+	}
+	return err
+}
+
+// viewLogsCache loads log entries from the local cache of drivers that cannot
+// read logs back.
+func viewLogsCache(lvopts LogViewOptions, stdout, stderr io.Writer, stopChannel chan os.Signal) error {
+	cachePath := local.CachePath(lvopts.DatastoreRootPath, lvopts.Namespace, lvopts.ContainerID)
+	if _, err := os.Stat(cachePath); err != nil {
+		return fmt.Errorf("failed to stat log cache: %w", err)
+	}
+	return viewLogsLocalDirect(lvopts, cachePath, stdout, stderr, stopChannel)
+}
+
+// logCacheEnabled returns true when the logs of a container with the given
+// logging configuration are cached locally.
+func logCacheEnabled(lcfg LogConfig) bool {
+	if !usesLogCache(lcfg.Driver) {
+		return false
+	}
+	disabled, _ := strconv.ParseBool(lcfg.Opts[CacheDisabled])
+	return !disabled
+}
@@ -0,0 +1,95 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package logging
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/containerd/containerd/v2/core/runtime/v2/logging"
+)
+
+func TestGetDriverLogCache(t *testing.T) {
+	driver, err := GetDriver("syslog", map[string]string{CacheMaxSize: "1m", CacheMaxFile: "2"}, "")
+	assert.NilError(t, err)
+	dual, ok := driver.(*DualLogger)
+	assert.Assert(t, ok)
+	assert.DeepEqual(t, dual.cacheOpts, map[string]string{MaxSize: "1m", MaxFile: "2"})
+	syslogLogger, ok := dual.Driver.(*SyslogLogger)
+	assert.Assert(t, ok)
+	assert.DeepEqual(t, syslogLogger.Opts, map[string]string{})
+
+	driver, err = GetDriver("syslog", map[string]string{CacheDisabled: "true"}, "")
+	assert.NilError(t, err)
+	_, ok = driver.(*SyslogLogger)
+	assert.Assert(t, ok)
+
+	// Drivers which can read logs back are not cached.
+	driver, err = GetDriver("json-file", map[string]string{}, "")
+	assert.NilError(t, err)
+	_, ok = driver.(*JSONLogger)
+	assert.Assert(t, ok)
+
+	_, err = GetDriver("syslog", map[string]string{CacheMaxFile: "0"}, "")
+	assert.ErrorContains(t, err, "max-file cannot be less than 1")
+	assert.ErrorContains(t, ValidateLogOpts("gelf", map[string]string{CacheDisabled: "maybe"}), CacheDisabled)
+}
+
+func TestDualLogger(t *testing.T) {
+	dataStore := t.TempDir()
+	config := &logging.Config{
+		ID:        "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef",
+		Namespace: "default",
+	}
+	driver := &MockDriver{}
+	dual := &DualLogger{Driver: driver, cacheOpts: map[string]string{}}
+	assert.NilError(t, dual.Init(dataStore, config.Namespace, config.ID))
+	assert.NilError(t, dual.PreProcess(context.Background(), dataStore, config))
+
+	stdout := make(chan string, 10)
+	stderr := make(chan string, 10)
+	stdout <- "foo\n"
+	stderr <- "bar\n"
+	stdout <- "baz\n"
+	close(stdout)
+	close(stderr)
+	assert.NilError(t, dual.Process(stdout, stderr))
+	assert.NilError(t, dual.PostProcess())
+	assert.DeepEqual(t, driver.receivedStdout, []string{"foo\n", "baz\n"})
+	assert.DeepEqual(t, driver.receivedStderr, []string{"bar\n"})
+
+	lv := &ContainerLogViewer{
+		loggingConfig: LogConfig{Driver: "syslog"},
+		logViewingOptions: LogViewOptions{
+			ContainerID:       config.ID,
+			Namespace:         config.Namespace,
+			DatastoreRootPath: dataStore,
+		},
+		stopChannel: make(chan os.Signal),
+	}
+	var stdoutBuf, stderrBuf bytes.Buffer
+	assert.NilError(t, lv.PrintLogsTo(&stdoutBuf, &stderrBuf))
+	assert.Equal(t, stdoutBuf.String(), "foo\nbaz\n")
+	assert.Equal(t, stderrBuf.String(), "bar\n")
+
+	lv.loggingConfig.Opts = map[string]string{CacheDisabled: "true"}
+	assert.ErrorContains(t, lv.PrintLogsTo(&stdoutBuf, &stderrBuf), "no log viewer")
+}
@@ -0,0 +1,256 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+// Package gelf implements a client for the Graylog Extended Log Format (GELF).
+//
+// See https://go2docs.graylog.org/current/getting_in_log_data/gelf.html
+package gelf
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+)
+
+// Version is the GELF specification version of the messages.
+const Version = "1.1"
+
+// Syslog severity levels used by GELF.
+const (
+	LevelError = 3
+	LevelInfo  = 6
+)
+
+// Message is a GELF message.
+type Message struct {
+	Version      string
+	Host         string
+	ShortMessage string
+	// Timestamp is the number of seconds since the UNIX epoch.
+	Timestamp float64
+	Level     int
+	// Extra holds additional fields. Their names must start with "_".
+	Extra map[string]string
+}
+
+// MarshalJSON encodes the message with its additional fields at the top level.
+func (m *Message) MarshalJSON() ([]byte, error) {
+	fields := make(map[string]any, len(m.Extra)+5)
+	for k, v := range m.Extra {
+		fields[k] = v
+	}
+	fields["version"] = m.Version
+	fields["host"] = m.Host
+	fields["short_message"] = m.ShortMessage
+	fields["timestamp"] = m.Timestamp
+	fields["level"] = m.Level
+	return json.Marshal(fields)
+}
+
+// CompressionType is the compression of UDP messages.
+type CompressionType string
+
+const (
+	CompressionGzip CompressionType = "gzip"
+	CompressionZlib CompressionType = "zlib"
+	CompressionNone CompressionType = "none"
+)
+
+// ParseCompressionType parses a compression type, defaulting to gzip.
+func ParseCompressionType(s string) (CompressionType, error) {
+	switch t := CompressionType(s); t {
+	case "":
+		return CompressionGzip, nil
+	case CompressionGzip, CompressionZlib, CompressionNone:
+		return t, nil
+	default:
+		return "", fmt.Errorf("unsupported GELF compression type %q, must be one of gzip, zlib or none", s)
+	}
+}
+
+const (
+	// ChunkSize is the maximum size of a UDP datagram sent by UDPWriter.
+	ChunkSize = 1420
+	// MaxChunks is the maximum number of chunks of a message.
+	MaxChunks = 128
+
+	chunkMagic0      = 0x1e
+	chunkMagic1      = 0x0f
+	chunkHeaderSize  = 2 + 8 + 1 + 1
+	chunkPayloadSize = ChunkSize - chunkHeaderSize
+)
+
+// Writer sends GELF messages.
+type Writer interface {
+	WriteMessage(m *Message) error
+	Close() error
+}
+
+// UDPWriter sends compressed, chunked GELF messages over UDP.
+type UDPWriter struct {
+	conn             net.Conn
+	compression      CompressionType
+	compressionLevel int
+}
+
+// NewUDPWriter returns a UDPWriter sending messages to addr ("host:port").
+// compressionLevel is a flate compression level, e.g. flate.DefaultCompression.
+func NewUDPWriter(addr string, compression CompressionType, compressionLevel int) (*UDPWriter, error) {
+	if compressionLevel < flate.HuffmanOnly || compressionLevel > flate.BestCompression {
+		return nil, fmt.Errorf("invalid GELF compression level %d", compressionLevel)
+	}
+	conn, err := net.Dial("udp", addr)
+	if err != nil {
+		return nil, err
+	}
+	return &UDPWriter{
+		conn:             conn,
+		compression:      compression,
+		compressionLevel: compressionLevel,
+	}, nil
+}
+
+// WriteMessage sends the message, splitting it in chunks if it does not fit in
+// a single datagram.
+func (w *UDPWriter) WriteMessage(m *Message) error {
+	b, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	if b, err = compress(b, w.compression, w.compressionLevel); err != nil {
+		return err
+	}
+	if len(b) <= ChunkSize {
+		_, err = w.conn.Write(b)
+		return err
+	}
+	return writeChunks(w.conn, b)
+}
+
+// Close closes the connection.
+func (w *UDPWriter) Close() error {
+	return w.conn.Close()
+}
+
+func compress(b []byte, compression CompressionType, level int) ([]byte, error) {
+	var (
+		buf bytes.Buffer
+		zw  io.WriteCloser
+		err error
+	)
+	switch compression {
+	case CompressionGzip:
+		zw, err = gzip.NewWriterLevel(&buf, level)
+	case CompressionZlib:
+		zw, err = zlib.NewWriterLevel(&buf, level)
+	default:
+		return b, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if _, err := zw.Write(b); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// writeChunks sends b as GELF chunks, each in its own datagram.
+func writeChunks(w io.Writer, b []byte) error {
+	count := (len(b) + chunkPayloadSize - 1) / chunkPayloadSize
+	if count > MaxChunks {
+		return fmt.Errorf("GELF message of %d bytes exceeds the maximum of %d chunks", len(b), MaxChunks)
+	}
+	var id [8]byte
+	if _, err := rand.Read(id[:]); err != nil {
+		return err
+	}
+	chunk := make([]byte, 0, ChunkSize)
+	for i := 0; i < count; i++ {
+		payload := b[i*chunkPayloadSize : min((i+1)*chunkPayloadSize, len(b))]
+		chunk = append(chunk[:0], chunkMagic0, chunkMagic1)
+		chunk = append(chunk, id[:]...)
+		chunk = append(chunk, byte(i), byte(count))
+		chunk = append(chunk, payload...)
+		if _, err := w.Write(chunk); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// TCPWriter sends null-byte delimited GELF messages over TCP, reconnecting
+// once when sending fails.
+type TCPWriter struct {
+	mu   sync.Mutex
+	addr string
+	conn net.Conn
+}
+
+// NewTCPWriter returns a TCPWriter sending messages to addr ("host:port").
+func NewTCPWriter(addr string) (*TCPWriter, error) {
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	return &TCPWriter{addr: addr, conn: conn}, nil
+}
+
+// WriteMessage sends the message.
+func (w *TCPWriter) WriteMessage(m *Message) error {
+	b, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	b = append(b, 0)
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.conn != nil {
+		if _, err = w.conn.Write(b); err == nil {
+			return nil
+		}
+		w.conn.Close()
+		w.conn = nil
+	}
+	conn, dialErr := net.Dial("tcp", w.addr)
+	if dialErr != nil {
+		return errors.Join(err, dialErr)
+	}
+	w.conn = conn
+	_, err = w.conn.Write(b)
+	return err
+}
+
+// Close closes the connection.
+func (w *TCPWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.conn == nil {
+		return nil
+	}
+	return w.conn.Close()
+}
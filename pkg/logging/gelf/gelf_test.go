@@ -0,0 +1,142 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package gelf
+
+import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/json"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+)
+
+func testMessage(short string) *Message {
+	return &Message{
+		Version:      Version,
+		Host:         "host",
+		ShortMessage: short,
+		Timestamp:    1700000000.5,
+		Level:        LevelInfo,
+		Extra:        map[string]string{"_container_id": "abc"},
+	}
+}
+
+// readUDPMessage reads a message from conn, reassembling its chunks.
+func readUDPMessage(t *testing.T, conn net.PacketConn) []byte {
+	t.Helper()
+	assert.NilError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	buf := make([]byte, 65536)
+	var chunks [][]byte
+	for {
+		n, _, err := conn.ReadFrom(buf)
+		assert.NilError(t, err)
+		b := append([]byte{}, buf[:n]...)
+		if len(b) < 2 || b[0] != chunkMagic0 || b[1] != chunkMagic1 {
+			return b
+		}
+		assert.Assert(t, n <= ChunkSize)
+		seq, count := int(b[10]), int(b[11])
+		if chunks == nil {
+			chunks = make([][]byte, count)
+		}
+		chunks[seq] = b[chunkHeaderSize:]
+		if seq == count-1 {
+			return bytes.Join(chunks, nil)
+		}
+	}
+}
+
+func decompress(t *testing.T, b []byte, compression CompressionType) []byte {
+	t.Helper()
+	var (
+		r   io.Reader
+		err error
+	)
+	switch compression {
+	case CompressionGzip:
+		r, err = gzip.NewReader(bytes.NewReader(b))
+	case CompressionZlib:
+		r, err = zlib.NewReader(bytes.NewReader(b))
+	default:
+		return b
+	}
+	assert.NilError(t, err)
+	out, err := io.ReadAll(r)
+	assert.NilError(t, err)
+	return out
+}
+
+func TestUDPWriter(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	assert.NilError(t, err)
+	defer conn.Close()
+
+	for _, compression := range []CompressionType{CompressionGzip, CompressionZlib, CompressionNone} {
+		for _, short := range []string{"hello", strings.Repeat("x", 10*ChunkSize)} {
+			w, err := NewUDPWriter(conn.LocalAddr().String(), compression, flate.NoCompression)
+			assert.NilError(t, err)
+			assert.NilError(t, w.WriteMessage(testMessage(short)))
+			assert.NilError(t, w.Close())
+
+			var got map[string]any
+			assert.NilError(t, json.Unmarshal(decompress(t, readUDPMessage(t, conn), compression), &got))
+			assert.Equal(t, got["version"], Version)
+			assert.Equal(t, got["host"], "host")
+			assert.Equal(t, got["short_message"], short)
+			assert.Equal(t, got["timestamp"], 1700000000.5)
+			assert.Equal(t, got["level"], float64(LevelInfo))
+			assert.Equal(t, got["_container_id"], "abc")
+		}
+	}
+}
+
+func TestWriteChunksTooLarge(t *testing.T) {
+	err := writeChunks(io.Discard, make([]byte, MaxChunks*chunkPayloadSize+1))
+	assert.ErrorContains(t, err, "exceeds the maximum")
+}
+
+func TestTCPWriter(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NilError(t, err)
+	defer l.Close()
+
+	w, err := NewTCPWriter(l.Addr().String())
+	assert.NilError(t, err)
+	defer w.Close()
+	conn, err := l.Accept()
+	assert.NilError(t, err)
+	defer conn.Close()
+
+	assert.NilError(t, w.WriteMessage(testMessage("foo")))
+	assert.NilError(t, w.WriteMessage(testMessage("bar")))
+	r := bufio.NewReader(conn)
+	for _, want := range []string{"foo", "bar"} {
+		b, err := r.ReadBytes(0)
+		assert.NilError(t, err)
+		var got map[string]any
+		assert.NilError(t, json.Unmarshal(b[:len(b)-1], &got))
+		assert.Equal(t, got["short_message"], want)
+	}
+}
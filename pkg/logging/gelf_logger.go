@@ -0,0 +1,261 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package logging
+
+import (
+	"compress/flate"
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/opencontainers/runtime-spec/specs-go"
+
+	"github.com/containerd/containerd/v2/core/runtime/v2/logging"
+	"github.com/containerd/log"
+
+	"github.com/containerd/nerdctl/v2/pkg/clientutil"
+	"github.com/containerd/nerdctl/v2/pkg/containerutil"
+	"github.com/containerd/nerdctl/v2/pkg/logging/gelf"
+	"github.com/containerd/nerdctl/v2/pkg/strutil"
+)
+
+const (
+	gelfAddress          = "gelf-address"
+	gelfCompressionType  = "gelf-compression-type"
+	gelfCompressionLevel = "gelf-compression-level"
+)
+
+var GelfLogOpts = []string{
+	gelfAddress,
+	gelfCompressionType,
+	gelfCompressionLevel,
+	Tag,
+	Labels,
+	Env,
+}
+
+func GelfLogOptsValidate(logOptMap map[string]string) error {
+	for key := range logOptMap {
+		if !strutil.InStringSlice(GelfLogOpts, key) {
+			log.L.Warnf("log-opt %s is ignored for gelf log driver", key)
+		}
+	}
+	proto, _, err := parseGelfAddress(logOptMap[gelfAddress])
+	if err != nil {
+		return err
+	}
+	if proto == "tcp" {
+		for _, key := range []string{gelfCompressionType, gelfCompressionLevel} {
+			if _, ok := logOptMap[key]; ok {
+				return fmt.Errorf("log-opt %s is not supported with the tcp protocol of the gelf log driver", key)
+			}
+		}
+		return nil
+	}
+	if _, err := gelf.ParseCompressionType(logOptMap[gelfCompressionType]); err != nil {
+		return err
+	}
+	_, err = parseGelfCompressionLevel(logOptMap[gelfCompressionLevel])
+	return err
+}
+
+// parseGelfAddress parses the gelf-address log option, e.g. "udp://host:12201".
+func parseGelfAddress(address string) (string, string, error) {
+	if address == "" {
+		return "", "", fmt.Errorf("%s is required for gelf log driver", gelfAddress)
+	}
+	addr, err := url.Parse(address)
+	if err != nil {
+		return "", "", err
+	}
+	if addr.Scheme != "udp" && addr.Scheme != "tcp" {
+		return "", "", fmt.Errorf("%s should be in the form proto://address, got unsupported scheme %q", gelfAddress, addr.Scheme)
+	}
+	if _, _, err := net.SplitHostPort(addr.Host); err != nil {
+		return "", "", fmt.Errorf("%s should be in the form proto://address:port: %w", gelfAddress, err)
+	}
+	return addr.Scheme, addr.Host, nil
+}
+
+func parseGelfCompressionLevel(s string) (int, error) {
+	if s == "" {
+		return flate.BestSpeed, nil
+	}
+	level, err := strconv.Atoi(s)
+	if err != nil || level < flate.DefaultCompression || level > flate.BestCompression {
+		return 0, fmt.Errorf("invalid %s %q, must be an integer between %d and %d", gelfCompressionLevel, s, flate.DefaultCompression, flate.BestCompression)
+	}
+	return level, nil
+}
+
+type GelfLogger struct {
+	Opts    map[string]string
+	Address string
+	writer  gelf.Writer
+	host    string
+	extra   map[string]string
+}
+
+func (gelfLogger *GelfLogger) Init(dataStore, ns, id string) error {
+	return nil
+}
+
+func (gelfLogger *GelfLogger) PreProcess(ctx context.Context, dataStore string, config *logging.Config) error {
+	proto, address, err := parseGelfAddress(gelfLogger.Opts[gelfAddress])
+	if err != nil {
+		return err
+	}
+	extra, err := gelfLogger.containerFields(ctx, config)
+	if err != nil {
+		return err
+	}
+	host, err := os.Hostname()
+	if err != nil {
+		return err
+	}
+	var writer gelf.Writer
+	if proto == "tcp" {
+		writer, err = gelf.NewTCPWriter(address)
+	} else {
+		compression, parseErr := gelf.ParseCompressionType(gelfLogger.Opts[gelfCompressionType])
+		if parseErr != nil {
+			return parseErr
+		}
+		level, parseErr := parseGelfCompressionLevel(gelfLogger.Opts[gelfCompressionLevel])
+		if parseErr != nil {
+			return parseErr
+		}
+		writer, err = gelf.NewUDPWriter(address, compression, level)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to connect to gelf endpoint %s: %w", gelfLogger.Opts[gelfAddress], err)
+	}
+	gelfLogger.writer = writer
+	gelfLogger.host = host
+	gelfLogger.extra = extra
+	return nil
+}
+
+// containerFields returns the additional fields of the container's messages:
+// the container metadata, plus the labels and environment variables selected
+// by the labels and env log options.
+func (gelfLogger *GelfLogger) containerFields(ctx context.Context, config *logging.Config) (map[string]string, error) {
+	tag, err := formatLogTag(gelfLogger.Opts, config)
+	if err != nil {
+		return nil, err
+	}
+	client, ctx, cancel, err := clientutil.NewClient(ctx, config.Namespace, gelfLogger.Address)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		cancel()
+		client.Close()
+	}()
+	container, err := client.LoadContainer(ctx, config.ID)
+	if err != nil {
+		return nil, err
+	}
+	info, err := container.Info(ctx)
+	if err != nil {
+		return nil, err
+	}
+	extra := map[string]string{
+		"_container_id":   config.ID,
+		"_container_name": containerutil.GetContainerName(info.Labels),
+		"_image_name":     info.Image,
+		"_created":        info.CreatedAt.Format(time.RFC3339Nano),
+		"_tag":            tag,
+	}
+	if image, err := container.Image(ctx); err == nil {
+		extra["_image_id"] = image.Target().Digest.String()
+	}
+	spec, err := container.Spec(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if spec.Process != nil {
+		extra["_command"] = strings.Join(spec.Process.Args, " ")
+	}
+	for key, value := range gelfSelectedFields(gelfLogger.Opts, info.Labels, spec) {
+		// Do not let labels or environment variables override the metadata.
+		if _, ok := extra["_"+key]; !ok {
+			extra["_"+key] = value
+		}
+	}
+	return extra, nil
+}
+
+// gelfSelectedFields returns the container labels and environment variables
+// listed by the labels and env log options.
+func gelfSelectedFields(opts map[string]string, containerLabels map[string]string, spec *specs.Spec) map[string]string {
+	fields := make(map[string]string)
+	if keys, ok := opts[Labels]; ok {
+		for _, key := range strings.Split(keys, ",") {
+			if value, ok := containerLabels[key]; ok {
+				fields[key] = value
+			}
+		}
+	}
+	if keys, ok := opts[Env]; ok && spec.Process != nil {
+		env := strutil.ConvertKVStringsToMap(spec.Process.Env)
+		for _, key := range strings.Split(keys, ",") {
+			if value, ok := env[key]; ok {
+				fields[key] = value
+			}
+		}
+	}
+	return fields
+}
+
+func (gelfLogger *GelfLogger) Process(stdout <-chan string, stderr <-chan string) error {
+	var wg sync.WaitGroup
+	wg.Add(2)
+	fn := func(dataChan <-chan string, level int) {
+		defer wg.Done()
+		for line := range dataChan {
+			if err := gelfLogger.writer.WriteMessage(gelfLogger.newMessage(line, level, time.Now())); err != nil {
+				log.L.WithError(err).Error("failed to send gelf message")
+			}
+		}
+	}
+	go fn(stdout, gelf.LevelInfo)
+	go fn(stderr, gelf.LevelError)
+	wg.Wait()
+	return nil
+}
+
+func (gelfLogger *GelfLogger) newMessage(line string, level int, t time.Time) *gelf.Message {
+	return &gelf.Message{
+		Version:      gelf.Version,
+		Host:         gelfLogger.host,
+		ShortMessage: strings.TrimSuffix(line, "\n"),
+		Timestamp:    float64(t.UnixNano()) / float64(time.Second),
+		Level:        level,
+		Extra:        gelfLogger.extra,
+	}
+}
+
+func (gelfLogger *GelfLogger) PostProcess() error {
+	return gelfLogger.writer.Close()
+}
@@ -0,0 +1,65 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package logging
+
+import (
+	"testing"
+
+	"github.com/opencontainers/runtime-spec/specs-go"
+	"gotest.tools/v3/assert"
+)
+
+func TestGelfLogOptsValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    map[string]string
+		wantErr string
+	}{
+		{name: "udp", opts: map[string]string{gelfAddress: "udp://127.0.0.1:12201"}},
+		{name: "tcp", opts: map[string]string{gelfAddress: "tcp://graylog:12201"}},
+		{name: "compression", opts: map[string]string{gelfAddress: "udp://127.0.0.1:12201", gelfCompressionType: "zlib", gelfCompressionLevel: "9"}},
+		{name: "missingAddress", opts: map[string]string{}, wantErr: "gelf-address is required"},
+		{name: "missingPort", opts: map[string]string{gelfAddress: "udp://127.0.0.1"}, wantErr: "missing port"},
+		{name: "invalidScheme", opts: map[string]string{gelfAddress: "http://127.0.0.1:12201"}, wantErr: "unsupported scheme"},
+		{name: "invalidCompression", opts: map[string]string{gelfAddress: "udp://127.0.0.1:12201", gelfCompressionType: "lz4"}, wantErr: "unsupported GELF compression type"},
+		{name: "invalidLevel", opts: map[string]string{gelfAddress: "udp://127.0.0.1:12201", gelfCompressionLevel: "10"}, wantErr: "invalid gelf-compression-level"},
+		{name: "tcpCompression", opts: map[string]string{gelfAddress: "tcp://127.0.0.1:12201", gelfCompressionType: "gzip"}, wantErr: "not supported with the tcp protocol"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := GelfLogOptsValidate(tt.opts)
+			if tt.wantErr == "" {
+				assert.NilError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGelfSelectedFields(t *testing.T) {
+	opts := map[string]string{
+		Labels: "app,missing",
+		Env:    "REGION",
+	}
+	containerLabels := map[string]string{"app": "web", "other": "x"}
+	spec := &specs.Spec{Process: &specs.Process{Env: []string{"REGION=eu", "SECRET=s"}}}
+	assert.DeepEqual(t, gelfSelectedFields(opts, containerLabels, spec), map[string]string{
+		"app":    "web",
+		"REGION": "eu",
+	})
+}
@@ -17,7 +17,6 @@
 package logging
 
 import (
-	"bytes"
 	"context"
 	"errors"
 	"fmt"
@@ -26,11 +25,9 @@ import (
 	"os/exec"
 	"strconv"
 	"sync"
-	"text/template"
 	"time"
 
 	"github.com/coreos/go-systemd/v22/journal"
-	"github.com/docker/cli/templates"
 
 	"github.com/containerd/containerd/v2/core/runtime/v2/logging"
 	"github.com/containerd/log"
@@ -62,12 +59,6 @@ type JournaldLogger struct {
 	Address string
 }
 
-type identifier struct {
-	ID        string
-	FullID    string
-	Namespace string
-}
-
 func (journaldLogger *JournaldLogger) Init(dataStore, ns, id string) error {
 	return nil
 }
@@ -77,29 +68,9 @@ func (journaldLogger *JournaldLogger) PreProcess(ctx context.Context, dataStore
 		return errors.New("the local systemd journal is not available for logging")
 	}
 	shortID := config.ID[:12]
-	var syslogIdentifier string
-	if _, ok := journaldLogger.Opts[Tag]; !ok {
-		syslogIdentifier = shortID
-	} else {
-		var tmpl *template.Template
-		var err error
-		tmpl, err = templates.Parse(journaldLogger.Opts[Tag])
-		if err != nil {
-			return err
-		}
-
-		if tmpl != nil {
-			idn := identifier{
-				ID:        shortID,
-				FullID:    config.ID,
-				Namespace: config.Namespace,
-			}
-			var b bytes.Buffer
-			if err := tmpl.Execute(&b, idn); err != nil {
-				return err
-			}
-			syslogIdentifier = b.String()
-		}
+	syslogIdentifier, err := formatLogTag(journaldLogger.Opts, config)
+	if err != nil {
+		return err
 	}
 
 	client, ctx, cancel, err := clientutil.NewClient(ctx, config.Namespace, journaldLogger.Address)
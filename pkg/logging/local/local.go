@@ -0,0 +1,673 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+// Package local implements the on-disk format of the "local" logging driver.
+//
+// Each log entry is stored as a length-prefixed binary record:
+//
+//	| size (uint32) | stream (uint8) | time (int64, unix nanoseconds) | line | size (uint32) |
+//
+// where size is the length of the stream, time and line fields. The trailing
+// size allows walking a log file backwards when tailing it.
+//
+// Alongside each log file, an index file records the time and the offset of a
+// record every indexInterval bytes, so that readers can seek to the first
+// record of interest instead of decoding the whole file.
+//
+// Rotated log files are optionally gzip-compressed in the background, so that
+// the writer is not blocked meanwhile. The offsets in their index refer to the
+// uncompressed stream.
+package local
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/containerd/log"
+)
+
+const (
+	// sizeFieldLen is the length of the leading and trailing size fields.
+	sizeFieldLen = 4
+	// minRecordSize is the size of a record holding an empty line.
+	minRecordSize = 1 + 8
+	// maxLineSize is the maximum length of the line of a record.
+	// Longer lines are split into several records.
+	maxLineSize = 1 << 20
+	// indexInterval is the minimum number of bytes between indexed records.
+	indexInterval = 32 << 10
+	// indexEntrySize is the size of an index entry: time and offset.
+	indexEntrySize = 8 + 8
+
+	indexSuffix      = ".idx"
+	compressedSuffix = ".gz"
+)
+
+const (
+	streamStdout byte = 1
+	streamStderr byte = 2
+)
+
+// ErrCorrupted is returned when a log file contains an invalid record.
+var ErrCorrupted = errors.New("corrupted log record")
+
+// Entry is a log entry of the "local" logging driver.
+type Entry struct {
+	Stream string    // "stdout" or "stderr"
+	Time   time.Time // time the line was logged
+	Log    string    // line, including "\n"
+}
+
+// Path returns the path of the log file of a container.
+func Path(dataStore, ns, id string) string {
+	// the directory and file names correspond to Docker
+	return filepath.Join(dataStore, "containers", ns, id, "local-logs", "container.log")
+}
+
+// CachePath returns the path of the local cache of the logs of a container
+// whose logging driver cannot read logs back.
+func CachePath(dataStore, ns, id string) string {
+	// the file name corresponds to Docker
+	return filepath.Join(dataStore, "containers", ns, id, "local-logs", "container-cached.log")
+}
+
+func indexPath(path string) string {
+	return path + indexSuffix
+}
+
+func rotatedPath(path string, n int) string {
+	return path + "." + strconv.Itoa(n)
+}
+
+func appendRecord(buf []byte, stream string, t time.Time, line string) ([]byte, error) {
+	var s byte
+	switch stream {
+	case "stdout":
+		s = streamStdout
+	case "stderr":
+		s = streamStderr
+	default:
+		return nil, fmt.Errorf("unknown stream name %q", stream)
+	}
+	size := uint32(minRecordSize + len(line))
+	buf = binary.BigEndian.AppendUint32(buf, size)
+	buf = append(buf, s)
+	buf = binary.BigEndian.AppendUint64(buf, uint64(t.UnixNano()))
+	buf = append(buf, line...)
+	buf = binary.BigEndian.AppendUint32(buf, size)
+	return buf, nil
+}
+
+// Writer writes log entries to a log file, rotating the file when it exceeds
+// its maximum size. It is safe for concurrent use.
+type Writer struct {
+	mu       sync.Mutex
+	path     string
+	maxSize  int64
+	maxFile  int
+	compress bool
+
+	file        *os.File
+	index       *os.File
+	size        int64
+	lastIndexed int64
+	buf         []byte
+	// compressing is closed once the last rotated file is compressed.
+	compressing chan struct{}
+}
+
+// NewWriter opens the log file at path for appending, creating it if needed.
+// When maxSize is positive, the file is rotated once it would exceed maxSize
+// bytes, keeping at most maxFile files including the current one. Rotated
+// files are gzip-compressed in the background when compress is true.
+func NewWriter(path string, maxSize int64, maxFile int, compress bool) (*Writer, error) {
+	if maxFile < 1 {
+		return nil, errors.New("max-file cannot be less than 1")
+	}
+	w := &Writer{
+		path:     path,
+		maxSize:  maxSize,
+		maxFile:  maxFile,
+		compress: compress,
+	}
+	if err := w.open(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+func (w *Writer) open() error {
+	file, err := os.OpenFile(w.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	st, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	index, err := os.OpenFile(indexPath(w.path), os.O_RDWR|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		file.Close()
+		return err
+	}
+	entries, err := readIndex(index)
+	if err != nil {
+		file.Close()
+		index.Close()
+		return err
+	}
+	// Keep the indexing interval when appending to an existing log file,
+	// e.g. on container restart.
+	w.lastIndexed = -indexInterval
+	if len(entries) > 0 {
+		w.lastIndexed = entries[len(entries)-1].Offset
+	}
+	w.file, w.index, w.size = file, index, st.Size()
+	return nil
+}
+
+// WriteEntry writes a log line for the given stream ("stdout" or "stderr"),
+// timestamped with the current time.
+func (w *Writer) WriteEntry(stream, line string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	now := time.Now().UTC()
+	for {
+		n := min(len(line), maxLineSize)
+		if err := w.write(stream, now, line[:n]); err != nil {
+			return err
+		}
+		line = line[n:]
+		if line == "" {
+			return nil
+		}
+	}
+}
+
+func (w *Writer) write(stream string, t time.Time, line string) error {
+	rec, err := appendRecord(w.buf[:0], stream, t, line)
+	if err != nil {
+		return err
+	}
+	w.buf = rec
+	if w.maxSize > 0 && w.size > 0 && w.size+int64(len(rec)) > w.maxSize {
+		if err := w.rotate(); err != nil {
+			return fmt.Errorf("failed to rotate log file %q: %w", w.path, err)
+		}
+	}
+	offset := w.size
+	n, err := w.file.Write(rec)
+	w.size += int64(n)
+	if err != nil {
+		return err
+	}
+	if offset-w.lastIndexed >= indexInterval {
+		var e [indexEntrySize]byte
+		binary.BigEndian.PutUint64(e[:8], uint64(t.UnixNano()))
+		binary.BigEndian.PutUint64(e[8:], uint64(offset))
+		if _, err := w.index.Write(e[:]); err != nil {
+			return err
+		}
+		w.lastIndexed = offset
+	}
+	return nil
+}
+
+// rotate moves the current log file and its index to "<path>.1", shifting the
+// previously rotated files and dropping the oldest one, and opens a new log
+// file.
+func (w *Writer) rotate() error {
+	if err := w.closeFiles(); err != nil {
+		return err
+	}
+	// The rotated files are renamed below, so the compression of the last
+	// one must be done. It normally is, unless rotating faster than compressing.
+	w.waitCompression()
+	if w.maxFile == 1 {
+		for _, p := range []string{w.path, indexPath(w.path)} {
+			if err := os.Remove(p); err != nil && !errors.Is(err, os.ErrNotExist) {
+				return err
+			}
+		}
+		return w.open()
+	}
+	for n := w.maxFile - 1; n > 0; n-- {
+		src := rotatedPath(w.path, n)
+		for _, suffix := range []string{"", compressedSuffix, indexSuffix} {
+			var err error
+			if n == w.maxFile-1 {
+				err = os.Remove(src + suffix)
+			} else {
+				err = os.Rename(src+suffix, rotatedPath(w.path, n+1)+suffix)
+			}
+			if err != nil && !errors.Is(err, os.ErrNotExist) {
+				return err
+			}
+		}
+	}
+	rotated := rotatedPath(w.path, 1)
+	if err := os.Rename(w.path, rotated); err != nil {
+		return err
+	}
+	if err := os.Rename(indexPath(w.path), indexPath(rotated)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	if err := w.open(); err != nil {
+		return err
+	}
+	if w.compress {
+		done := make(chan struct{})
+		w.compressing = done
+		go func() {
+			defer close(done)
+			if err := compressFile(rotated); err != nil {
+				log.L.WithError(err).Errorf("failed to compress the rotated log file %q", rotated)
+			}
+		}()
+	}
+	return nil
+}
+
+func (w *Writer) waitCompression() {
+	if w.compressing != nil {
+		<-w.compressing
+		w.compressing = nil
+	}
+}
+
+// compressFile replaces the file at path with a gzip-compressed copy named
+// "<path>.gz". The compressed file only appears once complete.
+func compressFile(path string) error {
+	tmp := path + compressedSuffix + ".tmp"
+	if err := writeCompressed(tmp, path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, path+compressedSuffix); err != nil {
+		return err
+	}
+	return os.Remove(path)
+}
+
+func writeCompressed(dst, src string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+	zw := gzip.NewWriter(out)
+	if _, err := io.Copy(zw, in); err != nil {
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		return err
+	}
+	return out.Close()
+}
+
+func (w *Writer) closeFiles() error {
+	err := w.file.Close()
+	if indexErr := w.index.Close(); err == nil {
+		err = indexErr
+	}
+	return err
+}
+
+// Close closes the log file, after the compression of the last rotated file.
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.waitCompression()
+	return w.closeFiles()
+}
+
+// Decoder reads records sequentially from a log file.
+type Decoder struct {
+	r      *bufio.Reader
+	offset int64
+	buf    []byte
+}
+
+// NewDecoder returns a Decoder reading records from r.
+func NewDecoder(r io.Reader) *Decoder {
+	return &Decoder{r: bufio.NewReader(r)}
+}
+
+// Reset makes the decoder read records from r, which is positioned at the
+// given offset of the log file.
+func (d *Decoder) Reset(r io.Reader, offset int64) {
+	d.r.Reset(r)
+	d.offset = offset
+}
+
+// Offset returns the offset of the next record in the log file.
+// It is not advanced by records that could only be partially read.
+func (d *Decoder) Offset() int64 {
+	return d.offset
+}
+
+// Decode reads the next entry. It returns io.EOF at the end of the log file,
+// and io.ErrUnexpectedEOF when the file ends in the middle of a record, e.g.
+// while it is being written.
+func (d *Decoder) Decode() (*Entry, error) {
+	size, err := d.readSize()
+	if err != nil {
+		return nil, err
+	}
+	n := int(size) + sizeFieldLen
+	if cap(d.buf) < n {
+		d.buf = make([]byte, n)
+	}
+	buf := d.buf[:n]
+	if _, err := io.ReadFull(d.r, buf); err != nil {
+		if errors.Is(err, io.EOF) {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	if binary.BigEndian.Uint32(buf[size:]) != size {
+		return nil, ErrCorrupted
+	}
+	e := &Entry{
+		Time: time.Unix(0, int64(binary.BigEndian.Uint64(buf[1:9]))).UTC(),
+		Log:  string(buf[9:size]),
+	}
+	switch buf[0] {
+	case streamStdout:
+		e.Stream = "stdout"
+	case streamStderr:
+		e.Stream = "stderr"
+	default:
+		return nil, ErrCorrupted
+	}
+	d.offset += int64(sizeFieldLen + n)
+	return e, nil
+}
+
+// skip skips the next record without decoding it.
+func (d *Decoder) skip() error {
+	size, err := d.readSize()
+	if err != nil {
+		return err
+	}
+	n := int(size) + sizeFieldLen
+	if _, err := d.r.Discard(n); err != nil {
+		if errors.Is(err, io.EOF) {
+			err = io.ErrUnexpectedEOF
+		}
+		return err
+	}
+	d.offset += int64(sizeFieldLen + n)
+	return nil
+}
+
+func (d *Decoder) readSize() (uint32, error) {
+	var b [sizeFieldLen]byte
+	if _, err := io.ReadFull(d.r, b[:]); err != nil {
+		return 0, err
+	}
+	size := binary.BigEndian.Uint32(b[:])
+	if size < minRecordSize || size > minRecordSize+maxLineSize {
+		return 0, ErrCorrupted
+	}
+	return size, nil
+}
+
+// Segment is a log file: either the current one, or a rotated one.
+type Segment struct {
+	// Path is the path of the log file.
+	Path string
+	// Compressed is true when the log file is gzip-compressed.
+	Compressed bool
+
+	index string
+}
+
+// Segments returns the log files of the log at path, oldest first.
+// The last segment is always the current log file.
+func Segments(path string) ([]Segment, error) {
+	dir, base := filepath.Split(path)
+	dirEntries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	rotated := make(map[int]Segment)
+	for _, de := range dirEntries {
+		rest, ok := strings.CutPrefix(de.Name(), base+".")
+		if !ok {
+			continue
+		}
+		rest, compressed := strings.CutSuffix(rest, compressedSuffix)
+		n, err := strconv.Atoi(rest)
+		if err != nil || n < 1 {
+			continue
+		}
+		// While a rotated file is being compressed, both the uncompressed
+		// and the compressed files may exist. The compressed one is only
+		// renamed into place once complete, so prefer it.
+		if s, ok := rotated[n]; ok && s.Compressed {
+			continue
+		}
+		rotated[n] = Segment{
+			Path:       filepath.Join(dir, de.Name()),
+			Compressed: compressed,
+			index:      indexPath(rotatedPath(path, n)),
+		}
+	}
+	nums := make([]int, 0, len(rotated))
+	for n := range rotated {
+		nums = append(nums, n)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(nums)))
+	segments := make([]Segment, 0, len(nums)+1)
+	for _, n := range nums {
+		segments = append(segments, rotated[n])
+	}
+	return append(segments, Segment{Path: path, index: indexPath(path)}), nil
+}
+
+// Open opens the segment for reading, decompressing it if needed.
+// A rotated file that has been compressed since Segments listed it is opened
+// compressed.
+func (s Segment) Open() (io.ReadCloser, error) {
+	f, err := os.Open(s.Path)
+	if err != nil && !s.Compressed && errors.Is(err, os.ErrNotExist) {
+		var gzErr error
+		if f, gzErr = os.Open(s.Path + compressedSuffix); gzErr == nil {
+			s.Compressed, err = true, nil
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !s.Compressed {
+		return f, nil
+	}
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return &gzipReadCloser{Reader: zr, file: f}, nil
+}
+
+type gzipReadCloser struct {
+	*gzip.Reader
+	file *os.File
+}
+
+func (r *gzipReadCloser) Close() error {
+	err := r.Reader.Close()
+	if fileErr := r.file.Close(); err == nil {
+		err = fileErr
+	}
+	return err
+}
+
+// IndexEntry locates a record of a log file.
+type IndexEntry struct {
+	Time   time.Time
+	Offset int64
+}
+
+// Index returns the index entries of the segment, in order. A missing index
+// is treated as empty.
+func (s Segment) Index() ([]IndexEntry, error) {
+	f, err := os.Open(s.index)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+	return readIndex(f)
+}
+
+func readIndex(f *os.File) ([]IndexEntry, error) {
+	st, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	// Ignore a trailing partial entry, which may be being written.
+	buf := make([]byte, st.Size()/indexEntrySize*indexEntrySize)
+	if _, err := f.ReadAt(buf, 0); err != nil {
+		return nil, err
+	}
+	entries := make([]IndexEntry, 0, len(buf)/indexEntrySize)
+	for b := buf; len(b) > 0; b = b[indexEntrySize:] {
+		entries = append(entries, IndexEntry{
+			Time:   time.Unix(0, int64(binary.BigEndian.Uint64(b[:8]))).UTC(),
+			Offset: int64(binary.BigEndian.Uint64(b[8:indexEntrySize])),
+		})
+	}
+	return entries, nil
+}
+
+// SeekIndex returns the offset of a record such that all the records before
+// it were logged before since. It returns 0 when no such record is indexed.
+func SeekIndex(entries []IndexEntry, since time.Time) int64 {
+	i := sort.Search(len(entries), func(i int) bool {
+		return !entries[i].Time.Before(since)
+	})
+	if i == 0 {
+		return 0
+	}
+	return entries[i-1].Offset
+}
+
+// TailOffset returns the offset of the n-th last record of the uncompressed
+// log file f, walking the file backwards, and the number of records found,
+// which is less than n when the file holds fewer records.
+func TailOffset(f io.ReadSeeker, n uint) (int64, uint, error) {
+	end, err := f.Seek(0, io.SeekEnd)
+	if err != nil {
+		return 0, 0, err
+	}
+	var (
+		b     [sizeFieldLen]byte
+		pos   = end
+		found uint
+	)
+	for found < n && pos > 0 {
+		if pos < 2*sizeFieldLen+minRecordSize {
+			return tailOffsetScan(f, n)
+		}
+		if _, err := f.Seek(pos-sizeFieldLen, io.SeekStart); err != nil {
+			return 0, 0, err
+		}
+		if _, err := io.ReadFull(f, b[:]); err != nil {
+			return 0, 0, err
+		}
+		start := pos - 2*sizeFieldLen - int64(binary.BigEndian.Uint32(b[:]))
+		if start < 0 {
+			return tailOffsetScan(f, n)
+		}
+		if _, err := f.Seek(start, io.SeekStart); err != nil {
+			return 0, 0, err
+		}
+		prefix := b
+		if _, err := io.ReadFull(f, prefix[:]); err != nil {
+			return 0, 0, err
+		}
+		if prefix != b {
+			// The file does not end with a complete record, e.g. because
+			// it is being written: fall back to scanning it forwards.
+			return tailOffsetScan(f, n)
+		}
+		pos = start
+		found++
+	}
+	return pos, found, nil
+}
+
+func tailOffsetScan(f io.ReadSeeker, n uint) (int64, uint, error) {
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return 0, 0, err
+	}
+	return TailOffsetStream(f, n)
+}
+
+// TailOffsetStream is like TailOffset, but reads the log file r forwards, so
+// that it also works with compressed log files. A trailing partial record is
+// ignored.
+func TailOffsetStream(r io.Reader, n uint) (int64, uint, error) {
+	if n == 0 {
+		return 0, 0, nil
+	}
+	d := NewDecoder(r)
+	// ring holds the offsets of the last n records.
+	var (
+		ring  []int64
+		count uint
+	)
+	for {
+		offset := d.Offset()
+		if err := d.skip(); err != nil {
+			if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+				break
+			}
+			return 0, 0, err
+		}
+		if uint(len(ring)) < n {
+			ring = append(ring, offset)
+		} else {
+			ring[count%n] = offset
+		}
+		count++
+	}
+	if count < n {
+		return 0, count, nil
+	}
+	return ring[count%n], n, nil
+}
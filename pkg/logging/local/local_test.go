@@ -0,0 +1,197 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package local
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+)
+
+func readAll(t *testing.T, r io.Reader) []*Entry {
+	t.Helper()
+	var entries []*Entry
+	dec := NewDecoder(r)
+	for {
+		e, err := dec.Decode()
+		if errors.Is(err, io.EOF) {
+			return entries
+		}
+		assert.NilError(t, err)
+		entries = append(entries, e)
+	}
+}
+
+func TestWriteDecode(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "container.log")
+	w, err := NewWriter(path, 0, 1, false)
+	assert.NilError(t, err)
+	assert.NilError(t, w.WriteEntry("stdout", "foo\n"))
+	assert.NilError(t, w.WriteEntry("stderr", "bar\n"))
+	assert.NilError(t, w.WriteEntry("stdout", "no newline"))
+	assert.NilError(t, w.Close())
+
+	f, err := os.Open(path)
+	assert.NilError(t, err)
+	defer f.Close()
+	entries := readAll(t, f)
+	assert.Equal(t, len(entries), 3)
+	assert.Equal(t, entries[0].Stream, "stdout")
+	assert.Equal(t, entries[0].Log, "foo\n")
+	assert.Equal(t, entries[1].Stream, "stderr")
+	assert.Equal(t, entries[1].Log, "bar\n")
+	assert.Equal(t, entries[2].Log, "no newline")
+	assert.Assert(t, !entries[1].Time.Before(entries[0].Time))
+}
+
+func TestDecodePartialRecord(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "container.log")
+	w, err := NewWriter(path, 0, 1, false)
+	assert.NilError(t, err)
+	assert.NilError(t, w.WriteEntry("stdout", "foo\n"))
+	assert.NilError(t, w.WriteEntry("stdout", "bar\n"))
+	assert.NilError(t, w.Close())
+
+	data, err := os.ReadFile(path)
+	assert.NilError(t, err)
+	dec := NewDecoder(strings.NewReader(string(data[:len(data)-3])))
+	e, err := dec.Decode()
+	assert.NilError(t, err)
+	assert.Equal(t, e.Log, "foo\n")
+	offset := dec.Offset()
+	_, err = dec.Decode()
+	assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+	assert.Equal(t, dec.Offset(), offset)
+}
+
+func TestRotate(t *testing.T) {
+	for _, compress := range []bool{false, true} {
+		t.Run(fmt.Sprintf("compress=%v", compress), func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "container.log")
+			line := strings.Repeat("x", 99) + "\n"
+			// Each record is 121 bytes: fit 4 records per file.
+			w, err := NewWriter(path, 500, 3, compress)
+			assert.NilError(t, err)
+			for i := 0; i < 20; i++ {
+				assert.NilError(t, w.WriteEntry("stdout", line))
+			}
+			assert.NilError(t, w.Close())
+
+			segments, err := Segments(path)
+			assert.NilError(t, err)
+			assert.Equal(t, len(segments), 3)
+			assert.Equal(t, segments[0].Path, path+".2"+map[bool]string{true: ".gz"}[compress])
+			assert.Equal(t, segments[0].Compressed, compress)
+			assert.Equal(t, segments[1].Path, path+".1"+map[bool]string{true: ".gz"}[compress])
+			assert.Equal(t, segments[2].Path, path)
+			assert.Equal(t, segments[2].Compressed, false)
+
+			for _, s := range segments {
+				r, err := s.Open()
+				assert.NilError(t, err)
+				assert.Equal(t, len(readAll(t, r)), 4)
+				r.Close()
+				index, err := s.Index()
+				assert.NilError(t, err)
+				assert.Equal(t, len(index), 1)
+				assert.Equal(t, index[0].Offset, int64(0))
+			}
+		})
+	}
+}
+
+func TestOpenCompressedMeanwhile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "container.log")
+	line := strings.Repeat("x", 99) + "\n"
+	w, err := NewWriter(path, 500, 3, false)
+	assert.NilError(t, err)
+	for i := 0; i < 8; i++ {
+		assert.NilError(t, w.WriteEntry("stdout", line))
+	}
+	assert.NilError(t, w.Close())
+
+	segments, err := Segments(path)
+	assert.NilError(t, err)
+	assert.Equal(t, len(segments), 2)
+	assert.Equal(t, segments[0].Compressed, false)
+	// The rotated file is compressed after being listed.
+	assert.NilError(t, compressFile(segments[0].Path))
+
+	r, err := segments[0].Open()
+	assert.NilError(t, err)
+	defer r.Close()
+	assert.Equal(t, len(readAll(t, r)), 4)
+}
+
+func TestTailOffset(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "container.log")
+	w, err := NewWriter(path, 0, 1, false)
+	assert.NilError(t, err)
+	for i := 0; i < 10; i++ {
+		assert.NilError(t, w.WriteEntry("stdout", fmt.Sprintf("line%d\n", i)))
+	}
+	assert.NilError(t, w.Close())
+
+	f, err := os.Open(path)
+	assert.NilError(t, err)
+	defer f.Close()
+	for _, tc := range []struct {
+		n     uint
+		found uint
+		first string
+	}{
+		{n: 3, found: 3, first: "line7\n"},
+		{n: 10, found: 10, first: "line0\n"},
+		{n: 20, found: 10, first: "line0\n"},
+	} {
+		offset, found, err := TailOffset(f, tc.n)
+		assert.NilError(t, err)
+		assert.Equal(t, found, tc.found)
+		_, err = f.Seek(offset, io.SeekStart)
+		assert.NilError(t, err)
+		assert.Equal(t, readAll(t, f)[0].Log, tc.first)
+
+		_, err = f.Seek(0, io.SeekStart)
+		assert.NilError(t, err)
+		streamOffset, streamFound, err := TailOffsetStream(f, tc.n)
+		assert.NilError(t, err)
+		assert.Equal(t, streamOffset, offset)
+		assert.Equal(t, streamFound, found)
+	}
+}
+
+func TestSeekIndex(t *testing.T) {
+	base := time.Unix(1000, 0)
+	index := []IndexEntry{
+		{Time: base, Offset: 0},
+		{Time: base.Add(10 * time.Second), Offset: 100},
+		{Time: base.Add(20 * time.Second), Offset: 200},
+	}
+	assert.Equal(t, SeekIndex(index, base.Add(-time.Second)), int64(0))
+	assert.Equal(t, SeekIndex(index, base), int64(0))
+	assert.Equal(t, SeekIndex(index, base.Add(15*time.Second)), int64(100))
+	assert.Equal(t, SeekIndex(index, base.Add(20*time.Second)), int64(100))
+	assert.Equal(t, SeekIndex(index, base.Add(time.Minute)), int64(200))
+	assert.Equal(t, SeekIndex(nil, base), int64(0))
+}
@@ -0,0 +1,448 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package logging
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/docker/go-units"
+
+	"github.com/containerd/containerd/v2/core/runtime/v2/logging"
+	"github.com/containerd/log"
+
+	"github.com/containerd/nerdctl/v2/pkg/logging/local"
+	"github.com/containerd/nerdctl/v2/pkg/strutil"
+	"github.com/containerd/nerdctl/v2/pkg/timestamp"
+)
+
+const (
+	localCompress = "compress"
+
+	// The defaults correspond to Docker.
+	localDefaultMaxSize  = 20 * 1024 * 1024
+	localDefaultMaxFile  = 5
+	localDefaultCompress = true
+)
+
+var LocalDriverLogOpts = []string{
+	MaxSize,
+	MaxFile,
+	localCompress,
+}
+
+type LocalLogger struct {
+	Opts   map[string]string
+	writer *local.Writer
+}
+
+type localLogOpts struct {
+	maxSize  int64
+	maxFile  int
+	compress bool
+}
+
+func parseLocalLogOpts(logOptMap map[string]string) (localLogOpts, error) {
+	opts := localLogOpts{
+		maxSize:  localDefaultMaxSize,
+		maxFile:  localDefaultMaxFile,
+		compress: localDefaultCompress,
+	}
+	if s, ok := logOptMap[MaxSize]; ok {
+		v, err := units.FromHumanSize(s)
+		if err != nil {
+			return opts, err
+		}
+		if v < 0 {
+			return opts, fmt.Errorf("max-size cannot be negative")
+		}
+		// A max-size of 0 disables rotation, as with Docker.
+		opts.maxSize = v
+	}
+	if s, ok := logOptMap[MaxFile]; ok {
+		v, err := strconv.Atoi(s)
+		if err != nil {
+			return opts, err
+		}
+		if v < 1 {
+			return opts, fmt.Errorf("max-file cannot be less than 1")
+		}
+		opts.maxFile = v
+	}
+	if s, ok := logOptMap[localCompress]; ok {
+		v, err := strconv.ParseBool(s)
+		if err != nil {
+			return opts, fmt.Errorf("invalid value for %s: %w", localCompress, err)
+		}
+		opts.compress = v
+	}
+	return opts, nil
+}
+
+func LocalLogOptsValidate(logOptMap map[string]string) error {
+	for key := range logOptMap {
+		if !strutil.InStringSlice(LocalDriverLogOpts, key) {
+			log.L.Warnf("log-opt %s is ignored for local log driver", key)
+		}
+	}
+	_, err := parseLocalLogOpts(logOptMap)
+	return err
+}
+
+func (localLogger *LocalLogger) Init(dataStore, ns, id string) error {
+	logPath := local.Path(dataStore, ns, id)
+	if err := os.MkdirAll(filepath.Dir(logPath), 0700); err != nil {
+		return err
+	}
+	// Create the log file, so that viewing logs does not fail before the
+	// container has started.
+	f, err := os.OpenFile(logPath, os.O_WRONLY|os.O_CREATE, 0600)
+	if err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+func (localLogger *LocalLogger) PreProcess(ctx context.Context, dataStore string, config *logging.Config) error {
+	opts, err := parseLocalLogOpts(localLogger.Opts)
+	if err != nil {
+		return err
+	}
+	logPath := local.Path(dataStore, config.Namespace, config.ID)
+	if err := os.MkdirAll(filepath.Dir(logPath), 0700); err != nil {
+		return err
+	}
+	localLogger.writer, err = local.NewWriter(logPath, opts.maxSize, opts.maxFile, opts.compress)
+	return err
+}
+
+func (localLogger *LocalLogger) Process(stdout <-chan string, stderr <-chan string) error {
+	// LocalLogger is a SyncDriver, so Process is only here to implement Driver.
+	done := make(chan struct{})
+	go func() {
+		for line := range stderr {
+			localLogger.writeOrLog(streamStderr, line)
+		}
+		close(done)
+	}()
+	for line := range stdout {
+		localLogger.writeOrLog(streamStdout, line)
+	}
+	<-done
+	return nil
+}
+
+func (localLogger *LocalLogger) writeOrLog(stream, line string) {
+	if err := localLogger.WriteLogEntry(stream, line); err != nil {
+		log.L.WithError(err).Error("failed to write log entry")
+	}
+}
+
+// WriteLogEntry writes a single log line synchronously, implementing SyncDriver.
+func (localLogger *LocalLogger) WriteLogEntry(stream, line string) error {
+	return localLogger.writer.WriteEntry(stream, line)
+}
+
+func (localLogger *LocalLogger) PostProcess() error {
+	if localLogger.writer == nil {
+		return nil
+	}
+	return localLogger.writer.Close()
+}
+
+// Loads log entries from logfiles produced by the local driver and forwards
+// them to the provided io.Writers after applying the provided logging options.
+func viewLogsLocal(lvopts LogViewOptions, stdout, stderr io.Writer, stopChannel chan os.Signal) error {
+	logPath := local.Path(lvopts.DatastoreRootPath, lvopts.Namespace, lvopts.ContainerID)
+	if _, err := os.Stat(logPath); err != nil {
+		return fmt.Errorf("failed to stat local log file: %w", err)
+	}
+	return viewLogsLocalDirect(lvopts, logPath, stdout, stderr, stopChannel)
+}
+
+// localLogView writes the entries of a local log matching the viewing options.
+type localLogView struct {
+	stdout, stderr io.Writer
+	timestamps     bool
+	since, until   time.Time
+	// done is set once an entry logged after until has been read.
+	done bool
+}
+
+func parseLogViewTime(value string, now time.Time) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	ts, err := timestamp.GetTimestamp(value, now)
+	if err != nil {
+		return time.Time{}, err
+	}
+	sec, nsec, err := timestamp.ParseTimestamps(ts, 0)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(sec, nsec), nil
+}
+
+func (v *localLogView) write(e *local.Entry) error {
+	if !v.until.IsZero() && e.Time.After(v.until) {
+		v.done = true
+		return nil
+	}
+	if !v.since.IsZero() && e.Time.Before(v.since) {
+		return nil
+	}
+	w := v.stdout
+	if e.Stream == streamStderr {
+		w = v.stderr
+	}
+	if v.timestamps {
+		if _, err := io.WriteString(w, e.Time.Format(time.RFC3339Nano)+" "); err != nil {
+			return err
+		}
+	}
+	_, err := io.WriteString(w, e.Log)
+	return err
+}
+
+// decode writes the complete entries read by dec. It returns nil at the end
+// of the log file, or when an entry logged after until has been read.
+func (v *localLogView) decode(dec *local.Decoder) error {
+	for !v.done {
+		e, err := dec.Decode()
+		if err != nil {
+			if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+				return nil
+			}
+			return err
+		}
+		if err := v.write(e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// localLogPosition is a position in a local log: the index of a segment and an
+// offset in its uncompressed content.
+type localLogPosition struct {
+	segment int
+	offset  int64
+}
+
+func (p localLogPosition) after(o localLogPosition) bool {
+	return p.segment > o.segment || (p.segment == o.segment && p.offset > o.offset)
+}
+
+// localLogStart returns the position of the first entry to read, using the
+// trailing record sizes of the log files for tail, and their time index for
+// since, so that the entries before it do not need to be decoded.
+func localLogStart(segments []local.Segment, tail uint, since time.Time) (localLogPosition, error) {
+	var start localLogPosition
+	if tail > 0 {
+		remaining := tail
+		for i := len(segments) - 1; i >= 0 && remaining > 0; i-- {
+			r, err := segments[i].Open()
+			if err != nil {
+				return start, err
+			}
+			var (
+				offset int64
+				found  uint
+			)
+			if f, ok := r.(*os.File); ok {
+				offset, found, err = local.TailOffset(f, remaining)
+			} else {
+				offset, found, err = local.TailOffsetStream(r, remaining)
+			}
+			r.Close()
+			if err != nil {
+				return start, fmt.Errorf("failed to tail %d lines of local log file %q: %w", tail, segments[i].Path, err)
+			}
+			start = localLogPosition{segment: i, offset: offset}
+			remaining -= found
+		}
+	}
+	if !since.IsZero() {
+		for i, s := range segments {
+			if i < start.segment {
+				continue
+			}
+			if i+1 < len(segments) {
+				// Skip the segment altogether if the next one starts before since.
+				next, err := segments[i+1].Index()
+				if err != nil {
+					return start, err
+				}
+				if len(next) > 0 && next[0].Offset == 0 && next[0].Time.Before(since) {
+					continue
+				}
+			}
+			index, err := s.Index()
+			if err != nil {
+				return start, err
+			}
+			if p := (localLogPosition{segment: i, offset: local.SeekIndex(index, since)}); p.after(start) {
+				start = p
+			}
+			break
+		}
+	}
+	return start, nil
+}
+
+// Loads local log entries directly from the provided log file and its rotated
+// files. If `LogViewOptions.Follow` is provided, it will re-read the log file
+// as it is written until it receives something through the stopChannel.
+func viewLogsLocalDirect(lvopts LogViewOptions, logPath string, stdout, stderr io.Writer, stopChannel chan os.Signal) error {
+	now := time.Now()
+	since, err := parseLogViewTime(lvopts.Since, now)
+	if err != nil {
+		return fmt.Errorf("invalid value for \"since\": %w", err)
+	}
+	until, err := parseLogViewTime(lvopts.Until, now)
+	if err != nil {
+		return fmt.Errorf("invalid value for \"until\": %w", err)
+	}
+	view := &localLogView{
+		stdout:     stdout,
+		stderr:     stderr,
+		timestamps: lvopts.Timestamps,
+		since:      since,
+		until:      until,
+	}
+
+	segments, err := local.Segments(logPath)
+	if err != nil {
+		return err
+	}
+	start, err := localLogStart(segments, lvopts.Tail, since)
+	if err != nil {
+		return err
+	}
+
+	// Read the rotated log files.
+	for i := start.segment; i < len(segments)-1 && !view.done; i++ {
+		r, err := segments[i].Open()
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				// Removed by a concurrent rotation.
+				continue
+			}
+			return err
+		}
+		var offset int64
+		if i == start.segment {
+			offset = start.offset
+		}
+		if _, err := io.CopyN(io.Discard, r, offset); err != nil {
+			r.Close()
+			return fmt.Errorf("failed to seek in local log file %q to position %d: %w", segments[i].Path, offset, err)
+		}
+		err = view.decode(local.NewDecoder(r))
+		r.Close()
+		if err != nil {
+			return fmt.Errorf("error occurred while reading local log file %q: %w", segments[i].Path, err)
+		}
+	}
+	if view.done {
+		return nil
+	}
+
+	// Read the current log file.
+	fin, err := openFileShareDelete(logPath)
+	if err != nil {
+		return err
+	}
+	defer func() { fin.Close() }()
+	var offset int64
+	if start.segment == len(segments)-1 {
+		offset = start.offset
+	}
+	if _, err := fin.Seek(offset, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to seek in local log file %q to position %d: %w", logPath, offset, err)
+	}
+	dec := local.NewDecoder(fin)
+	dec.Reset(fin, offset)
+	// readCurrent reads the current log file up to its last complete record.
+	readCurrent := func() error {
+		if err := view.decode(dec); err != nil {
+			return fmt.Errorf("error occurred while reading local log file %q: %w", logPath, err)
+		}
+		// Rewind a trailing partial record, to read it again once complete.
+		if _, err := fin.Seek(dec.Offset(), io.SeekStart); err != nil {
+			return err
+		}
+		dec.Reset(fin, dec.Offset())
+		return nil
+	}
+	if err := readCurrent(); err != nil || !lvopts.Follow {
+		return err
+	}
+
+	watcher, err := NewLogFileWatcher(filepath.Dir(logPath))
+	if err != nil {
+		return err
+	}
+	defer watcher.Close()
+	baseName := filepath.Base(logPath)
+	for !view.done {
+		select {
+		case <-stopChannel:
+			// Drain the entries written right before the container exited,
+			// see viewLogsJSONFileDirect.
+			return readCurrent()
+		default:
+		}
+		// Read again after creating the watcher, as we might have missed events.
+		if err := readCurrent(); err != nil {
+			return err
+		}
+		recreated, err := startTail(context.Background(), baseName, watcher)
+		if err != nil {
+			return err
+		}
+		if !recreated {
+			continue
+		}
+		// The log file was rotated: finish reading the previous one before
+		// switching to the new one.
+		if err := readCurrent(); err != nil {
+			return err
+		}
+		newF, err := openFileShareDelete(logPath)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				time.Sleep(10 * time.Millisecond)
+				newF, err = openFileShareDelete(logPath)
+			}
+			if err != nil {
+				return fmt.Errorf("failed to open local log file %q: %w", logPath, err)
+			}
+		}
+		fin.Close()
+		fin = newF
+		dec.Reset(fin, 0)
+	}
+	return nil
+}
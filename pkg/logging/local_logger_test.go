@@ -0,0 +1,92 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package logging
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/containerd/nerdctl/v2/pkg/logging/local"
+)
+
+func TestViewLogsLocal(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "container.log")
+	// Rotate every few lines, compressing the rotated files.
+	w, err := local.NewWriter(path, 100, 10, true)
+	assert.NilError(t, err)
+	for i := 0; i < 10; i++ {
+		stream := "stdout"
+		if i%2 == 1 {
+			stream = "stderr"
+		}
+		assert.NilError(t, w.WriteEntry(stream, fmt.Sprintf("line%d\n", i)))
+	}
+	assert.NilError(t, w.Close())
+
+	view := func(lvopts LogViewOptions) (string, string) {
+		var stdout, stderr bytes.Buffer
+		assert.NilError(t, viewLogsLocalDirect(lvopts, path, &stdout, &stderr, make(chan os.Signal)))
+		return stdout.String(), stderr.String()
+	}
+
+	stdout, stderr := view(LogViewOptions{})
+	assert.Equal(t, stdout, "line0\nline2\nline4\nline6\nline8\n")
+	assert.Equal(t, stderr, "line1\nline3\nline5\nline7\nline9\n")
+
+	stdout, stderr = view(LogViewOptions{Tail: 3})
+	assert.Equal(t, stdout, "line8\n")
+	assert.Equal(t, stderr, "line7\nline9\n")
+
+	stdout, stderr = view(LogViewOptions{Since: "1m", Until: time.Now().Add(-time.Hour).Format(time.RFC3339)})
+	assert.Equal(t, stdout, "")
+	assert.Equal(t, stderr, "")
+
+	stdout, stderr = view(LogViewOptions{Tail: 1, Timestamps: true})
+	assert.Equal(t, stdout, "")
+	assert.Assert(t, strings.HasSuffix(stderr, " line9\n"), stderr)
+}
+
+func TestViewLogsLocalFollow(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "container.log")
+	w, err := local.NewWriter(path, 100, 3, true)
+	assert.NilError(t, err)
+	assert.NilError(t, w.WriteEntry("stdout", "line0\n"))
+
+	var stdout bytes.Buffer
+	stop := make(chan os.Signal)
+	done := make(chan error)
+	go func() {
+		done <- viewLogsLocalDirect(LogViewOptions{Follow: true}, path, &stdout, &stdout, stop)
+	}()
+	// Let the viewer start following, then write through rotations.
+	time.Sleep(50 * time.Millisecond)
+	for i := 1; i < 10; i++ {
+		assert.NilError(t, w.WriteEntry("stdout", fmt.Sprintf("line%d\n", i)))
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.NilError(t, w.Close())
+	stop <- os.Interrupt
+	assert.NilError(t, <-done)
+	assert.Equal(t, stdout.String(), "line0\nline1\nline2\nline3\nline4\nline5\nline6\nline7\nline8\nline9\n")
+}
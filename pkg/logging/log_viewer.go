@@ -45,6 +45,7 @@ func RegisterLogViewer(driverName string, lvfn LogViewerFunc) {
 
 func init() {
 	RegisterLogViewer("json-file", viewLogsJSONFile)
+	RegisterLogViewer("local", viewLogsLocal)
 	RegisterLogViewer("journald", viewLogsJournald)
 	RegisterLogViewer("cri", viewLogsCRI)
 }
@@ -166,7 +167,11 @@ func (lv *ContainerLogViewer) PrintLogsTo(stdout, stderr io.Writer) error {
 	}
 	viewerFunc, err := getLogViewer(lv.loggingConfig.Driver)
 	if err != nil {
-		return err
+		if !logCacheEnabled(lv.loggingConfig) {
+			return err
+		}
+		// The driver cannot read logs back: read them from the local cache.
+		viewerFunc = viewLogsCache
 	}
 
 	return viewerFunc(lv.logViewingOptions, stdout, stderr, lv.stopChannel)
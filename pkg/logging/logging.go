@@ -31,6 +31,7 @@ import (
 	"sync/atomic"
 	"time"
 
+	"github.com/docker/cli/templates"
 	"github.com/fsnotify/fsnotify"
 	"github.com/muesli/cancelreader"
 
@@ -88,6 +89,17 @@ var drivers = make(map[string]DriverFactory)
 var driversLogOptsValidateFunctions = make(map[string]LogOptsValidateFunc)
 
 func ValidateLogOpts(logDriver string, logOpts map[string]string) error {
+	logOpts, _, err := splitModeLogOpts(logOpts)
+	if err != nil {
+		return err
+	}
+	if usesLogCache(logDriver) {
+		driverOpts, _, _, err := splitCacheLogOpts(logOpts)
+		if err != nil {
+			return err
+		}
+		logOpts = driverOpts
+	}
 	if value, ok := driversLogOptsValidateFunctions[logDriver]; ok && value != nil {
 		return value(logOpts)
 	}
@@ -113,7 +125,32 @@ func GetDriver(name string, opts map[string]string, address string) (Driver, err
 	if !ok {
 		return nil, fmt.Errorf("unknown logging driver %q: %w", name, errdefs.ErrNotFound)
 	}
-	return driverFactory(opts, address)
+	driverOpts, maxBufferSize, err := splitModeLogOpts(opts)
+	if err != nil {
+		return nil, err
+	}
+	driver, err := newDriver(name, driverFactory, driverOpts, address)
+	if err != nil || maxBufferSize == 0 {
+		return driver, err
+	}
+	return &NonBlockingLogger{Driver: driver, maxBufferSize: maxBufferSize}, nil
+}
+
+// newDriver creates a driver, wrapped to cache its logs locally if it cannot
+// read them back.
+func newDriver(name string, driverFactory DriverFactory, opts map[string]string, address string) (Driver, error) {
+	if !usesLogCache(name) {
+		return driverFactory(opts, address)
+	}
+	driverOpts, cacheOpts, disabled, err := splitCacheLogOpts(opts)
+	if err != nil {
+		return nil, err
+	}
+	driver, err := driverFactory(driverOpts, address)
+	if err != nil || disabled {
+		return driver, err
+	}
+	return &DualLogger{Driver: driver, cacheOpts: cacheOpts}, nil
 }
 
 func init() {
@@ -123,6 +160,9 @@ func init() {
 	RegisterDriver("json-file", func(opts map[string]string, address string) (Driver, error) {
 		return &JSONLogger{Opts: opts}, nil
 	}, JSONFileLogOptsValidate)
+	RegisterDriver("local", func(opts map[string]string, address string) (Driver, error) {
+		return &LocalLogger{Opts: opts}, nil
+	}, LocalLogOptsValidate)
 	RegisterDriver("journald", func(opts map[string]string, address string) (Driver, error) {
 		return &JournaldLogger{Opts: opts, Address: address}, nil
 	}, JournalLogOptsValidate)
@@ -132,6 +172,39 @@ func init() {
 	RegisterDriver("syslog", func(opts map[string]string, address string) (Driver, error) {
 		return &SyslogLogger{Opts: opts}, nil
 	}, SyslogOptsValidate)
+	RegisterDriver("gelf", func(opts map[string]string, address string) (Driver, error) {
+		return &GelfLogger{Opts: opts, Address: address}, nil
+	}, GelfLogOptsValidate)
+}
+
+type identifier struct {
+	ID        string
+	FullID    string
+	Namespace string
+}
+
+// formatLogTag returns the tag of the container's log entries, rendered from
+// the template of the tag log option. It defaults to the short container ID.
+func formatLogTag(opts map[string]string, config *logging.Config) (string, error) {
+	shortID := config.ID[:12]
+	tag, ok := opts[Tag]
+	if !ok {
+		return shortID, nil
+	}
+	tmpl, err := templates.Parse(tag)
+	if err != nil {
+		return "", err
+	}
+	idn := identifier{
+		ID:        shortID,
+		FullID:    config.ID,
+		Namespace: config.Namespace,
+	}
+	var b bytes.Buffer
+	if err := tmpl.Execute(&b, idn); err != nil {
+		return "", err
+	}
+	return b.String(), nil
 }
 
 // Main is the entrypoint for the containerd runtime v2 logging plugin mode.
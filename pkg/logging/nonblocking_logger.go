@@ -0,0 +1,327 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package logging
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/docker/go-units"
+
+	"github.com/containerd/containerd/v2/core/runtime/v2/logging"
+	"github.com/containerd/log"
+
+	"github.com/containerd/nerdctl/v2/pkg/internal/filesystem"
+)
+
+// Log options of the delivery mode of log messages to the driver.
+const (
+	Mode          = "mode"
+	MaxBufferSize = "max-buffer-size"
+
+	ModeBlocking    = "blocking"
+	ModeNonBlocking = "non-blocking"
+
+	// defaultMaxBufferSize corresponds to Docker.
+	defaultMaxBufferSize = 1024 * 1024
+
+	// logStatsSaveInterval is the interval at which the log stats are saved
+	// while messages are being dropped.
+	logStatsSaveInterval = time.Second
+)
+
+// splitModeLogOpts separates the delivery mode log options from the driver's
+// own options. It returns the maximum buffer size in non-blocking mode, or 0 in
+// blocking mode.
+func splitModeLogOpts(logOptMap map[string]string) (driverOpts map[string]string, maxBufferSize int64, err error) {
+	driverOpts = make(map[string]string, len(logOptMap))
+	for key, value := range logOptMap {
+		if key != Mode && key != MaxBufferSize {
+			driverOpts[key] = value
+		}
+	}
+	switch mode := logOptMap[Mode]; mode {
+	case "", ModeBlocking:
+		if _, ok := logOptMap[MaxBufferSize]; ok {
+			log.L.Warnf("log-opt %s is ignored unless %s is %s", MaxBufferSize, Mode, ModeNonBlocking)
+		}
+		return driverOpts, 0, nil
+	case ModeNonBlocking:
+	default:
+		return nil, 0, fmt.Errorf("invalid log mode %q, must be %s or %s", mode, ModeBlocking, ModeNonBlocking)
+	}
+	maxBufferSize = defaultMaxBufferSize
+	if s, ok := logOptMap[MaxBufferSize]; ok {
+		if maxBufferSize, err = units.RAMInBytes(s); err != nil {
+			return nil, 0, fmt.Errorf("invalid %s: %w", MaxBufferSize, err)
+		}
+		if maxBufferSize <= 0 {
+			return nil, 0, fmt.Errorf("%s must be a positive number", MaxBufferSize)
+		}
+	}
+	return driverOpts, maxBufferSize, nil
+}
+
+// LogStats is marshalled as "log-stats.json"
+type LogStats struct {
+	// DroppedMessages is the number of log messages dropped in non-blocking
+	// mode because the buffer was full.
+	DroppedMessages uint64 `json:"droppedMessages"`
+}
+
+func logStatsFilePath(stateDir string) string {
+	return filepath.Join(stateDir, "log-stats.json")
+}
+
+// LoadLogStats loads the log-stats.json in the state directory of a container.
+// A missing file means that no log message has been dropped.
+func LoadLogStats(stateDir string) (LogStats, error) {
+	var stats LogStats
+	data, err := filesystem.ReadFile(logStatsFilePath(stateDir))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return stats, nil
+		}
+		return stats, err
+	}
+	if err := json.Unmarshal(data, &stats); err != nil {
+		return stats, fmt.Errorf("failed to load log stats: %w", err)
+	}
+	return stats, nil
+}
+
+func saveLogStats(stateDir string, stats LogStats) error {
+	data, err := json.Marshal(stats)
+	if err != nil {
+		return err
+	}
+	return filesystem.WriteFile(logStatsFilePath(stateDir), data, 0600)
+}
+
+type bufferedMessage struct {
+	stream string
+	line   string
+}
+
+// messageRing is a FIFO queue of log messages bounded by the total size of the
+// lines. When full, the oldest messages are dropped to make room for new ones.
+type messageRing struct {
+	mu      sync.Mutex
+	cond    *sync.Cond
+	maxSize int64
+	size    int64
+	queue   []bufferedMessage
+	closed  bool
+	dropped uint64
+}
+
+func newMessageRing(maxSize int64) *messageRing {
+	r := &messageRing{maxSize: maxSize}
+	r.cond = sync.NewCond(&r.mu)
+	return r
+}
+
+// enqueue adds a message to the ring without blocking. A message larger than
+// the maximum size is only kept when the ring is otherwise empty.
+func (r *messageRing) enqueue(m bufferedMessage) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return
+	}
+	for len(r.queue) > 0 && r.size+int64(len(m.line)) > r.maxSize {
+		r.size -= int64(len(r.queue[0].line))
+		r.queue[0] = bufferedMessage{}
+		r.queue = r.queue[1:]
+		r.dropped++
+	}
+	r.queue = append(r.queue, m)
+	r.size += int64(len(m.line))
+	r.cond.Signal()
+}
+
+// dequeue removes the oldest message, waiting for one if the ring is empty.
+// It returns false once the ring is closed and drained.
+func (r *messageRing) dequeue() (bufferedMessage, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for len(r.queue) == 0 && !r.closed {
+		r.cond.Wait()
+	}
+	if len(r.queue) == 0 {
+		return bufferedMessage{}, false
+	}
+	m := r.queue[0]
+	r.queue[0] = bufferedMessage{}
+	r.queue = r.queue[1:]
+	r.size -= int64(len(m.line))
+	return m, true
+}
+
+// close stops accepting messages. The queued messages can still be dequeued.
+func (r *messageRing) close() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.closed = true
+	r.cond.Broadcast()
+}
+
+func (r *messageRing) droppedMessages() uint64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.dropped
+}
+
+// NonBlockingLogger wraps a driver to deliver log messages through a bounded
+// ring buffer, so that a stalled driver never blocks the container's output.
+// When the buffer is full the oldest messages are dropped, and counted in the
+// container's log stats.
+type NonBlockingLogger struct {
+	Driver
+	maxBufferSize int64
+
+	ring      *messageRing
+	stateDir  string
+	stats     LogStats
+	delivered chan error
+	stop      chan struct{}
+	wg        sync.WaitGroup
+}
+
+func (nonBlockingLogger *NonBlockingLogger) PreProcess(ctx context.Context, dataStore string, config *logging.Config) error {
+	if err := nonBlockingLogger.Driver.PreProcess(ctx, dataStore, config); err != nil {
+		return err
+	}
+	stateDir := filepath.Join(dataStore, "containers", config.Namespace, config.ID)
+	stats, err := LoadLogStats(stateDir)
+	if err != nil {
+		return err
+	}
+	nonBlockingLogger.stateDir = stateDir
+	nonBlockingLogger.stats = stats
+	nonBlockingLogger.ring = newMessageRing(nonBlockingLogger.maxBufferSize)
+	nonBlockingLogger.delivered = make(chan error, 1)
+	nonBlockingLogger.stop = make(chan struct{})
+	go func() {
+		nonBlockingLogger.delivered <- nonBlockingLogger.deliver()
+	}()
+	nonBlockingLogger.wg.Add(1)
+	go nonBlockingLogger.saveStatsLoop()
+	return nil
+}
+
+// deliver feeds the buffered messages to the wrapped driver until the ring is
+// closed and drained.
+func (nonBlockingLogger *NonBlockingLogger) deliver() error {
+	ring := nonBlockingLogger.ring
+	if syncDriver, ok := nonBlockingLogger.Driver.(SyncDriver); ok {
+		for {
+			m, ok := ring.dequeue()
+			if !ok {
+				return nil
+			}
+			if err := syncDriver.WriteLogEntry(m.stream, m.line); err != nil {
+				log.L.WithError(err).Error("failed to write log entry")
+			}
+		}
+	}
+	// The channels are unbuffered, so that messages stay in the ring, where
+	// they can be dropped, while the driver is stalled.
+	stdout := make(chan string)
+	stderr := make(chan string)
+	processed := make(chan error, 1)
+	go func() {
+		processed <- nonBlockingLogger.Driver.Process(stdout, stderr)
+	}()
+	for {
+		m, ok := ring.dequeue()
+		if !ok {
+			break
+		}
+		if m.stream == streamStdout {
+			stdout <- m.line
+		} else {
+			stderr <- m.line
+		}
+	}
+	close(stdout)
+	close(stderr)
+	return <-processed
+}
+
+// saveStatsLoop periodically saves the number of dropped messages, so that it
+// can be inspected while the container is running.
+func (nonBlockingLogger *NonBlockingLogger) saveStatsLoop() {
+	defer nonBlockingLogger.wg.Done()
+	ticker := time.NewTicker(logStatsSaveInterval)
+	defer ticker.Stop()
+	var saved uint64
+	for {
+		select {
+		case <-nonBlockingLogger.stop:
+			return
+		case <-ticker.C:
+			if dropped := nonBlockingLogger.ring.droppedMessages(); dropped != saved {
+				if err := nonBlockingLogger.saveStats(dropped); err != nil {
+					log.L.WithError(err).Warn("failed to save log stats")
+				}
+				saved = dropped
+			}
+		}
+	}
+}
+
+func (nonBlockingLogger *NonBlockingLogger) saveStats(dropped uint64) error {
+	stats := nonBlockingLogger.stats
+	stats.DroppedMessages += dropped
+	return saveLogStats(nonBlockingLogger.stateDir, stats)
+}
+
+// NonBlockingLogger is a SyncDriver, so Process is only here to implement Driver.
+func (nonBlockingLogger *NonBlockingLogger) Process(stdout <-chan string, stderr <-chan string) error {
+	for line := range stdout {
+		nonBlockingLogger.ring.enqueue(bufferedMessage{stream: streamStdout, line: line})
+	}
+	for line := range stderr {
+		nonBlockingLogger.ring.enqueue(bufferedMessage{stream: streamStderr, line: line})
+	}
+	return nil
+}
+
+// WriteLogEntry buffers a single log line without blocking, implementing SyncDriver.
+func (nonBlockingLogger *NonBlockingLogger) WriteLogEntry(stream, line string) error {
+	nonBlockingLogger.ring.enqueue(bufferedMessage{stream: stream, line: line})
+	return nil
+}
+
+func (nonBlockingLogger *NonBlockingLogger) PostProcess() error {
+	nonBlockingLogger.ring.close()
+	err := <-nonBlockingLogger.delivered
+	close(nonBlockingLogger.stop)
+	nonBlockingLogger.wg.Wait()
+	if dropped := nonBlockingLogger.ring.droppedMessages(); dropped > 0 {
+		log.L.Warnf("dropped %d log messages because the log buffer of %d bytes was full", dropped, nonBlockingLogger.maxBufferSize)
+		err = errors.Join(err, nonBlockingLogger.saveStats(dropped))
+	}
+	return errors.Join(err, nonBlockingLogger.Driver.PostProcess())
+}
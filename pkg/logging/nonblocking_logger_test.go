@@ -0,0 +1,139 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package logging
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/containerd/containerd/v2/core/runtime/v2/logging"
+)
+
+func TestMessageRing(t *testing.T) {
+	r := newMessageRing(10)
+	r.enqueue(bufferedMessage{stream: streamStdout, line: "aaaa"})
+	r.enqueue(bufferedMessage{stream: streamStdout, line: "bbbb"})
+	// Drops "aaaa" to make room.
+	r.enqueue(bufferedMessage{stream: streamStderr, line: "cccc"})
+	// Larger than the ring: drops everything else.
+	r.enqueue(bufferedMessage{stream: streamStdout, line: "dddddddddddd"})
+	r.enqueue(bufferedMessage{stream: streamStdout, line: "e"})
+	r.close()
+	r.enqueue(bufferedMessage{stream: streamStdout, line: "ignored"})
+
+	var lines []string
+	for {
+		m, ok := r.dequeue()
+		if !ok {
+			break
+		}
+		lines = append(lines, m.line)
+	}
+	assert.DeepEqual(t, lines, []string{"e"})
+	assert.Equal(t, r.droppedMessages(), uint64(4))
+}
+
+func TestGetDriverNonBlocking(t *testing.T) {
+	driver, err := GetDriver("json-file", map[string]string{Mode: ModeNonBlocking, MaxBufferSize: "4m"}, "")
+	assert.NilError(t, err)
+	nonBlocking, ok := driver.(*NonBlockingLogger)
+	assert.Assert(t, ok)
+	assert.Equal(t, nonBlocking.maxBufferSize, int64(4*1024*1024))
+	jsonLogger, ok := nonBlocking.Driver.(*JSONLogger)
+	assert.Assert(t, ok)
+	assert.DeepEqual(t, jsonLogger.Opts, map[string]string{})
+
+	driver, err = GetDriver("json-file", map[string]string{Mode: ModeBlocking}, "")
+	assert.NilError(t, err)
+	_, ok = driver.(*JSONLogger)
+	assert.Assert(t, ok)
+
+	assert.ErrorContains(t, ValidateLogOpts("json-file", map[string]string{Mode: "async"}), "invalid log mode")
+	assert.ErrorContains(t, ValidateLogOpts("json-file", map[string]string{Mode: ModeNonBlocking, MaxBufferSize: "-1"}), MaxBufferSize)
+}
+
+// StalledMockDriver does not consume log messages until it is released.
+type StalledMockDriver struct {
+	MockDriver
+	release chan struct{}
+}
+
+func (m *StalledMockDriver) Process(stdout <-chan string, stderr <-chan string) error {
+	<-m.release
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for line := range stderr {
+			m.receivedStderr = append(m.receivedStderr, line)
+		}
+	}()
+	for line := range stdout {
+		m.receivedStdout = append(m.receivedStdout, line)
+	}
+	wg.Wait()
+	return nil
+}
+
+func TestNonBlockingLogger(t *testing.T) {
+	dataStore := t.TempDir()
+	config := &logging.Config{
+		ID:        "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef",
+		Namespace: "default",
+	}
+	stateDir := filepath.Join(dataStore, "containers", config.Namespace, config.ID)
+	assert.NilError(t, os.MkdirAll(stateDir, 0700))
+
+	driver := &StalledMockDriver{release: make(chan struct{})}
+	l := &NonBlockingLogger{Driver: driver, maxBufferSize: 8}
+	assert.NilError(t, l.PreProcess(context.Background(), dataStore, config))
+	// The driver is stalled: writing must not block, and drops the oldest messages.
+	for _, line := range []string{"a1\n", "a2\n", "a3\n", "a4\n"} {
+		assert.NilError(t, l.WriteLogEntry(streamStdout, line))
+	}
+	assert.NilError(t, l.WriteLogEntry(streamStderr, "b1\n"))
+	close(driver.release)
+	assert.NilError(t, l.PostProcess())
+
+	// The first message may have been dequeued before the driver stalled.
+	assert.Assert(t, len(driver.receivedStdout) <= 2)
+	assert.Equal(t, driver.receivedStdout[len(driver.receivedStdout)-1], "a4\n")
+	assert.DeepEqual(t, driver.receivedStderr, []string{"b1\n"})
+	stats, err := LoadLogStats(stateDir)
+	assert.NilError(t, err)
+	dropped := uint64(4 - len(driver.receivedStdout))
+	assert.Equal(t, stats.DroppedMessages, dropped)
+
+	// The dropped messages accumulate across container restarts.
+	driver = &StalledMockDriver{release: make(chan struct{})}
+	l = &NonBlockingLogger{Driver: driver, maxBufferSize: 3}
+	assert.NilError(t, l.PreProcess(context.Background(), dataStore, config))
+	assert.NilError(t, l.WriteLogEntry(streamStdout, "c1\n"))
+	assert.NilError(t, l.WriteLogEntry(streamStdout, "c2\n"))
+	close(driver.release)
+	assert.NilError(t, l.PostProcess())
+	assert.Equal(t, driver.receivedStdout[len(driver.receivedStdout)-1], "c2\n")
+	dropped += uint64(2 - len(driver.receivedStdout))
+	stats, err = LoadLogStats(stateDir)
+	assert.NilError(t, err)
+	assert.Equal(t, stats.DroppedMessages, dropped)
+}
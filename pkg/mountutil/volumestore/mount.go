@@ -0,0 +1,67 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package volumestore
+
+import (
+	"errors"
+
+	"github.com/containerd/log"
+)
+
+// MountVolumes mounts the devices of the volumes created with options (`volume create -o type=...`)
+// that are used by a container, before its task is created, and by the createRuntime OCI hook.
+// The devices are unmounted by UnmountVolumes, which is called by the postStop OCI hook,
+// once the last container using them has stopped.
+func MountVolumes(dataStore, namespace, id string, names []string) error {
+	if len(names) == 0 {
+		return nil
+	}
+	volStore, err := New(dataStore, namespace)
+	if err != nil {
+		return err
+	}
+	for i, name := range names {
+		if err := volStore.Mount(name, id); err != nil {
+			for _, mounted := range names[:i] {
+				if uerr := volStore.Unmount(mounted, id); uerr != nil {
+					log.L.WithError(uerr).Warnf("failed to unmount volume %q", mounted)
+				}
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// UnmountVolumes releases the volumes used by a container, and unmounts the devices of those
+// that are no longer used by any container.
+func UnmountVolumes(dataStore, namespace, id string, names []string) error {
+	if len(names) == 0 {
+		return nil
+	}
+	volStore, err := New(dataStore, namespace)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, name := range names {
+		if err := volStore.Unmount(name, id); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -24,6 +24,7 @@ import (
 	"errors"
 	"fmt"
 	"path/filepath"
+	"slices"
 
 	"github.com/containerd/log"
 
@@ -37,6 +38,7 @@ const (
 	volumeDirBasename  = "volumes"
 	dataDirName        = "_data"
 	volumeJSONFileName = "volume.json"
+	usersJSONFileName  = "users.json"
 )
 
 // ErrVolumeStore will wrap all errors here
@@ -48,9 +50,10 @@ type VolumeStore interface {
 	// Get returns an existing volume
 	Get(name string, size bool) (*native.Volume, error)
 	// Create will either return an existing volume, or create a new one
-	// NOTE that different labels will NOT create a new volume if there is one by that name already,
-	// but instead return the existing one with the (possibly different) labels
-	Create(name string, labels []string) (vol *native.Volume, err error)
+	// NOTE that different labels or options will NOT create a new volume if there is one by that name already,
+	// but instead return the existing one with the (possibly different) labels and options
+	// Options are the options of the `local` driver (type, device, o), see ValidateOptions
+	Create(name string, labels []string, options map[string]string) (vol *native.Volume, err error)
 	// List returns all existing volumes.
 	// Note that list is expensive as it reads all volumes individual info
 	List(size bool) (map[string]native.Volume, error)
@@ -60,6 +63,13 @@ type VolumeStore interface {
 	Prune(filter func(volumes []*native.Volume) ([]string, error)) (err error)
 	// Count returns the number of volumes
 	Count() (count int, err error)
+	// Mount mounts the device of a volume created with options, unless it is already mounted,
+	// and records the user (a container ID) of the volume.
+	// This is a no-op for volumes without options.
+	Mount(name, user string) error
+	// Unmount forgets about the user of a volume, and unmounts the device of the volume
+	// when it was the last one.
+	Unmount(name, user string) error
 
 	// Lock: see store implementation
 	Lock() error
@@ -158,10 +168,10 @@ func (vs *volumeStore) CreateWithoutLock(name string, labels []string) (vol *nat
 		return nil, err
 	}
 
-	return vs.rawCreate(name, labels)
+	return vs.rawCreate(name, labels, nil)
 }
 
-func (vs *volumeStore) Create(name string, labels []string) (vol *native.Volume, err error) {
+func (vs *volumeStore) Create(name string, labels []string, options map[string]string) (vol *native.Volume, err error) {
 	defer func() {
 		if err != nil {
 			err = errors.Join(ErrVolumeStore, err)
@@ -172,8 +182,12 @@ func (vs *volumeStore) Create(name string, labels []string) (vol *native.Volume,
 		return nil, err
 	}
 
+	if err = ValidateOptions(options); err != nil {
+		return nil, err
+	}
+
 	err = vs.Locker.WithLock(func() error {
-		vol, err = vs.rawCreate(name, labels)
+		vol, err = vs.rawCreate(name, labels, options)
 		return err
 	})
 
@@ -263,6 +277,8 @@ func (vs *volumeStore) Remove(generator func() ([]string, []error, error)) (remo
 				// TODO: see above
 				warns = append(warns, fmt.Errorf("volume %q: %w", name, store.ErrNotFound))
 				continue
+			} else if err = vs.removeMounts(name); err != nil {
+				return err
 			} else if err = vs.manager.Delete(name); err != nil {
 				return err
 			}
@@ -306,6 +322,9 @@ func (vs *volumeStore) Prune(filter func(vol []*native.Volume) ([]string, error)
 		}
 
 		for _, name := range toDelete {
+			if err = vs.removeMounts(name); err != nil {
+				return err
+			}
 			err = vs.manager.Delete(name)
 			if err != nil {
 				return err
@@ -322,9 +341,11 @@ func (vs *volumeStore) rawGet(name string, size bool) (vol *native.Volume, err e
 		return nil, err
 	}
 
+	meta := parseMeta(content)
 	vol = &native.Volume{
-		Name:   name,
-		Labels: labels(content),
+		Name:    name,
+		Labels:  meta.Labels,
+		Options: meta.Options,
 	}
 
 	vol.Mountpoint, err = vs.manager.Location(name, dataDirName)
@@ -342,10 +363,13 @@ func (vs *volumeStore) rawGet(name string, size bool) (vol *native.Volume, err e
 	return vol, nil
 }
 
-func (vs *volumeStore) rawCreate(name string, labels []string) (vol *native.Volume, err error) {
+func (vs *volumeStore) rawCreate(name string, labels []string, options map[string]string) (vol *native.Volume, err error) {
 	volOpts := struct {
-		Labels map[string]string `json:"labels"`
-	}{}
+		Labels  map[string]string `json:"labels"`
+		Options map[string]string `json:"options,omitempty"`
+	}{
+		Options: options,
+	}
 
 	if len(labels) > 0 {
 		volOpts.Labels = strutil.ConvertKVStringsToMap(labels)
@@ -369,29 +393,181 @@ func (vs *volumeStore) rawCreate(name string, labels []string) (vol *native.Volu
 	}
 
 	// At this point, we either have an existing volume, or created a new one successfully
-	vol = &native.Volume{
-		Name: name,
+	if err = vs.manager.GroupEnsure(name, dataDirName); err != nil {
+		return nil, err
 	}
 
-	if err = vs.manager.GroupEnsure(name, dataDirName); err != nil {
+	return vs.rawGet(name, false)
+}
+
+func (vs *volumeStore) Mount(name, user string) (err error) {
+	defer func() {
+		if err != nil {
+			err = errors.Join(ErrVolumeStore, err)
+		}
+	}()
+
+	return vs.Locker.WithLock(func() error {
+		vol, err := vs.rawGet(name, false)
+		if errors.Is(err, store.ErrNotFound) {
+			// The volume was removed under the feet of the container, there is nothing to mount
+			return nil
+		} else if err != nil || len(vol.Options) == 0 {
+			return err
+		}
+
+		users, err := vs.users(name)
+		if err != nil {
+			return err
+		}
+
+		mounted, err := isMounted(vol.Mountpoint)
+		if err != nil {
+			return err
+		}
+		if !mounted {
+			if err = makeShared(filepath.Dir(vol.Mountpoint)); err != nil {
+				return fmt.Errorf("failed to prepare volume %q: %w", name, err)
+			}
+			if err = mountDevice(vol.Mountpoint, vol.Options); err != nil {
+				return fmt.Errorf("failed to mount volume %q: %w", name, err)
+			}
+			// Users recorded with the former mount are gone
+			users = nil
+		}
+
+		if !slices.Contains(users, user) {
+			users = append(users, user)
+		}
+		return vs.setUsers(name, users)
+	})
+}
+
+func (vs *volumeStore) Unmount(name, user string) (err error) {
+	defer func() {
+		if err != nil {
+			err = errors.Join(ErrVolumeStore, err)
+		}
+	}()
+
+	return vs.Locker.WithLock(func() error {
+		if doesExist, err := vs.manager.Exists(name, usersJSONFileName); err != nil || !doesExist {
+			return err
+		}
+
+		users, err := vs.users(name)
+		if err != nil {
+			return err
+		}
+
+		users = slices.DeleteFunc(users, func(u string) bool { return u == user })
+		if len(users) > 0 {
+			return vs.setUsers(name, users)
+		}
+
+		return vs.unmountDevice(name)
+	})
+}
+
+// unmountDevice unmounts the device of a volume, if any, and forgets about its users.
+func (vs *volumeStore) unmountDevice(name string) error {
+	if doesExist, err := vs.manager.Exists(name, usersJSONFileName); err != nil || !doesExist {
+		return err
+	}
+
+	mountpoint, err := vs.manager.Location(name, dataDirName)
+	if err != nil {
+		return err
+	}
+
+	if mounted, err := isMounted(mountpoint); err != nil {
+		return err
+	} else if mounted {
+		if err = unmountDevice(mountpoint); err != nil {
+			return fmt.Errorf("failed to unmount volume %q: %w", name, err)
+		}
+	}
+
+	return vs.manager.Delete(name, usersJSONFileName)
+}
+
+// removeMounts unmounts the device of a volume and the shared mount point of its directory,
+// which is kept between the mounts of the device, before the volume is removed.
+func (vs *volumeStore) removeMounts(name string) error {
+	if err := vs.unmountDevice(name); err != nil {
+		return err
+	}
+
+	dir, err := vs.manager.Location(name)
+	if err != nil {
+		return err
+	}
+
+	return unmakeShared(dir)
+}
+
+func (vs *volumeStore) users(name string) ([]string, error) {
+	content, err := vs.manager.Get(name, usersJSONFileName)
+	if errors.Is(err, store.ErrNotFound) {
+		return nil, nil
+	} else if err != nil {
 		return nil, err
 	}
 
-	if vol.Mountpoint, err = vs.manager.Location(name, dataDirName); err != nil {
+	var users []string
+	if err = json.Unmarshal(content, &users); err != nil {
 		return nil, err
 	}
 
-	return vol, nil
+	return users, nil
 }
 
-// Private helpers
-func labels(b []byte) *map[string]string {
-	type volumeOpts struct {
-		Labels *map[string]string `json:"labels,omitempty"`
+func (vs *volumeStore) setUsers(name string, users []string) error {
+	content, err := json.Marshal(users)
+	if err != nil {
+		return err
 	}
-	var vo volumeOpts
-	if err := json.Unmarshal(b, &vo); err != nil {
+
+	return vs.manager.Set(content, name, usersJSONFileName)
+}
+
+// ValidateOptions checks the options of the `local` volume driver.
+// Like Docker, the supported options are `type` (the filesystem type, e.g., tmpfs or nfs),
+// `device` (the block device, image file or remote export to mount) and `o` (the mount options).
+func ValidateOptions(options map[string]string) error {
+	if len(options) == 0 {
 		return nil
 	}
-	return vo.Labels
+
+	for k := range options {
+		switch k {
+		case "type", "device", "o":
+		default:
+			return fmt.Errorf("invalid option key: %q: %w", k, store.ErrInvalidArgument)
+		}
+	}
+
+	if options["type"] == "" {
+		return fmt.Errorf("missing required option: \"type\": %w", store.ErrInvalidArgument)
+	}
+
+	if options["device"] == "" {
+		return fmt.Errorf("missing required option: \"device\": %w", store.ErrInvalidArgument)
+	}
+
+	return nil
+}
+
+// Private helpers
+type volumeMeta struct {
+	Labels  *map[string]string `json:"labels,omitempty"`
+	Options map[string]string  `json:"options,omitempty"`
+}
+
+func parseMeta(b []byte) volumeMeta {
+	var vm volumeMeta
+	if err := json.Unmarshal(b, &vm); err != nil {
+		return volumeMeta{}
+	}
+	return vm
 }
@@ -0,0 +1,104 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package volumestore
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/containerd/containerd/v2/core/mount"
+)
+
+func isMounted(mountpoint string) (bool, error) {
+	mountpoint, err := filepath.EvalSymlinks(mountpoint)
+	if err != nil {
+		return false, err
+	}
+	info, err := mount.Lookup(mountpoint)
+	if err != nil {
+		return false, err
+	}
+	return info.Mountpoint == mountpoint, nil
+}
+
+func mountDevice(mountpoint string, options map[string]string) error {
+	m := mount.Mount{
+		Type:   options["type"],
+		Source: options["device"],
+	}
+	if o := options["o"]; o != "" {
+		m.Options = strings.Split(o, ",")
+	}
+	switch m.Type {
+	case "nfs", "nfs4":
+		// Unlike mount(8), the kernel does not resolve the address of the server
+		for i, opt := range m.Options {
+			addr, ok := strings.CutPrefix(opt, "addr=")
+			if !ok || net.ParseIP(addr) != nil {
+				continue
+			}
+			ips, err := net.LookupIP(addr)
+			if err != nil {
+				return fmt.Errorf("failed to resolve the address of the NFS server %q: %w", addr, err)
+			}
+			m.Options[i] = "addr=" + ips[0].String()
+		}
+	default:
+		// Image files are mounted through a loop device
+		if st, err := os.Stat(m.Source); err == nil && filepath.IsAbs(m.Source) && st.Mode().IsRegular() && !slices.Contains(m.Options, "loop") {
+			m.Options = append(m.Options, "loop")
+		}
+	}
+	return m.Mount(mountpoint)
+}
+
+func unmountDevice(mountpoint string) error {
+	return mount.UnmountAll(mountpoint, 0)
+}
+
+// makeShared turns dir into a shared mount point, so that the devices mounted beneath it
+// also propagate to the containers created before (runc mounts the volumes as slaves).
+// This is what makes the devices mounted by the createRuntime hook visible in the container.
+func makeShared(dir string) error {
+	mounted, err := isMounted(dir)
+	if err != nil {
+		return err
+	}
+	if !mounted {
+		if err = unix.Mount(dir, dir, "", unix.MS_BIND, ""); err != nil {
+			return fmt.Errorf("failed to bind mount %q: %w", dir, err)
+		}
+	}
+	if err = unix.Mount("", dir, "", unix.MS_SHARED, ""); err != nil {
+		return fmt.Errorf("failed to make %q shared: %w", dir, err)
+	}
+	return nil
+}
+
+// unmakeShared unmounts the shared mount point created by makeShared, if any.
+func unmakeShared(dir string) error {
+	if mounted, err := isMounted(dir); err != nil || !mounted {
+		return err
+	}
+	return unix.Unmount(dir, 0)
+}
@@ -0,0 +1,46 @@
+//go:build !linux
+
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package volumestore
+
+import (
+	"fmt"
+	"runtime"
+
+	"github.com/containerd/errdefs"
+)
+
+func isMounted(string) (bool, error) {
+	return false, nil
+}
+
+func mountDevice(string, map[string]string) error {
+	return fmt.Errorf("volume options are not supported on %s: %w", runtime.GOOS, errdefs.ErrNotImplemented)
+}
+
+func unmountDevice(string) error {
+	return nil
+}
+
+func makeShared(string) error {
+	return nil
+}
+
+func unmakeShared(string) error {
+	return nil
+}
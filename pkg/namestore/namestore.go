@@ -25,6 +25,7 @@ import (
 	"fmt"
 	"path/filepath"
 
+	"github.com/containerd/errdefs"
 	"github.com/containerd/log"
 
 	"github.com/containerd/nerdctl/v2/pkg/identifiers"
@@ -95,7 +96,7 @@ func (x *nameStore) Acquire(name, id string) (err error) {
 			log.L.Warnf("name %q was locked by an empty id - this is abnormal and should be reported", name)
 		} else if string(previousID) != id {
 			// If the name is already used by another container, that is a hard error
-			return fmt.Errorf("name %q is already used by ID %q", name, previousID)
+			return fmt.Errorf("name %q is already used by ID %q: %w", name, previousID, errdefs.ErrAlreadyExists)
 		}
 
 		// If the id was the same, we are "re-acquiring".
@@ -0,0 +1,171 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package netutil
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/docker/go-units"
+
+	"github.com/containerd/go-cni"
+)
+
+// Network options of the bandwidth plugin.
+// Rates are in bits per second (e.g., "10mbit"), bursts are sizes in bytes (e.g., "64k").
+const (
+	IngressRateOption  = "ingress-rate"
+	IngressBurstOption = "ingress-burst"
+	EgressRateOption   = "egress-rate"
+	EgressBurstOption  = "egress-burst"
+)
+
+// minBurst is the default burst, in bits, for low rates.
+// The burst must hold the largest packet, and packets up to 64 KiB are common with segmentation offloading.
+const minBurst = 64 * 1024 * 8
+
+// rateUnits are the units of rates, in bits per second, as in tc(8).
+var rateUnits = map[string]float64{
+	"":      1,
+	"bit":   1,
+	"kbit":  1e3,
+	"mbit":  1e6,
+	"gbit":  1e9,
+	"tbit":  1e12,
+	"kibit": 1 << 10,
+	"mibit": 1 << 20,
+	"gibit": 1 << 30,
+	"tibit": 1 << 40,
+	"bps":   8,
+	"kbps":  8e3,
+	"mbps":  8e6,
+	"gbps":  8e9,
+	"tbps":  8e12,
+}
+
+// ParseRate parses a rate like "10mbit" and returns it in bits per second.
+// A rate without unit is in bits per second, "bps" units are in bytes per second.
+func ParseRate(s string) (uint64, error) {
+	lower := strings.ToLower(strings.TrimSpace(s))
+	i := strings.IndexFunc(lower, func(r rune) bool {
+		return (r < '0' || r > '9') && r != '.'
+	})
+	if i < 0 {
+		i = len(lower)
+	}
+	unit, ok := rateUnits[lower[i:]]
+	if !ok {
+		return 0, fmt.Errorf("invalid rate %q: unknown unit %q", s, lower[i:])
+	}
+	v, err := strconv.ParseFloat(lower[:i], 64)
+	if err != nil || v <= 0 {
+		return 0, fmt.Errorf("invalid rate %q", s)
+	}
+	return uint64(v * unit), nil
+}
+
+// ParseBandwidth parses the bandwidth options in opts.
+// The burst of a rate defaults to the traffic of 100ms at that rate, and at least 64 KiB.
+func ParseBandwidth(opts map[string]string) (cni.BandWidth, error) {
+	var (
+		bw  cni.BandWidth
+		err error
+	)
+	for opt, v := range opts {
+		switch opt {
+		case IngressRateOption:
+			bw.IngressRate, err = ParseRate(v)
+		case EgressRateOption:
+			bw.EgressRate, err = ParseRate(v)
+		case IngressBurstOption:
+			bw.IngressBurst, err = parseBurst(v)
+		case EgressBurstOption:
+			bw.EgressBurst, err = parseBurst(v)
+		default:
+			return bw, fmt.Errorf("unsupported network option %q", opt)
+		}
+		if err != nil {
+			return bw, fmt.Errorf("invalid network option %q: %w", opt, err)
+		}
+	}
+	if bw.IngressRate == 0 && bw.IngressBurst != 0 {
+		return bw, fmt.Errorf("network option %q requires %q", IngressBurstOption, IngressRateOption)
+	}
+	if bw.EgressRate == 0 && bw.EgressBurst != 0 {
+		return bw, fmt.Errorf("network option %q requires %q", EgressBurstOption, EgressRateOption)
+	}
+	if bw.IngressRate != 0 && bw.IngressBurst == 0 {
+		bw.IngressBurst = defaultBurst(bw.IngressRate)
+	}
+	if bw.EgressRate != 0 && bw.EgressBurst == 0 {
+		bw.EgressBurst = defaultBurst(bw.EgressRate)
+	}
+	return bw, nil
+}
+
+// parseBurst parses a size like "64k" and returns it in bits.
+func parseBurst(s string) (uint64, error) {
+	size, err := units.RAMInBytes(s)
+	if err != nil {
+		return 0, err
+	}
+	// The bandwidth plugin rejects bursts of 4 GiB and more
+	if size <= 0 || size >= math.MaxUint32 {
+		return 0, fmt.Errorf("burst %q must be between 1 byte and 4 GiB", s)
+	}
+	return uint64(size) * 8, nil
+}
+
+func defaultBurst(rate uint64) uint64 {
+	return max(rate/10, minBurst)
+}
+
+// ParseBandwidthLabel parses the value of the labels.Bandwidth label.
+// An empty value yields no limit.
+func ParseBandwidthLabel(bandwidthJSON string) (cni.BandWidth, error) {
+	var bw cni.BandWidth
+	if bandwidthJSON == "" {
+		return bw, nil
+	}
+	if err := json.Unmarshal([]byte(bandwidthJSON), &bw); err != nil {
+		return bw, fmt.Errorf("failed to parse bandwidth %q: %w", bandwidthJSON, err)
+	}
+	return bw, nil
+}
+
+// BandwidthLimits reports whether the bandwidth plugin of the network accepts the limits of the containers
+// as runtimeConfig, and whether the network has limits of its own, which take precedence over them.
+func (n *NetworkConfig) BandwidthLimits() (capable, limited bool) {
+	for _, p := range n.Plugins {
+		if p.Network.Type != "bandwidth" {
+			continue
+		}
+		var bandwidth struct {
+			Capabilities map[string]bool `json:"capabilities"`
+			IngressRate  uint64          `json:"ingressRate"`
+			EgressRate   uint64          `json:"egressRate"`
+		}
+		if err := json.Unmarshal(p.Bytes, &bandwidth); err != nil {
+			return false, false
+		}
+		return bandwidth.Capabilities["bandwidth"], bandwidth.IngressRate != 0 || bandwidth.EgressRate != 0
+	}
+	return false, false
+}
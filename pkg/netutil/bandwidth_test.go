@@ -0,0 +1,111 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package netutil
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/containernetworking/cni/libcni"
+	"gotest.tools/v3/assert"
+
+	"github.com/containerd/go-cni"
+)
+
+func TestParseRate(t *testing.T) {
+	for s, expected := range map[string]uint64{
+		"1000":     1000,
+		"10mbit":   10_000_000,
+		"10Mbit":   10_000_000,
+		"1.5gbit":  1_500_000_000,
+		"1kibit":   1024,
+		"100kbps":  800_000,
+		"512bit":   512,
+		" 1mbit ":  1_000_000,
+		"2.5mibit": 2_621_440,
+	} {
+		rate, err := ParseRate(s)
+		assert.NilError(t, err, s)
+		assert.Equal(t, rate, expected, s)
+	}
+	for _, s := range []string{"", "mbit", "0", "-1mbit", "10mb", "1.2.3kbit"} {
+		_, err := ParseRate(s)
+		assert.Assert(t, err != nil, s)
+	}
+}
+
+func TestParseBandwidth(t *testing.T) {
+	bw, err := ParseBandwidth(map[string]string{"ingress-rate": "10mbit", "ingress-burst": "1m"})
+	assert.NilError(t, err)
+	assert.Equal(t, bw, cni.BandWidth{IngressRate: 10_000_000, IngressBurst: 8 * 1024 * 1024})
+
+	// The burst defaults to 100ms of traffic, and at least 64 KiB
+	bw, err = ParseBandwidth(map[string]string{"ingress-rate": "100mbit", "egress-rate": "1mbit"})
+	assert.NilError(t, err)
+	assert.Equal(t, bw, cni.BandWidth{IngressRate: 100_000_000, IngressBurst: 10_000_000, EgressRate: 1_000_000, EgressBurst: 64 * 1024 * 8})
+
+	_, err = ParseBandwidth(map[string]string{"egress-burst": "1m"})
+	assert.ErrorContains(t, err, `network option "egress-burst" requires "egress-rate"`)
+
+	_, err = ParseBandwidth(map[string]string{"egress-rate": "1mbit", "egress-burst": "4g"})
+	assert.ErrorContains(t, err, "must be between 1 byte and 4 GiB")
+
+	_, err = ParseBandwidth(map[string]string{"ingress-rate": "fast"})
+	assert.ErrorContains(t, err, `invalid network option "ingress-rate"`)
+
+	_, err = ParseBandwidth(map[string]string{"mtu": "1500"})
+	assert.ErrorContains(t, err, `unsupported network option "mtu"`)
+}
+
+func TestParseBandwidthLabel(t *testing.T) {
+	bw, err := ParseBandwidthLabel("")
+	assert.NilError(t, err)
+	assert.Equal(t, bw, cni.BandWidth{})
+
+	b, err := json.Marshal(cni.BandWidth{EgressRate: 1000, EgressBurst: 8000})
+	assert.NilError(t, err)
+	bw, err = ParseBandwidthLabel(string(b))
+	assert.NilError(t, err)
+	assert.Equal(t, bw, cni.BandWidth{EgressRate: 1000, EgressBurst: 8000})
+
+	_, err = ParseBandwidthLabel("{")
+	assert.ErrorContains(t, err, "failed to parse bandwidth")
+}
+
+func TestBandwidthLimits(t *testing.T) {
+	t.Parallel()
+	conf := func(t *testing.T, plugins string) *NetworkConfig {
+		t.Helper()
+		l, err := libcni.ConfListFromBytes([]byte(`{"cniVersion": "1.0.0", "name": "n1", "plugins": [` + plugins + `]}`))
+		assert.NilError(t, err)
+		return &NetworkConfig{NetworkConfigList: l}
+	}
+
+	for _, tc := range []struct {
+		plugins          string
+		capable, limited bool
+	}{
+		{`{"type": "bridge"}, {"type": "tuning"}`, false, false},
+		{`{"type": "bridge"}, {"type": "bandwidth", "capabilities": {"bandwidth": true}}`, true, false},
+		{`{"type": "bridge"}, {"type": "bandwidth", "capabilities": {"bandwidth": true}, "egressRate": 1000, "egressBurst": 8000}`, true, true},
+		{`{"type": "bridge"}, {"type": "bandwidth", "ingressRate": 1000, "ingressBurst": 8000}`, false, true},
+	} {
+		capable, limited := conf(t, tc.plugins).BandwidthLimits()
+		assert.Equal(t, capable, tc.capable, tc.plugins)
+		assert.Equal(t, limited, tc.limited, tc.plugins)
+	}
+}
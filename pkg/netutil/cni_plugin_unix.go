@@ -18,7 +18,11 @@
 
 package netutil
 
-import "github.com/containerd/nerdctl/v2/pkg/rootlessutil"
+import (
+	"github.com/containerd/go-cni"
+
+	"github.com/containerd/nerdctl/v2/pkg/rootlessutil"
+)
 
 // bridgeConfig describes the bridge plugin
 type bridgeConfig struct {
@@ -134,6 +138,33 @@ func (*tuningConfig) GetPluginType() string {
 	return "tuning"
 }
 
+// bandwidthConfig describes the bandwidth plugin
+type bandwidthConfig struct {
+	PluginType   string          `json:"type"`
+	Capabilities map[string]bool `json:"capabilities,omitempty"`
+	IngressRate  uint64          `json:"ingressRate,omitempty"`
+	IngressBurst uint64          `json:"ingressBurst,omitempty"`
+	EgressRate   uint64          `json:"egressRate,omitempty"`
+	EgressBurst  uint64          `json:"egressBurst,omitempty"`
+}
+
+func newBandwidthPlugin(bw cni.BandWidth) *bandwidthConfig {
+	return &bandwidthConfig{
+		PluginType: "bandwidth",
+		Capabilities: map[string]bool{
+			"bandwidth": true,
+		},
+		IngressRate:  bw.IngressRate,
+		IngressBurst: bw.IngressBurst,
+		EgressRate:   bw.EgressRate,
+		EgressBurst:  bw.EgressBurst,
+	}
+}
+
+func (*bandwidthConfig) GetPluginType() string {
+	return "bandwidth"
+}
+
 // https://github.com/containernetworking/plugins/blob/v1.0.1/plugins/ipam/host-local/backend/allocator/config.go#L47-L56
 type hostLocalIPAMConfig struct {
 	Type        string        `json:"type"`
@@ -0,0 +1,167 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package netutil
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/containernetworking/cni/libcni"
+	"github.com/containernetworking/cni/pkg/invoke"
+	types100 "github.com/containernetworking/cni/pkg/types/100"
+	"github.com/containernetworking/cni/pkg/version"
+
+	"github.com/containerd/go-cni"
+)
+
+// EndpointSettings holds the settings of a container on a single network.
+// They are stored in the labels.NetworkEndpoints label, keyed by network.
+type EndpointSettings struct {
+	// IfName is the name of the interface inside the container.
+	// Networks with an IfName are attached one by one with AttachNetwork,
+	// instead of being numbered by their position through go-cni.
+	IfName     string   `json:",omitempty"`
+	IPAddress  string   `json:",omitempty"`
+	IP6Address string   `json:",omitempty"`
+	MACAddress string   `json:",omitempty"`
+	Aliases    []string `json:",omitempty"`
+}
+
+// ParseEndpoints parses the value of the labels.NetworkEndpoints label.
+// An empty value yields an empty map.
+func ParseEndpoints(endpointsJSON string) (map[string]EndpointSettings, error) {
+	endpoints := make(map[string]EndpointSettings)
+	if endpointsJSON == "" {
+		return endpoints, nil
+	}
+	if err := json.Unmarshal([]byte(endpointsJSON), &endpoints); err != nil {
+		return nil, fmt.Errorf("failed to parse network endpoints %q: %w", endpointsJSON, err)
+	}
+	return endpoints, nil
+}
+
+// InterfaceName returns the name of the i-th container interface, following the go-cni naming.
+func InterfaceName(i int) string {
+	return fmt.Sprintf("%s%d", cni.DefaultPrefix, i)
+}
+
+// InterfaceNames returns the name of the container interface of each network.
+func InterfaceNames(networks []string, endpoints map[string]EndpointSettings) map[string]string {
+	res := make(map[string]string, len(networks))
+	i := 0
+	for _, netstr := range networks {
+		if ep := endpoints[netstr]; ep.IfName != "" {
+			res[netstr] = ep.IfName
+			continue
+		}
+		res[netstr] = InterfaceName(i)
+		i++
+	}
+	return res
+}
+
+// PinInterfaceNames sets IfName on every network in networks that does not have one yet,
+// using the name go-cni gives it from its position.
+// The IP and MAC addresses that go-cni passes to every network are copied along,
+// so that the networks keep the same configuration once they are attached one by one.
+func PinInterfaceNames(networks []string, endpoints map[string]EndpointSettings, ipAddress, ip6Address, macAddress string) {
+	for netstr, ifName := range InterfaceNames(networks, endpoints) {
+		ep := endpoints[netstr]
+		if ep.IfName != "" {
+			continue
+		}
+		ep.IfName = ifName
+		ep.IPAddress = ipAddress
+		ep.IP6Address = ip6Address
+		ep.MACAddress = macAddress
+		endpoints[netstr] = ep
+	}
+}
+
+// FreeInterfaceName returns the first interface name that is not used by any of the endpoints.
+func FreeInterfaceName(endpoints map[string]EndpointSettings) string {
+	used := make(map[string]struct{}, len(endpoints))
+	for _, ep := range endpoints {
+		used[ep.IfName] = struct{}{}
+	}
+	for i := 0; ; i++ {
+		if _, ok := used[InterfaceName(i)]; !ok {
+			return InterfaceName(i)
+		}
+	}
+}
+
+// AttachNetwork calls CNI ADD for a single network in the network namespace nsPath.
+// id is the CNI container ID, i.e. "<NAMESPACE>-<CONTAINER ID>".
+// bw is passed as the runtimeConfig of the bandwidth plugin, when the container has its own limits.
+func AttachNetwork(ctx context.Context, cniPath string, netw *NetworkConfig, id, nsPath string, ep EndpointSettings, ports []cni.PortMapping, bw cni.BandWidth, args map[string]string) (*types100.Result, error) {
+	res, err := newCNIConfig(cniPath).AddNetworkList(ctx, netw.NetworkConfigList, endpointRuntimeConf(id, nsPath, ep, ports, bw, args))
+	if err != nil {
+		return nil, fmt.Errorf("failed to attach network %q: %w", netw.Name, err)
+	}
+	return types100.NewResultFromResult(res)
+}
+
+// DetachNetwork calls CNI DEL for a single network previously attached with AttachNetwork.
+// nsPath may be empty if the network namespace is already gone.
+func DetachNetwork(ctx context.Context, cniPath string, netw *NetworkConfig, id, nsPath string, ep EndpointSettings, ports []cni.PortMapping) error {
+	if err := newCNIConfig(cniPath).DelNetworkList(ctx, netw.NetworkConfigList, endpointRuntimeConf(id, nsPath, ep, ports, cni.BandWidth{}, nil)); err != nil {
+		return fmt.Errorf("failed to detach network %q: %w", netw.Name, err)
+	}
+	return nil
+}
+
+func newCNIConfig(cniPath string) *libcni.CNIConfig {
+	// Same as go-cni's WithPluginDir
+	return libcni.NewCNIConfig([]string{cniPath}, &invoke.DefaultExec{
+		RawExec:       &invoke.RawExec{Stderr: os.Stderr},
+		PluginDecoder: version.PluginDecoder{},
+	})
+}
+
+func endpointRuntimeConf(id, nsPath string, ep EndpointSettings, ports []cni.PortMapping, bw cni.BandWidth, args map[string]string) *libcni.RuntimeConf {
+	rt := &libcni.RuntimeConf{
+		ContainerID: id,
+		NetNS:       nsPath,
+		IfName:      ep.IfName,
+		// allow loose CNI argument verification
+		// FYI: https://github.com/containernetworking/cni/issues/560
+		Args:           [][2]string{{"IgnoreUnknown", "1"}},
+		CapabilityArgs: make(map[string]interface{}),
+	}
+	if ep.IPAddress != "" {
+		rt.Args = append(rt.Args, [2]string{"IP", ep.IPAddress})
+	}
+	if ep.MACAddress != "" {
+		rt.Args = append(rt.Args, [2]string{"MAC", ep.MACAddress})
+	}
+	for k, v := range args {
+		rt.Args = append(rt.Args, [2]string{k, v})
+	}
+	if ep.IP6Address != "" {
+		rt.CapabilityArgs["ips"] = []string{ep.IP6Address}
+	}
+	if len(ports) > 0 {
+		rt.CapabilityArgs["portMappings"] = ports
+	}
+	if bw != (cni.BandWidth{}) {
+		rt.CapabilityArgs["bandwidth"] = bw
+	}
+	return rt
+}
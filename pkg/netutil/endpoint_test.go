@@ -0,0 +1,70 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package netutil
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestParseEndpoints(t *testing.T) {
+	endpoints, err := ParseEndpoints("")
+	assert.NilError(t, err)
+	assert.Equal(t, len(endpoints), 0)
+
+	endpoints, err = ParseEndpoints(`{"foo":{"IfName":"eth1","Aliases":["db"]}}`)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, endpoints, map[string]EndpointSettings{
+		"foo": {IfName: "eth1", Aliases: []string{"db"}},
+	})
+
+	_, err = ParseEndpoints("{")
+	assert.ErrorContains(t, err, "failed to parse network endpoints")
+}
+
+func TestInterfaceNames(t *testing.T) {
+	endpoints := map[string]EndpointSettings{
+		"bar": {IfName: "eth5"},
+	}
+	assert.DeepEqual(t, InterfaceNames([]string{"foo", "bar", "baz"}, endpoints), map[string]string{
+		"foo": "eth0",
+		"bar": "eth5",
+		"baz": "eth1",
+	})
+}
+
+func TestPinInterfaceNames(t *testing.T) {
+	endpoints := map[string]EndpointSettings{}
+	PinInterfaceNames([]string{"foo", "bar"}, endpoints, "10.4.0.2", "", "aa:bb:cc:dd:ee:ff")
+	assert.DeepEqual(t, endpoints, map[string]EndpointSettings{
+		"foo": {IfName: "eth0", IPAddress: "10.4.0.2", MACAddress: "aa:bb:cc:dd:ee:ff"},
+		"bar": {IfName: "eth1", IPAddress: "10.4.0.2", MACAddress: "aa:bb:cc:dd:ee:ff"},
+	})
+
+	// Pinned endpoints are left untouched
+	PinInterfaceNames([]string{"foo", "bar"}, endpoints, "10.4.0.3", "", "")
+	assert.Equal(t, endpoints["foo"].IPAddress, "10.4.0.2")
+}
+
+func TestFreeInterfaceName(t *testing.T) {
+	assert.Equal(t, FreeInterfaceName(nil), "eth0")
+	assert.Equal(t, FreeInterfaceName(map[string]EndpointSettings{
+		"foo": {IfName: "eth0"},
+		"bar": {IfName: "eth2"},
+	}), "eth1")
+}
@@ -17,6 +17,7 @@
 package netutil
 
 import (
+	"bytes"
 	"context"
 	"crypto/sha256"
 	"encoding/hex"
@@ -27,6 +28,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"runtime"
 	"sort"
 	"strconv"
 	"strings"
@@ -47,16 +49,24 @@ import (
 	"github.com/containerd/nerdctl/v2/pkg/strutil"
 )
 
+// Firewall backends of the bridge networks.
+const (
+	FirewallBackendIPTables = "iptables"
+	FirewallBackendNFTables = "nftables"
+)
+
 type CNIEnv struct {
 	Path        string
 	NetconfPath string
 	Namespace   string
+	// FirewallBackend is the backend of the port mappings, masquerading and isolation rules of the bridge networks.
+	FirewallBackend string
 }
 
 type CNIEnvOpt func(e *CNIEnv) error
 
 func (e *CNIEnv) ListNetworksMatch(reqs []string, allowPseudoNetwork bool) (list map[string][]*NetworkConfig, errs []error) {
-	networkConfigs, err := fsRead(e)
+	networkConfigs, err := e.networkConfigs()
 	if err != nil {
 		return nil, []error{err}
 	}
@@ -226,6 +236,34 @@ func WithNamespace(namespace string) CNIEnvOpt {
 	}
 }
 
+// WithFirewallBackend sets the firewall backend of the networks.
+// The network configurations are kept backend-agnostic on disk, and converted when they are read.
+func WithFirewallBackend(backend string) CNIEnvOpt {
+	return func(e *CNIEnv) error {
+		switch backend {
+		case "", FirewallBackendIPTables:
+			e.FirewallBackend = FirewallBackendIPTables
+		case FirewallBackendNFTables:
+			if runtime.GOOS != "linux" {
+				return fmt.Errorf("firewall backend %q is only supported on Linux", backend)
+			}
+			e.FirewallBackend = backend
+		default:
+			return fmt.Errorf("unknown firewall backend %q, must be one of %q or %q", backend, FirewallBackendIPTables, FirewallBackendNFTables)
+		}
+		return nil
+	}
+}
+
+// ContainerFirewallBackend returns the firewall backend recorded in the labels of a container at creation,
+// so that its networks are torn down as they were set up. The containers created without the label use defaultBackend.
+func ContainerFirewallBackend(containerLabels map[string]string, defaultBackend string) string {
+	if backend := containerLabels[labels.FirewallBackend]; backend != "" {
+		return backend
+	}
+	return defaultBackend
+}
+
 func NewCNIEnv(cniPath, cniConfPath string, opts ...CNIEnvOpt) (*CNIEnv, error) {
 	e := CNIEnv{
 		Path:        cniPath,
@@ -245,12 +283,31 @@ func NewCNIEnv(cniPath, cniConfPath string, opts ...CNIEnvOpt) (*CNIEnv, error)
 	return &e, nil
 }
 
+// networkConfigs reads the network configurations, converted for the firewall backend.
+func (e *CNIEnv) networkConfigs() ([]*NetworkConfig, error) {
+	configs, err := fsRead(e)
+	if err != nil || e.FirewallBackend != FirewallBackendNFTables {
+		return configs, err
+	}
+	for i, nc := range configs {
+		if configs[i], err = nc.withNFTables(); err != nil {
+			return nil, fmt.Errorf("failed to convert network %q for the nftables backend: %w", nc.Name, err)
+		}
+		if configs[i] != nc {
+			if err := checkNFTablesPlugins(e.Path, configs[i]); err != nil {
+				return nil, fmt.Errorf("network %q cannot use the nftables backend: %w", nc.Name, err)
+			}
+		}
+	}
+	return configs, nil
+}
+
 func (e *CNIEnv) NetworkList() ([]*NetworkConfig, error) {
-	return fsRead(e)
+	return e.networkConfigs()
 }
 
 func (e *CNIEnv) NetworkMap() (map[string]*NetworkConfig, error) { //nolint:revive
-	netConfigList, err := fsRead(e)
+	netConfigList, err := e.networkConfigs()
 	if err != nil {
 		return nil, err
 	}
@@ -266,7 +323,7 @@ func (e *CNIEnv) NetworkMap() (map[string]*NetworkConfig, error) { //nolint:revi
 }
 
 func (e *CNIEnv) NetworkByNameOrID(key string) (*NetworkConfig, error) {
-	netConfigList, err := fsRead(e)
+	netConfigList, err := e.networkConfigs()
 	if err != nil {
 		return nil, err
 	}
@@ -284,7 +341,7 @@ func (e *CNIEnv) NetworkByNameOrID(key string) (*NetworkConfig, error) {
 }
 
 func (e *CNIEnv) filterNetworks(filterf func(*NetworkConfig) bool) ([]*NetworkConfig, error) {
-	netConfigList, err := fsRead(e)
+	netConfigList, err := e.networkConfigs()
 	if err != nil {
 		return nil, err
 	}
@@ -303,7 +360,7 @@ func (e *CNIEnv) usedSubnets() ([]*net.IPNet, error) {
 		return nil, err
 	}
 
-	netConfigList, err := fsRead(e)
+	netConfigList, err := e.networkConfigs()
 	if err != nil {
 		return nil, err
 	}
@@ -319,6 +376,43 @@ type NetworkConfig struct {
 	NerdctlID     *string
 	NerdctlLabels *map[string]string
 	File          string
+	// nftIsolation is the ingress policy of the firewall plugin that nerdctl enforces with nftables rules instead,
+	// when the network is converted for the nftables backend.
+	nftIsolation string
+}
+
+// rewritePlugins returns a copy of the network configuration whose plugins are replaced by the result of fn.
+// The plugins are passed as generic JSON objects, so that the fields unknown to nerdctl are kept.
+// Configurations that are not lists are returned as is.
+func (n *NetworkConfig) rewritePlugins(fn func(plugins []map[string]interface{}) []map[string]interface{}) (*NetworkConfig, error) {
+	var conf map[string]interface{}
+	dec := json.NewDecoder(bytes.NewReader(n.Bytes))
+	dec.UseNumber()
+	if err := dec.Decode(&conf); err != nil {
+		return nil, err
+	}
+	raw, ok := conf["plugins"].([]interface{})
+	if !ok {
+		return n, nil
+	}
+	plugins := make([]map[string]interface{}, 0, len(raw))
+	for _, p := range raw {
+		if m, ok := p.(map[string]interface{}); ok {
+			plugins = append(plugins, m)
+		}
+	}
+	conf["plugins"] = fn(plugins)
+	b, err := json.Marshal(conf)
+	if err != nil {
+		return nil, err
+	}
+	l, err := libcni.ConfListFromBytes(b)
+	if err != nil {
+		return nil, err
+	}
+	res := *n
+	res.NetworkConfigList = l
+	return &res, nil
 }
 
 type cniNetworkConfig struct {
@@ -377,7 +471,13 @@ func (e *CNIEnv) CreateNetwork(opts types.NetworkCreateOptions) (*NetworkConfig,
 }
 
 func (e *CNIEnv) RemoveNetwork(net *NetworkConfig) error {
-	return fsRemove(e, net)
+	if err := fsRemove(e, net); err != nil {
+		return err
+	}
+	if err := net.removeNFTablesIsolation(); err != nil {
+		log.L.WithError(err).Warnf("failed to remove the nftables isolation rules of network %q", net.Name)
+	}
+	return nil
 }
 
 // GetDefaultNetworkConfig checks whether the default network exists
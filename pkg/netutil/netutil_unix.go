@@ -33,6 +33,7 @@ import (
 	"github.com/go-viper/mapstructure/v2"
 	"github.com/vishvananda/netlink"
 
+	"github.com/containerd/go-cni"
 	"github.com/containerd/log"
 
 	"github.com/containerd/nerdctl/v2/pkg/defaults"
@@ -79,6 +80,45 @@ func (n *NetworkConfig) subnets() []*net.IPNet {
 	return subnets
 }
 
+// BridgeGatewayIPv4 returns the IPv4 address of the bridge of a bridge network that acts as the gateway,
+// or nil for the other networks.
+func (n *NetworkConfig) BridgeGatewayIPv4() net.IP {
+	if len(n.Plugins) == 0 || n.Plugins[0].Network.Type != "bridge" {
+		return nil
+	}
+	var bridge bridgeConfig
+	if err := json.Unmarshal(n.Plugins[0].Bytes, &bridge); err != nil {
+		return nil
+	}
+	if !bridge.IsGW || bridge.IPAM["type"] != "host-local" {
+		return nil
+	}
+	var ipam hostLocalIPAMConfig
+	if err := mapstructure.Decode(bridge.IPAM, &ipam); err != nil {
+		return nil
+	}
+	for _, irange := range ipam.Ranges {
+		for _, r := range irange {
+			_, subnet, err := net.ParseCIDR(r.Subnet)
+			if err != nil || subnet.IP.To4() == nil {
+				continue
+			}
+			if r.Gateway != "" {
+				if gw := net.ParseIP(r.Gateway).To4(); gw != nil {
+					return gw
+				}
+				continue
+			}
+			// host-local defaults to the first address of the subnet
+			gw := make(net.IP, net.IPv4len)
+			copy(gw, subnet.IP.To4())
+			gw[3]++
+			return gw
+		}
+	}
+	return nil
+}
+
 func (n *NetworkConfig) clean() error {
 	// Remove the bridge network interface on the host.
 	if len(n.Plugins) > 0 && n.Plugins[0].Network.Type == "bridge" {
@@ -101,6 +141,7 @@ func (e *CNIEnv) generateCNIPlugins(driver string, name string, ipam map[string]
 		mtu := 0
 		iPMasq := true
 		icc := true
+		bwOpts := make(map[string]string)
 		for opt, v := range opts {
 			switch opt {
 			case "mtu", "com.docker.network.driver.mtu":
@@ -118,6 +159,8 @@ func (e *CNIEnv) generateCNIPlugins(driver string, name string, ipam map[string]
 				if err != nil {
 					return nil, err
 				}
+			case IngressRateOption, IngressBurstOption, EgressRateOption, EgressBurstOption:
+				bwOpts[opt] = v
 			default:
 				return nil, fmt.Errorf("unsupported %q network option %q", driver, opt)
 			}
@@ -161,6 +204,15 @@ func (e *CNIEnv) generateCNIPlugins(driver string, name string, ipam map[string]
 		} else {
 			plugins = []CNIPlugin{bridge, newPortMapPlugin(), newFirewallPlugin(ingressPolicy), newTuningPlugin()}
 		}
+		// The bandwidth limits of the network apply to all its containers.
+		// Without them, the containers may set their own (`--network-opt`), passed as runtimeConfig.
+		var bw cni.BandWidth
+		if len(bwOpts) > 0 {
+			if bw, err = ParseBandwidth(bwOpts); err != nil {
+				return nil, err
+			}
+		}
+		plugins = append(plugins, newBandwidthPlugin(bw))
 		if name != DefaultNetworkName {
 			ok, err := FirewallPluginGEQVersion(firewallPath, "v1.1.0")
 			if err != nil {
@@ -469,13 +521,19 @@ func pairIPAMRanges(subnets []*net.IPNet, gateways []string, ipRanges []string,
 
 // FirewallPluginGEQVersion checks if the firewall plugin is greater than or equal to the specified version
 func FirewallPluginGEQVersion(firewallPath string, versionStr string) (bool, error) {
+	return pluginGEQVersion(firewallPath, "firewall", versionStr)
+}
+
+// pluginGEQVersion checks if the CNI plugin at pluginPath, such as "firewall" or "bridge",
+// is greater than or equal to the specified version
+func pluginGEQVersion(pluginPath, plugin, versionStr string) (bool, error) {
 	// TODO: guess true by default in 2023
 	guessed := false
 
-	// Parse the stderr (NOT stdout) of `firewall`, such as "CNI firewall plugin v1.1.0\n", or "CNI firewall plugin version unknown\n"
+	// Parse the stderr (NOT stdout) of the plugin, such as "CNI firewall plugin v1.1.0\n", or "CNI firewall plugin version unknown\n"
 	//
 	// We do NOT set `CNI_COMMAND=VERSION` here, because the CNI "VERSION" command reports the version of the CNI spec,
-	// not the version of the firewall plugin implementation.
+	// not the version of the plugin implementation.
 	//
 	// ```
 	// $ /opt/cni/bin/firewall
@@ -484,7 +542,7 @@ func FirewallPluginGEQVersion(firewallPath string, versionStr string) (bool, err
 	// {"cniVersion":"1.0.0","supportedVersions":["0.4.0","1.0.0"]}
 	// ```
 	//
-	cmd := exec.Command(firewallPath)
+	cmd := exec.Command(pluginPath)
 	var stdout, stderr bytes.Buffer
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
@@ -493,19 +551,19 @@ func FirewallPluginGEQVersion(firewallPath string, versionStr string) (bool, err
 		return guessed, err
 	}
 
-	ver, err := guessFirewallPluginVersion(stderr.String()) // NOT stdout
+	ver, err := guessPluginVersion(plugin, stderr.String()) // NOT stdout
 	if err != nil {
-		return guessed, fmt.Errorf("failed to guess the version of %q: %w", firewallPath, err)
+		return guessed, fmt.Errorf("failed to guess the version of %q: %w", pluginPath, err)
 	}
 	targetVer := semver.MustParse(versionStr)
 	return ver.GreaterThan(targetVer) || ver.Equal(targetVer), nil
 }
 
-// guessFirewallPluginVersion guess the version of the CNI firewall plugin (not the version of the implemented CNI spec).
+// guessPluginVersion guess the version of a CNI plugin (not the version of the implemented CNI spec).
 //
 // stderr is like "CNI firewall plugin v1.1.0\n", or "CNI firewall plugin version unknown\n"
-func guessFirewallPluginVersion(stderr string) (*semver.Version, error) {
-	const prefix = "CNI firewall plugin "
+func guessPluginVersion(plugin, stderr string) (*semver.Version, error) {
+	prefix := "CNI " + plugin + " plugin "
 	lines := strings.Split(stderr, "\n")
 	for i, l := range lines {
 		trimmed := strings.TrimPrefix(l, prefix)
@@ -23,6 +23,7 @@ import (
 	"testing"
 
 	"github.com/Masterminds/semver/v3"
+	"github.com/containernetworking/cni/libcni"
 	"gotest.tools/v3/assert"
 )
 
@@ -61,7 +62,7 @@ func TestGuessFirewallPluginVersion(t *testing.T) {
 	}
 
 	for _, tc := range testCases {
-		got, err := guessFirewallPluginVersion(tc.stderr)
+		got, err := guessPluginVersion("firewall", tc.stderr)
 		if tc.err == "" {
 			assert.NilError(t, err)
 			assert.Equal(t, tc.expected, got.String())
@@ -199,3 +200,33 @@ func TestPairIPAMRangesAuxAddress(t *testing.T) {
 		assert.ErrorContains(t, err, "no matching subnet for aux-address fd00:7::9")
 	})
 }
+
+func TestBridgeGatewayIPv4(t *testing.T) {
+	t.Parallel()
+	conf := func(t *testing.T, plugin string) *NetworkConfig {
+		t.Helper()
+		l, err := libcni.ConfListFromBytes([]byte(`{"cniVersion": "1.0.0", "name": "n1", "plugins": [` + plugin + `]}`))
+		assert.NilError(t, err)
+		return &NetworkConfig{NetworkConfigList: l}
+	}
+
+	t.Run("the gateway of the range is used", func(t *testing.T) {
+		n := conf(t, `{"type": "bridge", "isGateway": true, "ipam": {"type": "host-local", "ranges": [[{"subnet": "fd00::/64"}], [{"subnet": "10.4.0.0/24", "gateway": "10.4.0.254"}]]}}`)
+		assert.Equal(t, "10.4.0.254", n.BridgeGatewayIPv4().String())
+	})
+
+	t.Run("the gateway defaults to the first address of the subnet", func(t *testing.T) {
+		n := conf(t, `{"type": "bridge", "isGateway": true, "ipam": {"type": "host-local", "ranges": [[{"subnet": "10.4.0.0/24"}]]}}`)
+		assert.Equal(t, "10.4.0.1", n.BridgeGatewayIPv4().String())
+	})
+
+	t.Run("a bridge that is not a gateway has none", func(t *testing.T) {
+		n := conf(t, `{"type": "bridge", "ipam": {"type": "host-local", "ranges": [[{"subnet": "10.4.0.0/24"}]]}}`)
+		assert.Assert(t, n.BridgeGatewayIPv4() == nil)
+	})
+
+	t.Run("other networks have none", func(t *testing.T) {
+		n := conf(t, `{"type": "macvlan", "master": "eth0", "ipam": {"type": "host-local", "ranges": [[{"subnet": "10.4.0.0/24"}]]}}`)
+		assert.Assert(t, n.BridgeGatewayIPv4() == nil)
+	})
+}
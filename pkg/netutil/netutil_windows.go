@@ -55,6 +55,11 @@ func (n *NetworkConfig) subnets() []*net.IPNet {
 	return subnets
 }
 
+// BridgeGatewayIPv4 always returns nil, as there are no bridge networks on Windows.
+func (n *NetworkConfig) BridgeGatewayIPv4() net.IP {
+	return nil
+}
+
 func (n *NetworkConfig) clean() error {
 	return nil
 }
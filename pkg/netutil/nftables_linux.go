@@ -0,0 +1,204 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package netutil
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"text/template"
+)
+
+// nftTable is the nftables table of the isolation rules of the bridge networks, for the nftables backend.
+//
+// The forward chain dispatches the packets to the chain of their output bridge, through the isolation map.
+// The chain of a bridge drops the packets coming from the other bridges, and, for the "isolated" policy,
+// from the bridge itself. This is the same as the iptables rules of the firewall plugin:
+// https://www.cni.dev/plugins/current/meta/firewall/
+const nftTable = "nerdctl"
+
+// nftPluginsMinVersion is the first version of the bridge and portmap plugins that support the nftables backend.
+// The older ones ignore the backend fields of their configuration, and silently use iptables.
+const nftPluginsMinVersion = "v1.5.0"
+
+// nftPluginChecks caches the result of checkNFTablesPlugin by plugin path, as running the plugins is not free.
+var nftPluginChecks sync.Map
+
+var nftIsolationTemplate = template.Must(template.New("").Parse(`table inet {{.Table}} {
+	set bridges {
+		type ifname
+	}
+	map isolation {
+		type ifname : verdict
+	}
+	chain forward {
+		type filter hook forward priority filter; policy accept;
+	}
+	chain {{.Bridge}} {
+	}
+}
+flush chain inet {{.Table}} forward
+add rule inet {{.Table}} forward oifname vmap @isolation
+flush chain inet {{.Table}} {{.Bridge}}
+add rule inet {{.Table}} {{.Bridge}} iifname != "{{.Bridge}}" iifname @bridges drop
+{{- if .Isolated}}
+add rule inet {{.Table}} {{.Bridge}} iifname "{{.Bridge}}" drop
+{{- end}}
+add element inet {{.Table}} bridges { "{{.Bridge}}" }
+add element inet {{.Table}} isolation { "{{.Bridge}}" : jump {{.Bridge}} }
+`))
+
+// withNFTables returns a copy of a nerdctl bridge network that uses the nftables backend.
+//   - The bridge plugin masquerades the traffic with nftables.
+//   - The portmap plugin publishes the ports with nftables.
+//   - The firewall plugin, that only supports iptables and firewalld, is removed.
+//     Its ingress policy is enforced by the rules of SetupNFTablesIsolation instead.
+//
+// The other networks are returned as is.
+func (n *NetworkConfig) withNFTables() (*NetworkConfig, error) {
+	if n.NerdctlID == nil || len(n.Plugins) == 0 || n.Plugins[0].Network.Type != "bridge" {
+		return n, nil
+	}
+	var ingressPolicy string
+	res, err := n.rewritePlugins(func(plugins []map[string]interface{}) []map[string]interface{} {
+		res := make([]map[string]interface{}, 0, len(plugins))
+		for _, p := range plugins {
+			switch p["type"] {
+			case "bridge":
+				if p["ipMasq"] == true {
+					p["ipMasqBackend"] = FirewallBackendNFTables
+				}
+			case "portmap":
+				p["backend"] = FirewallBackendNFTables
+			case "firewall":
+				ingressPolicy, _ = p["ingressPolicy"].(string)
+				continue
+			}
+			res = append(res, p)
+		}
+		return res
+	})
+	if err != nil {
+		return nil, err
+	}
+	if res != n && (ingressPolicy == "same-bridge" || ingressPolicy == "isolated") {
+		res.nftIsolation = ingressPolicy
+	}
+	return res, nil
+}
+
+// checkNFTablesPlugins checks that the plugins of a network converted by withNFTables support the nftables backend.
+func checkNFTablesPlugins(cniPath string, n *NetworkConfig) error {
+	for _, p := range n.Plugins {
+		if p.Network.Type != "bridge" && p.Network.Type != "portmap" {
+			continue
+		}
+		pluginPath := filepath.Join(cniPath, p.Network.Type)
+		res, ok := nftPluginChecks.Load(pluginPath)
+		if !ok {
+			res, _ = nftPluginChecks.LoadOrStore(pluginPath, checkNFTablesPlugin(pluginPath, p.Network.Type))
+		}
+		if err, _ := res.(error); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func checkNFTablesPlugin(pluginPath, plugin string) error {
+	ok, err := pluginGEQVersion(pluginPath, plugin, nftPluginsMinVersion)
+	if err != nil {
+		return fmt.Errorf("failed to detect whether %q is newer than %s: %w", pluginPath, nftPluginsMinVersion, err)
+	}
+	if !ok {
+		return fmt.Errorf("the nftables firewall backend needs CNI plugin %q (>= %s) in CNI_PATH (%q), see https://github.com/containernetworking/plugins",
+			plugin, nftPluginsMinVersion, filepath.Dir(pluginPath))
+	}
+	return nil
+}
+
+// SetupNFTablesIsolation adds the nftables rules that isolate the bridge of the network from the other bridges.
+// It is a no-op unless the network was converted for the nftables backend.
+// The rules are replaced if they already exist, as they do not survive a reboot.
+func (n *NetworkConfig) SetupNFTablesIsolation() error {
+	if n.nftIsolation == "" {
+		return nil
+	}
+	bridge, err := n.bridgeName()
+	if err != nil {
+		return err
+	}
+	script, err := nftIsolationRules(bridge, n.nftIsolation == "isolated")
+	if err != nil {
+		return err
+	}
+	return runNFT(script)
+}
+
+func nftIsolationRules(bridge string, isolated bool) (string, error) {
+	var script bytes.Buffer
+	err := nftIsolationTemplate.Execute(&script, map[string]interface{}{
+		"Table":    nftTable,
+		"Bridge":   bridge,
+		"Isolated": isolated,
+	})
+	return script.String(), err
+}
+
+// removeNFTablesIsolation removes the rules of SetupNFTablesIsolation.
+func (n *NetworkConfig) removeNFTablesIsolation() error {
+	if n.nftIsolation == "" {
+		return nil
+	}
+	bridge, err := n.bridgeName()
+	if err != nil {
+		return err
+	}
+	return runNFT(fmt.Sprintf(`delete element inet %[1]s isolation { "%[2]s" }
+delete element inet %[1]s bridges { "%[2]s" }
+delete chain inet %[1]s %[2]s
+`, nftTable, bridge))
+}
+
+// bridgeName returns the name of the bridge interface of a bridge network.
+func (n *NetworkConfig) bridgeName() (string, error) {
+	var bridge bridgeConfig
+	if err := json.Unmarshal(n.Plugins[0].Bytes, &bridge); err != nil {
+		return "", err
+	}
+	if bridge.BrName == "" {
+		return "", fmt.Errorf("network %q has no bridge name", n.Name)
+	}
+	return bridge.BrName, nil
+}
+
+func runNFT(script string) error {
+	nft, err := exec.LookPath("nft")
+	if err != nil {
+		return fmt.Errorf("the nftables firewall backend needs the nft command: %w", err)
+	}
+	cmd := exec.Command(nft, "-f", "-")
+	cmd.Stdin = strings.NewReader(script)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to run nft: %w (output: %q)", err, string(out))
+	}
+	return nil
+}
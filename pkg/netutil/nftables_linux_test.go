@@ -0,0 +1,124 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package netutil
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/containernetworking/cni/libcni"
+	"gotest.tools/v3/assert"
+)
+
+func TestWithNFTables(t *testing.T) {
+	t.Parallel()
+	conf := func(t *testing.T, plugins string) *NetworkConfig {
+		t.Helper()
+		l, err := libcni.ConfListFromBytes([]byte(`{"cniVersion": "1.0.0", "name": "n1", "nerdctlID": "0123456789ab", "plugins": [` + plugins + `]}`))
+		assert.NilError(t, err)
+		id := "0123456789ab"
+		return &NetworkConfig{NetworkConfigList: l, NerdctlID: &id}
+	}
+	pluginMaps := func(t *testing.T, n *NetworkConfig) []map[string]interface{} {
+		t.Helper()
+		var res []map[string]interface{}
+		for _, p := range n.Plugins {
+			var m map[string]interface{}
+			assert.NilError(t, json.Unmarshal(p.Bytes, &m))
+			res = append(res, m)
+		}
+		return res
+	}
+
+	t.Run("the bridge plugins use nftables and the firewall plugin is removed", func(t *testing.T) {
+		n, err := conf(t, `{"type": "bridge", "bridge": "br-0123456789ab", "ipMasq": true}, {"type": "portmap"}, {"type": "firewall", "ingressPolicy": "isolated"}, {"type": "tuning"}`).withNFTables()
+		assert.NilError(t, err)
+		assert.DeepEqual(t, pluginMaps(t, n), []map[string]interface{}{
+			{"type": "bridge", "bridge": "br-0123456789ab", "ipMasq": true, "ipMasqBackend": "nftables"},
+			{"type": "portmap", "backend": "nftables"},
+			{"type": "tuning"},
+		})
+		assert.Equal(t, n.nftIsolation, "isolated")
+		bridge, err := n.bridgeName()
+		assert.NilError(t, err)
+		assert.Equal(t, bridge, "br-0123456789ab")
+	})
+
+	t.Run("internal networks are not masqueraded", func(t *testing.T) {
+		n, err := conf(t, `{"type": "bridge", "bridge": "br-0123456789ab"}, {"type": "firewall", "ingressPolicy": "same-bridge"}`).withNFTables()
+		assert.NilError(t, err)
+		assert.DeepEqual(t, pluginMaps(t, n), []map[string]interface{}{
+			{"type": "bridge", "bridge": "br-0123456789ab"},
+		})
+		assert.Equal(t, n.nftIsolation, "same-bridge")
+	})
+
+	t.Run("networks not managed by nerdctl are left untouched", func(t *testing.T) {
+		orig := conf(t, `{"type": "bridge", "ipMasq": true}, {"type": "firewall"}`)
+		orig.NerdctlID = nil
+		n, err := orig.withNFTables()
+		assert.NilError(t, err)
+		assert.Assert(t, n == orig)
+	})
+
+	t.Run("other drivers are left untouched", func(t *testing.T) {
+		orig := conf(t, `{"type": "macvlan", "master": "eth0"}`)
+		n, err := orig.withNFTables()
+		assert.NilError(t, err)
+		assert.Assert(t, n == orig)
+		assert.NilError(t, n.SetupNFTablesIsolation())
+	})
+}
+
+func TestCheckNFTablesPlugins(t *testing.T) {
+	t.Parallel()
+	// fakePlugins installs plugins that only print their version, as the CNI plugins do when run without CNI_COMMAND.
+	fakePlugins := func(t *testing.T, versions map[string]string) string {
+		t.Helper()
+		dir := t.TempDir()
+		for plugin, version := range versions {
+			script := fmt.Sprintf("#!/bin/sh\necho 'CNI %s plugin %s' >&2\n", plugin, version)
+			assert.NilError(t, os.WriteFile(filepath.Join(dir, plugin), []byte(script), 0o755))
+		}
+		return dir
+	}
+	l, err := libcni.ConfListFromBytes([]byte(`{"cniVersion": "1.0.0", "name": "n1", "plugins": [{"type": "bridge"}, {"type": "portmap"}, {"type": "tuning"}]}`))
+	assert.NilError(t, err)
+	n := &NetworkConfig{NetworkConfigList: l}
+
+	assert.NilError(t, checkNFTablesPlugins(fakePlugins(t, map[string]string{"bridge": "v1.5.0", "portmap": "v1.7.1"}), n))
+	assert.ErrorContains(t, checkNFTablesPlugins(fakePlugins(t, map[string]string{"bridge": "v1.5.0", "portmap": "v1.4.1"}), n),
+		`the nftables firewall backend needs CNI plugin "portmap" (>= v1.5.0)`)
+	assert.ErrorContains(t, checkNFTablesPlugins(fakePlugins(t, map[string]string{"portmap": "v1.5.0"}), n),
+		"failed to detect whether")
+}
+
+func TestNFTIsolationRules(t *testing.T) {
+	rules, err := nftIsolationRules("br-0123456789ab", false)
+	assert.NilError(t, err)
+	assert.Assert(t, strings.Contains(rules, `add rule inet nerdctl br-0123456789ab iifname != "br-0123456789ab" iifname @bridges drop`))
+	assert.Assert(t, !strings.Contains(rules, `add rule inet nerdctl br-0123456789ab iifname "br-0123456789ab" drop`))
+	assert.Assert(t, strings.Contains(rules, `add element inet nerdctl isolation { "br-0123456789ab" : jump br-0123456789ab }`))
+
+	rules, err = nftIsolationRules("nerdctl0", true)
+	assert.NilError(t, err)
+	assert.Assert(t, strings.Contains(rules, `add rule inet nerdctl nerdctl0 iifname "nerdctl0" drop`))
+}
@@ -0,0 +1,37 @@
+//go:build !linux
+
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package netutil
+
+// withNFTables is a no-op, as the nftables backend is only supported on Linux.
+func (n *NetworkConfig) withNFTables() (*NetworkConfig, error) {
+	return n, nil
+}
+
+func checkNFTablesPlugins(cniPath string, n *NetworkConfig) error {
+	return nil
+}
+
+// SetupNFTablesIsolation is a no-op, as the nftables backend is only supported on Linux.
+func (n *NetworkConfig) SetupNFTablesIsolation() error {
+	return nil
+}
+
+func (n *NetworkConfig) removeNFTablesIsolation() error {
+	return nil
+}
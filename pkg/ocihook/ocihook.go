@@ -39,9 +39,11 @@ import (
 	"github.com/containerd/log"
 
 	"github.com/containerd/nerdctl/v2/pkg/bypass4netnsutil"
+	"github.com/containerd/nerdctl/v2/pkg/dnsutil/dnsserver"
 	"github.com/containerd/nerdctl/v2/pkg/dnsutil/hostsstore"
 	"github.com/containerd/nerdctl/v2/pkg/internal/filesystem"
 	"github.com/containerd/nerdctl/v2/pkg/labels"
+	"github.com/containerd/nerdctl/v2/pkg/mountutil/volumestore"
 	"github.com/containerd/nerdctl/v2/pkg/namestore"
 	"github.com/containerd/nerdctl/v2/pkg/netutil"
 	"github.com/containerd/nerdctl/v2/pkg/netutil/nettype"
@@ -64,7 +66,7 @@ const (
 	NetworkNamespace = labels.Prefix + "network-namespace"
 )
 
-func Run(stdin io.Reader, stderr io.Writer, event, dataStore, cniPath, cniNetconfPath, bridgeIP string) error {
+func Run(stdin io.Reader, stderr io.Writer, event, dataStore, cniPath, cniNetconfPath, bridgeIP, firewallBackend, nerdctlCmd string, nerdctlArgs []string) error {
 	if stdin == nil || event == "" || dataStore == "" || cniPath == "" || cniNetconfPath == "" {
 		return errors.New("got insufficient args")
 	}
@@ -118,10 +120,18 @@ func Run(stdin io.Reader, stderr io.Writer, event, dataStore, cniPath, cniNetcon
 	}
 	defer filesystem.Unlock(lock)
 
-	opts, err := newHandlerOpts(&state, dataStore, cniPath, cniNetconfPath, bridgeIP)
+	if event == "postStop" {
+		if err := loadRuntimeNetworks(&state); err != nil {
+			return err
+		}
+	}
+
+	opts, err := newHandlerOpts(&state, dataStore, cniPath, cniNetconfPath, bridgeIP, firewallBackend)
 	if err != nil {
 		return err
 	}
+	opts.nerdctlCmd = nerdctlCmd
+	opts.nerdctlArgs = nerdctlArgs
 
 	switch event {
 	case "createRuntime":
@@ -133,7 +143,7 @@ func Run(stdin io.Reader, stderr io.Writer, event, dataStore, cniPath, cniNetcon
 	}
 }
 
-func newHandlerOpts(state *specs.State, dataStore, cniPath, cniNetconfPath, bridgeIP string) (*handlerOpts, error) {
+func newHandlerOpts(state *specs.State, dataStore, cniPath, cniNetconfPath, bridgeIP, firewallBackend string) (*handlerOpts, error) {
 	o := &handlerOpts{
 		state:     state,
 		dataStore: dataStore,
@@ -178,10 +188,21 @@ func newHandlerOpts(state *specs.State, dataStore, cniPath, cniNetconfPath, brid
 	case nettype.Host, nettype.None, nettype.Container, nettype.Namespace:
 		// NOP
 	case nettype.CNI:
-		e, err := netutil.NewCNIEnv(cniPath, cniNetconfPath, netutil.WithNamespace(namespace), netutil.WithDefaultNetwork(bridgeIP))
+		firewallBackend = netutil.ContainerFirewallBackend(o.state.Annotations, firewallBackend)
+		e, err := netutil.NewCNIEnv(cniPath, cniNetconfPath, netutil.WithNamespace(namespace), netutil.WithFirewallBackend(firewallBackend), netutil.WithDefaultNetwork(bridgeIP))
 		if err != nil {
 			return nil, err
 		}
+		endpoints, err := netutil.ParseEndpoints(o.state.Annotations[labels.NetworkEndpoints])
+		if err != nil {
+			return nil, err
+		}
+		o.bandwidth, err = netutil.ParseBandwidthLabel(o.state.Annotations[labels.Bandwidth])
+		if err != nil {
+			return nil, err
+		}
+		o.cniPath = cniPath
+		o.aliases = make(map[string][]string)
 		cniOpts := []cni.Opt{
 			cni.WithPluginDir([]string{cniPath}),
 		}
@@ -190,15 +211,30 @@ func newHandlerOpts(state *specs.State, dataStore, cniPath, cniNetconfPath, brid
 			if netw, err = e.NetworkByNameOrID(netstr); err != nil {
 				return nil, err
 			}
+			o.networks = append(o.networks, netw)
+			if netw.Name == o.state.Annotations[labels.EmbeddedDNS] && netw.BridgeGatewayIPv4() != nil {
+				o.embeddedDNSNetwork = netw
+			}
+			ep := endpoints[netstr]
+			if len(ep.Aliases) > 0 {
+				o.aliases[netstr] = ep.Aliases
+			}
+			// Networks with a pinned interface name are attached one by one, see netutil.EndpointSettings.
+			if ep.IfName != "" {
+				o.endpoints = append(o.endpoints, endpoint{name: netstr, config: netw, settings: ep})
+				continue
+			}
 			cniOpts = append(cniOpts, cni.WithConfListBytes(netw.Bytes))
 			o.cniNames = append(o.cniNames, netstr)
 		}
-		o.cni, err = cni.New(cniOpts...)
-		if err != nil {
-			return nil, err
-		}
-		if o.cni == nil {
-			log.L.Warnf("no CNI network could be loaded from the provided network names: %v", networks)
+		if len(o.cniNames) > 0 {
+			o.cni, err = cni.New(cniOpts...)
+			if err != nil {
+				return nil, err
+			}
+			if o.cni == nil {
+				log.L.Warnf("no CNI network could be loaded from the provided network names: %v", networks)
+			}
 		}
 	default:
 		return nil, fmt.Errorf("unexpected network type %v", netType)
@@ -258,6 +294,10 @@ type handlerOpts struct {
 	ports             []cni.PortMapping
 	cni               cni.CNI
 	cniNames          []string
+	cniPath           string
+	endpoints         []endpoint
+	aliases           map[string][]string // network name:aliases
+	networks          []*netutil.NetworkConfig
 	fullID            string
 	rootlessKitClient rlkclient.Client
 	bypassClient      b4nndclient.Client
@@ -265,6 +305,49 @@ type handlerOpts struct {
 	containerIP       string
 	containerMAC      string
 	containerIP6      string
+	bandwidth         cni.BandWidth // limits of the container, passed to the bandwidth plugin as runtimeConfig
+	// embeddedDNSNetwork is the network whose embedded DNS server is the nameserver of the container
+	embeddedDNSNetwork *netutil.NetworkConfig
+	nerdctlCmd         string
+	nerdctlArgs        []string
+}
+
+// endpoint is a network attached on its own rather than through handlerOpts.cni.
+type endpoint struct {
+	name     string
+	config   *netutil.NetworkConfig
+	settings netutil.EndpointSettings
+}
+
+// hasNetworks returns whether the container is attached to any CNI network.
+func (o *handlerOpts) hasNetworks() bool {
+	return o.cni != nil || len(o.endpoints) > 0
+}
+
+// networkNames returns the names of all the CNI networks of the container.
+func (o *handlerOpts) networkNames() []string {
+	names := append([]string{}, o.cniNames...)
+	for _, ep := range o.endpoints {
+		names = append(names, ep.name)
+	}
+	return names
+}
+
+// loadRuntimeNetworks replaces the network annotations with the ones recorded in the container state
+// by `nerdctl network connect` and `nerdctl network disconnect`, if any.
+func loadRuntimeNetworks(st *specs.State) error {
+	lf, err := state.New(st.Annotations[labels.StateDir])
+	if err != nil {
+		return err
+	}
+	if err = lf.Load(); err != nil {
+		return err
+	}
+	if lf.Networks != "" {
+		st.Annotations[labels.Networks] = lf.Networks
+		st.Annotations[labels.NetworkEndpoints] = lf.NetworkEndpoints
+	}
+	return nil
 }
 
 // hookSpec is from https://github.com/containerd/containerd/blob/v1.4.3/cmd/containerd/command/oci-hook.go#L59-L64
@@ -328,9 +411,18 @@ func getNetNSPath(state *specs.State) (string, error) {
 }
 
 func getPortMapOpts(opts *handlerOpts) ([]cni.NamespaceOpts, error) {
+	ports, err := getPortMappings(opts)
+	if err != nil || len(ports) == 0 {
+		return nil, err
+	}
+	return []cni.NamespaceOpts{cni.WithCapabilityPortMap(ports)}, nil
+}
+
+// getPortMappings returns the port mappings to be passed to the CNI plugins.
+func getPortMappings(opts *handlerOpts) ([]cni.PortMapping, error) {
 	if len(opts.ports) > 0 {
 		if !rootlessutil.IsRootlessChild() {
-			return []cni.NamespaceOpts{cni.WithCapabilityPortMap(opts.ports)}, nil
+			return opts.ports, nil
 		}
 		var (
 			childIP                            net.IP
@@ -366,7 +458,7 @@ func getPortMapOpts(opts *handlerOpts) ([]cni.NamespaceOpts, error) {
 			}
 			ports[i] = p
 		}
-		return []cni.NamespaceOpts{cni.WithCapabilityPortMap(ports)}, nil
+		return ports, nil
 	}
 	return nil, nil
 }
@@ -404,6 +496,15 @@ func getMACAddressOpts(opts *handlerOpts) ([]cni.NamespaceOpts, error) {
 	return nil, nil
 }
 
+// getBandwidthOpts returns the limits of the container for the bandwidth plugin.
+// The plugin ignores them on the networks that have limits of their own.
+func getBandwidthOpts(opts *handlerOpts) []cni.NamespaceOpts {
+	if opts.bandwidth == (cni.BandWidth{}) {
+		return nil
+	}
+	return []cni.NamespaceOpts{cni.WithCapabilityBandWidth(opts.bandwidth)}
+}
+
 func getIP6AddressOpts(opts *handlerOpts) ([]cni.NamespaceOpts, error) {
 	if opts.containerIP6 != "" {
 		if rootlessutil.IsRootlessChild() {
@@ -552,48 +653,84 @@ func applyNetworkSettings(opts *handlerOpts) (err error) {
 	namespaceOpts = append(namespaceOpts, ipAddressOpts...)
 	namespaceOpts = append(namespaceOpts, macAddressOpts...)
 	namespaceOpts = append(namespaceOpts, ip6AddressOpts...)
+	namespaceOpts = append(namespaceOpts, getBandwidthOpts(opts)...)
+	cniArgs := map[string]string{
+		"NERDCTL_CNI_DHCP_HOSTNAME": opts.state.Annotations[labels.Hostname],
+	}
 	namespaceOpts = append(namespaceOpts,
 		cni.WithLabels(map[string]string{
 			"IgnoreUnknown": "1",
 		}),
-		cni.WithArgs("NERDCTL_CNI_DHCP_HOSTNAME", opts.state.Annotations[labels.Hostname]),
+		cni.WithArgs("NERDCTL_CNI_DHCP_HOSTNAME", cniArgs["NERDCTL_CNI_DHCP_HOSTNAME"]),
 	)
 	hsMeta := hostsstore.Meta{
 		ID:         opts.state.ID,
-		Networks:   make(map[string]*types100.Result, len(opts.cniNames)),
+		Networks:   make(map[string]*types100.Result, len(opts.cniNames)+len(opts.endpoints)),
 		Hostname:   opts.state.Annotations[labels.Hostname],
 		Domainname: opts.state.Annotations[labels.Domainname],
 		ExtraHosts: opts.extraHosts,
 		Name:       opts.state.Annotations[labels.Name],
+		Aliases:    opts.aliases,
+		// resolv.conf points to the embedded DNS server, even if its network is gone
+		EmbeddedDNS: opts.state.Annotations[labels.EmbeddedDNS] != "",
 	}
 
-	// When containerd gets bounced, containers that were previously running and that are restarted will go again
-	// through onCreateRuntime (*unlike* in a normal stop/start flow).
-	// As such, a container may very well have an ip already. The bridge plugin would thus refuse to loan a new one
-	// and error out, thus making the onCreateRuntime hook fail. In turn, runc (or containerd) will mis-interpret this,
-	// and subsequently call onPostStop (although the container will not get deleted), and we will release the name...
-	// leading to a bricked system where multiple containers may share the same name.
-	// Thus, we do pre-emptively clean things up - error is not checked, as in the majority of cases, that would
-	// legitimately error (and that does not matter)
-	// See https://github.com/containerd/nerdctl/issues/3355
-	_ = opts.cni.Remove(ctx, opts.fullID, "", namespaceOpts...)
+	for _, netw := range opts.networks {
+		if err := netw.SetupNFTablesIsolation(); err != nil {
+			return fmt.Errorf("failed to isolate network %q: %w", netw.Name, err)
+		}
+	}
 
-	// Defer CNI configuration removal to ensure idempotency of oci-hook.
-	defer func() {
+	if opts.cni != nil {
+		// When containerd gets bounced, containers that were previously running and that are restarted will go again
+		// through onCreateRuntime (*unlike* in a normal stop/start flow).
+		// As such, a container may very well have an ip already. The bridge plugin would thus refuse to loan a new one
+		// and error out, thus making the onCreateRuntime hook fail. In turn, runc (or containerd) will mis-interpret this,
+		// and subsequently call onPostStop (although the container will not get deleted), and we will release the name...
+		// leading to a bricked system where multiple containers may share the same name.
+		// Thus, we do pre-emptively clean things up - error is not checked, as in the majority of cases, that would
+		// legitimately error (and that does not matter)
+		// See https://github.com/containerd/nerdctl/issues/3355
+		_ = opts.cni.Remove(ctx, opts.fullID, "", namespaceOpts...)
+
+		// Defer CNI configuration removal to ensure idempotency of oci-hook.
+		defer func() {
+			if err != nil {
+				log.L.Warn("Container failed starting. Removing allocated network configuration.")
+				_ = opts.cni.Remove(ctx, opts.fullID, nsPath, namespaceOpts...)
+			}
+		}()
+
+		cniRes, err := opts.cni.Setup(ctx, opts.fullID, nsPath, namespaceOpts...)
 		if err != nil {
-			log.L.Warn("Container failed starting. Removing allocated network configuration.")
-			_ = opts.cni.Remove(ctx, opts.fullID, nsPath, namespaceOpts...)
+			return fmt.Errorf("failed to call cni.Setup: %w", err)
 		}
-	}()
 
-	cniRes, err := opts.cni.Setup(ctx, opts.fullID, nsPath, namespaceOpts...)
+		cniResRaw := cniRes.Raw()
+		for i, cniName := range opts.cniNames {
+			hsMeta.Networks[cniName] = cniResRaw[i]
+		}
+	}
+
+	ports, err := getPortMappings(opts)
 	if err != nil {
-		return fmt.Errorf("failed to call cni.Setup: %w", err)
+		return err
 	}
+	for _, ep := range opts.endpoints {
+		// Pre-emptive cleanup, for the same reason as above
+		_ = netutil.DetachNetwork(ctx, opts.cniPath, ep.config, opts.fullID, "", ep.settings, ports)
+
+		defer func() {
+			if err != nil {
+				_ = netutil.DetachNetwork(ctx, opts.cniPath, ep.config, opts.fullID, nsPath, ep.settings, ports)
+			}
+		}()
 
-	cniResRaw := cniRes.Raw()
-	for i, cniName := range opts.cniNames {
-		hsMeta.Networks[cniName] = cniResRaw[i]
+		res, err := netutil.AttachNetwork(ctx, opts.cniPath, ep.config, opts.fullID, nsPath, ep.settings, ports, opts.bandwidth, cniArgs)
+		if err != nil {
+			return err
+		}
+		hsMeta.Networks[ep.name] = res
 	}
 
 	b4nnEnabled, b4nnBindEnabled, err := bypass4netnsutil.IsBypass4netnsEnabled(opts.state.Annotations)
@@ -605,6 +742,13 @@ func applyNetworkSettings(opts *handlerOpts) (err error) {
 		return err
 	}
 
+	if netw := opts.embeddedDNSNetwork; netw != nil {
+		// The container can still run without resolving the other containers, so only warn
+		if err := dnsserver.Ensure(opts.dataStore, netw.Name, netw.BridgeGatewayIPv4(), opts.nerdctlCmd, opts.nerdctlArgs); err != nil {
+			log.L.WithError(err).Warnf("failed to start the embedded DNS server of network %q", netw.Name)
+		}
+	}
+
 	if rootlessutil.IsRootlessChild() {
 		if b4nnEnabled {
 			bm, err := bypass4netnsutil.NewBypass4netnsCNIBypassManager(opts.bypassClient, opts.rootlessKitClient, opts.state.Annotations)
@@ -637,9 +781,11 @@ func onCreateRuntime(opts *handlerOpts) error {
 		log.L.WithError(err).Error("failed re-acquiring name - see https://github.com/containerd/nerdctl/issues/2992")
 	}
 
-	var netError error
-	if opts.cni != nil {
-		netError = applyNetworkSettings(opts)
+	// Mount the devices of the volumes, unless they are already mounted for another container.
+	// This covers the tasks restarted by containerd (--restart) without nerdctl.
+	createError := volumestore.MountVolumes(opts.dataStore, ns, opts.state.ID, labels.GetVolumeNames(opts.state.Annotations))
+	if createError == nil && opts.hasNetworks() {
+		createError = applyNetworkSettings(opts)
 	}
 
 	// Set StartedAt and CreateError
@@ -650,14 +796,17 @@ func onCreateRuntime(opts *handlerOpts) error {
 
 	err = lf.Transform(func(lf *state.Store) error {
 		lf.StartedAt = time.Now()
-		lf.CreateError = netError != nil
+		lf.CreateError = createError != nil
+		// The annotations of the new task are authoritative again
+		lf.Networks = ""
+		lf.NetworkEndpoints = ""
 		return nil
 	})
 	if err != nil {
 		return err
 	}
 
-	return netError
+	return createError
 }
 
 func onPostStop(opts *handlerOpts) error {
@@ -680,13 +829,19 @@ func onPostStop(opts *handlerOpts) error {
 	if err != nil {
 		return err
 	}
+
+	ns := opts.state.Annotations[labels.Namespace]
+	// Unmount the devices of the volumes that are no longer used by any container.
+	// This is also done when the creation failed, as the task is gone either way.
+	if err := volumestore.UnmountVolumes(opts.dataStore, ns, opts.state.ID, labels.GetVolumeNames(opts.state.Annotations)); err != nil {
+		log.L.WithError(err).Warnf("failed to unmount the volumes of container %s", opts.state.ID)
+	}
 	if shouldExit {
 		return nil
 	}
 
 	ctx := context.Background()
-	ns := opts.state.Annotations[labels.Namespace]
-	if opts.cni != nil {
+	if opts.hasNetworks() {
 		var err error
 		b4nnEnabled, b4nnBindEnabled, err := bypass4netnsutil.IsBypass4netnsEnabled(opts.state.Annotations)
 		if err != nil {
@@ -730,15 +885,27 @@ func onPostStop(opts *handlerOpts) error {
 		namespaceOpts = append(namespaceOpts, ipAddressOpts...)
 		namespaceOpts = append(namespaceOpts, macAddressOpts...)
 		namespaceOpts = append(namespaceOpts, ip6AddressOpts...)
-		if err := opts.cni.Remove(ctx, opts.fullID, "", namespaceOpts...); err != nil {
-			log.L.WithError(err).Errorf("failed to call cni.Remove")
+		if opts.cni != nil {
+			if err := opts.cni.Remove(ctx, opts.fullID, "", namespaceOpts...); err != nil {
+				log.L.WithError(err).Errorf("failed to call cni.Remove")
+				return err
+			}
+		}
+		ports, err := getPortMappings(opts)
+		if err != nil {
 			return err
 		}
+		for _, ep := range opts.endpoints {
+			if err := netutil.DetachNetwork(ctx, opts.cniPath, ep.config, opts.fullID, "", ep.settings, ports); err != nil {
+				log.L.WithError(err).Errorf("failed to detach network %q", ep.name)
+				return err
+			}
+		}
 
 		// opts.cni.Remove has trouble removing network configurations when netns is empty.
 		// Therefore, we force the deletion of iptables rules here to prevent netns exhaustion.
 		// This is a workaround until https://github.com/containernetworking/plugins/pull/1078 is merged.
-		if err := cleanupIptablesRules(opts.fullID, opts.cniNames); err != nil {
+		if err := cleanupIptablesRules(opts.fullID, opts.networkNames()); err != nil {
 			log.L.WithError(err).Warnf("failed to clean up iptables rules for container %s", opts.fullID)
 			// Don't return error here, continue with the rest of the cleanup
 		}
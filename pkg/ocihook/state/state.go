@@ -58,6 +58,11 @@ type Store struct {
 	// StartedAt reflects the time at which we received the oci-hook onCreateRuntime event
 	StartedAt   time.Time `json:"started_at"`
 	CreateError bool      `json:"create_error"`
+	// Networks and NetworkEndpoints carry the labels.Networks and labels.NetworkEndpoints values
+	// of a running container after `nerdctl network connect` or `disconnect`, as the postStop hook
+	// otherwise only sees the annotations the task was started with.
+	Networks         string `json:"networks,omitempty"`
+	NetworkEndpoints string `json:"network_endpoints,omitempty"`
 }
 
 // Load will populate the struct with existing in-store lifecycle information
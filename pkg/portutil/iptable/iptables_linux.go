@@ -17,6 +17,7 @@
 package iptable
 
 import (
+	"os/exec"
 	"strings"
 
 	"github.com/coreos/go-iptables/iptables"
@@ -30,7 +31,12 @@ const cniDnatChain = "CNI-HOSTPORT-DNAT"
 // destination IP filtering (e.g. -d 192.168.1.141/32 --dport 80 -j DNAT).
 const cniDNChainPrefix = "CNI-DN-"
 
+// ReadIPTables returns the port forwarding rules of the iptables backend of the CNI portmap plugin.
+// It returns no rule if the iptables command does not exist, e.g., on hosts that only have nftables.
 func ReadIPTables(table string) ([]string, error) {
+	if _, err := exec.LookPath("iptables"); err != nil {
+		return nil, nil
+	}
 	ipt, err := iptables.New()
 	if err != nil {
 		return nil, err
@@ -0,0 +1,50 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package iptable
+
+import (
+	"regexp"
+	"strconv"
+)
+
+// ParseNFTablesRules takes a slice of nftables rules, as printed by `nft list`, and returns
+// a slice of PortRule containing the parsed destination IP and port from the rules.
+// When a rule has no "daddr" match, IP is empty (meaning the rule applies to all addresses).
+func ParseNFTablesRules(rules []string) []PortRule {
+	portRules := []PortRule{}
+
+	dportRegex := regexp.MustCompile(`\bdport (\d+)\b`)
+	destRegex := regexp.MustCompile(`\bip6? daddr (\S+?)(?:/\d+)?\s`)
+
+	for _, rule := range rules {
+		matches := dportRegex.FindStringSubmatch(rule)
+		if len(matches) < 2 {
+			continue
+		}
+		port64, err := strconv.ParseUint(matches[1], 10, 16)
+		if err != nil {
+			continue
+		}
+		var ip string
+		if destMatches := destRegex.FindStringSubmatch(rule); len(destMatches) > 1 {
+			ip = destMatches[1]
+		}
+		portRules = append(portRules, PortRule{IP: ip, Port: port64})
+	}
+
+	return portRules
+}
@@ -0,0 +1,60 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package iptable
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// Table and chain used for port forwarding rules by the nftables backend of the CNI portmap plugin.
+// The rules of the published ports are in the hostports chain, the other chains only dispatch to it.
+const (
+	cniNFTTable     = "cni_hostport"
+	cniNFTPortChain = "hostports"
+)
+
+// ReadNFTables returns the port forwarding rules of the nftables backend of the CNI portmap plugin,
+// for both IPv4 and IPv6. It returns no rule if the nft command or the table does not exist.
+func ReadNFTables() ([]string, error) {
+	nft, err := exec.LookPath("nft")
+	if err != nil {
+		return nil, nil
+	}
+	var rules []string
+	for _, family := range []string{"ip", "ip6"} {
+		var stdout, stderr bytes.Buffer
+		cmd := exec.Command(nft, "list", "chain", family, cniNFTTable, cniNFTPortChain)
+		cmd.Stdout = &stdout
+		cmd.Stderr = &stderr
+		if err := cmd.Run(); err != nil {
+			// Like ReadIPTables, a missing table or the lack of privileges (rootless) is not an error
+			if strings.Contains(stderr.String(), "No such file or directory") || strings.Contains(stderr.String(), "Operation not permitted") {
+				continue
+			}
+			return nil, fmt.Errorf("failed to list the nftables rules of %s %s: %w (stderr: %q)", family, cniNFTTable, err, stderr.String())
+		}
+		for _, line := range strings.Split(stdout.String(), "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				rules = append(rules, line)
+			}
+		}
+	}
+	return rules, nil
+}
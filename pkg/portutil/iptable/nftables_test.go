@@ -0,0 +1,79 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package iptable
+
+import (
+	"testing"
+)
+
+func TestParseNFTablesRules(t *testing.T) {
+	testCases := []struct {
+		name  string
+		rules []string
+		want  []PortRule
+	}{
+		{
+			name:  "Empty input",
+			rules: []string{},
+			want:  []PortRule{},
+		},
+		{
+			name: "Chain header lines are ignored",
+			rules: []string{
+				"table ip cni_hostport {",
+				"\tchain hostports {",
+				"\t}",
+				"}",
+			},
+			want: []PortRule{},
+		},
+		{
+			name: "Rule without destination IP",
+			rules: []string{
+				"\t\ttcp dport 8080 dnat ip to 10.4.0.2:80 comment \"some-id\"",
+			},
+			want: []PortRule{{IP: "", Port: 8080}},
+		},
+		{
+			name: "Rules with destination IPs",
+			rules: []string{
+				"\t\tip daddr 127.0.0.1 tcp dport 8080 dnat ip to 10.4.0.2:80 comment \"some-id\"",
+				"\t\tip daddr 192.168.1.141 udp dport 53 dnat ip to 10.4.0.3:53 comment \"other-id\"",
+			},
+			want: []PortRule{
+				{IP: "127.0.0.1", Port: 8080},
+				{IP: "192.168.1.141", Port: 53},
+			},
+		},
+		{
+			name: "IPv6 rule with prefix length",
+			rules: []string{
+				"\t\tip6 daddr fd00::1/128 tcp dport 443 dnat ip6 to [fd00:4::2]:443 comment \"some-id\"",
+			},
+			want: []PortRule{{IP: "fd00::1", Port: 443}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := ParseNFTablesRules(tc.rules)
+			if !equal(got, tc.want) {
+				t.Errorf("ParseNFTablesRules(%v) = %v; want %v", tc.rules, got, tc.want)
+			}
+		})
+	}
+}
@@ -52,7 +52,11 @@ func portAllocate(protocol string, ip string, count uint64) (uint64, uint64, err
 	if err != nil {
 		return 0, 0, err
 	}
+	return allocatePortRange(usedPorts, count)
+}
 
+// allocatePortRange returns the first range of count consecutive ports that are not in usedPorts.
+func allocatePortRange(usedPorts map[uint64]bool, count uint64) (uint64, uint64, error) {
 	start := allocateStart
 	if count > allocateEnd-allocateStart+1 {
 		return 0, 0, fmt.Errorf("can not allocate %d ports", count)
@@ -127,6 +131,12 @@ func getUsedPorts(ip string, protocol string) (map[uint64]bool, error) {
 		return nil, err
 	}
 	portRules := iptable.ParseIPTableRules(ipTableItems)
+	// The ports may be published by either firewall backend
+	nfTableItems, err := iptable.ReadNFTables()
+	if err != nil {
+		return nil, err
+	}
+	portRules = append(portRules, iptable.ParseNFTablesRules(nfTableItems)...)
 
 	requestedIP := net.ParseIP(ip)
 	requestedIsWildcard := ip == "" || requestedIP.IsUnspecified()
@@ -24,6 +24,10 @@ func portAllocate(protocol string, ip string, count uint64) (uint64, uint64, err
 	return 0, 0, fmt.Errorf("auto port allocate are not support Non-Linux platform yet")
 }
 
+func allocatePortRange(usedPorts map[uint64]bool, count uint64) (uint64, uint64, error) {
+	return 0, 0, fmt.Errorf("auto port allocate are not support Non-Linux platform yet")
+}
+
 func getUsedPorts(ip string, protocol string) (map[uint64]bool, error) {
 	return nil, nil
 }
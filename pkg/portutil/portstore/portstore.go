@@ -0,0 +1,210 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+// Package portstore is the registry of the host ports reserved by the containers.
+// The ports of a container are reserved when it is created, and released when it is removed,
+// so that they are not allocated to another container while it is stopped.
+// The reservations of the containers removed without nerdctl are dropped by the next reservation.
+package portstore
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/containerd/go-cni"
+
+	"github.com/containerd/nerdctl/v2/pkg/store"
+)
+
+const portsDirBaseName = "ports"
+
+// staleGracePeriod is the time during which a reservation is kept even if its container does not exist,
+// as a container is only created after its ports are reserved.
+const staleGracePeriod = 5 * time.Minute
+
+var ErrPortStore = errors.New("port-store error")
+
+// Reservation holds the host ports reserved by a container.
+type Reservation struct {
+	Namespace    string            `json:"namespace"`
+	ContainerID  string            `json:"containerID"`
+	PortMappings []cni.PortMapping `json:"portMappings"`
+}
+
+// New returns the registry of the host ports reserved by the containers of all the namespaces of dataStore.
+func New(dataStore string) (ps *PortStore, err error) {
+	defer func() {
+		if err != nil {
+			err = errors.Join(ErrPortStore, err)
+		}
+	}()
+
+	if dataStore == "" {
+		return nil, fmt.Errorf("dataStore is empty")
+	}
+
+	st, err := store.New(filepath.Join(dataStore, portsDirBaseName), 0, 0o600)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PortStore{
+		safeStore: st,
+	}, nil
+}
+
+type PortStore struct {
+	safeStore store.Store
+}
+
+// Reserve calls allocate with the reservations of the other containers, while holding the lock of the registry,
+// and reserves the port mappings it returns for the container, in place of its previous reservation.
+// The reservations of the other containers for which exists, if not nil, returns false are dropped beforehand,
+// unless they were made within staleGracePeriod.
+func (ps *PortStore) Reserve(namespace, id string, exists func(Reservation) bool, allocate func(others []Reservation) ([]cni.PortMapping, error)) (ports []cni.PortMapping, err error) {
+	defer func() {
+		if err != nil {
+			err = errors.Join(ErrPortStore, err)
+		}
+	}()
+
+	err = ps.safeStore.WithLock(func() error {
+		reservations, err := ps.list()
+		if err != nil {
+			return err
+		}
+		var others []Reservation
+		for _, r := range reservations {
+			if r.Namespace == namespace && r.ContainerID == id {
+				continue
+			}
+			if exists != nil && !exists(r) {
+				stale, err := ps.stale(r)
+				if err != nil {
+					return err
+				}
+				if stale {
+					if err := ps.delete(r.Namespace, r.ContainerID); err != nil {
+						return err
+					}
+					continue
+				}
+			}
+			others = append(others, r)
+		}
+
+		ports, err = allocate(others)
+		if err != nil {
+			return err
+		}
+		if len(ports) == 0 {
+			return ps.delete(namespace, id)
+		}
+
+		data, err := json.Marshal(Reservation{
+			Namespace:    namespace,
+			ContainerID:  id,
+			PortMappings: ports,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to marshal port reservation to JSON: %w", err)
+		}
+		return ps.safeStore.Set(data, namespace, id)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ports, nil
+}
+
+// Release removes the reservation of the container, if any.
+func (ps *PortStore) Release(namespace, id string) (err error) {
+	defer func() {
+		if err != nil {
+			err = errors.Join(ErrPortStore, err)
+		}
+	}()
+
+	return ps.safeStore.WithLock(func() error {
+		return ps.delete(namespace, id)
+	})
+}
+
+// List returns the reservations of all the containers.
+func (ps *PortStore) List() (reservations []Reservation, err error) {
+	defer func() {
+		if err != nil {
+			err = errors.Join(ErrPortStore, err)
+		}
+	}()
+
+	err = ps.safeStore.WithLock(func() error {
+		reservations, err = ps.list()
+		return err
+	})
+	return reservations, err
+}
+
+func (ps *PortStore) list() ([]Reservation, error) {
+	namespaces, err := ps.safeStore.List()
+	if err != nil {
+		return nil, err
+	}
+
+	var reservations []Reservation
+	for _, namespace := range namespaces {
+		ids, err := ps.safeStore.List(namespace)
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range ids {
+			data, err := ps.safeStore.Get(namespace, id)
+			if err != nil {
+				return nil, err
+			}
+			var r Reservation
+			if err := json.Unmarshal(data, &r); err != nil {
+				return nil, fmt.Errorf("failed to parse port reservation %q: %w", filepath.Join(namespace, id), err)
+			}
+			reservations = append(reservations, r)
+		}
+	}
+	return reservations, nil
+}
+
+// stale checks whether a reservation is older than staleGracePeriod.
+func (ps *PortStore) stale(r Reservation) (bool, error) {
+	p, err := ps.safeStore.Location(r.Namespace, r.ContainerID)
+	if err != nil {
+		return false, err
+	}
+	st, err := os.Stat(p)
+	if err != nil {
+		return false, err
+	}
+	return time.Since(st.ModTime()) > staleGracePeriod, nil
+}
+
+func (ps *PortStore) delete(namespace, id string) error {
+	if err := ps.safeStore.Delete(namespace, id); err != nil && !errors.Is(err, store.ErrNotFound) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package portstore
+
+import (
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/containerd/go-cni"
+)
+
+func TestPortStoreReserve(t *testing.T) {
+	ps, err := New(t.TempDir())
+	assert.NilError(t, err)
+
+	ports := []cni.PortMapping{{HostPort: 8080, ContainerPort: 80, Protocol: "tcp", HostIP: "0.0.0.0"}}
+	got, err := ps.Reserve("default", "c1", nil, func(others []Reservation) ([]cni.PortMapping, error) {
+		assert.Equal(t, len(others), 0)
+		return ports, nil
+	})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, got, ports)
+
+	// The reservations of the other containers, of all the namespaces, are passed to allocate
+	_, err = ps.Reserve("other", "c2", nil, func(others []Reservation) ([]cni.PortMapping, error) {
+		assert.DeepEqual(t, others, []Reservation{{Namespace: "default", ContainerID: "c1", PortMappings: ports}})
+		return []cni.PortMapping{{HostPort: 8081, ContainerPort: 80, Protocol: "tcp", HostIP: "0.0.0.0"}}, nil
+	})
+	assert.NilError(t, err)
+
+	// The reservation of the container is replaced
+	_, err = ps.Reserve("default", "c1", nil, func(others []Reservation) ([]cni.PortMapping, error) {
+		assert.Equal(t, len(others), 1)
+		assert.Equal(t, others[0].ContainerID, "c2")
+		return []cni.PortMapping{{HostPort: 8082, ContainerPort: 80, Protocol: "tcp", HostIP: "0.0.0.0"}}, nil
+	})
+	assert.NilError(t, err)
+
+	// A failed allocation keeps the previous reservation
+	errConflict := errors.New("conflict")
+	_, err = ps.Reserve("default", "c1", nil, func(others []Reservation) ([]cni.PortMapping, error) {
+		return nil, errConflict
+	})
+	assert.ErrorIs(t, err, errConflict)
+	assert.ErrorIs(t, err, ErrPortStore)
+
+	reservations, err := ps.List()
+	assert.NilError(t, err)
+	assert.Equal(t, len(reservations), 2)
+	assert.DeepEqual(t, reservations[0].PortMappings, []cni.PortMapping{{HostPort: 8082, ContainerPort: 80, Protocol: "tcp", HostIP: "0.0.0.0"}})
+}
+
+func TestPortStoreReserveStale(t *testing.T) {
+	ps, err := New(t.TempDir())
+	assert.NilError(t, err)
+
+	ports := []cni.PortMapping{{HostPort: 8080, ContainerPort: 80, Protocol: "tcp", HostIP: "0.0.0.0"}}
+	for _, id := range []string{"c1", "c2"} {
+		_, err = ps.Reserve("default", id, nil, func(others []Reservation) ([]cni.PortMapping, error) {
+			return ports, nil
+		})
+		assert.NilError(t, err)
+	}
+	// c1 was reserved long ago, c2 is being created
+	p, err := ps.safeStore.Location("default", "c1")
+	assert.NilError(t, err)
+	old := time.Now().Add(-2 * staleGracePeriod)
+	assert.NilError(t, os.Chtimes(p, old, old))
+
+	// The reservations of the containers that do not exist are dropped, unless they are recent
+	_, err = ps.Reserve("default", "c3", func(Reservation) bool { return false }, func(others []Reservation) ([]cni.PortMapping, error) {
+		assert.DeepEqual(t, others, []Reservation{{Namespace: "default", ContainerID: "c2", PortMappings: ports}})
+		return nil, nil
+	})
+	assert.NilError(t, err)
+	reservations, err := ps.List()
+	assert.NilError(t, err)
+	assert.Equal(t, len(reservations), 1)
+	assert.Equal(t, reservations[0].ContainerID, "c2")
+}
+
+func TestPortStoreRelease(t *testing.T) {
+	ps, err := New(t.TempDir())
+	assert.NilError(t, err)
+
+	_, err = ps.Reserve("default", "c1", nil, func(others []Reservation) ([]cni.PortMapping, error) {
+		return []cni.PortMapping{{HostPort: 8080, ContainerPort: 80, Protocol: "tcp", HostIP: "0.0.0.0"}}, nil
+	})
+	assert.NilError(t, err)
+
+	assert.NilError(t, ps.Release("default", "c1"))
+	reservations, err := ps.List()
+	assert.NilError(t, err)
+	assert.Equal(t, len(reservations), 0)
+
+	// Releasing a container without reservation is not an error
+	assert.NilError(t, ps.Release("default", "c1"))
+	assert.NilError(t, ps.Release("default", "c2"))
+}
+
+func TestPortStoreNew(t *testing.T) {
+	_, err := New("")
+	assert.ErrorIs(t, err, ErrPortStore)
+}
@@ -17,18 +17,24 @@
 package portutil
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net"
+	"slices"
 	"strings"
 
 	"github.com/docker/go-connections/nat"
 
+	containerd "github.com/containerd/containerd/v2/client"
+	"github.com/containerd/containerd/v2/pkg/namespaces"
+	"github.com/containerd/errdefs"
 	"github.com/containerd/go-cni"
 	"github.com/containerd/log"
 
 	"github.com/containerd/nerdctl/v2/pkg/labels"
 	"github.com/containerd/nerdctl/v2/pkg/netutil/networkstore"
+	"github.com/containerd/nerdctl/v2/pkg/portutil/portstore"
 	"github.com/containerd/nerdctl/v2/pkg/rootlessutil"
 )
 
@@ -52,6 +58,16 @@ func splitParts(rawport string) (string, string, string) {
 // ParseFlagP parse port mapping pair, like "127.0.0.1:3000:8080/tcp",
 // "127.0.0.1:3000-3001:8080-8081/tcp" and "3000:8080" ...
 func ParseFlagP(s string) ([]cni.PortMapping, error) {
+	return parseFlagP(s, true)
+}
+
+// ParseFlagPWithoutAllocation is like ParseFlagP, but leaves the host ports that are not specified to 0,
+// so that they are allocated by ReservePorts when the container is created.
+func ParseFlagPWithoutAllocation(s string) ([]cni.PortMapping, error) {
+	return parseFlagP(s, false)
+}
+
+func parseFlagP(s string, allocate bool) ([]cni.PortMapping, error) {
 	proto := "tcp"
 	splitBySlash := strings.Split(s, "/")
 	switch len(splitBySlash) {
@@ -103,11 +119,13 @@ func ParseFlagP(s string) ([]cni.PortMapping, error) {
 			return nil, fmt.Errorf("automatic port allocation is not implemented for rootless mode (Hint: specify the port like \"12345:%s\", not just \"%s\")",
 				containerPort, containerPort)
 		}
-		startHostPort, endHostPort, err = portAllocate(proto, ip, endPort-startPort+1)
-		if err != nil {
-			return nil, err
+		if allocate {
+			startHostPort, endHostPort, err = portAllocate(proto, ip, endPort-startPort+1)
+			if err != nil {
+				return nil, err
+			}
+			log.L.Debugf("There is no hostPort has been spec in command, the auto allocate port is from %d:%d to %d:%d", startHostPort, startPort, endHostPort, endPort)
 		}
-		log.L.Debugf("There is no hostPort has been spec in command, the auto allocate port is from %d:%d to %d:%d", startHostPort, startPort, endHostPort, endPort)
 	} else {
 		startHostPort, endHostPort, err = nat.ParsePortRange(hostPort)
 		if err != nil {
@@ -153,7 +171,9 @@ func ParseFlagP(s string) ([]cni.PortMapping, error) {
 	for i := int32(0); i <= (int32(endPort) - int32(startPort)); i++ {
 
 		res.ContainerPort = int32(startPort) + i
-		res.HostPort = int32(startHostPort) + i
+		if startHostPort != 0 {
+			res.HostPort = int32(startHostPort) + i
+		}
 		res.HostIP = hostIP
 
 		mr = append(mr, res)
@@ -170,6 +190,116 @@ func StoreNetworkConfig(dataStore, namespace, id string, netConf networkstore.Ne
 	return ns.Acquire(netConf)
 }
 
+// ReservePorts reserves the host ports of the port mappings of a container in the port registry of dataStore,
+// until the container is removed and ReleasePorts is called, so that they are kept across restarts.
+// The host ports that are 0 are allocated, consecutive port mappings getting consecutive host ports,
+// and the port mappings with all their host ports set are returned.
+// It fails when a host port is used on the host or reserved by another container, even a stopped one.
+// The reservations of the containers that no longer exist in containerd, e.g., removed with ctr, are dropped.
+func ReservePorts(ctx context.Context, client *containerd.Client, dataStore, namespace, id string, ports []cni.PortMapping) ([]cni.PortMapping, error) {
+	ps, err := portstore.New(dataStore)
+	if err != nil {
+		return nil, err
+	}
+	exists := func(r portstore.Reservation) bool {
+		_, err := client.ContainerService().Get(namespaces.WithNamespace(ctx, r.Namespace), r.ContainerID)
+		if err != nil && !errdefs.IsNotFound(err) {
+			log.G(ctx).WithError(err).Warnf("failed to check whether container %q of the port reservation exists", r.ContainerID)
+			return true
+		}
+		return err == nil
+	}
+	return ps.Reserve(namespace, id, exists, func(others []portstore.Reservation) ([]cni.PortMapping, error) {
+		return allocatePorts(ports, others)
+	})
+}
+
+// ReleasePorts releases the host ports reserved by a container.
+func ReleasePorts(dataStore, namespace, id string) error {
+	ps, err := portstore.New(dataStore)
+	if err != nil {
+		return err
+	}
+	return ps.Release(namespace, id)
+}
+
+func allocatePorts(ports []cni.PortMapping, others []portstore.Reservation) ([]cni.PortMapping, error) {
+	res := slices.Clone(ports)
+	for i := 0; i < len(res); {
+		// The port mappings with consecutive container ports are allocated together
+		j := i + 1
+		if res[i].HostPort == 0 {
+			for j < len(res) && res[j].HostPort == 0 && res[j].Protocol == res[i].Protocol &&
+				res[j].HostIP == res[i].HostIP && res[j].ContainerPort == res[j-1].ContainerPort+1 {
+				j++
+			}
+		}
+
+		ip := res[i].HostIP
+		if isWildcardIP(ip) {
+			ip = ""
+		}
+		usedPorts, err := getUsedPorts(ip, res[i].Protocol)
+		if err != nil {
+			return nil, err
+		}
+		reservedBy := make(map[uint64]string)
+		for _, r := range others {
+			for _, p := range r.PortMappings {
+				if p.Protocol == res[i].Protocol && hostIPsOverlap(p.HostIP, res[i].HostIP) {
+					reservedBy[uint64(p.HostPort)] = r.ContainerID
+				}
+			}
+		}
+
+		if res[i].HostPort != 0 {
+			hostPort := uint64(res[i].HostPort)
+			if id, ok := reservedBy[hostPort]; ok {
+				return nil, fmt.Errorf("bind for %s:%d failed: port is already reserved by container %s", res[i].HostIP, hostPort, id[:min(12, len(id))])
+			}
+			if usedPorts[hostPort] {
+				return nil, fmt.Errorf("bind for %s:%d failed: port is already allocated", res[i].HostIP, hostPort)
+			}
+			i = j
+			continue
+		}
+
+		// The ports of the other containers, and the ones of this container, cannot be allocated
+		if usedPorts == nil {
+			usedPorts = make(map[uint64]bool)
+		}
+		for p := range reservedBy {
+			usedPorts[p] = true
+		}
+		for _, p := range res {
+			if p.HostPort != 0 && p.Protocol == res[i].Protocol && hostIPsOverlap(p.HostIP, res[i].HostIP) {
+				usedPorts[uint64(p.HostPort)] = true
+			}
+		}
+		start, _, err := allocatePortRange(usedPorts, uint64(j-i))
+		if err != nil {
+			return nil, err
+		}
+		for k := i; k < j; k++ {
+			res[k].HostPort = int32(start) + int32(k-i)
+		}
+		log.L.Debugf("allocated host ports %d-%d to container ports %d-%d", start, res[j-1].HostPort, res[i].ContainerPort, res[j-1].ContainerPort)
+		i = j
+	}
+	return res, nil
+}
+
+func isWildcardIP(ip string) bool {
+	return ip == "" || net.ParseIP(ip).IsUnspecified()
+}
+
+func hostIPsOverlap(a, b string) bool {
+	if isWildcardIP(a) || isWildcardIP(b) {
+		return true
+	}
+	return net.ParseIP(a).Equal(net.ParseIP(b))
+}
+
 func LoadPortMappings(dataStore, namespace, id string, containerLabels map[string]string) ([]cni.PortMapping, error) {
 	var ports []cni.PortMapping
 
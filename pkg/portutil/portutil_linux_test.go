@@ -22,6 +22,10 @@ import (
 	"testing"
 
 	"gotest.tools/v3/assert"
+
+	"github.com/containerd/go-cni"
+
+	"github.com/containerd/nerdctl/v2/pkg/portutil/portstore"
 )
 
 // TestParseFlagPHostRangePool verifies the Docker-compatible behavior for a single
@@ -71,3 +75,50 @@ func TestParseFlagPHostRangePool(t *testing.T) {
 	assert.Equal(t, got[0].HostIP, "127.0.0.1")
 	assert.Equal(t, got[0].HostPort, int32(first+1))
 }
+
+func TestAllocatePorts(t *testing.T) {
+	others := []portstore.Reservation{
+		{
+			Namespace:   "default",
+			ContainerID: "0123456789abcdef",
+			PortMappings: []cni.PortMapping{
+				{HostPort: 33001, ContainerPort: 80, Protocol: "tcp", HostIP: "127.0.0.1"},
+				{HostPort: int32(allocateStart), ContainerPort: 80, Protocol: "tcp", HostIP: "0.0.0.0"},
+				{HostPort: int32(allocateStart) + 1, ContainerPort: 81, Protocol: "tcp", HostIP: "0.0.0.0"},
+			},
+		},
+	}
+
+	t.Run("a host port reserved by another container is rejected", func(t *testing.T) {
+		_, err := allocatePorts([]cni.PortMapping{{HostPort: 33001, ContainerPort: 80, Protocol: "tcp", HostIP: "0.0.0.0"}}, others)
+		assert.ErrorContains(t, err, "bind for 0.0.0.0:33001 failed: port is already reserved by container 0123456789ab")
+	})
+
+	t.Run("a host port reserved on another IP or for another protocol is accepted", func(t *testing.T) {
+		ports := []cni.PortMapping{
+			{HostPort: 33001, ContainerPort: 80, Protocol: "tcp", HostIP: "127.0.0.2"},
+			{HostPort: 33001, ContainerPort: 80, Protocol: "udp", HostIP: "0.0.0.0"},
+		}
+		got, err := allocatePorts(ports, others)
+		assert.NilError(t, err)
+		assert.DeepEqual(t, got, ports)
+	})
+
+	t.Run("the host ports are allocated outside of the reserved ones", func(t *testing.T) {
+		ports := []cni.PortMapping{
+			{ContainerPort: 80, Protocol: "tcp", HostIP: "0.0.0.0"},
+			{ContainerPort: 81, Protocol: "tcp", HostIP: "0.0.0.0"},
+			{HostPort: int32(allocateStart) + 2, ContainerPort: 82, Protocol: "tcp", HostIP: "0.0.0.0"},
+		}
+		got, err := allocatePorts(ports, others)
+		assert.NilError(t, err)
+		assert.Equal(t, len(got), 3)
+		assert.Equal(t, got[1].HostPort, got[0].HostPort+1)
+		for _, p := range got[:2] {
+			assert.Assert(t, p.HostPort != others[0].PortMappings[1].HostPort && p.HostPort != others[0].PortMappings[2].HostPort)
+			assert.Assert(t, p.HostPort != got[2].HostPort)
+		}
+		// The ports are not modified
+		assert.Equal(t, ports[0].HostPort, int32(0))
+	})
+}
@@ -359,3 +359,22 @@ func TestParseFlagP(t *testing.T) {
 		})
 	}
 }
+
+func TestParseFlagPWithoutAllocation(t *testing.T) {
+	if rootlessutil.IsRootless() {
+		t.Skip("auto host port is not supported in rootless mode")
+	}
+	got, err := ParseFlagPWithoutAllocation("127.0.0.1::3000-3001/udp")
+	assert.NilError(t, err)
+	assert.DeepEqual(t, got, []cni.PortMapping{
+		{ContainerPort: 3000, Protocol: "udp", HostIP: "127.0.0.1"},
+		{ContainerPort: 3001, Protocol: "udp", HostIP: "127.0.0.1"},
+	})
+
+	got, err = ParseFlagPWithoutAllocation("3000")
+	assert.NilError(t, err)
+	assert.DeepEqual(t, got, []cni.PortMapping{{ContainerPort: 3000, Protocol: "tcp", HostIP: "0.0.0.0"}})
+
+	_, err = ParseFlagPWithoutAllocation("3000/foo")
+	assert.ErrorContains(t, err, "invalid protocol")
+}
@@ -0,0 +1,64 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package tarutil
+
+import (
+	"archive/tar"
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestCreateAndExtractDir(t *testing.T) {
+	src := t.TempDir()
+	assert.NilError(t, os.WriteFile(filepath.Join(src, "oci-layout"), []byte(`{"imageLayoutVersion":"1.0.0"}`), 0o644))
+	assert.NilError(t, os.MkdirAll(filepath.Join(src, "blobs", "sha256"), 0o755))
+	assert.NilError(t, os.WriteFile(filepath.Join(src, "blobs", "sha256", "abc"), []byte("blob"), 0o644))
+
+	var buf bytes.Buffer
+	assert.NilError(t, CreateDir(&buf, src))
+
+	dst := filepath.Join(t.TempDir(), "out")
+	assert.NilError(t, ExtractDir(&buf, dst))
+
+	b, err := os.ReadFile(filepath.Join(dst, "oci-layout"))
+	assert.NilError(t, err)
+	assert.Equal(t, string(b), `{"imageLayoutVersion":"1.0.0"}`)
+	b, err = os.ReadFile(filepath.Join(dst, "blobs", "sha256", "abc"))
+	assert.NilError(t, err)
+	assert.Equal(t, string(b), "blob")
+}
+
+func TestExtractDirRejectsUnsafeEntries(t *testing.T) {
+	testCases := map[string]tar.Header{
+		"parent":   {Name: "../escape", Typeflag: tar.TypeReg},
+		"absolute": {Name: "/etc/escape", Typeflag: tar.TypeReg},
+		"symlink":  {Name: "link", Typeflag: tar.TypeSymlink, Linkname: "/etc"},
+	}
+	for name, hdr := range testCases {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+			tw := tar.NewWriter(&buf)
+			assert.NilError(t, tw.WriteHeader(&hdr))
+			assert.NilError(t, tw.Close())
+			assert.Assert(t, ExtractDir(&buf, t.TempDir()) != nil)
+		})
+	}
+}